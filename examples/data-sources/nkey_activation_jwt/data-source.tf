@@ -0,0 +1,21 @@
+variable "partner_activation" {
+  type        = string
+  description = "Activation JWT sent by the partner for its orders stream"
+}
+
+variable "partner_account" {
+  type = string
+}
+
+variable "shop_account" {
+  type = string
+}
+
+# Fail the plan when the token is forged, expired or meant for another
+# account or subject
+data "nkey_activation_jwt" "partner_orders" {
+  jwt                     = var.partner_activation
+  expected_issuer_account = var.partner_account
+  expected_importer       = var.shop_account
+  expected_subject        = "partner.orders.>"
+}
@@ -0,0 +1,39 @@
+resource "nkey_keypair" "orders" {
+  type = "user"
+}
+
+resource "nkey_keypair" "monitoring" {
+  type = "user"
+}
+
+data "nkey_authorization" "main" {
+  # Users without permissions of their own
+  default_permissions = {
+    subscribe = {
+      allow = ["_INBOX.>", "metrics.>"]
+    }
+  }
+
+  users = [
+    {
+      public_key = nkey_keypair.orders.public_key
+      permissions = {
+        publish = {
+          allow = ["orders.>"]
+          deny  = ["orders.admin.>"]
+        }
+        subscribe = {
+          allow = ["_INBOX.>", "orders.created workers"]
+        }
+      }
+    },
+    {
+      public_key = nkey_keypair.monitoring.public_key
+    },
+  ]
+}
+
+resource "local_file" "authorization" {
+  filename = "${path.module}/nats-server/authorization.conf"
+  content  = data.nkey_authorization.main.config
+}
@@ -0,0 +1,14 @@
+variable "account_seed" {
+  type      = string
+  sensitive = true
+}
+
+variable "account_public_key" {
+  type = string
+}
+
+# Fail the plan when the stored seed no longer matches the account key
+data "nkey_check" "account" {
+  seed                = var.account_seed
+  expected_public_key = var.account_public_key
+}
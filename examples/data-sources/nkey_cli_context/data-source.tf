@@ -0,0 +1,23 @@
+resource "nkey_user" "alice" {
+  signing_seed = var.account_seed
+  name         = "alice"
+}
+
+# A context reading the creds file of alice from her machine
+data "nkey_cli_context" "alice" {
+  url         = "tls://nats.example.com:4222"
+  description = "Orders production"
+  creds_path  = "~/.nats/alice.creds"
+  tls_ca      = "~/.nats/ca.pem"
+}
+
+# A context carrying the JWT and seed inline, so a single file is handed over
+data "nkey_cli_context" "alice_inline" {
+  url  = "tls://nats.example.com:4222"
+  jwt  = nkey_user.alice.jwt
+  seed = nkey_user.alice.seed
+}
+
+output "alice_context" {
+  value = nonsensitive(data.nkey_cli_context.alice.json)
+}
@@ -0,0 +1,13 @@
+# Creds dropped on the runner by the identity system
+data "nkey_creds_file" "ci" {
+  path     = pathexpand("~/.nats/ci.creds")
+  validate = true
+}
+
+# Issue the user again with a fresh expiry, keeping its nkey
+resource "nkey_user" "ci" {
+  seed         = data.nkey_creds_file.ci.seed
+  signing_seed = var.account_seed
+  name         = "ci"
+  expires_in   = "720h"
+}
@@ -0,0 +1,19 @@
+resource "nkey_user" "worker" {
+  signing_seed = var.account_seed
+  name         = "worker"
+}
+
+# Environment of the worker containers
+data "nkey_env_file" "worker" {
+  url  = "nats://nats.example.com:4222"
+  jwt  = nkey_user.worker.jwt
+  seed = nkey_user.worker.seed
+}
+
+# The same variables for the EnvironmentFile of a systemd unit
+data "nkey_env_file" "worker_unit" {
+  url    = "nats://nats.example.com:4222"
+  jwt    = nkey_user.worker.jwt
+  seed   = nkey_user.worker.seed
+  format = "systemd"
+}
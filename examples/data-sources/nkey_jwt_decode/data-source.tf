@@ -0,0 +1,12 @@
+variable "user_jwt" {
+  type = string
+}
+
+data "nkey_jwt_decode" "user" {
+  jwt    = var.user_jwt
+  verify = true
+}
+
+output "user_permissions" {
+  value = jsondecode(data.nkey_jwt_decode.user.claims_json).nats.pub
+}
@@ -0,0 +1,30 @@
+variable "operator_jwt" {
+  type = string
+}
+
+variable "account_jwt" {
+  type = string
+}
+
+variable "user_jwt" {
+  type = string
+}
+
+# Fail the plan when nats-server would reject the user, e.g. because it is
+# signed by a key the account no longer lists or the account revoked it
+data "nkey_jwt_validate" "chain" {
+  user_jwt     = var.user_jwt
+  account_jwt  = var.account_jwt
+  operator_jwt = var.operator_jwt
+}
+
+# Only report the outcome of a partial check
+data "nkey_jwt_validate" "account" {
+  account_jwt     = var.account_jwt
+  operator_jwt    = var.operator_jwt
+  fail_on_invalid = false
+}
+
+output "account_problems" {
+  value = data.nkey_jwt_validate.account.problems
+}
@@ -0,0 +1,4 @@
+# Operator signing key managed by nsc on the runner
+data "nkey_keystore_seed" "operator_signing" {
+  public_key = "OCY3XQ2SFNQWG5H7TG3AXCTBFV3HXXM6MKBCH6YXNV7W2ZWUTLK4J6QD"
+}
@@ -0,0 +1,17 @@
+resource "nkey_user" "orders" {
+  signing_seed = var.account_seed
+  name         = "orders"
+}
+
+# Mount the creds in the pods of the orders service
+data "nkey_kubernetes_secret" "orders" {
+  name      = "orders-nats-creds"
+  namespace = "orders"
+  data = {
+    "user.creds" = nkey_user.orders.creds
+  }
+}
+
+resource "kubernetes_manifest" "orders_creds" {
+  manifest = yamldecode(data.nkey_kubernetes_secret.orders.yaml)
+}
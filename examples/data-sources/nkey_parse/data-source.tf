@@ -0,0 +1,18 @@
+variable "signing_keys" {
+  type = list(string)
+}
+
+data "nkey_parse" "signing_key" {
+  for_each = toset(var.signing_keys)
+
+  key    = each.value
+  strict = true
+}
+
+# Only account public keys may be used as signing keys
+check "signing_keys_are_account_public_keys" {
+  assert {
+    condition     = alltrue([for k in data.nkey_parse.signing_key : k.kind == "public" && k.type == "account"])
+    error_message = "All signing keys must be account public keys."
+  }
+}
@@ -0,0 +1,8 @@
+variable "account_seed" {
+  type      = string
+  sensitive = true
+}
+
+data "nkey_public_key" "account" {
+  seed = var.account_seed
+}
@@ -0,0 +1,27 @@
+resource "nkey_operator_bootstrap" "main" {
+  name = "main"
+}
+
+resource "nkey_keypair" "orders" {
+  type = "account"
+}
+
+resource "nkey_account_jwt" "orders" {
+  subject      = nkey_keypair.orders.public_key
+  signing_seed = nkey_operator_bootstrap.main.operator_seed
+  name         = "orders"
+}
+
+# The system account defaults to the one the operator JWT names
+data "nkey_resolver_preload" "main" {
+  operator_jwt = nkey_operator_bootstrap.main.operator_jwt
+  account_jwts = [
+    nkey_operator_bootstrap.main.system_account_jwt,
+    nkey_account_jwt.orders.jwt,
+  ]
+}
+
+resource "local_file" "resolver" {
+  filename = "${path.module}/nats-server/resolver.conf"
+  content  = data.nkey_resolver_preload.main.config
+}
@@ -0,0 +1,9 @@
+variable "officer_shares" {
+  type      = list(string)
+  sensitive = true
+}
+
+# Recover the operator seed from the shares of two officers
+data "nkey_seed_from_shares" "operator" {
+  shares = var.officer_shares
+}
@@ -0,0 +1,12 @@
+# Decrypt a secret another workspace sealed to the xkey of this one
+data "nkey_xkey_open" "bootstrap" {
+  ciphertext_base64 = var.sealed_token
+  recipient_seed    = nkey_keypair.workspace.seed
+  sender_public_key = var.sender_xkey
+}
+
+# Open creds sealed by seal_to_xkey, whose envelope names the sender
+data "nkey_xkey_open" "creds" {
+  ciphertext_base64 = var.creds_sealed
+  recipient_seed    = nkey_keypair.workspace.seed
+}
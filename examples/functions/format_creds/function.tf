@@ -0,0 +1,21 @@
+resource "nkey_keyset" "users" {
+  type  = "user"
+  names = ["alice", "bob"]
+}
+
+resource "nkey_user_jwt" "users" {
+  for_each = nkey_keyset.users.public_keys
+
+  subject      = each.value
+  signing_seed = var.account_seed
+  name         = each.key
+}
+
+# Write one creds file per user. The creds contain the seed, so they are only
+# written to sensitive files
+resource "local_sensitive_file" "creds" {
+  for_each = nkey_user_jwt.users
+
+  filename = "${path.module}/creds/${each.key}.creds"
+  content  = provider::nkey::format_creds(each.value.jwt, nkey_keyset.users.keys[each.key].seed)
+}
@@ -0,0 +1,8 @@
+variable "account_public_key" {
+  type = string
+
+  validation {
+    condition     = provider::nkey::is_valid_public_key(var.account_public_key, "account")
+    error_message = "The account_public_key must be an account public key starting with A, not a seed or a key of another type."
+  }
+}
@@ -0,0 +1,8 @@
+variable "account_jwts" {
+  type = map(string)
+}
+
+# Expiry of each account JWT for a dashboard, null for those that never expire
+output "account_expiry" {
+  value = { for name, token in var.account_jwts : name => provider::nkey::jwt_expires_at(token) }
+}
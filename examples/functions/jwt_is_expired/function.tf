@@ -0,0 +1,14 @@
+variable "partner_activation" {
+  type = string
+}
+
+resource "terraform_data" "partner_import" {
+  input = var.partner_activation
+
+  lifecycle {
+    precondition {
+      condition     = !provider::nkey::jwt_is_expired(var.partner_activation)
+      error_message = "The activation JWT of the partner has expired, ask them for a new one."
+    }
+  }
+}
@@ -0,0 +1,14 @@
+variable "account_jwt" {
+  type = string
+}
+
+variable "operator_signing_key" {
+  type = string
+}
+
+check "account_signer" {
+  assert {
+    condition     = provider::nkey::jwt_issuer(var.account_jwt) == var.operator_signing_key
+    error_message = "The account JWT is not signed by the operator signing key."
+  }
+}
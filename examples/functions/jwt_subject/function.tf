@@ -0,0 +1,12 @@
+variable "account_jwts" {
+  type = list(string)
+}
+
+# Write each account JWT where the resolver of nats-server looks it up, keyed
+# by the account public key
+resource "local_file" "resolver" {
+  for_each = { for token in var.account_jwts : provider::nkey::jwt_subject(token) => token }
+
+  filename = "${path.module}/jwt/${each.key}.jwt"
+  content  = each.value
+}
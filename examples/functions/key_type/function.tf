@@ -0,0 +1,5 @@
+# Route trusted keys into the right nats-server stanza
+locals {
+  trusted_operators = [for k in var.trusted_keys : k if provider::nkey::key_type(k) == "operator"]
+  trusted_accounts  = [for k in var.trusted_keys : k if provider::nkey::key_type(k) == "account"]
+}
@@ -0,0 +1,6 @@
+# Decrypt a secret another workspace sealed to the xkey of this one. The result
+# is not sensitive unless wrapped in sensitive().
+output "bootstrap_token" {
+  value     = sensitive(provider::nkey::open_xkey(var.sealed_token, nkey_keypair.workspace.seed, var.sender_xkey))
+  sensitive = true
+}
@@ -0,0 +1,12 @@
+locals {
+  ci = provider::nkey::parse_creds(file("${path.module}/ci.creds"))
+}
+
+# Issue the user of an existing creds file again with a fresh expiry,
+# keeping its nkey
+resource "nkey_user" "ci" {
+  seed         = sensitive(local.ci.seed)
+  signing_seed = var.account_seed
+  name         = "ci"
+  expires_in   = "720h"
+}
@@ -0,0 +1,10 @@
+# Byte limits take numbers, so sizes show as stable byte counts in the plan
+resource "nkey_user_jwt" "uploader" {
+  subject      = nkey_keypair.uploader.public_key
+  signing_seed = nkey_keypair.account.seed
+
+  limits = {
+    max_data    = provider::nkey::parse_size("1GiB")
+    max_payload = provider::nkey::parse_size("8MB")
+  }
+}
@@ -0,0 +1,8 @@
+variable "user_seeds" {
+  type      = map(string)
+  sensitive = true
+}
+
+locals {
+  user_public_keys = { for name, seed in var.user_seeds : name => provider::nkey::public_from_seed(seed) }
+}
@@ -0,0 +1,5 @@
+# Sign the nonce from the INFO line of a nats-server to hand craft a CONNECT
+output "connect_sig" {
+  value     = provider::nkey::sign_nonce(nkey_keypair.user.seed, var.server_nonce)
+  sensitive = true
+}
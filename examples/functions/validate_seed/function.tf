@@ -0,0 +1,9 @@
+variable "account_seed" {
+  type      = string
+  sensitive = true
+
+  validation {
+    condition     = provider::nkey::validate_seed(var.account_seed).valid
+    error_message = "The account_seed is invalid: ${nonsensitive(provider::nkey::validate_seed(var.account_seed).reason)}."
+  }
+}
@@ -0,0 +1,11 @@
+# Refuse to deploy a manifest that was not signed by the operator
+resource "terraform_data" "manifest" {
+  input = var.manifest
+
+  lifecycle {
+    precondition {
+      condition     = provider::nkey::verify_signature(var.operator_public_key, base64encode(var.manifest), var.manifest_signature)
+      error_message = "The manifest was not signed by the operator."
+    }
+  }
+}
@@ -1,2 +1,4 @@
 provider "nkey" {
+  # Generate user keys wherever a resource omits type
+  default_key_type = "user"
 }
@@ -0,0 +1,30 @@
+resource "nkey_keypair" "operator" {
+  type = "operator"
+}
+
+resource "nkey_operator_jwt" "main" {
+  subject      = nkey_keypair.operator.public_key
+  signing_seed = nkey_keypair.operator.seed
+  name         = "main"
+}
+
+# Generates the account nkey and issues its JWT in one resource. Change
+# rotate_key to replace the nkey and issue the JWT again for the new one.
+resource "nkey_account" "shop" {
+  signing_seed = nkey_keypair.operator.seed
+  name         = "shop"
+  operator_jwt = nkey_operator_jwt.main.jwt
+  rotate_key   = "2026-10"
+
+  limits = {
+    max_connections = 100
+  }
+
+  exports = [
+    {
+      name    = "orders"
+      subject = "shop.orders.>"
+      type    = "stream"
+    },
+  ]
+}
@@ -0,0 +1,144 @@
+resource "nkey_keypair" "operator" {
+  type = "operator"
+}
+
+resource "nkey_keypair" "operator_signing" {
+  type = "operator"
+}
+
+resource "nkey_operator_jwt" "main" {
+  subject      = nkey_keypair.operator.public_key
+  signing_seed = nkey_keypair.operator.seed
+  name         = "main"
+  signing_keys = [nkey_keypair.operator_signing.public_key]
+
+  strict_signing_key_usage = true
+}
+
+resource "nkey_keypair" "billing" {
+  type = "account"
+}
+
+resource "nkey_keypair" "billing_signing" {
+  type = "account"
+}
+
+resource "nkey_keypair" "billing_frontend" {
+  type = "account"
+}
+
+# Sign with the operator signing key, checked against the operator JWT at
+# plan time.
+resource "nkey_account_jwt" "billing" {
+  subject      = nkey_keypair.billing.public_key
+  signing_seed = nkey_keypair.operator_signing.seed
+  name         = "billing"
+  description  = "Invoicing and payments"
+  info_url     = "https://wiki.example.com/billing"
+  tags         = ["team:payments"]
+  operator_jwt = nkey_operator_jwt.main.jwt
+  signing_keys = [nkey_keypair.billing_signing.public_key]
+
+  # Every user signed by the frontend key gets these permissions
+  scoped_signing_keys = {
+    (nkey_keypair.billing_frontend.public_key) = {
+      role = "frontend"
+      permissions = {
+        publish   = { allow = ["billing.charge"] }
+        subscribe = { allow = ["_INBOX.>"] }
+      }
+      allowed_connection_types = ["WEBSOCKET"]
+    }
+  }
+
+  exports = [
+    {
+      name    = "invoices"
+      subject = "billing.invoices.>"
+      type    = "stream"
+    },
+    {
+      name           = "charge"
+      subject        = "billing.charge"
+      type           = "service"
+      token_required = true
+      description    = "Charges a card"
+
+      response_threshold = "5s"
+      latency = {
+        sampling = "10"
+        results  = "billing.latency.charge"
+      }
+    },
+  ]
+}
+
+resource "nkey_keypair" "tenant" {
+  type = "account"
+}
+
+# Limits that are not set stay unlimited
+resource "nkey_account_jwt" "tenant" {
+  subject      = nkey_keypair.tenant.public_key
+  signing_seed = nkey_keypair.operator_signing.seed
+  name         = "tenant"
+  operator_jwt = nkey_operator_jwt.main.jwt
+
+  limits = {
+    max_connections   = 100
+    max_payload       = 1048576
+    max_subscriptions = 1000
+  }
+
+  jetstream = {
+    disk_storage = 1073741824
+    streams      = 10
+  }
+
+  # Canary 10% of the orders on the new service
+  mappings = {
+    "orders.*" = {
+      destinations = [
+        { destination = "orders.v1.$1", weight = 90 },
+        { destination = "orders.v2.$1", weight = 10 },
+      ]
+    }
+  }
+
+  # Trace a fifth of the messages marked as sampled
+  trace = {
+    destination = "tenant.trace"
+    sampling    = 20
+  }
+
+  # Users without permissions of their own only get to use their inbox
+  default_permissions = {
+    publish   = { allow = ["tenant.>"] }
+    subscribe = { allow = ["_INBOX.>", "tenant.jobs workers"] }
+    response  = { max = 1, ttl = "30s" }
+  }
+
+  imports = [
+    {
+      name          = "invoices"
+      account       = nkey_account_jwt.billing.subject
+      subject       = "billing.invoices.>"
+      type          = "stream"
+      local_subject = "invoices.>"
+    },
+  ]
+
+  # Claims without an attribute of their own, merged into the JWT
+  additional_claims_json = jsonencode({
+    nats = { cluster_traffic = "owner" }
+  })
+
+  # Reject every user JWT of the tenant issued before the start of 2026, and
+  # revoke a compromised user at the time of the apply. JWTs issued to the
+  # user after that are accepted again, so rotate its nkey rather than only
+  # issuing it a new JWT
+  revocations = {
+    "*"                                                        = "2026-01-01T00:00:00Z"
+    "UDXU4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF4" = "now"
+  }
+}
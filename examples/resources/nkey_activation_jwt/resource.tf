@@ -0,0 +1,62 @@
+resource "nkey_keypair" "operator" {
+  type = "operator"
+}
+
+resource "nkey_operator_jwt" "main" {
+  subject      = nkey_keypair.operator.public_key
+  signing_seed = nkey_keypair.operator.seed
+  name         = "main"
+}
+
+resource "nkey_keypair" "billing" {
+  type = "account"
+}
+
+resource "nkey_keypair" "tenant" {
+  type = "account"
+}
+
+resource "nkey_account_jwt" "billing" {
+  subject      = nkey_keypair.billing.public_key
+  signing_seed = nkey_keypair.operator.seed
+  name         = "billing"
+  operator_jwt = nkey_operator_jwt.main.jwt
+
+  exports = [
+    {
+      name           = "charge"
+      subject        = "billing.charge"
+      type           = "service"
+      token_required = true
+    },
+  ]
+}
+
+# Activates the charge service for the tenant, checked against the exports
+# of the billing account at plan time.
+resource "nkey_activation_jwt" "tenant_charge" {
+  signing_seed     = nkey_keypair.billing.seed
+  account_jwt      = nkey_account_jwt.billing.jwt
+  name             = "tenant charge"
+  export_subject   = "billing.charge"
+  export_type      = "service"
+  importer_account = nkey_keypair.tenant.public_key
+  expires_in       = "8760h"
+}
+
+resource "nkey_account_jwt" "tenant" {
+  subject      = nkey_keypair.tenant.public_key
+  signing_seed = nkey_keypair.operator.seed
+  name         = "tenant"
+  operator_jwt = nkey_operator_jwt.main.jwt
+
+  imports = [
+    {
+      name    = "charge"
+      account = nkey_keypair.billing.public_key
+      subject = "billing.charge"
+      type    = "service"
+      token   = nkey_activation_jwt.tenant_charge.jwt
+    },
+  ]
+}
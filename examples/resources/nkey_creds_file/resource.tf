@@ -0,0 +1,36 @@
+variable "target_xkey" {
+  type = string
+}
+
+resource "nkey_keypair" "account" {
+  type = "account"
+}
+
+resource "nkey_keypair" "alice" {
+  type = "user"
+}
+
+resource "nkey_user_jwt" "alice" {
+  subject      = nkey_keypair.alice.public_key
+  signing_seed = nkey_keypair.account.seed
+  name         = "alice"
+}
+
+# Only the hash of the creds is stored in state. A file that is removed or
+# changed on disk is written again by the next apply.
+resource "nkey_creds_file" "alice" {
+  path               = "${path.module}/creds/alice.creds"
+  jwt                = nkey_user_jwt.alice.jwt
+  seed               = nkey_keypair.alice.seed
+  create_directories = true
+}
+
+# Writes the creds sealed to the curve key of the target instead, so they
+# never reach the disk of the pipeline in plaintext.
+resource "nkey_creds_file" "alice_sealed" {
+  path         = "${path.module}/artifacts/alice.creds.sealed"
+  jwt          = nkey_user_jwt.alice.jwt
+  seed         = nkey_keypair.alice.seed
+  seal_to_xkey = var.target_xkey
+  seal_only    = true
+}
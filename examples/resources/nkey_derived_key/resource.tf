@@ -0,0 +1,14 @@
+variable "master_seed" {
+  type      = string
+  sensitive = true
+  ephemeral = true
+}
+
+# One user key per device, re-derivable from the master seed and the path
+resource "nkey_derived_key" "device" {
+  for_each = toset(["device-041", "device-042"])
+
+  type           = "user"
+  master_seed_wo = var.master_seed
+  path           = "tenants/acme/${each.key}"
+}
@@ -0,0 +1,28 @@
+variable "alice_jwt" {
+  description = "User JWT of alice, signed by the account nkey"
+  type        = string
+}
+
+variable "account_public_key" {
+  type = string
+}
+
+# The new signing key of the account, listed in its signing_keys
+resource "nkey_keypair" "account_signing" {
+  type = "account"
+}
+
+# Sign the user JWT again with the signing key, keeping its claims. The JWT
+# names the account as its issuer account, as nats-server requires.
+resource "nkey_jwt_resign" "alice" {
+  source_jwt         = var.alice_jwt
+  signing_seed       = nkey_keypair.account_signing.seed
+  new_issuer_account = var.account_public_key
+}
+
+output "alice_issuers" {
+  value = {
+    old = nkey_jwt_resign.alice.old_issuer
+    new = nkey_jwt_resign.alice.new_issuer
+  }
+}
@@ -0,0 +1,3 @@
+# An existing key pair can be imported using its seed. The key type is
+# detected from the seed prefix.
+terraform import nkey_keypair.operator SOALU7LPGJK2BDF7IHD7UZT6ZM23UMKYLGJLNN35QJSUI5BNR4DJRFH4R4
@@ -0,0 +1,50 @@
+resource "nkey_keypair" "operator" {
+  type = "operator"
+}
+
+# Keep the seed out of state by generating it ephemerally and handing it to
+# both the keypair and the secret store as write-only values.
+ephemeral "nkey_nkey" "account" {
+  type = "account"
+}
+
+resource "nkey_keypair" "account" {
+  seed_wo         = ephemeral.nkey_nkey.account.seed
+  seed_wo_version = 1
+}
+
+# Rotate the key whenever one of the keepers changes.
+resource "nkey_keypair" "user" {
+  type = "user"
+
+  keepers = {
+    rotation = "2024-q1"
+  }
+}
+
+# Generate an account key whose public key reads AACME...
+resource "nkey_keypair" "acme" {
+  type           = "account"
+  vanity_prefix  = "ACME"
+  vanity_timeout = "5m"
+}
+
+# Only the seed is needed by the NATS clients, so leave out the private key
+resource "nkey_keypair" "seed_only" {
+  type                = "user"
+  include_private_key = false
+}
+
+# Hand the seed to another team encrypted with a shared passphrase. They
+# decrypt it with the nkey_decrypted_seed ephemeral resource.
+variable "handover_passphrase" {
+  type      = string
+  ephemeral = true
+  sensitive = true
+}
+
+resource "nkey_keypair" "handover" {
+  type                             = "user"
+  encryption_passphrase_wo         = var.handover_passphrase
+  encryption_passphrase_wo_version = 1
+}
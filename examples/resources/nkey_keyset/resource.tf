@@ -0,0 +1,14 @@
+# One user key per service, adding a service only generates its key
+resource "nkey_keyset" "services" {
+  type  = "user"
+  names = ["billing", "orders", "shipping"]
+}
+
+output "billing_public_key" {
+  value = nkey_keyset.services.public_keys["billing"]
+}
+
+# Ten account keys keyed by index
+resource "nkey_keyset" "accounts" {
+  count_keys = 10
+}
@@ -0,0 +1,16 @@
+resource "nkey_nkey" "user" {
+  type = "user"
+}
+
+# Makes the key visible to the nsc CLI
+resource "nkey_keystore_entry" "user" {
+  keystore_root = "~/.nkeys"
+  seed          = nkey_nkey.user.seed
+
+  creds = {
+    operator = "acme"
+    account  = "orders"
+    user     = "orders-worker"
+    contents = var.orders_worker_creds
+  }
+}
@@ -0,0 +1,38 @@
+variable "ops_xkey" {
+  type = string
+}
+
+resource "nkey_operator_bootstrap" "prod" {
+  name = "prod"
+
+  # Bump to rotate a single key. Only the JWTs that depend on it are issued
+  # again, e.g. rotating the system user leaves the operator JWT alone.
+  rotate_system_user = "2024-q1"
+
+  # Also seal the system user creds to the curve key of the host that runs
+  # the nats CLI, which opens creds_sealed with its own seed.
+  seal_to_xkey = var.ops_xkey
+}
+
+# Each piece is a separate attribute, so the seeds can go to a different secret
+# backend than the JWTs the servers need.
+output "operator_jwt" {
+  value = nkey_operator_bootstrap.prod.operator_jwt
+}
+
+output "system_account" {
+  value = nkey_operator_bootstrap.prod.system_account_public_key
+}
+
+output "resolver_preload" {
+  value = nkey_operator_bootstrap.prod.resolver_preload
+}
+
+output "system_user_creds" {
+  value     = nkey_operator_bootstrap.prod.system_user_creds
+  sensitive = true
+}
+
+output "system_user_creds_sealed" {
+  value = nkey_operator_bootstrap.prod.creds_sealed
+}
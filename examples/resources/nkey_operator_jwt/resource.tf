@@ -0,0 +1,47 @@
+resource "nkey_keypair" "operator" {
+  type = "operator"
+}
+
+resource "nkey_keypair" "system" {
+  type = "account"
+}
+
+# The seed is write-only, so it is read while the JWT is issued but never
+# stored with the token.
+resource "nkey_operator_jwt" "main" {
+  subject      = nkey_keypair.operator.public_key
+  signing_seed = nkey_keypair.operator.seed
+  name         = "main"
+  tags         = ["env:prod", "team:platform"]
+
+  system_account        = nkey_keypair.system.public_key
+  account_server_url    = "https://accounts.example.com/jwt/v1"
+  operator_service_urls = ["tls://nats.example.com:4222"]
+}
+
+# Keep the operator nkey offline and sign the accounts with signing keys
+resource "nkey_keyset" "operator_signing" {
+  type       = "operator"
+  count_keys = 2
+}
+
+resource "nkey_operator_jwt" "offline" {
+  subject      = nkey_keypair.operator.public_key
+  signing_seed = nkey_keypair.operator.seed
+  name         = "offline"
+  signing_keys = [for k in nkey_keyset.operator_signing.keys : k.public_key]
+
+  # Reject account JWTs signed by the operator nkey itself
+  strict_signing_key_usage = true
+}
+
+# A partner sandbox that expires a year after it is issued. Refreshing the
+# state warns during the last two weeks.
+resource "nkey_operator_jwt" "sandbox" {
+  subject      = nkey_keypair.operator.public_key
+  signing_seed = nkey_keypair.operator.seed
+  name         = "sandbox"
+
+  expires_in     = "8760h"
+  expiry_warning = "336h"
+}
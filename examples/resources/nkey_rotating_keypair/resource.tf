@@ -0,0 +1,14 @@
+# Rotated by the first apply 90 days after it was generated
+resource "nkey_rotating_keypair" "account" {
+  type          = "account"
+  rotation_days = 90
+}
+
+# Rotated at a fixed point in time
+resource "nkey_rotating_keypair" "user" {
+  type             = "user"
+  rotation_rfc3339 = "2027-01-01T00:00:00Z"
+
+  # Change to rotate immediately
+  force_rotate = "1"
+}
@@ -0,0 +1,10 @@
+# A fresh operator key split across three security officers, any two of
+# which can recover the seed
+resource "nkey_seed_shares" "operator" {
+  shares    = 3
+  threshold = 2
+}
+
+output "operator_public_key" {
+  value = nkey_seed_shares.operator.public_key
+}
@@ -0,0 +1,61 @@
+variable "production_xkey" {
+  type = string
+}
+
+resource "nkey_keypair" "account" {
+  type = "account"
+}
+
+# Generates the user nkey and issues its JWT and creds in one resource. Change
+# rotate_key to replace the nkey and issue the JWT again for the new one.
+resource "nkey_user" "alice" {
+  signing_seed = nkey_keypair.account.seed
+  name         = "alice"
+  expires_in   = "2160h"
+  rotate_key   = "2026-10"
+
+  permissions = {
+    publish = {
+      allow = ["orders.>"]
+    }
+    subscribe = {
+      allow = ["_INBOX.>"]
+    }
+  }
+}
+
+output "alice_creds" {
+  value     = nkey_user.alice.creds
+  sensitive = true
+}
+
+# A user for leafnode servers, restricted to leafnode connections, with the
+# leafnodes block of their configuration. Write creds to credentials_path on
+# the leafnode servers and include leafnode_config in their nats-server.conf.
+resource "nkey_user" "edge" {
+  signing_seed = nkey_keypair.account.seed
+  name         = "edge"
+
+  leafnode_remote = {
+    urls             = ["nats-leaf://hub.example.com:7422"]
+    credentials_path = "/etc/nats/edge.creds"
+  }
+}
+
+output "edge_leafnode_config" {
+  value = nkey_user.edge.leafnode_config
+}
+
+# A user whose creds are only output sealed to the curve key of the
+# environment they are deployed to, so the pipeline never sees them in
+# plaintext. The environment opens creds_sealed with its own seed.
+resource "nkey_user" "deploy" {
+  signing_seed = nkey_keypair.account.seed
+  name         = "deploy"
+  seal_to_xkey = var.production_xkey
+  seal_only    = true
+}
+
+output "deploy_creds_sealed" {
+  value = nkey_user.deploy.creds_sealed
+}
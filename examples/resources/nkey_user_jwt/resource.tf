@@ -0,0 +1,86 @@
+resource "nkey_keypair" "account" {
+  type = "account"
+}
+
+resource "nkey_keypair" "alice" {
+  type = "user"
+}
+
+# Sign with the account nkey. The JWT is only issued again when its claims or
+# the signing key change.
+resource "nkey_user_jwt" "alice" {
+  subject      = nkey_keypair.alice.public_key
+  signing_seed = nkey_keypair.account.seed
+  name         = "alice"
+  tags         = ["team:orders", "env:prod"]
+
+  # Only connect from the office network, or from a single address
+  source_networks = ["10.1.0.0/16", "203.0.113.7"]
+
+  # Limits that are not set stay unlimited
+  limits = {
+    max_subscriptions = 100
+    max_payload       = provider::nkey::parse_size("1MB")
+  }
+
+  # An empty or unset allow list allows every subject, so deny what the user
+  # must not reach
+  permissions = {
+    publish = {
+      allow = ["orders.>"]
+      deny  = ["orders.admin.>"]
+    }
+    subscribe = {
+      allow = ["_INBOX.>", "orders.created workers"]
+    }
+
+    # Answer the requests received on orders.created, whatever their reply
+    # subject
+    response = {
+      max = 1
+      ttl = "30s"
+    }
+  }
+}
+
+resource "nkey_keypair" "browser" {
+  type = "user"
+}
+
+# A browser connects over websockets with the JWT alone, passed as its token
+resource "nkey_user_jwt" "browser" {
+  subject      = nkey_keypair.browser.public_key
+  signing_seed = nkey_keypair.account.seed
+  name         = "browser"
+  bearer_token = true
+
+  allowed_connection_types = ["WEBSOCKET"]
+}
+
+output "browser_token" {
+  value     = nkey_user_jwt.browser.token
+  sensitive = true
+}
+
+resource "nkey_keypair" "contractor" {
+  type = "user"
+}
+
+# Contractors only connect during business hours in Madrid
+resource "nkey_user_jwt" "contractor" {
+  subject      = nkey_keypair.contractor.public_key
+  signing_seed = nkey_keypair.account.seed
+  name         = "contractor"
+
+  # The credentials last a week from when they are issued, and a plan in
+  # their last day issues them again for another week. Change force_reissue
+  # to issue them again right away
+  expires_in    = "168h"
+  renew_before  = "24h"
+  force_reissue = "1"
+
+  usage_times = {
+    windows = [{ start = "09:00:00", end = "17:00:00" }]
+    locale  = "Europe/Madrid"
+  }
+}
@@ -0,0 +1,1503 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &AccountJWT{}
+var _ resource.ResourceWithModifyPlan = &AccountJWT{}
+var _ resource.ResourceWithValidateConfig = &AccountJWT{}
+var _ resource.ResourceWithConfigValidators = &AccountJWT{}
+
+func NewAccountJWT() resource.Resource {
+	return &AccountJWT{}
+}
+
+// AccountJWT defines the resource implementation.
+type AccountJWT struct {
+}
+
+// AccountJWTModel describes the resource data model.
+type AccountJWTModel struct {
+	AccountClaimsModel
+	Subject types.String `tfsdk:"subject"`
+}
+
+// AccountClaimsModel describes the claims of an account JWT and its signer,
+// shared by the account resources.
+type AccountClaimsModel struct {
+	JWTModel
+	SigningSeed           types.String `tfsdk:"signing_seed"`
+	Name                  types.String `tfsdk:"name"`
+	OperatorJWT           types.String `tfsdk:"operator_jwt"`
+	Limits                types.Object `tfsdk:"limits"`
+	JetStream             types.Object `tfsdk:"jetstream"`
+	JetStreamEnabled      types.Bool   `tfsdk:"jetstream_enabled"`
+	JetStreamTieredLimits types.Map    `tfsdk:"jetstream_tiered_limits"`
+	Exports               types.Set    `tfsdk:"exports"`
+	Imports               types.Set    `tfsdk:"imports"`
+	SigningKeys           types.Set    `tfsdk:"signing_keys"`
+	ScopedSigningKeys     types.Map    `tfsdk:"scoped_signing_keys"`
+	Revocations           types.Map    `tfsdk:"revocations"`
+	RevocationsUnix       types.Map    `tfsdk:"revocations_unix"`
+	DefaultPermissions    types.Object `tfsdk:"default_permissions"`
+	Mappings              types.Map    `tfsdk:"mappings"`
+	AuthCallout           types.Object `tfsdk:"auth_callout"`
+	DisallowBearer        types.Bool   `tfsdk:"disallow_bearer"`
+	Description           types.String `tfsdk:"description"`
+	InfoURL               types.String `tfsdk:"info_url"`
+	Tags                  types.Set    `tfsdk:"tags"`
+	Trace                 types.Object `tfsdk:"trace"`
+	AdditionalClaimsJSON  types.String `tfsdk:"additional_claims_json"`
+}
+
+// AccountTraceModel describes the trace attribute.
+type AccountTraceModel struct {
+	Destination types.String `tfsdk:"destination"`
+	Sampling    types.Int64  `tfsdk:"sampling"`
+}
+
+// AccountAuthCalloutModel describes the auth_callout attribute.
+type AccountAuthCalloutModel struct {
+	AuthUsers       types.Set    `tfsdk:"auth_users"`
+	AllowedAccounts types.Set    `tfsdk:"allowed_accounts"`
+	XKey            types.String `tfsdk:"xkey"`
+}
+
+// checkAllowedAccounts reports allowed_accounts that list * along with other
+// accounts.
+func (m *AccountAuthCalloutModel) checkAllowedAccounts() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.AllowedAccounts.IsUnknown() || len(m.AllowedAccounts.Elements()) < 2 {
+		return diags
+	}
+	if slices.Contains(m.AllowedAccounts.Elements(), attr.Value(types.StringValue(jwt.AnyAccount))) {
+		diags.AddAttributeError(path.Root("auth_callout").AtName("allowed_accounts"), "invalid allowed accounts", "The allowed_accounts either list accounts or are * alone for any account.")
+	}
+	return diags
+}
+
+// setAuthorization sets the external authorization of claims. It refuses
+// to set one without auth users, which nats-server would reject.
+func (m *AccountAuthCalloutModel) setAuthorization(ctx context.Context, authorization *jwt.ExternalAuthorization) diag.Diagnostics {
+	diags := addSorted(ctx, m.AuthUsers, &authorization.AuthUsers)
+	diags.Append(addSorted(ctx, m.AllowedAccounts, &authorization.AllowedAccounts)...)
+	if !diags.HasError() && len(authorization.AuthUsers) == 0 {
+		diags.AddAttributeError(path.Root("auth_callout").AtName("auth_users"), "no auth users", "The auth callout needs at least one auth user to issue the authorization responses.")
+	}
+	authorization.XKey = m.XKey.ValueString()
+	return diags
+}
+
+// AccountMappingModel describes an element of the mappings attribute.
+type AccountMappingModel struct {
+	Destinations types.Set `tfsdk:"destinations"`
+}
+
+// AccountMappingDestinationModel describes an element of the destinations of
+// a mapping.
+type AccountMappingDestinationModel struct {
+	Destination types.String `tfsdk:"destination"`
+	Weight      types.Int64  `tfsdk:"weight"`
+	Cluster     types.String `tfsdk:"cluster"`
+}
+
+// ScopedSigningKeyModel describes an element of the scoped_signing_keys
+// attribute.
+type ScopedSigningKeyModel struct {
+	Role                   types.String `tfsdk:"role"`
+	Description            types.String `tfsdk:"description"`
+	Permissions            types.Object `tfsdk:"permissions"`
+	Limits                 types.Object `tfsdk:"limits"`
+	BearerToken            types.Bool   `tfsdk:"bearer_token"`
+	AllowedConnectionTypes types.Set    `tfsdk:"allowed_connection_types"`
+}
+
+// scope returns the user scope of the signing key.
+func (m *ScopedSigningKeyModel) scope(ctx context.Context, key string) (*jwt.UserScope, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	scope := jwt.NewUserScope()
+	scope.Key = key
+	scope.Role = m.Role.ValueString()
+	scope.Description = m.Description.ValueString()
+	scope.Template.BearerToken = m.BearerToken.ValueBool()
+	if !m.Permissions.IsNull() {
+		var permissions PermissionsModel
+		diags.Append(m.Permissions.As(ctx, &permissions, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		diags.Append(permissions.setPermissions(ctx, &scope.Template.Permissions)...)
+	}
+	if !m.Limits.IsNull() {
+		var limits UserLimitsModel
+		diags.Append(m.Limits.As(ctx, &limits, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		limits.setLimits(&scope.Template.NatsLimits)
+	}
+	diags.Append(addConnectionTypes(ctx, m.AllowedConnectionTypes, &scope.Template.AllowedConnectionTypes)...)
+	return scope, diags
+}
+
+// AccountLimitsModel describes the limits attribute.
+type AccountLimitsModel struct {
+	MaxConnections         types.Int64 `tfsdk:"max_connections"`
+	MaxLeafnodeConnections types.Int64 `tfsdk:"max_leafnode_connections"`
+	MaxData                types.Int64 `tfsdk:"max_data"`
+	MaxPayload             types.Int64 `tfsdk:"max_payload"`
+	MaxSubscriptions       types.Int64 `tfsdk:"max_subscriptions"`
+}
+
+// setLimits sets the limits of claims. Unset limits are unlimited, the way
+// nsc leaves them, rather than 0, which would lock out the account.
+func (m *AccountLimitsModel) setLimits(limits *jwt.OperatorLimits) {
+	limits.Conn = limitValue(m.MaxConnections)
+	limits.LeafNodeConn = limitValue(m.MaxLeafnodeConnections)
+	limits.Data = limitValue(m.MaxData)
+	limits.Payload = limitValue(m.MaxPayload)
+	limits.Subs = limitValue(m.MaxSubscriptions)
+}
+
+// AccountJetStreamModel describes the jetstream attribute and the tiers of
+// jetstream_tiered_limits.
+type AccountJetStreamModel struct {
+	MemoryStorage types.Int64 `tfsdk:"memory_storage"`
+	DiskStorage   types.Int64 `tfsdk:"disk_storage"`
+	Streams       types.Int64 `tfsdk:"streams"`
+	Consumers     types.Int64 `tfsdk:"consumers"`
+	MaxAckPending types.Int64 `tfsdk:"max_ack_pending"`
+}
+
+// setLimits sets the JetStream limits of claims. JetStream is only enabled
+// for the storage that is set to other than 0, while the other limits are
+// unlimited unless set.
+func (m *AccountJetStreamModel) setLimits(limits *jwt.JetStreamLimits) {
+	limits.MemoryStorage = m.MemoryStorage.ValueInt64()
+	limits.DiskStorage = m.DiskStorage.ValueInt64()
+	limits.Streams = limitValue(m.Streams)
+	limits.Consumer = limitValue(m.Consumers)
+	limits.MaxAckPending = limitValue(m.MaxAckPending)
+}
+
+// AccountExportModel describes an element of the exports attribute.
+type AccountExportModel struct {
+	Name              types.String `tfsdk:"name"`
+	Subject           types.String `tfsdk:"subject"`
+	Type              types.String `tfsdk:"type"`
+	TokenRequired     types.Bool   `tfsdk:"token_required"`
+	Description       types.String `tfsdk:"description"`
+	InfoURL           types.String `tfsdk:"info_url"`
+	ResponseType      types.String `tfsdk:"response_type"`
+	ResponseThreshold types.String `tfsdk:"response_threshold"`
+	Latency           types.Object `tfsdk:"latency"`
+}
+
+// AccountLatencyModel describes the latency attribute of an export.
+type AccountLatencyModel struct {
+	Sampling types.String `tfsdk:"sampling"`
+	Results  types.String `tfsdk:"results"`
+}
+
+// export returns the export of the account claims.
+func (m *AccountExportModel) export(ctx context.Context) (*jwt.Export, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	export := &jwt.Export{
+		Name:     m.Name.ValueString(),
+		Subject:  jwt.Subject(m.Subject.ValueString()),
+		Type:     jwt.Stream,
+		TokenReq: m.TokenRequired.ValueBool(),
+		Info: jwt.Info{
+			Description: m.Description.ValueString(),
+			InfoURL:     m.InfoURL.ValueString(),
+		},
+	}
+	if m.Type.ValueString() == "service" {
+		export.Type = jwt.Service
+		export.ResponseType = jwt.ResponseType(m.ResponseType.ValueString())
+	}
+	if !m.ResponseThreshold.IsNull() {
+		threshold, err := time.ParseDuration(m.ResponseThreshold.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("exports"), "invalid response threshold", "The response_threshold of export "+m.Name.ValueString()+" could not be parsed: "+err.Error())
+			return nil, diags
+		}
+		export.ResponseThreshold = threshold
+	}
+	if !m.Latency.IsNull() {
+		var latency AccountLatencyModel
+		diags.Append(m.Latency.As(ctx, &latency, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		export.Latency = &jwt.ServiceLatency{
+			Sampling: jwt.Headers,
+			Results:  jwt.Subject(latency.Results.ValueString()),
+		}
+		if sampling := latency.Sampling.ValueString(); sampling != "headers" {
+			percent, err := strconv.Atoi(sampling)
+			if err != nil {
+				diags.AddAttributeError(path.Root("exports"), "invalid latency sampling", "The latency sampling of export "+m.Name.ValueString()+" could not be parsed: "+err.Error())
+				return nil, diags
+			}
+			export.Latency.Sampling = jwt.SamplingRate(percent)
+		}
+	}
+	return export, diags
+}
+
+// checkService reports the settings of a stream export that are only valid
+// for services.
+func (m *AccountExportModel) checkService() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Type.ValueString() != "stream" {
+		return diags
+	}
+	var set []string
+	if !m.ResponseType.IsNull() {
+		set = append(set, "response_type")
+	}
+	if !m.ResponseThreshold.IsNull() {
+		set = append(set, "response_threshold")
+	}
+	if !m.Latency.IsNull() {
+		set = append(set, "latency")
+	}
+	if len(set) > 0 {
+		diags.AddAttributeError(path.Root("exports"), "invalid stream export", fmt.Sprintf("The stream export %q sets %s, which only service exports can set.", m.Name.ValueString(), strings.Join(set, ", ")))
+	}
+	return diags
+}
+
+// checkExports reports exports of the same type and subject, skipping the
+// exports that are not known yet. Overlapping wildcard subjects are left to
+// the validation of the claims.
+func (m *AccountClaimsModel) checkExports(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Exports.IsUnknown() {
+		return diags
+	}
+	exported := make(map[string]bool, len(m.Exports.Elements()))
+	for _, element := range m.Exports.Elements() {
+		object, ok := element.(types.Object)
+		if !ok || object.IsUnknown() {
+			continue
+		}
+		var export AccountExportModel
+		asDiags := object.As(ctx, &export, basetypes.ObjectAsOptions{})
+		diags.Append(asDiags...)
+		if asDiags.HasError() {
+			return diags
+		}
+		diags.Append(export.checkService()...)
+		if !export.ResponseThreshold.IsNull() && !export.ResponseThreshold.IsUnknown() {
+			// An invalid duration is reported by the attribute validator
+			if threshold, err := time.ParseDuration(export.ResponseThreshold.ValueString()); err == nil && threshold <= 0 {
+				diags.AddAttributeError(path.Root("exports"), "invalid response threshold", fmt.Sprintf("The response_threshold of export %q must be positive.", export.Name.ValueString()))
+			}
+		}
+		if export.Subject.IsUnknown() || export.Type.IsUnknown() {
+			continue
+		}
+		key := export.Type.ValueString() + " " + export.Subject.ValueString()
+		if exported[key] {
+			diags.AddAttributeError(path.Root("exports"), "duplicate export", fmt.Sprintf("The %s subject %q is exported more than once.", export.Type.ValueString(), export.Subject.ValueString()))
+		}
+		exported[key] = true
+	}
+	return diags
+}
+
+// AccountImportModel describes an element of the imports attribute.
+type AccountImportModel struct {
+	Name         types.String `tfsdk:"name"`
+	Account      types.String `tfsdk:"account"`
+	Subject      types.String `tfsdk:"subject"`
+	Type         types.String `tfsdk:"type"`
+	LocalSubject types.String `tfsdk:"local_subject"`
+	Share        types.Bool   `tfsdk:"share"`
+	Token        types.String `tfsdk:"token"`
+}
+
+// isKnown returns whether the values the import is validated by are known.
+func (m *AccountImportModel) isKnown() bool {
+	return !m.Account.IsUnknown() && !m.Subject.IsUnknown() && !m.Type.IsUnknown() && !m.LocalSubject.IsUnknown() && !m.Share.IsUnknown()
+}
+
+// checkToken checks that the activation token of the import is issued by the
+// exporting account to importer for the subject of the import. importer is
+// empty when it is not known yet.
+func (m *AccountImportModel) checkToken(importer string) error {
+	activation, err := jwt.DecodeActivationClaims(m.Token.ValueString())
+	if err != nil {
+		return fmt.Errorf("the token is not an activation JWT: %w", err)
+	}
+	imp := m.jwtImport()
+	switch {
+	case activation.Issuer != imp.Account && activation.IssuerAccount != imp.Account:
+		return fmt.Errorf("the token is issued by %s rather than the exporting account %s", activation.Issuer, imp.Account)
+	case importer != "" && activation.Subject != importer:
+		return fmt.Errorf("the token is issued to %s rather than the importing account %s", activation.Subject, importer)
+	case activation.ImportType != imp.Type:
+		return fmt.Errorf("the token is for a %s import rather than a %s import", activation.ImportType, imp.Type)
+	case !imp.Subject.IsContainedIn(activation.ImportSubject):
+		return fmt.Errorf("the token is for subject %q, which does not contain the subject %q", activation.ImportSubject, imp.Subject)
+	}
+	return nil
+}
+
+// jwtImport returns the import of the account claims, without the token.
+func (m *AccountImportModel) jwtImport() *jwt.Import {
+	imp := &jwt.Import{
+		Name:         m.Name.ValueString(),
+		Account:      m.Account.ValueString(),
+		Subject:      jwt.Subject(m.Subject.ValueString()),
+		LocalSubject: jwt.RenamingSubject(m.LocalSubject.ValueString()),
+		Type:         jwt.Stream,
+		Share:        m.Share.ValueBool(),
+	}
+	if m.Type.ValueString() == "service" {
+		imp.Type = jwt.Service
+	}
+	return imp
+}
+
+// checkImports validates the imports that are known with the rules of the
+// jwt library, e.g. that a local_subject keeps the wildcards of the subject
+// and that service imports do not overlap, and checks their activation tokens.
+// Imports of accounts that are not known yet, such as one created in the same
+// apply, are checked when issuing, and so are the tokens when the importing
+// subject is not known.
+func (m *AccountClaimsModel) checkImports(ctx context.Context, subject types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Imports.IsUnknown() {
+		return diags
+	}
+	var imports jwt.Imports
+	for _, element := range m.Imports.Elements() {
+		object, ok := element.(types.Object)
+		if !ok || object.IsUnknown() {
+			continue
+		}
+		var model AccountImportModel
+		asDiags := object.As(ctx, &model, basetypes.ObjectAsOptions{})
+		diags.Append(asDiags...)
+		if asDiags.HasError() {
+			return diags
+		}
+		if !model.isKnown() {
+			continue
+		}
+		imports.Add(model.jwtImport())
+		if model.Token.IsNull() || model.Token.IsUnknown() {
+			continue
+		}
+		importer := ""
+		if !subject.IsUnknown() {
+			importer = subject.ValueString()
+		}
+		if err := model.checkToken(importer); err != nil {
+			diags.AddAttributeError(path.Root("imports"), "invalid activation token", fmt.Sprintf("The token of import %q is invalid: %s.", model.Name.ValueString(), err))
+		}
+	}
+
+	vr := jwt.ValidationResults{}
+	imports.Validate(subject.ValueString(), &vr)
+	for _, issue := range vr.Issues {
+		if issue.Blocking {
+			diags.AddAttributeError(path.Root("imports"), "invalid import", "The imports are invalid: "+issue.Description+".")
+		} else {
+			diags.AddAttributeWarning(path.Root("imports"), "questionable import", "nats-server may reject the imports: "+issue.Description+".")
+		}
+	}
+	return diags
+}
+
+// samplingPattern matches the latency sampling of an export.
+var samplingPattern = regexp.MustCompile(`^(headers|[1-9][0-9]?|100)$`)
+
+// tierPattern matches the name of a JetStream replication tier.
+var tierPattern = regexp.MustCompile(`^R[1-5]$`)
+
+// jetStreamLimitAttributes returns the attributes of AccountJetStreamModel.
+func jetStreamLimitAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"memory_storage": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of bytes stored in memory across all streams, -1 for unlimited or 0 to disable memory storage. Defaults to 0",
+			Validators: []validator.Int64{
+				int64validator.AtLeast(jwt.NoLimit),
+			},
+		},
+		"disk_storage": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Maximum number of bytes stored on disk across all streams, -1 for unlimited or 0 to disable disk storage. Defaults to 0",
+			Validators: []validator.Int64{
+				int64validator.AtLeast(jwt.NoLimit),
+			},
+		},
+		"streams":         limitAttribute("Maximum number of streams"),
+		"consumers":       limitAttribute("Maximum number of consumers"),
+		"max_ack_pending": limitAttribute("Maximum number of pending acknowledgements of a consumer"),
+	}
+}
+
+// limitValue returns the configured value of a limit, or jwt.NoLimit when it
+// is not set.
+func limitValue(limit types.Int64) int64 {
+	if limit.IsNull() {
+		return jwt.NoLimit
+	}
+	return limit.ValueInt64()
+}
+
+// limitAttribute returns the schema attribute of a limit of the JWT.
+func limitAttribute(description string) schema.Int64Attribute {
+	return schema.Int64Attribute{
+		Optional:            true,
+		MarkdownDescription: description + ", or -1 for unlimited. Defaults to unlimited",
+		Validators: []validator.Int64{
+			int64validator.AtLeast(jwt.NoLimit),
+		},
+	}
+}
+
+func (r *AccountJWT) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_account_jwt"
+}
+
+func (r *AccountJWT) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := accountClaimsAttributes()
+	attributes["subject"] = schema.StringAttribute{
+		Required:            true,
+		MarkdownDescription: "Public key of the account nkey. Changing it replaces the resource",
+		Validators: []validator.String{
+			isPublicKeyOfType("account"),
+		},
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "An account JWT declares an account nkey to the NATS servers of an operator, signed by the operator nkey or one of its signing keys. The token is kept in state and only issued again when one of its claims or the signing key changes.",
+
+		Attributes: jwtResourceAttributes(attributes),
+	}
+}
+
+// accountClaimsAttributes returns the schema attributes of the claims of an
+// account JWT and its signer, shared by the account resources.
+func accountClaimsAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"signing_seed": schema.StringAttribute{
+			Required:            true,
+			WriteOnly:           true,
+			Sensitive:           true,
+			MarkdownDescription: "Seed of the operator nkey or of one of its signing keys, which signs the JWT. Switching to another key issues the JWT again." + signingSeedDescription,
+			Validators: []validator.String{
+				isSeedOfType("operator"),
+			},
+		},
+		"name": schema.StringAttribute{
+			Required:            true,
+			MarkdownDescription: "Name of the account",
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
+			},
+		},
+		"description": schema.StringAttribute{
+			Optional:            true,
+			MarkdownDescription: "Description of the account",
+		},
+		"info_url": schema.StringAttribute{
+			Optional:            true,
+			MarkdownDescription: "URL with more information about the account",
+			Validators: []validator.String{
+				isURL("http", "https"),
+			},
+		},
+		"tags":                   jwtTagsAttribute(),
+		"additional_claims_json": additionalClaimsAttribute(),
+		"operator_jwt": schema.StringAttribute{
+			Optional:            true,
+			MarkdownDescription: "JWT of the operator, e.g. from `nkey_operator_jwt`. When set, `signing_seed` must be the operator nkey or one of its signing keys, and not the operator nkey when the operator has `strict_signing_key_usage`. Changing it does not issue the JWT again",
+		},
+		"signing_keys": schema.SetAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: "Public keys of the account signing keys, which sign the user JWTs so the account nkey itself can be kept offline. Changing them issues the JWT again in place",
+			Validators: []validator.Set{
+				setvalidator.ValueStringsAre(isPublicKeyOfType("account")),
+			},
+		},
+		"scoped_signing_keys": schema.MapNestedAttribute{
+			Optional:            true,
+			MarkdownDescription: "Scoped signing keys keyed by public key. The server applies the permissions and limits of the scope to every user signed by a scoped key, ignoring those of the user JWT. A key cannot be in both `signing_keys` and `scoped_signing_keys`",
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"role": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Name of the role of the users signed by the key",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+					"description": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Description of the scope",
+					},
+					"permissions":              permissionsAttribute("Publish and subscribe permissions of the users signed by the key"),
+					"limits":                   userLimitsAttribute("Limits of the users signed by the key. Limits that are not set are unlimited"),
+					"allowed_connection_types": connectionTypesAttribute(),
+					"bearer_token": schema.BoolAttribute{
+						Optional:            true,
+						MarkdownDescription: "Whether the users signed by the key connect with the JWT alone, without proving they hold the user nkey. Defaults to false",
+					},
+				},
+			},
+			Validators: []validator.Map{
+				mapvalidator.KeysAre(isPublicKeyOfType("account")),
+			},
+		},
+		"revocations": schema.MapAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: "Revoked users keyed by user public key, or `*` for all users, with the RFC 3339 time their JWTs are revoked at, or `now` for the time of the apply. User JWTs issued at or before that time are rejected, while JWTs issued to the same user after it are accepted again",
+			Validators: []validator.Map{
+				mapvalidator.KeysAre(stringvalidator.Any(stringvalidator.OneOf(jwt.All), isPublicKeyOfType("user"))),
+				mapvalidator.ValueStringsAre(stringvalidator.Any(stringvalidator.OneOf(revokeNow), isRFC3339())),
+			},
+		},
+		"revocations_unix": schema.MapAttribute{
+			ElementType:         types.Int64Type,
+			Computed:            true,
+			MarkdownDescription: "Unix time of each revocation in the JWT. A revocation at `now` keeps the time of the apply it was added in",
+		},
+		"mappings": schema.MapNestedAttribute{
+			Optional:            true,
+			MarkdownDescription: "Subject mappings keyed by source subject, which may contain wildcards. Messages published to the source are mapped to one of its destinations, picked by weight",
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"destinations": schema.SetNestedAttribute{
+						Required:            true,
+						MarkdownDescription: "Destinations of the mapping, in any order. The weights of the destinations of each cluster, and of the destinations without a cluster, add up to at most 100",
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"destination": schema.StringAttribute{
+									Required:            true,
+									MarkdownDescription: "Subject messages are mapped to, which refers to the `*` wildcards of the source as `$1` or `{{wildcard(1)}}`, and ends in `>` exactly when the source does",
+								},
+								"weight": schema.Int64Attribute{
+									Optional:            true,
+									MarkdownDescription: "Percentage of the messages mapped to the destination, from 1 to 100. Defaults to 100",
+									Validators: []validator.Int64{
+										int64validator.Between(1, 100),
+									},
+								},
+								"cluster": schema.StringAttribute{
+									Optional:            true,
+									MarkdownDescription: "Cluster the destination applies to. Destinations without a cluster apply to every cluster without destinations of its own",
+								},
+							},
+						},
+						Validators: []validator.Set{
+							setvalidator.SizeAtLeast(1),
+						},
+					},
+				},
+			},
+			Validators: []validator.Map{
+				mapvalidator.KeysAre(isSubject()),
+			},
+		},
+		"auth_callout": schema.SingleNestedAttribute{
+			Optional:            true,
+			MarkdownDescription: "Delegates the authentication of the users of the account to an auth callout service",
+			Attributes: map[string]schema.Attribute{
+				"auth_users": schema.SetAttribute{
+					ElementType:         types.StringType,
+					Required:            true,
+					MarkdownDescription: "Public keys of the users the auth callout service connects as, which bypass it",
+					Validators: []validator.Set{
+						setvalidator.SizeAtLeast(1),
+						setvalidator.ValueStringsAre(isPublicKeyOfType("user")),
+					},
+				},
+				"allowed_accounts": schema.SetAttribute{
+					ElementType:         types.StringType,
+					Optional:            true,
+					MarkdownDescription: "Public keys of the accounts the auth callout service may place users in, or `*` alone for any account. Defaults to the account itself",
+					Validators: []validator.Set{
+						setvalidator.ValueStringsAre(stringvalidator.Any(stringvalidator.OneOf(jwt.AnyAccount), isPublicKeyOfType("account"))),
+					},
+				},
+				"xkey": schema.StringAttribute{
+					Optional:            true,
+					MarkdownDescription: "Curve public key of the auth callout service, which the requests are encrypted to",
+					Validators: []validator.String{
+						isPublicKeyOfType("curve"),
+					},
+				},
+			},
+		},
+		"trace": schema.SingleNestedAttribute{
+			Optional:            true,
+			MarkdownDescription: "Traces the messages of the account that carry a `traceparent` header marking them as sampled",
+			Attributes: map[string]schema.Attribute{
+				"destination": schema.StringAttribute{
+					Required:            true,
+					MarkdownDescription: "Subject the traces are published to, which may not contain wildcards",
+					Validators: []validator.String{
+						isLiteralSubject(),
+					},
+				},
+				"sampling": schema.Int64Attribute{
+					Optional:            true,
+					MarkdownDescription: "Percentage of the sampled messages that are traced, from 1 to 100. Defaults to 100",
+					Validators: []validator.Int64{
+						int64validator.Between(1, 100),
+					},
+				},
+			},
+		},
+		"default_permissions": permissionsAttribute("Publish and subscribe permissions of the users of the account whose JWT has no permissions of its own"),
+		"limits": schema.SingleNestedAttribute{
+			Optional:            true,
+			MarkdownDescription: "Limits of the account. Limits that are not set are unlimited, as with nsc, rather than 0, which would lock out the account",
+			Attributes: map[string]schema.Attribute{
+				"max_connections":          limitAttribute("Maximum number of client connections"),
+				"max_leafnode_connections": limitAttribute("Maximum number of leaf node connections"),
+				"max_data":                 limitAttribute("Maximum number of bytes"),
+				"max_payload":              limitAttribute("Maximum message payload in bytes"),
+				"max_subscriptions":        limitAttribute("Maximum number of subscriptions"),
+			},
+		},
+		"jetstream": schema.SingleNestedAttribute{
+			Optional:            true,
+			MarkdownDescription: "JetStream limits of the account. JetStream stays disabled unless `memory_storage` or `disk_storage` is set to other than 0. Conflicts with `jetstream_enabled` and `jetstream_tiered_limits`",
+			Attributes:          jetStreamLimitAttributes(),
+		},
+		"disallow_bearer": schema.BoolAttribute{
+			Optional:            true,
+			MarkdownDescription: "Whether to reject user JWTs that are bearer tokens, so every user has to prove it holds its user nkey. Defaults to false",
+		},
+		"jetstream_enabled": schema.BoolAttribute{
+			Optional:            true,
+			MarkdownDescription: "Shorthand that enables JetStream without limits, like a `jetstream` with every limit set to -1. Conflicts with `jetstream` and `jetstream_tiered_limits`",
+		},
+		"jetstream_tiered_limits": schema.MapNestedAttribute{
+			Optional:            true,
+			MarkdownDescription: "JetStream limits of the account per replication tier, keyed by tier name such as `R1` or `R3`, with the same limits as `jetstream`. The server ignores the flat limits once there are tiers, so this conflicts with `jetstream` and `jetstream_enabled`",
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: jetStreamLimitAttributes(),
+			},
+			Validators: []validator.Map{
+				mapvalidator.KeysAre(stringvalidator.RegexMatches(tierPattern, "must be a replication tier from R1 to R5")),
+			},
+		},
+		"exports": schema.SetNestedAttribute{
+			Optional:            true,
+			MarkdownDescription: "Streams and services the account shares with other accounts. Their order does not matter, and a subject may only be exported once per type",
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"name": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Name of the export",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+					"subject": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Subject of the export, which may contain wildcards",
+						Validators: []validator.String{
+							isSubject(),
+						},
+					},
+					"type": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Type of the export, either `stream` or `service`",
+						Validators: []validator.String{
+							stringvalidator.OneOf("stream", "service"),
+						},
+					},
+					"token_required": schema.BoolAttribute{
+						Optional:            true,
+						MarkdownDescription: "Whether importing accounts need an activation token, making the export private. Defaults to false",
+					},
+					"description": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Description of the export",
+					},
+					"info_url": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "URL with more information about the export",
+						Validators: []validator.String{
+							isURL("http", "https"),
+						},
+					},
+					"response_type": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "How a service responds, either `Singleton`, `Stream` or `Chunked`. Defaults to `Singleton`. Only valid for services",
+						Validators: []validator.String{
+							stringvalidator.OneOf(jwt.ResponseTypeSingleton, jwt.ResponseTypeStream, jwt.ResponseTypeChunked),
+						},
+					},
+					"response_threshold": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "How long the server waits for responses of a service, as a positive duration such as `5s`. Only valid for services",
+						Validators: []validator.String{
+							isDuration(),
+						},
+					},
+					"latency": schema.SingleNestedAttribute{
+						Optional:            true,
+						MarkdownDescription: "Latency tracking of a service. Only valid for services",
+						Attributes: map[string]schema.Attribute{
+							"sampling": schema.StringAttribute{
+								Required:            true,
+								MarkdownDescription: "Percentage of requests to sample from 1 to 100, or `headers` to only sample requests with tracing headers",
+								Validators: []validator.String{
+									stringvalidator.RegexMatches(samplingPattern, "must be a percentage from 1 to 100 or headers"),
+								},
+							},
+							"results": schema.StringAttribute{
+								Required:            true,
+								MarkdownDescription: "Subject the latency measurements are published to, without wildcards",
+								Validators: []validator.String{
+									isLiteralSubject(),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"imports": schema.SetNestedAttribute{
+			Optional:            true,
+			MarkdownDescription: "Streams and services the account imports from the exports of other accounts. Their order does not matter",
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"name": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Name of the import",
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+					"account": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Public key of the account that exports the subject, e.g. the `subject` of another `nkey_account_jwt`",
+						Validators: []validator.String{
+							isPublicKeyOfType("account"),
+						},
+					},
+					"subject": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Subject exported by the account, which may contain wildcards",
+						Validators: []validator.String{
+							isSubject(),
+						},
+					},
+					"type": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Type of the import, either `stream` or `service`",
+						Validators: []validator.String{
+							stringvalidator.OneOf("stream", "service"),
+						},
+					},
+					"local_subject": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Subject the import is mapped to in the account. It has to keep the wildcards of `subject`, where `$1` refers to the first `*`. Defaults to `subject`",
+					},
+					"share": schema.BoolAttribute{
+						Optional:            true,
+						MarkdownDescription: "Whether to share the latency information of requests with the exporting account. Defaults to false. Only valid for services",
+					},
+					"token": schema.StringAttribute{
+						Optional:            true,
+						Sensitive:           true,
+						MarkdownDescription: "Activation token of an export with `token_required`, issued by the exporting account to this account for the subject of the import",
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *AccountJWT) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return accountConfigValidators()
+}
+
+// accountConfigValidators returns the config validators of the
+// AccountClaimsModel attributes.
+func accountConfigValidators() []resource.ConfigValidator {
+	return append(jwtConfigValidators(),
+		resourcevalidator.Conflicting(
+			path.MatchRoot("jetstream"),
+			path.MatchRoot("jetstream_enabled"),
+			path.MatchRoot("jetstream_tiered_limits"),
+		),
+	)
+}
+
+func (r *AccountJWT) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data AccountJWTModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.validate(ctx, data.Subject)...)
+	if claimsConfigKnown(req.Config) {
+		resp.Diagnostics.Append(data.validateAdditionalClaims(ctx, data.Subject.ValueString())...)
+	}
+}
+
+// validate checks the claims at plan time as far as they are known, with
+// subject the public key of the account, which may not be known yet.
+func (m *AccountClaimsModel) validate(ctx context.Context, subject types.String) diag.Diagnostics {
+	diags := m.validateLifetime()
+	diags.Append(m.checkExports(ctx)...)
+	diags.Append(m.checkImports(ctx, subject)...)
+	diags.Append(m.checkScopedSigningKeys()...)
+	diags.Append(m.checkMappings(ctx)...)
+	if !m.AuthCallout.IsNull() && !m.AuthCallout.IsUnknown() {
+		var authCallout AccountAuthCalloutModel
+		diags.Append(m.AuthCallout.As(ctx, &authCallout, basetypes.ObjectAsOptions{})...)
+		diags.Append(authCallout.checkAllowedAccounts()...)
+	}
+	if m.OperatorJWT.IsUnknown() || m.OperatorJWT.IsNull() {
+		return diags
+	}
+	diags.Append(m.checkSigningKeys()...)
+	if m.SigningSeed.IsUnknown() || m.SigningSeed.IsNull() {
+		return diags
+	}
+	diags.Append(m.checkOperator()...)
+	return diags
+}
+
+func (r *AccountJWT) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	resp.Diagnostics.Append(planJWTReissue(ctx, req.Config, req.State, &resp.Plan, "operator_jwt", "revocations_unix")...)
+	resp.Diagnostics.Append(planRevocations(ctx, req.State, &resp.Plan)...)
+	if resp.Diagnostics.HasError() || req.State.Raw.IsNull() || resp.Plan.Raw.IsNull() || !claimsConfigKnown(req.Config) {
+		return
+	}
+
+	var plan, state AccountJWTModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(plan.planUnchanged(ctx, &resp.Plan, &state.AccountClaimsModel, plan.Subject.ValueString())...)
+}
+
+func (r *AccountJWT) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data AccountJWTModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.issue(ctx, req.Config, data.Subject.ValueString())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created account JWT resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccountJWT) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data AccountJWTModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The token never changes outside of Terraform, so the only thing to
+	// check is that the stored token still decodes to the stored subject.
+	claims, err := jwt.DecodeAccountClaims(data.JWT.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted account JWT state", "The stored JWT could not be decoded: "+err.Error())
+		return
+	}
+	if claims.Subject != data.Subject.ValueString() {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted account JWT state", "The stored JWT is issued to "+claims.Subject+" rather than the stored subject.")
+		return
+	}
+
+	// Tags configured in another case are stored as configured when the JWT
+	// is issued, so store the lowercase form from now on
+	data.Tags = canonicalTags(data.Tags)
+	resp.Diagnostics.Append(data.expiryWarnings(time.Now())...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccountJWT) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	// The token is issued again in place when ModifyPlan planned it as
+	// unknown, i.e. when the claims or the signing key changed.
+	var plan AccountJWTModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.JWT.IsUnknown() {
+		resp.Diagnostics.Append(plan.issue(ctx, req.Config, plan.Subject.ValueString())...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *AccountJWT) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed
+	tflog.Trace(ctx, "deleted account JWT resource")
+}
+
+// issue encodes the claims of the model into its JWT for the account nkey
+// subject, signed with the write-only signing seed read from config.
+func (m *AccountClaimsModel) issue(ctx context.Context, config tfsdk.Config, subject string) diag.Diagnostics {
+	diags := config.GetAttribute(ctx, path.Root("signing_seed"), &m.SigningSeed)
+	if diags.HasError() {
+		return diags
+	}
+	defer func() { m.SigningSeed = types.StringNull() }()
+
+	if !m.OperatorJWT.IsNull() {
+		diags.Append(m.checkOperator()...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	claims, claimsDiags := m.issuedClaims(ctx, subject)
+	diags.Append(claimsDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	diags.Append(m.issueJWT(claims, m.SigningSeed.ValueString())...)
+	return diags
+}
+
+// claims returns the claims of the attributes of the model for the account
+// nkey subject.
+func (m *AccountClaimsModel) claims(ctx context.Context, subject string) (*jwt.AccountClaims, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	claims := jwt.NewAccountClaims(subject)
+	claims.Name = m.Name.ValueString()
+	claims.Limits.DisallowBearer = m.DisallowBearer.ValueBool()
+	claims.Description = m.Description.ValueString()
+	claims.InfoURL = m.InfoURL.ValueString()
+	addTags(m.Tags, &claims.Tags)
+	if !m.SigningKeys.IsNull() {
+		var signingKeys []string
+		diags.Append(m.SigningKeys.ElementsAs(ctx, &signingKeys, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		claims.SigningKeys.Add(signingKeys...)
+	}
+	if !m.ScopedSigningKeys.IsNull() {
+		var scopedSigningKeys map[string]ScopedSigningKeyModel
+		diags.Append(m.ScopedSigningKeys.ElementsAs(ctx, &scopedSigningKeys, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		for key, model := range scopedSigningKeys {
+			scope, scopeDiags := model.scope(ctx, key)
+			diags.Append(scopeDiags...)
+			if diags.HasError() {
+				return nil, diags
+			}
+			claims.SigningKeys.AddScopedSigner(scope)
+		}
+	}
+	if !m.Revocations.IsNull() {
+		diags.Append(m.revoke(claims, time.Now())...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	} else {
+		m.RevocationsUnix = types.MapNull(types.Int64Type)
+	}
+	if !m.Mappings.IsNull() {
+		var mappings map[string]AccountMappingModel
+		diags.Append(m.Mappings.ElementsAs(ctx, &mappings, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		claims.Mappings = jwt.Mapping{}
+		for source, mapping := range mappings {
+			var destinations []AccountMappingDestinationModel
+			diags.Append(mapping.Destinations.ElementsAs(ctx, &destinations, false)...)
+			if diags.HasError() {
+				return nil, diags
+			}
+			weighted := make([]jwt.WeightedMapping, 0, len(destinations))
+			for _, destination := range destinations {
+				weighted = append(weighted, jwt.WeightedMapping{
+					Subject: jwt.Subject(destination.Destination.ValueString()),
+					Weight:  uint8(destination.Weight.ValueInt64()),
+					Cluster: destination.Cluster.ValueString(),
+				})
+			}
+			// Sorted so that the token does not depend on set order
+			slices.SortFunc(weighted, func(a, b jwt.WeightedMapping) int {
+				return cmp.Or(strings.Compare(a.Cluster, b.Cluster), strings.Compare(string(a.Subject), string(b.Subject)), cmp.Compare(a.Weight, b.Weight))
+			})
+			claims.AddMapping(jwt.Subject(source), weighted...)
+		}
+	}
+	if !m.AuthCallout.IsNull() {
+		var authCallout AccountAuthCalloutModel
+		diags.Append(m.AuthCallout.As(ctx, &authCallout, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		diags.Append(authCallout.setAuthorization(ctx, &claims.Authorization)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+	if !m.Trace.IsNull() {
+		var trace AccountTraceModel
+		diags.Append(m.Trace.As(ctx, &trace, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		claims.Trace = &jwt.MsgTrace{
+			Destination: jwt.Subject(trace.Destination.ValueString()),
+			Sampling:    int(trace.Sampling.ValueInt64()),
+		}
+	}
+	if !m.DefaultPermissions.IsNull() {
+		var permissions PermissionsModel
+		diags.Append(m.DefaultPermissions.As(ctx, &permissions, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		diags.Append(permissions.setPermissions(ctx, &claims.DefaultPermissions)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+	if !m.Limits.IsNull() {
+		var limits AccountLimitsModel
+		diags.Append(m.Limits.As(ctx, &limits, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		limits.setLimits(&claims.Limits)
+	}
+	if !m.JetStream.IsNull() {
+		var jetStream AccountJetStreamModel
+		diags.Append(m.JetStream.As(ctx, &jetStream, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		jetStream.setLimits(&claims.Limits.JetStreamLimits)
+	}
+	if m.JetStreamEnabled.ValueBool() {
+		claims.Limits.JetStreamLimits = jwt.JetStreamLimits{
+			MemoryStorage: jwt.NoLimit,
+			DiskStorage:   jwt.NoLimit,
+			Streams:       jwt.NoLimit,
+			Consumer:      jwt.NoLimit,
+			MaxAckPending: jwt.NoLimit,
+		}
+	}
+	if !m.JetStreamTieredLimits.IsNull() {
+		var tiers map[string]AccountJetStreamModel
+		diags.Append(m.JetStreamTieredLimits.ElementsAs(ctx, &tiers, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		claims.Limits.JetStreamTieredLimits = make(jwt.JetStreamTieredLimits, len(tiers))
+		for tier, jetStream := range tiers {
+			var limits jwt.JetStreamLimits
+			jetStream.setLimits(&limits)
+			claims.Limits.JetStreamTieredLimits[tier] = limits
+		}
+	}
+
+	if !m.Exports.IsNull() {
+		var exports []AccountExportModel
+		diags.Append(m.Exports.ElementsAs(ctx, &exports, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		for _, model := range exports {
+			export, exportDiags := model.export(ctx)
+			diags.Append(exportDiags...)
+			if diags.HasError() {
+				return nil, diags
+			}
+			claims.Exports.Add(export)
+		}
+		// Sort the exports so that the token does not depend on set order
+		slices.SortFunc(claims.Exports, func(a, b *jwt.Export) int {
+			return cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.Subject, b.Subject))
+		})
+	}
+
+	if !m.Imports.IsNull() {
+		var imports []AccountImportModel
+		diags.Append(m.Imports.ElementsAs(ctx, &imports, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		for _, model := range imports {
+			imp := model.jwtImport()
+			imp.Token = model.Token.ValueString()
+			claims.Imports.Add(imp)
+		}
+		// Sort the imports so that the token does not depend on set order
+		slices.SortFunc(claims.Imports, func(a, b *jwt.Import) int {
+			return cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.Subject, b.Subject), cmp.Compare(a.Account, b.Account), cmp.Compare(a.LocalSubject, b.LocalSubject))
+		})
+	}
+	return claims, diags
+}
+
+// issuedClaims returns the claims the JWT is issued with, the claims of the
+// attributes with the additional claims, but for its lifetime.
+func (m *AccountClaimsModel) issuedClaims(ctx context.Context, subject string) (*jwt.AccountClaims, diag.Diagnostics) {
+	claims, diags := m.claims(ctx, subject)
+	if diags.HasError() {
+		return nil, diags
+	}
+	diags.Append(addAdditionalClaims(claims, jwt.NewAccountClaims(subject), m.AdditionalClaimsJSON)...)
+	return claims, diags
+}
+
+// planUnchanged keeps the token of prior, the model of the state, when the
+// claims of the model, the plan, are those of prior. Claims that cannot be
+// built are reported when the JWT is issued.
+func (m *AccountClaimsModel) planUnchanged(ctx context.Context, plan *tfsdk.Plan, prior *AccountClaimsModel, subject string) diag.Diagnostics {
+	if subject == "" {
+		return nil
+	}
+	claims, diags := m.issuedClaims(ctx, subject)
+	if diags.HasError() {
+		return nil
+	}
+	return planJWTUnchanged(ctx, plan, &m.JWTModel, &prior.JWTModel, claims)
+}
+
+// validateAdditionalClaims checks additional_claims_json against the claims
+// of the attributes at plan time.
+func (m *AccountClaimsModel) validateAdditionalClaims(ctx context.Context, subject string) diag.Diagnostics {
+	// Without a known subject the claims cannot be built yet
+	if m.AdditionalClaimsJSON.IsNull() || subject == "" {
+		return nil
+	}
+	claims, diags := m.claims(ctx, subject)
+	if diags.HasError() {
+		return diags
+	}
+	diags.Append(checkAdditionalClaims(claims, jwt.NewAccountClaims(subject), m.AdditionalClaimsJSON)...)
+	return diags
+}
+
+// checkOperator checks that the signing seed may sign accounts of the
+// operator of operator_jwt.
+func (m *AccountClaimsModel) checkOperator() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	operator, err := jwt.DecodeOperatorClaims(m.OperatorJWT.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("operator_jwt"), "invalid operator JWT", "The operator_jwt could not be decoded: "+err.Error())
+		return diags
+	}
+	signer, _, err := publicKeyFromSeed([]byte(m.SigningSeed.ValueString()))
+	if err != nil {
+		// Left for the attribute validator of signing_seed
+		return diags
+	}
+	if err := checkSigner(signer, operator.Subject, operator.SigningKeys, operator.StrictSigningKeyUsage); err != nil {
+		diags.AddAttributeError(path.Root("signing_seed"), "signing seed not allowed", "The signing seed cannot sign accounts of operator "+operator.Name+": "+err.Error()+".")
+	}
+	return diags
+}
+
+// mappingReferencePattern matches a reference to a wildcard of the source
+// subject in the destination of a mapping, e.g. "$1" or "{{wildcard(1)}}".
+var mappingReferencePattern = regexp.MustCompile(`\$(\d+)|\{\{\s*wildcard\s*\(\s*(\d+)\s*\)\s*\}\}`)
+
+// checkMappingDestination checks that destination is a subject that only
+// refers to the wildcards of source, and that ends in a full wildcard exactly
+// when source does.
+func checkMappingDestination(source, destination string) error {
+	vr := jwt.ValidationResults{}
+	jwt.Subject(destination).Validate(&vr)
+	if errs := vr.Errors(); len(errs) > 0 {
+		return errs[0]
+	}
+
+	wildcards := 0
+	for _, token := range strings.Split(source, ".") {
+		if token == "*" {
+			wildcards++
+		}
+	}
+	for _, match := range mappingReferencePattern.FindAllStringSubmatch(destination, -1) {
+		reference, err := strconv.Atoi(match[1] + match[2])
+		if err != nil || reference < 1 || reference > wildcards {
+			return fmt.Errorf("%s refers to wildcard %s, but the number of * wildcards in the source is %d", destination, match[1]+match[2], wildcards)
+		}
+	}
+	if sourceFull, destinationFull := strings.HasSuffix("."+source, ".>"), strings.HasSuffix("."+destination, ".>"); sourceFull != destinationFull {
+		return fmt.Errorf("%s has to end in the full wildcard > exactly when the source does", destination)
+	}
+	return nil
+}
+
+// checkMappings checks the destinations of the mappings that are known, and
+// that their weights add up to at most 100 per cluster.
+func (m *AccountClaimsModel) checkMappings(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Mappings.IsUnknown() {
+		return diags
+	}
+	for source, element := range m.Mappings.Elements() {
+		object, ok := element.(types.Object)
+		if !ok || object.IsUnknown() {
+			continue
+		}
+		var mapping AccountMappingModel
+		asDiags := object.As(ctx, &mapping, basetypes.ObjectAsOptions{})
+		diags.Append(asDiags...)
+		if asDiags.HasError() || mapping.Destinations.IsUnknown() {
+			continue
+		}
+		weights := make(map[string]int64)
+		for _, element := range mapping.Destinations.Elements() {
+			object, ok := element.(types.Object)
+			if !ok || object.IsUnknown() {
+				continue
+			}
+			var destination AccountMappingDestinationModel
+			asDiags := object.As(ctx, &destination, basetypes.ObjectAsOptions{})
+			diags.Append(asDiags...)
+			if asDiags.HasError() {
+				continue
+			}
+			if !destination.Destination.IsUnknown() {
+				if err := checkMappingDestination(source, destination.Destination.ValueString()); err != nil {
+					diags.AddAttributeError(path.Root("mappings").AtMapKey(source), "invalid mapping destination", "The destination of the mapping of "+source+" is invalid: "+err.Error()+".")
+				}
+			}
+			if destination.Weight.IsUnknown() || destination.Cluster.IsUnknown() {
+				continue
+			}
+			weight := int64(100)
+			if !destination.Weight.IsNull() {
+				weight = destination.Weight.ValueInt64()
+			}
+			weights[destination.Cluster.ValueString()] += weight
+		}
+		for cluster, weight := range weights {
+			if weight <= 100 {
+				continue
+			}
+			where := ""
+			if cluster != "" {
+				where = " in cluster " + cluster
+			}
+			diags.AddAttributeError(path.Root("mappings").AtMapKey(source), "invalid mapping weights", fmt.Sprintf("The weights of the destinations of %s%s add up to %d, which is more than 100.", source, where, weight))
+		}
+	}
+	return diags
+}
+
+// revokeNow is the revocation time resolved to the time of the apply.
+const revokeNow = "now"
+
+// planRevocations plans the Unix time of each revocation. Revocations at now
+// keep their prior time when they were at now before, and are otherwise
+// planned as unknown until the JWT is issued.
+func planRevocations(ctx context.Context, state tfsdk.State, plan *tfsdk.Plan) diag.Diagnostics {
+	if plan.Raw.IsNull() {
+		return nil
+	}
+
+	var revocations types.Map
+	diags := plan.GetAttribute(ctx, path.Root("revocations"), &revocations)
+	var priorRevocations, priorUnix types.Map
+	if !state.Raw.IsNull() {
+		diags.Append(state.GetAttribute(ctx, path.Root("revocations"), &priorRevocations)...)
+		diags.Append(state.GetAttribute(ctx, path.Root("revocations_unix"), &priorUnix)...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	switch {
+	case revocations.IsUnknown():
+		return plan.SetAttribute(ctx, path.Root("revocations_unix"), types.MapUnknown(types.Int64Type))
+	case revocations.IsNull():
+		return plan.SetAttribute(ctx, path.Root("revocations_unix"), types.MapNull(types.Int64Type))
+	}
+	unix := make(map[string]attr.Value, len(revocations.Elements()))
+	for key, element := range revocations.Elements() {
+		unix[key] = types.Int64Unknown()
+		value, ok := element.(types.String)
+		if !ok || value.IsUnknown() {
+			continue
+		}
+		if value.ValueString() == revokeNow {
+			if prior, ok := priorRevocations.Elements()[key].(types.String); ok && prior.ValueString() == revokeNow {
+				if priorTime, ok := priorUnix.Elements()[key]; ok {
+					unix[key] = priorTime
+				}
+			}
+			continue
+		}
+		// An invalid time is reported by the attribute validator
+		if revokedAt, err := time.Parse(time.RFC3339, value.ValueString()); err == nil {
+			unix[key] = types.Int64Value(revokedAt.Unix())
+		}
+	}
+	planned, mapDiags := types.MapValue(types.Int64Type, unix)
+	diags.Append(mapDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	return plan.SetAttribute(ctx, path.Root("revocations_unix"), planned)
+}
+
+// revoke adds the revocations to claims and sets their Unix times, keeping
+// the planned times and resolving the others to now.
+func (m *AccountClaimsModel) revoke(claims *jwt.AccountClaims, now time.Time) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	unix := make(map[string]attr.Value, len(m.Revocations.Elements()))
+	for key, element := range m.Revocations.Elements() {
+		revokedAt := now
+		if planned, ok := m.RevocationsUnix.Elements()[key].(types.Int64); ok && !planned.IsUnknown() {
+			revokedAt = time.Unix(planned.ValueInt64(), 0)
+		} else if value, ok := element.(types.String); ok && value.ValueString() != revokeNow {
+			parsed, err := time.Parse(time.RFC3339, value.ValueString())
+			if err != nil {
+				diags.AddAttributeError(path.Root("revocations"), "invalid revocation", "The revocation of "+key+" could not be parsed: "+err.Error())
+				return diags
+			}
+			revokedAt = parsed
+		}
+		claims.RevokeAt(key, revokedAt)
+		unix[key] = types.Int64Value(revokedAt.Unix())
+	}
+	var mapDiags diag.Diagnostics
+	m.RevocationsUnix, mapDiags = types.MapValue(types.Int64Type, unix)
+	diags.Append(mapDiags...)
+	return diags
+}
+
+// checkScopedSigningKeys reports keys that are both plain and scoped signing
+// keys.
+func (m *AccountClaimsModel) checkScopedSigningKeys() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.SigningKeys.IsUnknown() || m.ScopedSigningKeys.IsUnknown() {
+		return diags
+	}
+	for _, element := range m.SigningKeys.Elements() {
+		key, ok := element.(types.String)
+		if !ok || key.IsUnknown() {
+			continue
+		}
+		if _, ok := m.ScopedSigningKeys.Elements()[key.ValueString()]; ok {
+			diags.AddAttributeError(path.Root("scoped_signing_keys"), "signing key is also scoped", "The signing key "+key.ValueString()+" is in both signing_keys and scoped_signing_keys. A signing key is either plain or scoped, so remove it from one of them.")
+		}
+	}
+	return diags
+}
+
+// checkSigningKeys warns when the account has no signing keys although the
+// operator only accepts JWTs signed by signing keys. An invalid operator_jwt
+// is left for checkOperator.
+func (m *AccountClaimsModel) checkSigningKeys() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.SigningKeys.IsUnknown() || m.ScopedSigningKeys.IsUnknown() || len(m.SigningKeys.Elements())+len(m.ScopedSigningKeys.Elements()) > 0 {
+		return diags
+	}
+	operator, err := jwt.DecodeOperatorClaims(m.OperatorJWT.ValueString())
+	if err != nil || !operator.StrictSigningKeyUsage {
+		return diags
+	}
+	diags.AddAttributeWarning(path.Root("signing_keys"), "no signing keys", "Operator "+operator.Name+" has strict signing key usage, which rejects user JWTs signed by the account nkey, but signing_keys and scoped_signing_keys are empty, so no user JWT of this account will be accepted.")
+	return diags
+}
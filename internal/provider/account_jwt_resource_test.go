@@ -0,0 +1,522 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// accountJWTConfig returns the configuration of nkey_account_jwt.test, the
+// JWT of the test account signed by the test operator, with the attributes of
+// body.
+func accountJWTConfig(body string) string {
+	return `
+resource "nkey_account_jwt" "test" {
+  subject      = "` + testAccountPublicKey + `"
+  signing_seed = "` + testOperatorSeed + `"
+  name         = "test"
+` + body + `
+}
+`
+}
+
+// accountJWTStep returns a step that applies accountJWTConfig of body with
+// action, issuing the JWT again when reissued is set, and checks its claims.
+func accountJWTStep(body string, action tfjson.Action, reissued bool, check func(t *testing.T, claims *jwt.AccountClaims)) testStep {
+	return jwtStep(accountJWTConfig(body), "nkey_account_jwt.test", action, reissued, jwt.DecodeAccountClaims, func(t *testing.T, state *testState, claims *jwt.AccountClaims) {
+		if claims.Subject != testAccountPublicKey || claims.Issuer != testOperatorPublicKey {
+			t.Fatalf("the JWT is issued to %s by %s", claims.Subject, claims.Issuer)
+		}
+		check(t, claims)
+	})
+}
+
+func TestAccountLimitsUnset(t *testing.T) {
+	var limits jwt.OperatorLimits
+	(&AccountLimitsModel{}).setLimits(&limits)
+	if limits.Conn != jwt.NoLimit || limits.LeafNodeConn != jwt.NoLimit || limits.Data != jwt.NoLimit || limits.Payload != jwt.NoLimit || limits.Subs != jwt.NoLimit {
+		t.Errorf("setLimits() of unset limits = %+v, want every limit at %d", limits, jwt.NoLimit)
+	}
+}
+
+func TestAccountJWTResourceLimits(t *testing.T) {
+	// expectLimits checks the limits of the claims against those of nsc,
+	// every limit being unlimited but the ones in want
+	expectLimits := func(want map[string]int64) func(t *testing.T, claims *jwt.AccountClaims) {
+		return func(t *testing.T, claims *jwt.AccountClaims) {
+			limits := claims.Limits
+			for name, got := range map[string]int64{
+				"max_connections":          limits.Conn,
+				"max_leafnode_connections": limits.LeafNodeConn,
+				"max_data":                 limits.Data,
+				"max_payload":              limits.Payload,
+				"max_subscriptions":        limits.Subs,
+			} {
+				value, ok := want[name]
+				if !ok {
+					value = jwt.NoLimit
+				}
+				if got != value {
+					t.Errorf("%s = %d in the JWT, want %d", name, got, value)
+				}
+			}
+		}
+	}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			// Unset limits are unlimited rather than 0
+			accountJWTStep("", tfjson.ActionCreate, true, expectLimits(nil)),
+			accountJWTStep("limits = {}", tfjson.ActionUpdate, false, expectLimits(nil)),
+			// Changing a limit issues the JWT again in place
+			accountJWTStep(`
+  limits = {
+    max_connections = 10
+    max_data        = 0
+  }`, tfjson.ActionUpdate, true, expectLimits(map[string]int64{"max_connections": 10, "max_data": 0})),
+			accountJWTStep(`
+  limits = {
+    max_connections          = 10
+    max_leafnode_connections = 2
+    max_data                 = 1048576
+    max_payload              = 1024
+    max_subscriptions        = 100
+  }`, tfjson.ActionUpdate, true, expectLimits(map[string]int64{
+				"max_connections":          10,
+				"max_leafnode_connections": 2,
+				"max_data":                 1048576,
+				"max_payload":              1024,
+				"max_subscriptions":        100,
+			})),
+			// Setting limits to -1 is the same as leaving them unset
+			accountJWTStep(`
+  limits = {
+    max_connections   = -1
+    max_subscriptions = -1
+  }`, tfjson.ActionUpdate, true, expectLimits(nil)),
+			accountJWTStep("", tfjson.ActionUpdate, false, expectLimits(nil)),
+			{
+				Config:      accountJWTConfig("limits = { max_payload = -2 }"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Attribute limits.max_payload value must be at least -1, got: -2`),
+			},
+		},
+	})
+}
+
+func TestAccountJWTResourceJetStream(t *testing.T) {
+	// expectJetStream checks the JetStream limits of the claims, and that
+	// JetStream is enabled exactly when enabled is set
+	expectJetStream := func(want jwt.JetStreamLimits, enabled bool) func(t *testing.T, claims *jwt.AccountClaims) {
+		return func(t *testing.T, claims *jwt.AccountClaims) {
+			if got := claims.Limits.JetStreamLimits; got != want {
+				t.Errorf("JetStream limits = %+v, want %+v", got, want)
+			}
+			if got := claims.Limits.IsJSEnabled(); got != enabled {
+				t.Errorf("JetStream is enabled: %v, want %v", got, enabled)
+			}
+		}
+	}
+	unlimited := jwt.JetStreamLimits{
+		MemoryStorage: jwt.NoLimit,
+		DiskStorage:   jwt.NoLimit,
+		Streams:       jwt.NoLimit,
+		Consumer:      jwt.NoLimit,
+		MaxAckPending: jwt.NoLimit,
+	}
+	disabled := jwt.JetStreamLimits{Streams: jwt.NoLimit, Consumer: jwt.NoLimit, MaxAckPending: jwt.NoLimit}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			// Without a jetstream block JetStream stays disabled
+			accountJWTStep("", tfjson.ActionCreate, true, expectJetStream(jwt.JetStreamLimits{}, false)),
+			accountJWTStep("jetstream_enabled = false", tfjson.ActionUpdate, false, expectJetStream(jwt.JetStreamLimits{}, false)),
+			// So it does with a block that leaves both storages unset
+			accountJWTStep("jetstream = { streams = 10 }", tfjson.ActionUpdate, true, expectJetStream(jwt.JetStreamLimits{Streams: 10, Consumer: jwt.NoLimit, MaxAckPending: jwt.NoLimit}, false)),
+			accountJWTStep("jetstream = {}", tfjson.ActionUpdate, true, expectJetStream(disabled, false)),
+			accountJWTStep(`
+  jetstream = {
+    disk_storage    = 1073741824
+    streams         = 10
+    consumers       = 100
+    max_ack_pending = 1000
+  }`, tfjson.ActionUpdate, true, expectJetStream(jwt.JetStreamLimits{DiskStorage: 1073741824, Streams: 10, Consumer: 100, MaxAckPending: 1000}, true)),
+			accountJWTStep("jetstream = { memory_storage = -1 }", tfjson.ActionUpdate, true, expectJetStream(jwt.JetStreamLimits{MemoryStorage: jwt.NoLimit, Streams: jwt.NoLimit, Consumer: jwt.NoLimit, MaxAckPending: jwt.NoLimit}, true)),
+			accountJWTStep("jetstream_enabled = true", tfjson.ActionUpdate, true, expectJetStream(unlimited, true)),
+			// The shorthand issues the same claims as the block it stands for
+			accountJWTStep(`
+  jetstream = {
+    memory_storage  = -1
+    disk_storage    = -1
+    streams         = -1
+    consumers       = -1
+    max_ack_pending = -1
+  }`, tfjson.ActionUpdate, false, expectJetStream(unlimited, true)),
+			accountJWTStep("", tfjson.ActionUpdate, true, expectJetStream(jwt.JetStreamLimits{}, false)),
+			{
+				Config: accountJWTConfig(`
+  jetstream_enabled = true
+  jetstream         = { disk_storage = 1024 }`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`These attributes cannot be configured together: \[jetstream,jetstream_enabled,jetstream_tiered_limits\]`),
+			},
+			{
+				Config: accountJWTConfig(`
+  jetstream_enabled       = false
+  jetstream_tiered_limits = { R1 = { disk_storage = 1024 } }`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`These attributes cannot be configured together`),
+			},
+		},
+	})
+}
+
+func TestAccountJWTResourceScopedSigningKeys(t *testing.T) {
+	const scoped = `
+  signing_keys = ["` + testAccountSigningPublicKey + `"]
+  scoped_signing_keys = {
+    "` + testAccountScopedPublicKey + `" = {
+      role        = "frontend"
+      description = "Users of the web frontend"
+      permissions = {
+        publish   = { allow = ["billing.charge"], deny = ["billing.admin.>"] }
+        subscribe = { allow = ["_INBOX.>"] }
+        response  = { max = 5, ttl = "30s" }
+      }
+      limits                   = { max_subscriptions = 10, max_payload = 1024 }
+      allowed_connection_types = ["websocket", "STANDARD"]
+      bearer_token             = true
+    }
+  }`
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			accountJWTStep(scoped, tfjson.ActionCreate, true, func(t *testing.T, claims *jwt.AccountClaims) {
+				var vr jwt.ValidationResults
+				claims.Validate(&vr)
+				if errs := vr.Errors(); len(errs) != 0 {
+					t.Errorf("the decoded claims do not validate: %v", errs)
+				}
+
+				if scope, ok := claims.SigningKeys.GetScope(testAccountSigningPublicKey); !ok || scope != nil {
+					t.Errorf("the plain signing key has the scope %v, %v", scope, ok)
+				}
+				got, ok := claims.SigningKeys.GetScope(testAccountScopedPublicKey)
+				scope, isUserScope := got.(*jwt.UserScope)
+				if !ok || !isUserScope {
+					t.Fatalf("the scoped signing key has the scope %v, %v", got, ok)
+				}
+				want := jwt.NewUserScope()
+				want.Key = testAccountScopedPublicKey
+				want.Role = "frontend"
+				want.Description = "Users of the web frontend"
+				want.Template.Pub = jwt.Permission{Allow: jwt.StringList{"billing.charge"}, Deny: jwt.StringList{"billing.admin.>"}}
+				want.Template.Sub = jwt.Permission{Allow: jwt.StringList{"_INBOX.>"}}
+				want.Template.Resp = &jwt.ResponsePermission{MaxMsgs: 5, Expires: 30 * time.Second}
+				want.Template.Subs = 10
+				want.Template.Payload = 1024
+				want.Template.AllowedConnectionTypes = jwt.StringList{"STANDARD", "WEBSOCKET"}
+				want.Template.BearerToken = true
+				if scope.Key != want.Key || scope.Role != want.Role || scope.Description != want.Description {
+					t.Errorf("scope = %+v, want %+v", scope, want)
+				}
+				template, wantTemplate := scope.Template, want.Template
+				if !slices.Equal(template.Pub.Allow, wantTemplate.Pub.Allow) || !slices.Equal(template.Pub.Deny, wantTemplate.Pub.Deny) ||
+					!slices.Equal(template.Sub.Allow, wantTemplate.Sub.Allow) || len(template.Sub.Deny) != 0 ||
+					template.Resp == nil || *template.Resp != *wantTemplate.Resp {
+					t.Errorf("template permissions = %+v, want %+v", template.Permissions, wantTemplate.Permissions)
+				}
+				if template.NatsLimits != wantTemplate.NatsLimits || template.BearerToken != wantTemplate.BearerToken ||
+					!slices.Equal(template.AllowedConnectionTypes, wantTemplate.AllowedConnectionTypes) {
+					t.Errorf("template = %+v, want %+v", template, wantTemplate)
+				}
+			}),
+			// A key is either plain or scoped
+			{
+				Config: accountJWTConfig(`
+  signing_keys        = ["` + testAccountScopedPublicKey + `"]
+  scoped_signing_keys = { "` + testAccountScopedPublicKey + `" = { role = "frontend" } }`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`The signing key ` + testAccountScopedPublicKey + ` is in both signing_keys and scoped_signing_keys`),
+			},
+			{
+				Config:      accountJWTConfig(`scoped_signing_keys = { "` + testUserPublicKey + `" = { role = "frontend" } }`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`scoped_signing_keys`),
+			},
+		},
+	})
+}
+
+func TestAccountJWTResourceRevocations(t *testing.T) {
+	const alice = `
+resource "nkey_user_jwt" "alice" {
+  subject      = "` + testUserPublicKey + `"
+  signing_seed = "` + testAccountSeed + `"
+  name         = "alice"
+}
+`
+	config := func(revocations string) string {
+		return accountJWTConfig("revocations = {"+revocations+"}") + alice
+	}
+	const revokeUser = `"` + testUserPublicKey + `" = "2024-01-01T00:00:00Z"`
+	revokedUserAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// userClaims returns the claims of a user JWT of subject issued at
+	// issuedAt
+	userClaims := func(subject string, issuedAt time.Time) *jwt.UserClaims {
+		claims := jwt.NewUserClaims(subject)
+		claims.IssuedAt = issuedAt.Unix()
+		return claims
+	}
+	// expectRevoked checks whether the JWT of alice in state is revoked by
+	// the account, and that revocations_unix holds the revocations of the JWT
+	expectRevoked := func(t *testing.T, state *testState, revoked bool) *jwt.AccountClaims {
+		t.Helper()
+
+		claims, err := jwt.DecodeAccountClaims(state.stringAttribute(t, "nkey_account_jwt.test", "jwt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		aliceClaims, err := jwt.DecodeUserClaims(state.stringAttribute(t, "nkey_user_jwt.alice", "jwt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := claims.IsClaimRevoked(aliceClaims); got != revoked {
+			t.Errorf("the JWT of alice issued at %d is revoked: %v, want %v", aliceClaims.IssuedAt, got, revoked)
+		}
+		unix, _ := state.attribute(t, "nkey_account_jwt.test", "revocations_unix").(map[string]interface{})
+		if len(unix) != len(claims.Revocations) {
+			t.Errorf("revocations_unix = %v, want those of the JWT %v", unix, claims.Revocations)
+		}
+		for key, at := range claims.Revocations {
+			if got := fmt.Sprint(unix[key]); got != strconv.FormatInt(at, 10) {
+				t.Errorf("revocations_unix[%q] = %v, want %d", key, unix[key], at)
+			}
+		}
+		return claims
+	}
+
+	var start time.Time
+	var revokedAllAt int64
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			// A JWT issued to a revoked user after the revocation is valid
+			{
+				Config: config(revokeUser),
+				Check: func(t *testing.T, state *testState) {
+					claims := expectRevoked(t, state, false)
+					if got := claims.Revocations[testUserPublicKey]; got != revokedUserAt.Unix() {
+						t.Errorf("the revocation of the user is at %d, want %d", got, revokedUserAt.Unix())
+					}
+					for _, issuedAt := range []time.Time{revokedUserAt.Add(-time.Hour), revokedUserAt} {
+						if !claims.IsClaimRevoked(userClaims(testUserPublicKey, issuedAt)) {
+							t.Errorf("a JWT issued at %s is not revoked", issuedAt)
+						}
+					}
+					if claims.IsClaimRevoked(userClaims(testUserPublicKey, revokedUserAt.Add(time.Second))) {
+						t.Error("a JWT issued after the revocation is revoked")
+					}
+					if claims.IsClaimRevoked(userClaims(testAccountSigningPublicKey, revokedUserAt)) {
+						t.Error("the JWT of another user is revoked")
+					}
+				},
+			},
+			// Revoking all users now revokes alice, who was issued before
+			{
+				Config: config(revokeUser + `, "*" = "now"`),
+				PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+					start = time.Now().Truncate(time.Second)
+					expectActions(t, plan, "nkey_account_jwt.test", tfjson.ActionUpdate)
+				},
+				Check: func(t *testing.T, state *testState) {
+					claims := expectRevoked(t, state, true)
+					revokedAllAt = claims.Revocations[jwt.All]
+					if revokedAllAt < start.Unix() || revokedAllAt > time.Now().Unix() {
+						t.Errorf("now was resolved to %d, not to the time of the apply", revokedAllAt)
+					}
+					if !claims.IsClaimRevoked(userClaims(testAccountSigningPublicKey, time.Unix(revokedAllAt, 0))) {
+						t.Error("the JWT of another user is not revoked by *")
+					}
+					if claims.IsClaimRevoked(userClaims(testAccountSigningPublicKey, time.Unix(revokedAllAt+1, 0))) {
+						t.Error("a JWT issued after the revocation of all users is revoked")
+					}
+				},
+			},
+			// now keeps the time it was resolved to
+			{
+				PreConfig: func(t *testing.T) {
+					time.Sleep(time.Second)
+				},
+				Config: config(revokeUser + `, "*" = "now"`),
+				PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+					expectActions(t, plan, "nkey_account_jwt.test", tfjson.ActionNoop)
+				},
+				Check: func(t *testing.T, state *testState) {
+					if got := expectRevoked(t, state, true).Revocations[jwt.All]; got != revokedAllAt {
+						t.Errorf("the revocation of all users moved from %d to %d", revokedAllAt, got)
+					}
+				},
+			},
+			{
+				Config: config(revokeUser),
+				Check: func(t *testing.T, state *testState) {
+					expectRevoked(t, state, false)
+				},
+			},
+			{
+				Config:      config(`"` + testAccountPublicKey + `" = "now"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`revocations`),
+			},
+			{
+				Config:      config(`"` + testUserPublicKey + `" = "yesterday"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`revocations`),
+			},
+		},
+	})
+}
+
+func TestAccountJWTResourceMetadata(t *testing.T) {
+	operator, err := nkeys.FromSeed([]byte(testOperatorSeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// expectMetadata checks the metadata of the claims, also once they are
+	// encoded again
+	expectMetadata := func(tags ...string) func(t *testing.T, claims *jwt.AccountClaims) {
+		return func(t *testing.T, claims *jwt.AccountClaims) {
+			encoded, err := claims.Encode(operator)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := jwt.DecodeAccountClaims(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, claims := range []*jwt.AccountClaims{claims, again} {
+				if claims.Description != "Invoicing and payments" || claims.InfoURL != "https://wiki.example.com/billing" {
+					t.Errorf("description = %q, info_url = %q", claims.Description, claims.InfoURL)
+				}
+				if !slices.Equal(claims.Tags, jwt.TagList(tags)) {
+					t.Errorf("tags = %q, want %q", claims.Tags, tags)
+				}
+			}
+		}
+	}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			// Tags are lowercased and sorted in the JWT, as nsc writes them
+			accountJWTStep(`
+  description = "Invoicing and payments"
+  info_url    = "https://wiki.example.com/billing"
+  tags        = ["Team:Payments", "env:prod"]`, tfjson.ActionCreate, true, expectMetadata("env:prod", "team:payments")),
+			// Neither their order nor their case changes the plan
+			accountJWTStep(`
+  description = "Invoicing and payments"
+  info_url    = "https://wiki.example.com/billing"
+  tags        = ["ENV:PROD", "team:payments"]`, tfjson.ActionNoop, false, expectMetadata("env:prod", "team:payments")),
+			accountJWTStep(`
+  description = "Invoicing and payments"
+  info_url    = "https://wiki.example.com/billing"
+  tags        = ["team:payments", "env:prod", "Cost:42"]`, tfjson.ActionUpdate, true, expectMetadata("cost:42", "env:prod", "team:payments")),
+			{
+				Config:      accountJWTConfig(`info_url = "ftp://wiki.example.com/billing"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`info_url`),
+			},
+			{
+				Config:      accountJWTConfig(`info_url = "wiki.example.com/billing"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`info_url`),
+			},
+		},
+	})
+}
+
+func TestAccountJWTResourceTrace(t *testing.T) {
+	// step checks that the trace of the JWT is want, and that the payload of
+	// the JWT only holds a trace when want is set
+	step := func(body string, action tfjson.Action, want *jwt.MsgTrace) testStep {
+		return testStep{
+			Config: accountJWTConfig(body),
+			PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+				expectActions(t, plan, "nkey_account_jwt.test", action)
+			},
+			Check: func(t *testing.T, state *testState) {
+				token := state.stringAttribute(t, "nkey_account_jwt.test", "jwt")
+				claims, err := jwt.DecodeAccountClaims(token)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if (claims.Trace == nil) != (want == nil) || (want != nil && *claims.Trace != *want) {
+					t.Errorf("trace = %+v, want %+v", claims.Trace, want)
+				}
+
+				parts := strings.Split(token, ".")
+				if len(parts) != 3 {
+					t.Fatalf("the JWT has %d parts", len(parts))
+				}
+				payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+				if err != nil {
+					t.Fatal(err)
+				}
+				var decoded struct {
+					Nats map[string]json.RawMessage `json:"nats"`
+				}
+				if err := json.Unmarshal(payload, &decoded); err != nil {
+					t.Fatal(err)
+				}
+				if _, ok := decoded.Nats["trace"]; ok != (want != nil) {
+					t.Errorf("the JWT holds a trace: %v, want %v", ok, want != nil)
+				}
+			},
+		}
+	}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			step("", tfjson.ActionCreate, nil),
+			// The jwt library samples every message unless sampling is set
+			step(`trace = { destination = "trace.billing" }`, tfjson.ActionUpdate, &jwt.MsgTrace{Destination: "trace.billing", Sampling: 100}),
+			step(`trace = { destination = "trace.billing", sampling = 25 }`, tfjson.ActionUpdate, &jwt.MsgTrace{Destination: "trace.billing", Sampling: 25}),
+			step("", tfjson.ActionUpdate, nil),
+			{
+				Config:      accountJWTConfig(`trace = { destination = "trace.>" }`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`trace.destination`),
+			},
+			{
+				Config:      accountJWTConfig(`trace = { destination = "trace.*.billing" }`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`trace.destination`),
+			},
+			{
+				Config:      accountJWTConfig(`trace = { destination = "trace.billing", sampling = 0 }`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Attribute trace.sampling value must be between 1 and 100, got: 0`),
+			},
+			{
+				Config:      accountJWTConfig(`trace = { destination = "trace.billing", sampling = 101 }`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Attribute trace.sampling value must be between 1 and 100, got: 101`),
+			},
+		},
+	})
+}
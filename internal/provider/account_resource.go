@@ -0,0 +1,185 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Account{}
+var _ resource.ResourceWithModifyPlan = &Account{}
+var _ resource.ResourceWithValidateConfig = &Account{}
+var _ resource.ResourceWithConfigValidators = &Account{}
+
+func NewAccount() resource.Resource {
+	return &Account{}
+}
+
+// Account defines the resource implementation.
+type Account struct {
+}
+
+// AccountModel describes the resource data model.
+type AccountModel struct {
+	AccountClaimsModel
+	GeneratedKeyModel
+}
+
+func (r *Account) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_account"
+}
+
+func (r *Account) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "An account generates an account nkey, or takes an existing seed, and issues its account JWT signed by the operator nkey or one of its signing keys, with the same claims as `nkey_account_jwt`. The token is kept in state and only issued again when one of its claims, the signing key or the account nkey changes.\n\n" +
+			"Deleting the resource only removes it from state. It does not revoke anything: NATS servers that have the JWT keep accepting the account until the JWT expires or is removed from their resolver.",
+
+		Attributes: jwtResourceAttributes(generatedKeyAttributes(accountClaimsAttributes(), "account", "The users and activation tokens issued to the previous nkey are not valid for the new one.")),
+	}
+}
+
+func (r *Account) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return append(accountConfigValidators(), generatedKeyConfigValidators()...)
+}
+
+func (r *Account) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data AccountModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.validate(ctx, data.plannedSubject())...)
+	if claimsConfigKnown(req.Config) {
+		resp.Diagnostics.Append(data.validateAdditionalClaims(ctx, data.plannedSubject().ValueString())...)
+	}
+}
+
+func (r *Account) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	resp.Diagnostics.Append(planGeneratedKey(ctx, req.Config, req.State, &resp.Plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(planJWTReissue(ctx, req.Config, req.State, &resp.Plan, "operator_jwt", "revocations_unix")...)
+	resp.Diagnostics.Append(planRevocations(ctx, req.State, &resp.Plan)...)
+	if resp.Diagnostics.HasError() || req.State.Raw.IsNull() || resp.Plan.Raw.IsNull() || !claimsConfigKnown(req.Config) {
+		return
+	}
+
+	var plan, state AccountModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() || plan.PublicKey.IsUnknown() {
+		return
+	}
+	resp.Diagnostics.Append(plan.planUnchanged(ctx, &resp.Plan, &state.AccountClaimsModel, plan.PublicKey.ValueString())...)
+}
+
+func (r *Account) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data AccountModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.setKey(nkeys.PrefixByteAccount, "account")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(data.issue(ctx, req.Config, data.PublicKey.ValueString())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created account resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Account) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data AccountModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The key and token never change outside of Terraform, so the only thing
+	// to check is that the stored seed still derives the stored public key
+	// and that the stored token is issued to it.
+	claims, err := jwt.DecodeAccountClaims(data.JWT.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted account state", "The stored JWT could not be decoded: "+err.Error())
+		return
+	}
+	resp.Diagnostics.Append(data.checkKey("account", claims.Subject)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Tags = canonicalTags(data.Tags)
+	resp.Diagnostics.Append(data.expiryWarnings(time.Now())...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Account) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	// ModifyPlan planned the key as unknown when it is rotated, and the token
+	// as unknown when the claims, the signing key or the account nkey changed.
+	var plan AccountModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(plan.setKey(nkeys.PrefixByteAccount, "account")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.JWT.IsUnknown() {
+		resp.Diagnostics.Append(plan.issue(ctx, req.Config, plan.PublicKey.ValueString())...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *Account) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed. The account is
+	// not revoked, which the operator JWT has no notion of.
+	tflog.Trace(ctx, "deleted account resource")
+}
@@ -0,0 +1,220 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ActivationJWTDataSource{}
+
+func NewActivationJWTDataSource() datasource.DataSource {
+	return &ActivationJWTDataSource{}
+}
+
+// ActivationJWTDataSource defines the data source implementation.
+type ActivationJWTDataSource struct {
+}
+
+// ActivationJWTDataSourceModel describes the data source data model.
+type ActivationJWTDataSourceModel struct {
+	JWT                   types.String `tfsdk:"jwt"`
+	ExpectedIssuerAccount types.String `tfsdk:"expected_issuer_account"`
+	ExpectedSubject       types.String `tfsdk:"expected_subject"`
+	ExpectedImporter      types.String `tfsdk:"expected_importer"`
+	AccountJWT            types.String `tfsdk:"account_jwt"`
+	Name                  types.String `tfsdk:"name"`
+	Issuer                types.String `tfsdk:"issuer"`
+	IssuerAccount         types.String `tfsdk:"issuer_account"`
+	ImporterAccount       types.String `tfsdk:"importer_account"`
+	ExportSubject         types.String `tfsdk:"export_subject"`
+	ExportType            types.String `tfsdk:"export_type"`
+	IssuedAt              types.String `tfsdk:"issued_at"`
+	ExpiresAt             types.String `tfsdk:"expires_at"`
+	NotBefore             types.String `tfsdk:"not_before"`
+}
+
+func (d *ActivationJWTDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_activation_jwt"
+}
+
+func (d *ActivationJWTDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Decodes an activation JWT, e.g. one sent by the exporting account of a partner, verifies its signature and fails when it is expired or does not match the expectations, so a plan fails before the token goes into the `token` of an import.",
+
+		Attributes: map[string]schema.Attribute{
+			"jwt": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The encoded activation JWT",
+			},
+			"expected_issuer_account": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Public key of the account the token is expected to be issued by, the exporting account",
+				Validators: []validator.String{
+					isPublicKeyOfType("account"),
+				},
+			},
+			"expected_subject": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Subject the token is expected to activate, e.g. the subject of the import. It must be contained in the `export_subject` of the token",
+				Validators: []validator.String{
+					isSubject(),
+				},
+			},
+			"expected_importer": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Public key of the account the token is expected to be issued to, the importing account",
+				Validators: []validator.String{
+					isPublicKeyOfType("account"),
+				},
+			},
+			"account_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "JWT of the exporting account. When set, it must be the JWT of the issuer account of the token, and the token must be signed by the account nkey or one of its signing keys",
+			},
+			"name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Name of the activation, or null when it has none",
+			},
+			"issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the nkey that signed the token, whose signature is verified",
+			},
+			"issuer_account": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the exporting account, which is the issuer unless a signing key of the account signed the token",
+			},
+			"importer_account": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the importing account, the subject of the token",
+			},
+			"export_subject": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Subject the token activates, which may contain wildcards",
+			},
+			"export_type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Type of the export the token activates, either `stream` or `service`",
+			},
+			"issued_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp at which the token was issued",
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp at which the token expires, or null when it never expires",
+			},
+			"not_before": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp before which the token is not valid, or null when it is valid from when it was issued",
+			},
+		},
+	}
+}
+
+func (d *ActivationJWTDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data ActivationJWTDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Decoding verifies the signature against the issuer of the token
+	claims, err := jwt.DecodeActivationClaims(data.JWT.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "invalid activation JWT", "The jwt is not a validly signed activation JWT: "+err.Error())
+		return
+	}
+	vr := jwt.ValidationResults{}
+	claims.Activation.Validate(&vr)
+	for _, issue := range vr.Issues {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "invalid activation JWT", "The claims of the activation JWT are invalid: "+issue.Description+".")
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	issuerAccount := claims.IssuerAccount
+	if issuerAccount == "" {
+		issuerAccount = claims.Issuer
+	}
+	if !nkeys.IsValidPublicAccountKey(issuerAccount) {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "invalid activation JWT", "The activation JWT is issued by "+issuerAccount+", which is not an account.")
+		return
+	}
+
+	data.Name = types.StringNull()
+	if claims.Name != "" {
+		data.Name = types.StringValue(claims.Name)
+	}
+	data.Issuer = types.StringValue(claims.Issuer)
+	data.IssuerAccount = types.StringValue(issuerAccount)
+	data.ImporterAccount = types.StringValue(claims.Subject)
+	data.ExportSubject = types.StringValue(string(claims.ImportSubject))
+	data.ExportType = types.StringValue(claims.ImportType.String())
+	data.IssuedAt = types.StringValue(time.Unix(claims.IssuedAt, 0).UTC().Format(time.RFC3339))
+	data.ExpiresAt = types.StringNull()
+	if claims.Expires != 0 {
+		data.ExpiresAt = types.StringValue(time.Unix(claims.Expires, 0).UTC().Format(time.RFC3339))
+	}
+	data.NotBefore = types.StringNull()
+	if claims.NotBefore != 0 {
+		data.NotBefore = types.StringValue(time.Unix(claims.NotBefore, 0).UTC().Format(time.RFC3339))
+	}
+
+	now := time.Now()
+	if claims.Expires != 0 && now.Unix() > claims.Expires {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "activation JWT expired", "The activation JWT expired at "+data.ExpiresAt.ValueString()+", so nats-server rejects the import. Ask the exporting account for a new one.")
+	}
+	if claims.NotBefore != 0 && now.Unix() < claims.NotBefore {
+		resp.Diagnostics.AddAttributeWarning(path.Root("jwt"), "activation JWT not yet valid", "The activation JWT is only valid from "+data.NotBefore.ValueString()+", until then nats-server rejects the import.")
+	}
+	if !data.ExpectedIssuerAccount.IsNull() && data.ExpectedIssuerAccount.ValueString() != issuerAccount {
+		resp.Diagnostics.AddAttributeError(path.Root("expected_issuer_account"), "issuer account mismatch", "The activation JWT is issued by the account "+issuerAccount+" instead of the expected "+data.ExpectedIssuerAccount.ValueString()+".")
+	}
+	if !data.ExpectedImporter.IsNull() && data.ExpectedImporter.ValueString() != claims.Subject {
+		resp.Diagnostics.AddAttributeError(path.Root("expected_importer"), "importer mismatch", "The activation JWT is issued to the account "+claims.Subject+" instead of the expected "+data.ExpectedImporter.ValueString()+".")
+	}
+	if !data.ExpectedSubject.IsNull() && !jwt.Subject(data.ExpectedSubject.ValueString()).IsContainedIn(claims.ImportSubject) {
+		resp.Diagnostics.AddAttributeError(path.Root("expected_subject"), "subject mismatch", "The activation JWT is for the subject "+string(claims.ImportSubject)+", which does not contain the expected "+data.ExpectedSubject.ValueString()+".")
+	}
+	if !data.AccountJWT.IsNull() {
+		account, err := jwt.DecodeAccountClaims(data.AccountJWT.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("account_jwt"), "invalid account JWT", "The account_jwt could not be decoded: "+err.Error())
+			return
+		}
+		switch {
+		case account.Subject != issuerAccount:
+			resp.Diagnostics.AddAttributeError(path.Root("account_jwt"), "account mismatch", "The account_jwt is the JWT of "+account.Subject+" rather than of the issuer account of the activation JWT, "+issuerAccount+".")
+		case checkSigner(claims.Issuer, account.Subject, account.SigningKeys.Keys(), false) != nil:
+			resp.Diagnostics.AddAttributeError(path.Root("jwt"), "issuer not allowed", "The activation JWT is signed by "+claims.Issuer+", which is neither the account "+account.Name+" nor one of its signing keys.")
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "read activation JWT data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
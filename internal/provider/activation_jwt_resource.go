@@ -0,0 +1,310 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &ActivationJWT{}
+var _ resource.ResourceWithModifyPlan = &ActivationJWT{}
+var _ resource.ResourceWithValidateConfig = &ActivationJWT{}
+var _ resource.ResourceWithConfigValidators = &ActivationJWT{}
+
+func NewActivationJWT() resource.Resource {
+	return &ActivationJWT{}
+}
+
+// ActivationJWT defines the resource implementation.
+type ActivationJWT struct {
+}
+
+// ActivationJWTModel describes the resource data model.
+type ActivationJWTModel struct {
+	JWTModel
+	SigningSeed      types.String `tfsdk:"signing_seed"`
+	AccountPublicKey types.String `tfsdk:"account_public_key"`
+	AccountJWT       types.String `tfsdk:"account_jwt"`
+	Name             types.String `tfsdk:"name"`
+	ExportSubject    types.String `tfsdk:"export_subject"`
+	ExportType       types.String `tfsdk:"export_type"`
+	ImporterAccount  types.String `tfsdk:"importer_account"`
+}
+
+func (r *ActivationJWT) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_activation_jwt"
+}
+
+func (r *ActivationJWT) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "An activation JWT lets an importing account import an export with `token_required` of the exporting account, signed by the exporting account nkey or one of its signing keys. It goes into the `token` of the import. The token is kept in state and only issued again when one of its claims or the signing key changes.",
+
+		Attributes: jwtResourceAttributes(map[string]schema.Attribute{
+			"signing_seed": schema.StringAttribute{
+				Required:            true,
+				WriteOnly:           true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the exporting account nkey or of one of its signing keys, which signs the JWT. Switching to another key issues the JWT again." + signingSeedDescription,
+				Validators: []validator.String{
+					isSeedOfType("account"),
+				},
+			},
+			"account_public_key": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Public key of the exporting account nkey. When `signing_seed` is one of the signing keys of the account rather than the account nkey, the JWT names the account as its issuer account, without which nats-server rejects the import. Required in that case",
+				Validators: []validator.String{
+					isPublicKeyOfType("account"),
+				},
+			},
+			"account_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "JWT of the exporting account, e.g. from `nkey_account_jwt`. When set, `signing_seed` must be the account nkey or one of its signing keys, `account_public_key` must be its subject when set or needed, and the account must export `export_subject` with `export_type`. Changing it does not issue the JWT again",
+			},
+			"name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the activation",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"export_subject": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Subject the importing account may import, which may contain wildcards. It is the subject of the export or a subject contained in it, and contains the subject of the import",
+				Validators: []validator.String{
+					isSubject(),
+				},
+			},
+			"export_type": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Type of the export, either `stream` or `service`",
+				Validators: []validator.String{
+					stringvalidator.OneOf("stream", "service"),
+				},
+			},
+			"importer_account": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key of the importing account, the subject of the JWT",
+				Validators: []validator.String{
+					isPublicKeyOfType("account"),
+				},
+			},
+		}),
+	}
+}
+
+func (r *ActivationJWT) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return jwtConfigValidators()
+}
+
+func (r *ActivationJWT) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data ActivationJWTModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.validateLifetime()...)
+	if data.AccountJWT.IsUnknown() || data.AccountJWT.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(data.checkAccount()...)
+}
+
+func (r *ActivationJWT) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	resp.Diagnostics.Append(planJWTReissue(ctx, req.Config, req.State, &resp.Plan, "account_jwt")...)
+}
+
+func (r *ActivationJWT) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data ActivationJWTModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.issue(ctx, req.Config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created activation JWT resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ActivationJWT) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data ActivationJWTModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The token never changes outside of Terraform, so the only thing to
+	// check is that the stored token still decodes to the stored importer.
+	claims, err := jwt.DecodeActivationClaims(data.JWT.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted activation JWT state", "The stored JWT could not be decoded: "+err.Error())
+		return
+	}
+	if claims.Subject != data.ImporterAccount.ValueString() {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted activation JWT state", "The stored JWT is issued to "+claims.Subject+" rather than the stored importer account.")
+		return
+	}
+
+	resp.Diagnostics.Append(data.expiryWarnings(time.Now())...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ActivationJWT) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	// The token is issued again in place when ModifyPlan planned it as
+	// unknown, i.e. when the claims or the signing key changed.
+	var plan ActivationJWTModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.JWT.IsUnknown() {
+		resp.Diagnostics.Append(plan.issue(ctx, req.Config)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *ActivationJWT) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed. The activation
+	// is not revoked, which is up to the exporting account JWT.
+	tflog.Trace(ctx, "deleted activation JWT resource")
+}
+
+// issue encodes the claims of the model into a JWT issued to the importer
+// account, signed with the write-only signing seed read from config.
+func (m *ActivationJWTModel) issue(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	diags := config.GetAttribute(ctx, path.Root("signing_seed"), &m.SigningSeed)
+	if diags.HasError() {
+		return diags
+	}
+	defer func() { m.SigningSeed = types.StringNull() }()
+
+	// The attribute validator only sees seeds known at plan time
+	if _, keyType, err := publicKeyFromSeed([]byte(m.SigningSeed.ValueString())); err == nil && keyType != "account" {
+		diags.AddAttributeError(path.Root("signing_seed"), "invalid signing seed", "The signing seed is of type "+keyType+", but activation JWTs are signed by account nkeys.")
+		return diags
+	}
+
+	if !m.AccountJWT.IsNull() {
+		diags.Append(m.checkAccount()...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	claims := jwt.NewActivationClaims(m.ImporterAccount.ValueString())
+	claims.Name = m.Name.ValueString()
+	claims.IssuerAccount = issuerAccount(m.SigningSeed.ValueString(), m.AccountPublicKey.ValueString())
+	claims.ImportSubject = jwt.Subject(m.ExportSubject.ValueString())
+	claims.ImportType = jwt.Stream
+	if m.ExportType.ValueString() == "service" {
+		claims.ImportType = jwt.Service
+	}
+
+	diags.Append(m.issueJWT(claims, m.SigningSeed.ValueString())...)
+	return diags
+}
+
+// checkAccount checks the activation against the exporting account of
+// account_jwt: the signing seed must be allowed to sign for it,
+// account_public_key must be the account when the seed is a signing key, and
+// the account must export the subject with the type. The signing seed and the
+// export are only checked when they are known.
+func (m *ActivationJWTModel) checkAccount() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	account, err := jwt.DecodeAccountClaims(m.AccountJWT.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("account_jwt"), "invalid account JWT", "The account_jwt could not be decoded: "+err.Error())
+		return diags
+	}
+	if !m.ExportSubject.IsUnknown() && !m.ExportType.IsUnknown() {
+		diags.Append(m.checkExport(account)...)
+	}
+	if m.SigningSeed.IsUnknown() || m.SigningSeed.IsNull() {
+		return diags
+	}
+	signer, _, err := publicKeyFromSeed([]byte(m.SigningSeed.ValueString()))
+	if err != nil {
+		// Left for the attribute validator of signing_seed
+		return diags
+	}
+	if err := checkSigner(signer, account.Subject, account.SigningKeys.Keys(), false); err != nil {
+		diags.AddAttributeError(path.Root("signing_seed"), "signing seed not allowed", "The signing seed cannot sign activations of account "+account.Name+": "+err.Error()+".")
+		return diags
+	}
+	switch {
+	case m.AccountPublicKey.IsUnknown():
+	case m.AccountPublicKey.IsNull() && signer != account.Subject:
+		diags.AddAttributeError(path.Root("account_public_key"), "missing account public key", "The signing seed is a signing key of account "+account.Name+", so nats-server only accepts the activation when the JWT names the account. Set account_public_key to "+account.Subject+".")
+	case !m.AccountPublicKey.IsNull() && m.AccountPublicKey.ValueString() != account.Subject:
+		diags.AddAttributeError(path.Root("account_public_key"), "account mismatch", "The account_public_key is not the subject of account_jwt, "+account.Subject+".")
+	}
+	return diags
+}
+
+// checkExport checks that account exports the subject with the type, and
+// warns when the export does not require an activation.
+func (m *ActivationJWTModel) checkExport(account *jwt.AccountClaims) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	subject := jwt.Subject(m.ExportSubject.ValueString())
+	for _, export := range account.Exports {
+		if export.Type.String() != m.ExportType.ValueString() || !subject.IsContainedIn(export.Subject) {
+			continue
+		}
+		if !export.TokenReq {
+			diags.AddAttributeWarning(path.Root("export_subject"), "activation not required", "The "+m.ExportType.ValueString()+" export "+string(export.Subject)+" of account "+account.Name+" does not set token_required, so any account may import it without an activation JWT.")
+		}
+		return diags
+	}
+	diags.AddAttributeError(path.Root("export_subject"), "export not found", "The account "+account.Name+" of account_jwt has no "+m.ExportType.ValueString()+" export containing the subject "+string(subject)+".")
+	return diags
+}
@@ -0,0 +1,229 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &AuthorizationDataSource{}
+
+func NewAuthorizationDataSource() datasource.DataSource {
+	return &AuthorizationDataSource{}
+}
+
+// AuthorizationDataSource defines the data source implementation.
+type AuthorizationDataSource struct {
+}
+
+// AuthorizationDataSourceModel describes the data source data model.
+type AuthorizationDataSourceModel struct {
+	Users              types.Set    `tfsdk:"users"`
+	DefaultPermissions types.Object `tfsdk:"default_permissions"`
+	Config             types.String `tfsdk:"config"`
+}
+
+// AuthorizationUserModel describes the users attribute of
+// AuthorizationDataSourceModel.
+type AuthorizationUserModel struct {
+	PublicKey   types.String `tfsdk:"public_key"`
+	Permissions types.Object `tfsdk:"permissions"`
+}
+
+// authorizationUser is a user of the authorization block, with the
+// permissions it is rendered with.
+type authorizationUser struct {
+	publicKey   string
+	permissions *jwt.Permissions
+}
+
+func (d *AuthorizationDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_authorization"
+}
+
+func (d *AuthorizationDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Renders the `authorization` block of a nats-server configuration authenticating users by their nkey, for deployments without an operator and JWTs. The users are sorted by public key and their subjects sorted, so the configuration only changes when its contents do.",
+
+		Attributes: map[string]schema.Attribute{
+			"users": schema.SetNestedAttribute{
+				Required:            true,
+				MarkdownDescription: "The users that may connect",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"public_key": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Public key of the user nkey",
+							Validators: []validator.String{
+								isPublicKeyOfType("user"),
+							},
+						},
+						"permissions": permissionsDataSourceAttribute("Publish and subscribe permissions of the user. Users without permissions get `default_permissions`"),
+					},
+				},
+			},
+			"default_permissions": permissionsDataSourceAttribute("Publish and subscribe permissions of the users that have none of their own. Users without permissions may publish and subscribe to every subject when not set"),
+			"config": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The `authorization` block, to include in the server configuration",
+			},
+		},
+	}
+}
+
+// quoteConfigString quotes s as a string of the nats-server configuration.
+func quoteConfigString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// permissionsOf returns the permissions of an object of a PermissionsModel at
+// attribute, or nil when it is null or sets no permission.
+func permissionsOf(ctx context.Context, attribute path.Path, object types.Object) (*jwt.Permissions, diag.Diagnostics) {
+	if object.IsNull() {
+		return nil, nil
+	}
+	var model PermissionsModel
+	diags := object.As(ctx, &model, basetypes.ObjectAsOptions{})
+	if diags.HasError() {
+		return nil, diags
+	}
+	diags.Append(model.checkResponse(ctx, attribute)...)
+	var permissions jwt.Permissions
+	diags.Append(model.setPermissions(ctx, &permissions)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+	if len(permissions.Pub.Allow)+len(permissions.Pub.Deny)+len(permissions.Sub.Allow)+len(permissions.Sub.Deny) == 0 && permissions.Resp == nil {
+		return nil, diags
+	}
+	return &permissions, diags
+}
+
+// writePermissions writes permissions to b as the value of key, indented by
+// indent.
+func writePermissions(b *strings.Builder, indent, key string, permissions *jwt.Permissions) {
+	b.WriteString(indent + key + ": {\n")
+	for _, p := range []struct {
+		key        string
+		permission jwt.Permission
+	}{
+		{"publish", permissions.Pub},
+		{"subscribe", permissions.Sub},
+	} {
+		if len(p.permission.Allow)+len(p.permission.Deny) == 0 {
+			continue
+		}
+		b.WriteString(indent + "  " + p.key + ": {\n")
+		for _, list := range []struct {
+			key      string
+			subjects jwt.StringList
+		}{
+			{"allow", p.permission.Allow},
+			{"deny", p.permission.Deny},
+		} {
+			if len(list.subjects) == 0 {
+				continue
+			}
+			quoted := make([]string, len(list.subjects))
+			for i, subject := range list.subjects {
+				quoted[i] = quoteConfigString(subject)
+			}
+			b.WriteString(indent + "    " + list.key + ": [" + strings.Join(quoted, ", ") + "]\n")
+		}
+		b.WriteString(indent + "  }\n")
+	}
+	if permissions.Resp != nil {
+		b.WriteString(indent + "  allow_responses: {\n")
+		b.WriteString(indent + "    max: " + strconv.Itoa(permissions.Resp.MaxMsgs) + "\n")
+		b.WriteString(indent + "    expires: " + quoteConfigString(permissions.Resp.Expires.String()) + "\n")
+		b.WriteString(indent + "  }\n")
+	}
+	b.WriteString(indent + "}\n")
+}
+
+func (d *AuthorizationDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data AuthorizationDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	defaults, diags := permissionsOf(ctx, path.Root("default_permissions"), data.DefaultPermissions)
+	resp.Diagnostics.Append(diags...)
+	users := make([]authorizationUser, 0, len(data.Users.Elements()))
+	seen := make(map[string]bool, len(data.Users.Elements()))
+	for _, element := range data.Users.Elements() {
+		at := path.Root("users").AtSetValue(element)
+		var model AuthorizationUserModel
+		resp.Diagnostics.Append(element.(types.Object).As(ctx, &model, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		publicKey := model.PublicKey.ValueString()
+		if seen[publicKey] {
+			resp.Diagnostics.AddAttributeError(at, "duplicate user", "The user "+publicKey+" is listed more than once, with different permissions.")
+			continue
+		}
+		seen[publicKey] = true
+		permissions, diags := permissionsOf(ctx, at.AtName("permissions"), model.Permissions)
+		resp.Diagnostics.Append(diags...)
+		users = append(users, authorizationUser{publicKey: publicKey, permissions: permissions})
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	slices.SortFunc(users, func(a, b authorizationUser) int {
+		return cmp.Compare(a.publicKey, b.publicKey)
+	})
+
+	var b strings.Builder
+	b.WriteString("authorization {\n")
+	if defaults != nil {
+		writePermissions(&b, "  ", "default_permissions", defaults)
+	}
+	b.WriteString("  users: [\n")
+	for _, user := range users {
+		b.WriteString("    {\n")
+		b.WriteString("      nkey: " + user.publicKey + "\n")
+		if user.permissions != nil {
+			writePermissions(&b, "      ", "permissions", user.permissions)
+		}
+		b.WriteString("    }\n")
+	}
+	b.WriteString("  ]\n")
+	b.WriteString("}\n")
+	data.Config = types.StringValue(b.String())
+	tflog.Trace(ctx, "read authorization data source", map[string]interface{}{
+		"users": len(users),
+	})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &CheckDataSource{}
+
+func NewCheckDataSource() datasource.DataSource {
+	return &CheckDataSource{}
+}
+
+// CheckDataSource defines the data source implementation.
+type CheckDataSource struct {
+}
+
+// CheckDataSourceModel describes the data source data model.
+type CheckDataSourceModel struct {
+	Seed              types.String `tfsdk:"seed"`
+	ExpectedPublicKey types.String `tfsdk:"expected_public_key"`
+	FailOnMismatch    types.Bool   `tfsdk:"fail_on_mismatch"`
+	Matches           types.Bool   `tfsdk:"matches"`
+	PublicKey         types.String `tfsdk:"public_key"`
+	KeyType           types.String `tfsdk:"type"`
+}
+
+func (d *CheckDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_check"
+}
+
+func (d *CheckDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Checks that a seed and a public key belong to the same nkey, so a plan fails fast when one of them was rotated without the other.",
+
+		Attributes: map[string]schema.Attribute{
+			"seed": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Seed of the nkey",
+				Sensitive:           true,
+			},
+			"expected_public_key": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key the seed is expected to derive",
+			},
+			"fail_on_mismatch": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Fail with an error when the seed does not derive `expected_public_key`. When false the outcome is only reported in `matches`. Defaults to true",
+			},
+			"matches": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the seed derives `expected_public_key`",
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key derived from the seed",
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The type of the seed. One of user|account|server|cluster|operator|curve",
+			},
+		},
+	}
+}
+
+func (d *CheckDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data CheckDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys, keyType, err := parseSeed([]byte(data.Seed.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "invalid seed", err.Error())
+		return
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "invalid seed", err.Error())
+		return
+	}
+
+	expected, err := parseKey(data.ExpectedPublicKey.ValueString())
+	if err == nil && expected.kind != keyKindPublic {
+		err = errInvalidPublicKey
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("expected_public_key"), "invalid public key", "The expected_public_key is not a valid nkey public key: "+err.Error())
+		return
+	}
+
+	data.PublicKey = types.StringValue(pubKey)
+	data.KeyType = types.StringValue(keyType)
+	data.Matches = types.BoolValue(pubKey == expected.publicKey)
+
+	if !data.Matches.ValueBool() && (data.FailOnMismatch.IsNull() || data.FailOnMismatch.ValueBool()) {
+		if keyType != expected.keyType {
+			resp.Diagnostics.AddAttributeError(path.Root("expected_public_key"), "nkey type mismatch", "The seed is of type "+keyType+" and derives the public key "+pubKey+", but expected_public_key is of type "+expected.keyType+".")
+			return
+		}
+		resp.Diagnostics.AddAttributeError(path.Root("expected_public_key"), "nkey mismatch", "The seed derives the public key "+pubKey+" instead of the expected "+expected.publicKey+". The seed or the public key was rotated without the other.")
+		return
+	}
+	tflog.Trace(ctx, "read check data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,186 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &CLIContextDataSource{}
+
+func NewCLIContextDataSource() datasource.DataSource {
+	return &CLIContextDataSource{}
+}
+
+// CLIContextDataSource defines the data source implementation.
+type CLIContextDataSource struct {
+}
+
+// CLIContextDataSourceModel describes the data source data model.
+type CLIContextDataSourceModel struct {
+	URL         types.String `tfsdk:"url"`
+	Description types.String `tfsdk:"description"`
+	CredsPath   types.String `tfsdk:"creds_path"`
+	JWT         types.String `tfsdk:"jwt"`
+	Seed        types.String `tfsdk:"seed"`
+	TLSCert     types.String `tfsdk:"tls_cert"`
+	TLSKey      types.String `tfsdk:"tls_key"`
+	TLSCA       types.String `tfsdk:"tls_ca"`
+	TLSFirst    types.Bool   `tfsdk:"tls_first"`
+	JSON        types.String `tfsdk:"json"`
+}
+
+// cliContext is the context document of the nats CLI, with the field names
+// of its schema. Fields that are not set are left out, which the CLI reads
+// as empty.
+type cliContext struct {
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+	Creds       string `json:"creds,omitempty"`
+	NKey        string `json:"nkey,omitempty"`
+	Cert        string `json:"cert,omitempty"`
+	Key         string `json:"key,omitempty"`
+	CA          string `json:"ca,omitempty"`
+	UserJWT     string `json:"user_jwt,omitempty"`
+	TLSFirst    bool   `json:"tls_first,omitempty"`
+}
+
+func (d *CLIContextDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cli_context"
+}
+
+func (d *CLIContextDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Renders a context of the nats CLI, the JSON document it reads from `~/.config/nats/context/<name>.json`, connecting as a user either through a creds file or with its JWT and seed inline.",
+
+		Attributes: map[string]schema.Attribute{
+			"url": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "URL of the NATS servers, several separated by commas",
+			},
+			"description": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Description of the context, listed by `nats context ls`",
+			},
+			"creds_path": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path of the creds file of the user on the machine running the CLI",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("jwt"), path.MatchRoot("seed")),
+				},
+			},
+			"jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The encoded user JWT, inlined in the context",
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("seed")),
+				},
+			},
+			"seed": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the user nkey the JWT is issued to, inlined in the context",
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("jwt")),
+					isSeedOfType("user"),
+				},
+			},
+			"tls_cert": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path of the TLS client certificate",
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("tls_key")),
+				},
+			},
+			"tls_key": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path of the private key of the TLS client certificate",
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("tls_cert")),
+				},
+			},
+			"tls_ca": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path of the CA certificates that verify the servers",
+			},
+			"tls_first": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to start TLS before the servers send their INFO, for servers behind TLS terminating proxies. Defaults to false",
+			},
+			"json": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The context document. It is sensitive since it holds the seed when `seed` is set; wrap it in `nonsensitive()` when only `creds_path` is",
+			},
+		},
+	}
+}
+
+func (d *CLIContextDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data CLIContextDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.JWT.IsNull() {
+		claims, err := jwt.DecodeUserClaims(data.JWT.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("jwt"), "invalid user JWT", "The jwt is not a validly signed user JWT: "+err.Error()+".")
+			return
+		}
+		if _, err := checkCredsSeed(data.Seed.ValueString(), claims.Subject); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("seed"), "seed mismatch", "The seed does not fit the JWT: "+err.Error()+".")
+			return
+		}
+	}
+
+	// The CLI connects with user_jwt and the seed inline in nkey when
+	// user_jwt is set, and reads nkey as the path of a seed file otherwise
+	cliCtx := cliContext{
+		Description: data.Description.ValueString(),
+		URL:         data.URL.ValueString(),
+		Creds:       data.CredsPath.ValueString(),
+		NKey:        data.Seed.ValueString(),
+		Cert:        data.TLSCert.ValueString(),
+		Key:         data.TLSKey.ValueString(),
+		CA:          data.TLSCA.ValueString(),
+		UserJWT:     data.JWT.ValueString(),
+		TLSFirst:    data.TLSFirst.ValueBool(),
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cliCtx); err != nil {
+		resp.Diagnostics.AddError("rendering context", "The context could not be rendered: "+err.Error())
+		return
+	}
+	data.JSON = types.StringValue(buf.String())
+	tflog.Trace(ctx, "read cli context data source", map[string]interface{}{
+		"url": cliCtx.URL,
+	})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ ephemeral.EphemeralResource = &CredsEphemeral{}
+
+func NewCredsEphemeral() ephemeral.EphemeralResource {
+	return &CredsEphemeral{}
+}
+
+// CredsEphemeral defines the ephemeral resource implementation.
+type CredsEphemeral struct {
+}
+
+// CredsEphemeralModel describes the ephemeral resource data model.
+type CredsEphemeralModel struct {
+	CredsSealModel
+	JWT       types.String `tfsdk:"jwt"`
+	Seed      types.String `tfsdk:"seed"`
+	PublicKey types.String `tfsdk:"public_key"`
+	Creds     types.String `tfsdk:"creds"`
+}
+
+func (r *CredsEphemeral) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_creds"
+}
+
+func (r *CredsEphemeral) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Formats the creds file of a user from its JWT and seed without persisting anything to state, e.g. to hand an existing user JWT to a client during an apply. It fails when the seed is not the seed of the subject of the JWT.",
+
+		Attributes: credsSealEphemeralAttributes(map[string]schema.Attribute{
+			"jwt": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The encoded user JWT",
+			},
+			"seed": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the user nkey the JWT is issued to",
+				Validators: []validator.String{
+					isSeedOfType("user"),
+				},
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the user nkey, the subject of the JWT",
+			},
+			"creds": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Creds file with the JWT and the seed, as written by nsc and read by the NATS clients. Null when `seal_only` is set",
+			},
+		}, "Whether to null `creds`, so that the creds are only output sealed in `creds_sealed`."),
+	}
+}
+
+func (r *CredsEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data CredsEphemeralModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Decoding verifies the claim type and the signature against the issuer
+	claims, err := jwt.DecodeUserClaims(data.JWT.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "invalid user JWT", "The jwt is not a validly signed user JWT: "+err.Error()+".")
+		return
+	}
+	pubKey, err := checkCredsSeed(data.Seed.ValueString(), claims.Subject)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "seed mismatch", "The seed does not fit the JWT: "+err.Error()+".")
+		return
+	}
+
+	creds, err := jwt.FormatUserConfig(data.JWT.ValueString(), []byte(data.Seed.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddError("formatting creds", "The creds file could not be formatted: "+err.Error())
+		return
+	}
+	defer wipe(creds)
+
+	resp.Diagnostics.Append(data.seal(creds)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.PublicKey = types.StringValue(pubKey)
+	data.Creds = types.StringValue(string(creds))
+	if data.sealOnly() {
+		data.Creds = types.StringNull()
+	}
+	tflog.Trace(ctx, "opened ephemeral creds resource", map[string]interface{}{
+		"public_key": pubKey,
+		"sealed":     !data.CredsSealed.IsNull(),
+	})
+
+	// Save data into Terraform ephemeral result
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
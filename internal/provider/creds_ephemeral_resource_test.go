@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestCredsEphemeralSeal(t *testing.T) {
+	account, err := nkeys.FromSeed([]byte(testAccountSeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.NewUserClaims(testUserPublicKey).Encode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := jwt.FormatUserConfig(token, []byte(testUserSeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		seed       string
+		sealToXkey string
+		sealOnly   bool
+		wantErr    string
+	}{
+		{name: "plain", seed: testUserSeed},
+		{name: "sealed", seed: testUserSeed, sealToXkey: testCurvePublicKey},
+		{name: "seal only", seed: testUserSeed, sealToXkey: testCurvePublicKey, sealOnly: true},
+		{name: "other seed", seed: testAccountSeed, sealToXkey: testCurvePublicKey, wantErr: "seed mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			config := map[string]tftypes.Value{
+				"jwt":  tftypes.NewValue(tftypes.String, token),
+				"seed": tftypes.NewValue(tftypes.String, tt.seed),
+			}
+			if tt.sealToXkey != "" {
+				config["seal_to_xkey"] = tftypes.NewValue(tftypes.String, tt.sealToXkey)
+			}
+			if tt.sealOnly {
+				config["seal_only"] = tftypes.NewValue(tftypes.Bool, true)
+			}
+			result, diags := openEphemeral(ctx, t, &CredsEphemeral{}, config)
+			if tt.wantErr != "" {
+				if !diags.HasError() || !strings.Contains(diags.Errors()[0].Summary(), tt.wantErr) {
+					t.Fatalf("Open() diagnostics = %v, want %q", diags, tt.wantErr)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("Open() diagnostics = %v", diags)
+			}
+
+			var creds, sealed types.String
+			diags.Append(result.GetAttribute(ctx, path.Root("creds"), &creds)...)
+			diags.Append(result.GetAttribute(ctx, path.Root("creds_sealed"), &sealed)...)
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+			if tt.sealOnly != creds.IsNull() || (!tt.sealOnly && creds.ValueString() != string(want)) {
+				t.Errorf("creds = %s, want the plaintext creds unless seal_only", creds)
+			}
+			if tt.sealToXkey == "" {
+				if !sealed.IsNull() {
+					t.Errorf("creds_sealed without seal_to_xkey = %s, want null", sealed)
+				}
+				return
+			}
+			opened, err := openXkeyEnvelope(sealed.ValueString(), testCurveSeed)
+			if err != nil || string(opened) != string(want) {
+				t.Errorf("creds_sealed opens to %q, %v, want the creds", opened, err)
+			}
+		})
+	}
+}
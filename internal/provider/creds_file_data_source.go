@@ -0,0 +1,176 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &CredsFileDataSource{}
+
+func NewCredsFileDataSource() datasource.DataSource {
+	return &CredsFileDataSource{}
+}
+
+// CredsFileDataSource defines the data source implementation.
+type CredsFileDataSource struct {
+}
+
+// CredsFileDataSourceModel describes the data source data model.
+type CredsFileDataSourceModel struct {
+	Path          types.String `tfsdk:"path"`
+	Validate      types.Bool   `tfsdk:"validate"`
+	JWT           types.String `tfsdk:"jwt"`
+	Seed          types.String `tfsdk:"seed"`
+	Subject       types.String `tfsdk:"subject"`
+	Issuer        types.String `tfsdk:"issuer"`
+	IssuerAccount types.String `tfsdk:"issuer_account"`
+	ExpiresAt     types.String `tfsdk:"expires_at"`
+}
+
+func (d *CredsFileDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_creds_file"
+}
+
+func (d *CredsFileDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Reads a creds file from disk, e.g. one dropped on the runner by another system, and extracts its user JWT and seed the way the NATS clients do. It warns when the file is readable by others than its owner.",
+
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Path of the creds file",
+			},
+			"validate": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to fail when the seed does not belong to the subject of the JWT. Defaults to false",
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The user JWT of the creds, whose signature is verified",
+			},
+			"seed": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The user seed of the creds",
+			},
+			"subject": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the user the JWT is issued to",
+			},
+			"issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the nkey that signed the JWT",
+			},
+			"issuer_account": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the account of a JWT signed by one of the signing keys of the account, or null otherwise",
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp at which the JWT expires, or null when it never expires",
+			},
+		},
+	}
+}
+
+// readCredsFile reads the creds file at name and returns its contents and
+// permissions.
+func readCredsFile(name string) ([]byte, fs.FileMode, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	if info.IsDir() {
+		return nil, 0, errors.New(name + " is a directory")
+	}
+	contents, err := io.ReadAll(f)
+	if err != nil {
+		return nil, 0, err
+	}
+	return contents, info.Mode().Perm(), nil
+}
+
+func (d *CredsFileDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data CredsFileDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	name := data.Path.ValueString()
+	contents, mode, err := readCredsFile(name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "creds file not found", "There is no creds file at "+name+".")
+		return
+	case errors.Is(err, fs.ErrPermission):
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "creds file not readable", "The creds file "+name+" cannot be read by the user running Terraform: "+err.Error())
+		return
+	case err != nil:
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "reading creds file", err.Error())
+		return
+	}
+	defer wipe(contents)
+	if mode&^keystoreFileMode != 0 {
+		resp.Diagnostics.AddAttributeWarning(path.Root("path"), "creds file permissions too open", fmt.Sprintf("The creds file %s has permissions %04o, more permissive than %04o. Restrict them with chmod 600.", name, mode, keystoreFileMode))
+	}
+
+	token, seed, claims, err := parseCreds(string(contents))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "invalid creds file", fmt.Sprintf("The creds file %s could not be parsed: %s.", name, err))
+		return
+	}
+	defer wipe(seed)
+	if data.Validate.ValueBool() {
+		if _, err := checkCredsSeed(string(seed), claims.Subject); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("path"), "seed mismatch", fmt.Sprintf("The seed of the creds file %s does not fit its JWT: %s.", name, err))
+			return
+		}
+	}
+
+	data.JWT = types.StringValue(token)
+	data.Seed = types.StringValue(string(seed))
+	data.Subject = types.StringValue(claims.Subject)
+	data.Issuer = types.StringValue(claims.Issuer)
+	data.IssuerAccount = types.StringNull()
+	if claims.IssuerAccount != "" {
+		data.IssuerAccount = types.StringValue(claims.IssuerAccount)
+	}
+	data.ExpiresAt = types.StringNull()
+	if claims.Expires != 0 {
+		data.ExpiresAt = types.StringValue(time.Unix(claims.Expires, 0).UTC().Format(time.RFC3339))
+	}
+	tflog.Trace(ctx, "read creds file data source", map[string]interface{}{
+		"subject": claims.Subject,
+	})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,396 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &CredsFile{}
+var _ resource.ResourceWithValidateConfig = &CredsFile{}
+var _ resource.ResourceWithModifyPlan = &CredsFile{}
+
+func NewCredsFile() resource.Resource {
+	return &CredsFile{}
+}
+
+// defaultCredsFilePermission is the file_permission of creds files when it is
+// not configured, readable by the owner only like the keystore files.
+const defaultCredsFilePermission = "0600"
+
+// filePermissionPattern matches file permissions in the four digit octal
+// notation they are stored in.
+var filePermissionPattern = regexp.MustCompile(`^0[0-7]{3}$`)
+
+// CredsFile defines the resource implementation.
+type CredsFile struct {
+}
+
+// CredsFileModel describes the resource data model.
+type CredsFileModel struct {
+	CredsSealModel
+	Path              types.String `tfsdk:"path"`
+	JWT               types.String `tfsdk:"jwt"`
+	Seed              types.String `tfsdk:"seed"`
+	FilePermission    types.String `tfsdk:"file_permission"`
+	CreateDirectories types.Bool   `tfsdk:"create_directories"`
+	ContentSHA256     types.String `tfsdk:"content_sha256"`
+}
+
+func (r *CredsFile) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_creds_file"
+}
+
+func (r *CredsFile) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A creds file writes the creds of a user, its JWT and seed, to disk with strict permissions. Only the SHA-256 of the contents is kept in state, never the seed or the plaintext creds. Files that go missing or are changed outside of Terraform are written again by the next apply, symlinks are never written through, and destroying the resource removes the file.",
+
+		Attributes: credsSealResourceAttributes(map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Path of the creds file. Changing it moves the file",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"jwt": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The encoded user JWT. Changing it rewrites the file in place",
+			},
+			"seed": schema.StringAttribute{
+				Required:            true,
+				WriteOnly:           true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the user nkey the JWT is issued to. The value is write-only and never stored, it is read whenever the file is written. Requires Terraform 1.11 or later",
+				Validators: []validator.String{
+					isSeedOfType("user"),
+				},
+			},
+			"file_permission": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Permissions of the creds file in four digit octal notation. Defaults to `" + defaultCredsFilePermission + "`, readable by the owner only",
+				Default:             stringdefault.StaticString(defaultCredsFilePermission),
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(filePermissionPattern, "must be four octal digits such as \"0600\""),
+				},
+			},
+			"create_directories": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to create the missing parent directories of `path`, readable by the owner only. Defaults to false",
+			},
+			"content_sha256": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hex SHA-256 of the contents of the creds file, which are `creds_sealed` when `seal_only` is set",
+			},
+		}, "Whether to write `creds_sealed` to the file instead of the plaintext creds, so that the creds never reach the disk unsealed, e.g. to ship the file to the target as a build artifact."),
+	}
+}
+
+func (r *CredsFile) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data CredsFileModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.JWT.IsUnknown() || data.Seed.IsUnknown() {
+		return
+	}
+	// Invalid seeds are left for the attribute validators
+	if _, keyType, err := publicKeyFromSeed([]byte(data.Seed.ValueString())); err != nil || keyType != "user" {
+		return
+	}
+	resp.Diagnostics.Append(data.checkCreds()...)
+}
+
+// checkCreds checks that the JWT is a user JWT and that the seed belongs to
+// its subject.
+func (m *CredsFileModel) checkCreds() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	claims, err := jwt.DecodeUserClaims(m.JWT.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("jwt"), "invalid user JWT", "The jwt is not a validly signed user JWT: "+err.Error()+".")
+		return diags
+	}
+	if _, err := checkCredsSeed(m.Seed.ValueString(), claims.Subject); err != nil {
+		diags.AddAttributeError(path.Root("seed"), "seed mismatch", "The seed does not fit the JWT: "+err.Error()+".")
+	}
+	return diags
+}
+
+// credsHash returns the hex SHA-256 of the contents of a creds file.
+func credsHash(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// ModifyPlan plans the hash of the creds the configuration formats, or of the
+// sealed creds, so a file that drifted from them is written again. The
+// write-only seed is only available in the configuration.
+func (r *CredsFile) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	if req.Plan.Raw.IsNull() {
+		// The resource is being destroyed
+		return
+	}
+
+	var token, seed types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("jwt"), &token)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("seed"), &seed)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The seed has to be that of the subject of the JWT, so the creds change
+	// exactly when the JWT does
+	credsChanged := token.IsUnknown() || req.State.Raw.IsNull()
+	if !credsChanged {
+		var priorToken types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("jwt"), &priorToken)...)
+		credsChanged = !token.Equal(priorToken)
+	}
+	resp.Diagnostics.Append(planCredsSeal(ctx, req.State, &resp.Plan, credsChanged)...)
+
+	var sealOnly types.Bool
+	var sealed types.String
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("seal_only"), &sealOnly)...)
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("creds_sealed"), &sealed)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Creds that cannot be formatted are reported when the file is written
+	hash := types.StringUnknown()
+	switch {
+	case sealOnly.IsUnknown():
+	case sealOnly.ValueBool():
+		if !sealed.IsUnknown() {
+			hash = types.StringValue(credsHash([]byte(sealed.ValueString())))
+		}
+	case !token.IsUnknown() && !seed.IsUnknown():
+		if creds, err := jwt.FormatUserConfig(token.ValueString(), []byte(seed.ValueString())); err == nil {
+			hash = types.StringValue(credsHash(creds))
+			wipe(creds)
+		}
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("content_sha256"), hash)...)
+}
+
+// writeCredsFile writes contents to name with perm through a temporary file
+// in the same directory that is renamed over name, so that name is replaced
+// rather than followed and readers never see a partial file. Symlinks and
+// anything else but regular files are refused.
+func writeCredsFile(name string, contents []byte, perm fs.FileMode, createDirs bool) error {
+	info, err := os.Lstat(name)
+	switch {
+	case err == nil && info.Mode()&fs.ModeSymlink != 0:
+		return errors.New(name + " is a symlink, which creds files are never written through")
+	case err == nil && !info.Mode().IsRegular():
+		return errors.New(name + " is not a regular file")
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	dir := filepath.Dir(name)
+	if createDirs {
+		if err := os.MkdirAll(dir, keystoreDirMode); err != nil {
+			return err
+		}
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer func() { _ = os.Remove(tmp) }()
+
+	if err := f.Chmod(perm); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if _, err := f.Write(contents); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// write formats the creds of the model with the seed of config, seals them to
+// seal_to_xkey, and writes them, or only the sealed creds, to the file. The
+// seed is never kept in the model.
+func (m *CredsFileModel) write(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	diags := config.GetAttribute(ctx, path.Root("seed"), &m.Seed)
+	if diags.HasError() {
+		return diags
+	}
+	defer func() { m.Seed = types.StringNull() }()
+
+	diags.Append(m.checkCreds()...)
+	if diags.HasError() {
+		return diags
+	}
+	creds, err := jwt.FormatUserConfig(m.JWT.ValueString(), []byte(m.Seed.ValueString()))
+	if err != nil {
+		diags.AddError("formatting creds", "The creds file could not be formatted: "+err.Error())
+		return diags
+	}
+	defer wipe(creds)
+
+	diags.Append(m.seal(creds)...)
+	if diags.HasError() {
+		return diags
+	}
+	contents := creds
+	if m.sealOnly() {
+		contents = []byte(m.CredsSealed.ValueString())
+	}
+
+	perm, err := strconv.ParseUint(m.FilePermission.ValueString(), 8, 32)
+	if err != nil {
+		diags.AddAttributeError(path.Root("file_permission"), "invalid file permission", err.Error())
+		return diags
+	}
+	if err := writeCredsFile(m.Path.ValueString(), contents, fs.FileMode(perm), m.CreateDirectories.ValueBool()); err != nil {
+		diags.AddAttributeError(path.Root("path"), "writing creds file", err.Error())
+		return diags
+	}
+	m.ContentSHA256 = types.StringValue(credsHash(contents))
+	return diags
+}
+
+func (r *CredsFile) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data CredsFileModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.write(ctx, req.Config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created creds file resource", map[string]interface{}{
+		"path": data.Path.ValueString(),
+	})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CredsFile) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data CredsFileModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Drift shows up as a changed hash or permission, which the plan writes
+	// again in place
+	contents, mode, err := readCredsFile(data.Path.ValueString())
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		tflog.Debug(ctx, "creds file is missing", map[string]interface{}{
+			"path": data.Path.ValueString(),
+		})
+		data.ContentSHA256 = types.StringNull()
+	case err != nil:
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "reading creds file", err.Error())
+		return
+	default:
+		data.ContentSHA256 = types.StringValue(credsHash(contents))
+		data.FilePermission = types.StringValue(fmt.Sprintf("%04o", mode))
+		wipe(contents)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CredsFile) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data CredsFileModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.write(ctx, req.Config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CredsFile) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data CredsFileModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := os.Remove(data.Path.ValueString()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "deleting creds file", err.Error())
+		return
+	}
+	tflog.Trace(ctx, "deleted creds file resource")
+}
@@ -0,0 +1,96 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/nats-io/jwt/v2"
+)
+
+func TestCredsFileResourceSealOnly(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "alice.creds")
+	config := func(seal string) string {
+		return `
+resource "nkey_user_jwt" "test" {
+  subject      = "` + testUserPublicKey + `"
+  signing_seed = "` + testAccountSeed + `"
+}
+
+resource "nkey_creds_file" "test" {
+  path = "` + filepath.ToSlash(name) + `"
+  jwt  = nkey_user_jwt.test.jwt
+  seed = "` + testUserSeed + `"
+` + seal + `
+}
+`
+	}
+	var sealed string
+	// step checks that the file holds the sealed creds when sealOnly is set,
+	// and the plaintext creds otherwise
+	step := func(seal string, action tfjson.Action, sealOnly bool) testStep {
+		return testStep{
+			Config: config(seal),
+			PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+				expectActions(t, plan, "nkey_creds_file.test", action)
+			},
+			Check: func(t *testing.T, state *testState) {
+				creds, err := jwt.FormatUserConfig(state.stringAttribute(t, "nkey_user_jwt.test", "jwt"), []byte(testUserSeed))
+				if err != nil {
+					t.Fatal(err)
+				}
+				contents, err := os.ReadFile(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := state.stringAttribute(t, "nkey_creds_file.test", "content_sha256"); got != credsHash(contents) {
+					t.Errorf("content_sha256 = %s, want the hash of the file %s", got, credsHash(contents))
+				}
+
+				got, _ := state.attribute(t, "nkey_creds_file.test", "creds_sealed").(string)
+				if seal == "" {
+					if got != "" || string(contents) != string(creds) {
+						t.Errorf("creds_sealed = %q, and the file holds %q, want the creds", got, contents)
+					}
+					return
+				}
+				if sealed != "" && got != sealed {
+					t.Error("the creds were sealed again")
+				}
+				sealed = got
+				if opened, err := openXkeyEnvelope(sealed, testCurveSeed); err != nil || string(opened) != string(creds) {
+					t.Errorf("creds_sealed opens to %q, %v, want the creds", opened, err)
+				}
+				switch {
+				case sealOnly && string(contents) != sealed:
+					t.Errorf("the file holds %q, want creds_sealed", contents)
+				case !sealOnly && string(contents) != string(creds):
+					t.Errorf("the file holds %q, want the creds", contents)
+				}
+			},
+		}
+	}
+	const sealTo = `  seal_to_xkey = "` + testCurvePublicKey + `"`
+	const sealOnly = sealTo + "\n  seal_only    = true"
+	// A removed file is written again with the same sealed creds
+	removed := step(sealOnly, tfjson.ActionUpdate, true)
+	removed.PreConfig = func(t *testing.T) {
+		if err := os.Remove(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			step(sealOnly, tfjson.ActionCreate, true),
+			step(sealTo, tfjson.ActionUpdate, false),
+			step(sealOnly, tfjson.ActionUpdate, true),
+			removed,
+			step("", tfjson.ActionUpdate, false),
+		},
+	})
+}
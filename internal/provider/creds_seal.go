@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/boolvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	ephemeralschema "github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// credsSealedDescription describes the creds_sealed attribute.
+const credsSealedDescription = "Base64 encoded creds sealed to `seal_to_xkey` with a curve key generated for them only. The decoded value is the 56 character public key of that sender key followed by the creds sealed in the format of the nkeys package, so the target opens it with its own seed alone, e.g. with `nkey_xkey_open` or the `Open` method of the nkeys package. Only the target can open it, so it is not sensitive. Null without `seal_to_xkey`"
+
+// CredsSealModel describes the attributes that seal the creds of a resource
+// to the curve key of the target they are deployed to.
+type CredsSealModel struct {
+	SealToXkey  types.String `tfsdk:"seal_to_xkey"`
+	SealOnly    types.Bool   `tfsdk:"seal_only"`
+	CredsSealed types.String `tfsdk:"creds_sealed"`
+}
+
+// sealOnly reports whether the creds are only output sealed.
+func (s *CredsSealModel) sealOnly() bool {
+	return s.SealOnly.ValueBool()
+}
+
+// seal seals creds to seal_to_xkey unless they are already sealed, and nulls
+// creds_sealed without seal_to_xkey. Sealing is not deterministic, so sealed
+// creds are kept until planCredsSeal plans them as unknown.
+func (s *CredsSealModel) seal(creds []byte) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	switch {
+	case s.SealToXkey.IsNull():
+		s.CredsSealed = types.StringNull()
+	case s.CredsSealed.IsNull() || s.CredsSealed.IsUnknown():
+		sealed, err := sealXkeyEnvelope(creds, s.SealToXkey.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("seal_to_xkey"), "sealing creds", "The creds could not be sealed: "+err.Error())
+			return diags
+		}
+		s.CredsSealed = types.StringValue(sealed)
+	}
+	return diags
+}
+
+// planCredsSeal plans creds_sealed as null without seal_to_xkey, as unknown
+// when the creds change or are sealed to another key, and as the prior sealed
+// creds otherwise.
+func planCredsSeal(ctx context.Context, state tfsdk.State, plan *tfsdk.Plan, credsChanged bool) diag.Diagnostics {
+	var xkey types.String
+	diags := plan.GetAttribute(ctx, path.Root("seal_to_xkey"), &xkey)
+	if diags.HasError() {
+		return diags
+	}
+
+	sealed := types.StringNull()
+	if !xkey.IsNull() {
+		sealed = types.StringUnknown()
+		if !state.Raw.IsNull() && !credsChanged {
+			var prior CredsSealModel
+			diags.Append(state.GetAttribute(ctx, path.Root("seal_to_xkey"), &prior.SealToXkey)...)
+			diags.Append(state.GetAttribute(ctx, path.Root("creds_sealed"), &prior.CredsSealed)...)
+			if diags.HasError() {
+				return diags
+			}
+			if xkey.Equal(prior.SealToXkey) && !prior.CredsSealed.IsNull() {
+				sealed = prior.CredsSealed
+			}
+		}
+	}
+	diags.Append(plan.SetAttribute(ctx, path.Root("creds_sealed"), sealed)...)
+	return diags
+}
+
+// planSealedCreds plans the sealed creds, sealed again when the JWT at token
+// is issued again, and the plaintext creds at creds as null when they are only
+// output sealed, or as unknown when they no longer are.
+func planSealedCreds(ctx context.Context, state tfsdk.State, plan *tfsdk.Plan, token, creds path.Path) diag.Diagnostics {
+	var jwt types.String
+	var sealOnly types.Bool
+	diags := plan.GetAttribute(ctx, token, &jwt)
+	diags.Append(plan.GetAttribute(ctx, path.Root("seal_only"), &sealOnly)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	diags.Append(planCredsSeal(ctx, state, plan, jwt.IsUnknown())...)
+	var planned types.String
+	diags.Append(plan.GetAttribute(ctx, creds, &planned)...)
+	if diags.HasError() {
+		return diags
+	}
+	switch {
+	case sealOnly.IsUnknown():
+		planned = types.StringUnknown()
+	case sealOnly.ValueBool():
+		planned = types.StringNull()
+	case planned.IsNull():
+		// The prior creds were only output sealed
+		planned = types.StringUnknown()
+	}
+	diags.Append(plan.SetAttribute(ctx, creds, planned)...)
+	return diags
+}
+
+// credsSealResourceAttributes adds the CredsSealModel attributes to the schema
+// attributes of a managed resource, with sealOnly describing what seal_only
+// does instead of outputting the plaintext creds.
+func credsSealResourceAttributes(attrs map[string]schema.Attribute, sealOnly string) map[string]schema.Attribute {
+	attrs["seal_to_xkey"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "Curve public key, starting with `X`, to seal the creds to in `creds_sealed`, e.g. the key of the environment they are deployed to. Changing it seals the creds again",
+		Validators: []validator.String{
+			isPublicKeyFor(keyRoleSealing),
+		},
+	}
+	attrs["seal_only"] = schema.BoolAttribute{
+		Optional:            true,
+		MarkdownDescription: sealOnly + " Requires `seal_to_xkey`. Defaults to false",
+		Validators: []validator.Bool{
+			boolvalidator.AlsoRequires(path.MatchRoot("seal_to_xkey")),
+		},
+	}
+	attrs["creds_sealed"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: credsSealedDescription + ". The creds are only sealed again when they change or `seal_to_xkey` changes",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+	return attrs
+}
+
+// credsSealEphemeralAttributes adds the CredsSealModel attributes to the
+// schema attributes of an ephemeral resource, with sealOnly describing what
+// seal_only does instead of outputting the plaintext creds.
+func credsSealEphemeralAttributes(attrs map[string]ephemeralschema.Attribute, sealOnly string) map[string]ephemeralschema.Attribute {
+	attrs["seal_to_xkey"] = ephemeralschema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "Curve public key, starting with `X`, to seal the creds to in `creds_sealed`, e.g. the key of the environment they are deployed to",
+		Validators: []validator.String{
+			isPublicKeyFor(keyRoleSealing),
+		},
+	}
+	attrs["seal_only"] = ephemeralschema.BoolAttribute{
+		Optional:            true,
+		MarkdownDescription: sealOnly + " Requires `seal_to_xkey`. Defaults to false",
+		Validators: []validator.Bool{
+			boolvalidator.AlsoRequires(path.MatchRoot("seal_to_xkey")),
+		},
+	}
+	attrs["creds_sealed"] = ephemeralschema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: credsSealedDescription,
+	}
+	return attrs
+}
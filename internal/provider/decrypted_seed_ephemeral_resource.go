@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ ephemeral.EphemeralResource = &DecryptedSeedEphemeral{}
+
+func NewDecryptedSeedEphemeral() ephemeral.EphemeralResource {
+	return &DecryptedSeedEphemeral{}
+}
+
+// DecryptedSeedEphemeral defines the ephemeral resource implementation.
+type DecryptedSeedEphemeral struct {
+}
+
+// DecryptedSeedEphemeralModel describes the ephemeral resource data model.
+type DecryptedSeedEphemeralModel struct {
+	SeedEncrypted types.String `tfsdk:"seed_encrypted"`
+	Passphrase    types.String `tfsdk:"passphrase"`
+	Seed          types.String `tfsdk:"seed"`
+	PublicKey     types.String `tfsdk:"public_key"`
+	KeyType       types.String `tfsdk:"type"`
+}
+
+func (r *DecryptedSeedEphemeral) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_decrypted_seed"
+}
+
+func (r *DecryptedSeedEphemeral) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Decrypts a seed encrypted with a passphrase by the `encryption_passphrase_wo` attribute of the nkey resources, without persisting it to state. Pass the seed on to write-only attributes such as the `seed_wo` of an `nkey_keypair`.",
+
+		Attributes: map[string]schema.Attribute{
+			"seed_encrypted": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The encrypted seed, of the form `" + sealedSeedScheme + ":<salt>:<box>`",
+			},
+			"passphrase": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Passphrase the seed was encrypted with, e.g. from an ephemeral variable",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"seed": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The decrypted seed",
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the decrypted seed",
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The type of the decrypted seed. One of user|account|server|cluster|operator|curve",
+			},
+		},
+	}
+}
+
+func (r *DecryptedSeedEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data DecryptedSeedEphemeralModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	seed, err := openSealedSeed(data.SeedEncrypted.ValueString(), []byte(data.Passphrase.ValueString()))
+	if errors.Is(err, errWrongPassphrase) {
+		resp.Diagnostics.AddAttributeError(path.Root("passphrase"), "decrypting seed", err.Error())
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed_encrypted"), "decrypting seed", err.Error())
+		return
+	}
+	defer wipe(seed)
+
+	keys, keyType, err := parseSeed(seed)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed_encrypted"), "decrypting seed", "The decrypted seed could not be decoded: "+err.Error())
+		return
+	}
+	defer keys.Wipe()
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed_encrypted"), "decrypting seed", err.Error())
+		return
+	}
+
+	data.Seed = types.StringValue(string(seed))
+	data.PublicKey = types.StringValue(pubKey)
+	data.KeyType = types.StringValue(keyType)
+	tflog.Trace(ctx, "opened ephemeral decrypted seed resource")
+
+	// Save data into Terraform ephemeral result
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,252 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/hkdf"
+	"crypto/sha256"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &DerivedKey{}
+var _ resource.ResourceWithConfigure = &DerivedKey{}
+var _ resource.ResourceWithModifyPlan = &DerivedKey{}
+
+func NewDerivedKey() resource.Resource {
+	return &DerivedKey{}
+}
+
+// DerivedKey defines the resource implementation.
+type DerivedKey struct {
+	// defaultKeyType is the type generated when type is omitted.
+	defaultKeyType string
+}
+
+// DerivedKeyModel describes the resource data model.
+type DerivedKeyModel struct {
+	KeyModel
+	SeedEncryptionModel
+	MasterSeedWO        types.String `tfsdk:"master_seed_wo"`
+	MasterSeedWOVersion types.Int64  `tfsdk:"master_seed_wo_version"`
+	Path                types.String `tfsdk:"path"`
+}
+
+// minMasterSeedLen is the minimum length of a master seed.
+const minMasterSeedLen = 32
+
+// deriveRawSeed derives the raw seed of the key at keyPath from master with
+// HKDF-SHA256, using no salt and keyPath as the info. The scheme must never
+// change, since keys are re-derived from it. For example the master
+// "0123456789abcdef0123456789abcdef" and the path "tenants/acme/device-042"
+// derive the raw seed
+// 3c801f08a8f4e2783a312ed84cff1cd70c959b165aa557ba461d6487bdc30247, which is
+// the user public key UBQZX3JGNLK4AFEBVVLUJ4QVERLUES2PKJTHJGSIPVRK3ZXYNVFV7XAC.
+func deriveRawSeed(master []byte, keyPath string) ([]byte, error) {
+	return hkdf.Key(sha256.New, master, nil, keyPath, entropyLen)
+}
+
+// deriveKeysFromMaster stores the key of the configured type at keyPath below
+// master in the model.
+func (m *KeyModel) deriveKeysFromMaster(master []byte, keyPath string) error {
+	prefix, err := keyTypePrefix(m.KeyType.ValueString())
+	if err != nil {
+		return err
+	}
+
+	raw, err := deriveRawSeed(master, keyPath)
+	if err != nil {
+		return err
+	}
+	defer wipe(raw)
+
+	// FromRawSeed always makes an ed25519 key pair, while FromSeed makes an
+	// x25519 one of a curve seed
+	seed, err := nkeys.EncodeSeed(prefix, raw)
+	if err != nil {
+		return err
+	}
+	defer wipe(seed)
+
+	keys, err := nkeys.FromSeed(seed)
+	if err != nil {
+		return err
+	}
+	defer keys.Wipe()
+
+	return m.setKeys(keys)
+}
+
+func (r *DerivedKey) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_derived_key"
+}
+
+func (r *DerivedKey) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A derived key is an nkey derived deterministically from a master seed and a path with HKDF-SHA256, using no salt and the path as info. The same master seed, path and type always yield the same key, so a lost key can be re-derived without storing it, while different paths yield unrelated keys. Keys of different types on the same path share the same ed25519 key, so use one path per key. For example the master seed `0123456789abcdef0123456789abcdef` and the path `tenants/acme/device-042` derive the user public key `UBQZX3JGNLK4AFEBVVLUJ4QVERLUES2PKJTHJGSIPVRK3ZXYNVFV7XAC`.",
+
+		Attributes: keyResourceAttributes(seedEncryptionResourceAttributes(map[string]schema.Attribute{
+			"type": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The type of nkey to derive. Must be one of user|account|server|cluster|operator|curve. Defaults to the provider `default_key_type`, or account",
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive(keyTypes...),
+				},
+				PlanModifiers: []planmodifier.String{
+					caseInsensitive(),
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"master_seed_wo": schema.StringAttribute{
+				Required:            true,
+				WriteOnly:           true,
+				Sensitive:           true,
+				MarkdownDescription: "Master secret the key is derived from, at least 32 characters. It is never stored in state and only read when the resource is created, so bump `master_seed_wo_version` after changing it. Requires Terraform 1.11 or later",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(minMasterSeedLen),
+				},
+			},
+			"master_seed_wo_version": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Version marker for `master_seed_wo`. Changing it replaces the resource with the key derived from the current `master_seed_wo`",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"path": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Derivation path of the key, e.g. `tenants/acme/device-042`. Changing it derives an unrelated key",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		})),
+	}
+}
+
+func (r *DerivedKey) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.defaultKeyType = configuredDefaultKeyType(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *DerivedKey) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	resp.Diagnostics.Append(planDefaultKeyType(ctx, req.Config, &resp.Plan, r.defaultKeyType)...)
+	resp.Diagnostics.Append(planIncludePrivateKey(ctx, req.State, &resp.Plan)...)
+	resp.Diagnostics.Append(planFingerprintLength(ctx, req.State, &resp.Plan)...)
+	resp.Diagnostics.Append(planSeedEncryption(ctx, req.State, &resp.Plan)...)
+}
+
+func (r *DerivedKey) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data DerivedKeyModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Write-only values are only available in the configuration
+	var master types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("master_seed_wo"), &master)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := data.deriveKeysFromMaster([]byte(master.ValueString()), data.Path.ValueString()); err != nil {
+		addKeyError(&resp.Diagnostics, err)
+		return
+	}
+	resp.Diagnostics.Append(data.encryptSeed(ctx, req.Config, data.Seed)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(data.warnings()...)
+	tflog.Trace(ctx, "created derived key resource", data.logFields())
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DerivedKey) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data DerivedKeyModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A type configured in another case is stored as configured when the
+	// resource is created, so store the canonical form from now on
+	data.KeyType = canonicalKeyType(data.KeyType)
+
+	// Fill in attributes derived from the seed that older states lack
+	if err := data.deriveKeys(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "reading derived key", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DerivedKey) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var plan DerivedKeyModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only include_private_key, fingerprint_length and
+	// encryption_passphrase_wo_version change in place, so rederive the
+	// private key encodings and fingerprint and encrypt the seed again
+	if err := plan.deriveKeys(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "updating derived key", err.Error())
+		return
+	}
+	if plan.SeedEncrypted.IsUnknown() {
+		resp.Diagnostics.Append(plan.encryptSeed(ctx, req.Config, plan.Seed)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *DerivedKey) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed
+	tflog.Trace(ctx, "deleted derived key resource")
+}
@@ -0,0 +1,146 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/hex"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDeriveKeysFromMaster(t *testing.T) {
+	master := []byte("0123456789abcdef0123456789abcdef")
+	// 64 bytes counting from 0x00 to 0x3f
+	counting := make([]byte, 64)
+	for i := range counting {
+		counting[i] = byte(i)
+	}
+
+	// The raw seeds are HKDF-SHA256 with no salt and the path as info, so any
+	// change to the derivation fails these rather than silently deriving
+	// different keys from the same master seed
+	tests := []struct {
+		name      string
+		master    []byte
+		path      string
+		keyType   string
+		rawSeed   string
+		seed      string
+		publicKey string
+	}{
+		{
+			name:      "user",
+			master:    master,
+			path:      "tenants/acme/device-042",
+			keyType:   "user",
+			rawSeed:   "3c801f08a8f4e2783a312ed84cff1cd70c959b165aa557ba461d6487bdc30247",
+			seed:      "SUADZAA7BCUPJYTYHIYS5WCM74ONODEVTMLFVJKXXJDB2ZEHXXBQERYMPQ",
+			publicKey: "UBQZX3JGNLK4AFEBVVLUJ4QVERLUES2PKJTHJGSIPVRK3ZXYNVFV7XAC",
+		},
+		// The same path derives the same ed25519 key for another type
+		{
+			name:      "account on the same path",
+			master:    master,
+			path:      "tenants/acme/device-042",
+			keyType:   "account",
+			rawSeed:   "3c801f08a8f4e2783a312ed84cff1cd70c959b165aa557ba461d6487bdc30247",
+			seed:      "SAADZAA7BCUPJYTYHIYS5WCM74ONODEVTMLFVJKXXJDB2ZEHXXBQER77WA",
+			publicKey: "ABQZX3JGNLK4AFEBVVLUJ4QVERLUES2PKJTHJGSIPVRK3ZXYNVFV6TT5",
+		},
+		{
+			name:      "neighbouring path",
+			master:    master,
+			path:      "tenants/acme/device-043",
+			keyType:   "user",
+			rawSeed:   "e51410008d7f705e4488c9be541719ff2e834745a6257ee449831b940b1819ff",
+			seed:      "SUAOKFAQACGX64C6ISEMTPSUC4M76LUDI5C2MJL64REYGG4UBMMBT73OMU",
+			publicKey: "UCOEK5O7IEFVN2AIBLVWKJQJBTPAPEHHXP7KDJEHI5CV43DTWFUNL7YO",
+		},
+		{
+			name:      "binary master seed",
+			master:    counting,
+			path:      "operators/main/accounts/sys",
+			keyType:   "account",
+			rawSeed:   "6ea09089978b34985776a79f97b23f315d2345dff99cec150f893417b93d0aac",
+			seed:      "SAAG5IEQRGLYWNEYK53KPH4XWI7TCXJDIXP7THHMCUHYSNAXXE6QVLGKXM",
+			publicKey: "ADHYBT62NEJ25RXPDYVGAISNJAP3NRRBK5DLHOMQSVCKJ62GYMWBYLXF",
+		},
+		// The x25519 public key of the raw seed, not the ed25519 one
+		{
+			name:      "curve",
+			master:    counting,
+			path:      "operators/main/accounts/sys",
+			keyType:   "curve",
+			rawSeed:   "6ea09089978b34985776a79f97b23f315d2345dff99cec150f893417b93d0aac",
+			seed:      "SXAG5IEQRGLYWNEYK53KPH4XWI7TCXJDIXP7THHMCUHYSNAXXE6QVLB22I",
+			publicKey: "XBNHJLFBPML4T6EDIOOZRPNCOZ6ARG5ACGJ2PUFBJHTJPKYURCQUVN2C",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := deriveRawSeed(tt.master, tt.path)
+			if err != nil {
+				t.Fatalf("deriveRawSeed() error = %v", err)
+			}
+			if got := hex.EncodeToString(raw); got != tt.rawSeed {
+				t.Errorf("deriveRawSeed() = %s, want %s", got, tt.rawSeed)
+			}
+
+			m := KeyModel{KeyType: types.StringValue(tt.keyType), IncludePrivateKey: types.BoolValue(false)}
+			if err := m.deriveKeysFromMaster(tt.master, tt.path); err != nil {
+				t.Fatalf("deriveKeysFromMaster() error = %v", err)
+			}
+			if got := m.Seed.ValueString(); got != tt.seed {
+				t.Errorf("seed = %s, want %s", got, tt.seed)
+			}
+			if got := m.PublicKey.ValueString(); got != tt.publicKey {
+				t.Errorf("public_key = %s, want %s", got, tt.publicKey)
+			}
+		})
+	}
+
+	m := KeyModel{KeyType: types.StringValue("usr")}
+	if err := m.deriveKeysFromMaster(master, "tenants/acme/device-042"); err == nil {
+		t.Error("deriveKeysFromMaster() of an unsupported type succeeded")
+	}
+}
+
+func TestDerivedKeyResource(t *testing.T) {
+	config := func(path string) string {
+		return `
+resource "nkey_derived_key" "test" {
+  type           = "user"
+  master_seed_wo = "0123456789abcdef0123456789abcdef"
+  path           = "` + path + `"
+}
+`
+	}
+	// check checks that the path derives publicKey
+	check := func(publicKey string) func(t *testing.T, state *testState) {
+		return func(t *testing.T, state *testState) {
+			if got := state.stringAttribute(t, "nkey_derived_key.test", "public_key"); got != publicKey {
+				t.Errorf("public_key = %s, want %s", got, publicKey)
+			}
+		}
+	}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			{
+				Config: config("tenants/acme/device-042"),
+				Check:  check("UBQZX3JGNLK4AFEBVVLUJ4QVERLUES2PKJTHJGSIPVRK3ZXYNVFV7XAC"),
+			},
+			{
+				Config: config("tenants/acme/device-043"),
+				PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+					expectActions(t, plan, "nkey_derived_key.test", tfjson.ActionDelete, tfjson.ActionCreate)
+				},
+				Check: check("UCOEK5O7IEFVN2AIBLVWKJQJBTPAPEHHXP7KDJEHI5CV43DTWFUNL7YO"),
+			},
+		},
+	})
+}
@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &EnvFileDataSource{}
+var _ datasource.DataSourceWithConfigValidators = &EnvFileDataSource{}
+
+func NewEnvFileDataSource() datasource.DataSource {
+	return &EnvFileDataSource{}
+}
+
+const (
+	envFormatDotenv  = "dotenv"
+	envFormatSystemd = "systemd"
+)
+
+// envUnquotedPattern matches the values that need no quoting in either
+// format, which covers JWTs, seeds and most URLs.
+var envUnquotedPattern = regexp.MustCompile(`^[A-Za-z0-9_./:@,+=-]*$`)
+
+// EnvFileDataSource defines the data source implementation.
+type EnvFileDataSource struct {
+}
+
+// EnvFileDataSourceModel describes the data source data model.
+type EnvFileDataSourceModel struct {
+	JWT     types.String `tfsdk:"jwt"`
+	Seed    types.String `tfsdk:"seed"`
+	URL     types.String `tfsdk:"url"`
+	Format  types.String `tfsdk:"format"`
+	Content types.String `tfsdk:"content"`
+}
+
+func (d *EnvFileDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_env_file"
+}
+
+func (d *EnvFileDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Renders the `NATS_URL`, `NATS_JWT` and `NATS_NKEY_SEED` environment variables of a user as an environment file, e.g. for the `env_file` of a container or the `EnvironmentFile` of a systemd unit. Values are quoted and escaped as the format requires, so they are read back unchanged. When both `jwt` and `seed` are set the seed must belong to the subject of the JWT.",
+
+		Attributes: map[string]schema.Attribute{
+			"jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The encoded user JWT, rendered as `NATS_JWT`",
+			},
+			"seed": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the user nkey, rendered as `NATS_NKEY_SEED`",
+				Validators: []validator.String{
+					isSeedOfType("user"),
+				},
+			},
+			"url": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "URL of the NATS servers, rendered as `NATS_URL`",
+			},
+			"format": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Format of the file, either `dotenv` for shells, Docker Compose and the dotenv libraries, or `systemd` for the `EnvironmentFile` of systemd units. Defaults to `dotenv`",
+				Validators: []validator.String{
+					stringvalidator.OneOf(envFormatDotenv, envFormatSystemd),
+				},
+			},
+			"content": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The environment file, one variable per line",
+			},
+		},
+	}
+}
+
+func (d *EnvFileDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.AtLeastOneOf(
+			path.MatchRoot("jwt"),
+			path.MatchRoot("seed"),
+			path.MatchRoot("url"),
+		),
+	}
+}
+
+// quoteEnvValue quotes value for an environment file of format. Values of
+// only safe characters stay unquoted. Otherwise dotenv values are single
+// quoted, which every reader takes literally, unless they contain a single
+// quote, and systemd values are double quoted with the characters systemd
+// unescapes escaped. Line breaks and NUL cannot be represented in either.
+func quoteEnvValue(value, format string) (string, error) {
+	if strings.ContainsAny(value, "\n\r\x00") {
+		return "", errors.New("the value contains a line break or NUL, which environment files cannot hold")
+	}
+	if envUnquotedPattern.MatchString(value) {
+		return value, nil
+	}
+	if format == envFormatDotenv && !strings.Contains(value, "'") {
+		return "'" + value + "'", nil
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		if strings.ContainsRune("\\\"$`", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String(), nil
+}
+
+func (d *EnvFileDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data EnvFileDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.JWT.IsNull() && !data.Seed.IsNull() {
+		claims, err := jwt.DecodeUserClaims(data.JWT.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("jwt"), "invalid user JWT", "The jwt is not a validly signed user JWT: "+err.Error()+".")
+			return
+		}
+		if _, err := checkCredsSeed(data.Seed.ValueString(), claims.Subject); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("seed"), "seed mismatch", "The seed does not fit the JWT: "+err.Error()+".")
+			return
+		}
+	}
+
+	format := data.Format.ValueString()
+	if format == "" {
+		format = envFormatDotenv
+	}
+	variables := []struct {
+		attribute string
+		name      string
+		value     types.String
+	}{
+		{"url", "NATS_URL", data.URL},
+		{"jwt", "NATS_JWT", data.JWT},
+		{"seed", "NATS_NKEY_SEED", data.Seed},
+	}
+	var b strings.Builder
+	for _, v := range variables {
+		if v.value.IsNull() {
+			continue
+		}
+		quoted, err := quoteEnvValue(v.value.ValueString(), format)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root(v.attribute), "invalid environment value", "The "+v.attribute+" cannot be rendered as "+v.name+": "+err.Error()+".")
+			return
+		}
+		b.WriteString(v.name + "=" + quoted + "\n")
+	}
+	data.Content = types.StringValue(b.String())
+	tflog.Trace(ctx, "read env file data source", map[string]interface{}{
+		"format": format,
+	})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuoteEnvValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		dotenv  string
+		systemd string
+		wantErr bool
+	}{
+		{name: "seed", value: testUserSeed, dotenv: testUserSeed, systemd: testUserSeed},
+		{name: "url", value: "nats://a.example.com:4222,nats://b.example.com:4222", dotenv: "nats://a.example.com:4222,nats://b.example.com:4222", systemd: "nats://a.example.com:4222,nats://b.example.com:4222"},
+		{name: "empty", value: "", dotenv: "", systemd: ""},
+		{name: "space", value: "a b", dotenv: "'a b'", systemd: `"a b"`},
+		{name: "single quote", value: "it's", dotenv: `"it's"`, systemd: `"it's"`},
+		{name: "dollar", value: "$HOME", dotenv: "'$HOME'", systemd: `"\$HOME"`},
+		{name: "backslash", value: `a\b`, dotenv: `'a\b'`, systemd: `"a\\b"`},
+		{name: "backtick", value: "`id`", dotenv: "'`id`'", systemd: "\"\\`id\\`\""},
+		{name: "double quote", value: `say "hi"`, dotenv: `'say "hi"'`, systemd: `"say \"hi\""`},
+		{name: "comment", value: "a #b", dotenv: "'a #b'", systemd: `"a #b"`},
+		{name: "tab", value: "a\tb", dotenv: "'a\tb'", systemd: "\"a\tb\""},
+		{name: "unicode", value: "ünïcödé", dotenv: "'ünïcödé'", systemd: `"ünïcödé"`},
+		// Single quoted dotenv values cannot hold a single quote, so they are
+		// double quoted and escaped as systemd values are
+		{name: "single quote and specials", value: `it's "$HOME\"`, dotenv: `"it's \"\$HOME\\\""`, systemd: `"it's \"\$HOME\\\""`},
+		{name: "single quote and backtick", value: "it's `id`", dotenv: "\"it's \\`id\\`\"", systemd: "\"it's \\`id\\`\""},
+		{name: "newline", value: "a\nb", wantErr: true},
+		{name: "carriage return", value: "a\rb", wantErr: true},
+		{name: "NUL", value: "a\x00b", wantErr: true},
+	}
+	sh, shErr := exec.LookPath("sh")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for format, want := range map[string]string{envFormatDotenv: tt.dotenv, envFormatSystemd: tt.systemd} {
+				got, err := quoteEnvValue(tt.value, format)
+				if tt.wantErr {
+					if err == nil {
+						t.Errorf("quoteEnvValue(%q, %s) = %s, want an error", tt.value, format, got)
+					}
+					continue
+				}
+				if err != nil || got != want {
+					t.Errorf("quoteEnvValue(%q, %s) = %s, %v, want %s", tt.value, format, got, err, want)
+				}
+			}
+			if tt.wantErr || shErr != nil {
+				return
+			}
+
+			// A shell sourcing the dotenv file reads the value back unchanged
+			file := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(file, []byte("VALUE="+tt.dotenv+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			out, err := exec.Command(sh, "-c", `. "$1" && printf %s "$VALUE"`, "sh", file).Output()
+			if err != nil || string(out) != tt.value {
+				t.Errorf("sh reads %s back as %q, %v, want %q", tt.dotenv, out, err, tt.value)
+			}
+		})
+	}
+}
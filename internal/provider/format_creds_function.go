@@ -0,0 +1,74 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &FormatCredsFunction{}
+
+func NewFormatCredsFunction() function.Function {
+	return &FormatCredsFunction{}
+}
+
+// FormatCredsFunction defines the function implementation.
+type FormatCredsFunction struct {
+}
+
+func (f *FormatCredsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "format_creds"
+}
+
+func (f *FormatCredsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Format the creds file of a user from its JWT and seed",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Formats the creds file of a user, with the `USER JWT` and `USER NKEY SEED` armor blocks byte for byte as nsc writes it, e.g. in a `for` expression over a map of users. Fails when the seed does not belong to the subject of the JWT. Function results cannot be marked sensitive, so wrap the result in `sensitive()` or only use it in sensitive attributes and outputs, since it contains the seed.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "jwt",
+				MarkdownDescription: "The encoded user JWT, whose signature is verified",
+			},
+			function.StringParameter{
+				Name:                "seed",
+				MarkdownDescription: "Seed of the user nkey the JWT is issued to",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *FormatCredsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var token, seed string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &token, &seed))
+	if resp.Error != nil {
+		return
+	}
+
+	// Decoding verifies the claim type and the signature against the issuer
+	claims, err := jwt.DecodeUserClaims(token)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "The jwt is not a validly signed user JWT: "+err.Error())
+		return
+	}
+	if _, err := checkCredsSeed(seed, claims.Subject); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, "The seed does not fit the JWT: "+err.Error())
+		return
+	}
+
+	creds, err := jwt.FormatUserConfig(token, []byte(seed))
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, string(creds)))
+}
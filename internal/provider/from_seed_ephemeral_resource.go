@@ -0,0 +1,94 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ ephemeral.EphemeralResource = &FromSeedEphemeral{}
+
+func NewFromSeedEphemeral() ephemeral.EphemeralResource {
+	return &FromSeedEphemeral{}
+}
+
+// FromSeedEphemeral defines the ephemeral resource implementation.
+type FromSeedEphemeral struct {
+}
+
+// FromSeedEphemeralModel describes the ephemeral resource data model.
+type FromSeedEphemeralModel struct {
+	KeyModel
+}
+
+func (r *FromSeedEphemeral) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_from_seed"
+}
+
+func (r *FromSeedEphemeral) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	attrs := keyEphemeralAttributes(map[string]schema.Attribute{
+		"type": schema.StringAttribute{
+			Optional:    true,
+			Computed:    true,
+			Description: "The type of the seed. One of user|account|server|cluster|operator|curve. When set the seed must be of this type",
+			Validators: []validator.String{
+				stringvalidator.OneOfCaseInsensitive(keyTypes...),
+			},
+		},
+	})
+	// The seed is the input everything else is derived from
+	attrs["seed"] = schema.StringAttribute{
+		Required:            true,
+		Sensitive:           true,
+		MarkdownDescription: "Seed of the nkey, e.g. from an ephemeral variable",
+	}
+
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Derives the key pair of an existing nkey from its seed during plan/apply without persisting anything to state.",
+
+		Attributes: attrs,
+	}
+}
+
+func (r *FromSeedEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data FromSeedEphemeralModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	configuredType := data.KeyType
+	if err := data.setKeysFromSeed([]byte(data.Seed.ValueString())); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "invalid seed", "The seed could not be decoded: "+err.Error())
+		return
+	}
+	if !configuredType.IsNull() && !strings.EqualFold(configuredType.ValueString(), data.KeyType.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("type"), "seed type mismatch", "The seed is of type "+data.KeyType.ValueString()+" but type is set to "+configuredType.ValueString()+".")
+		return
+	}
+	if !configuredType.IsNull() {
+		data.KeyType = types.StringValue(configuredType.ValueString())
+	}
+	resp.Diagnostics.Append(data.warnings()...)
+	tflog.Trace(ctx, "opened ephemeral from seed resource", data.logFields())
+
+	// Save data into Terraform ephemeral result
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,161 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/nkeys"
+)
+
+// GeneratedKeyModel describes the nkey of a resource that generates it along
+// with its JWT, or takes an existing seed, e.g. nkey_account.
+type GeneratedKeyModel struct {
+	PublicKey types.String `tfsdk:"public_key"`
+	Seed      types.String `tfsdk:"seed"`
+	RotateKey types.String `tfsdk:"rotate_key"`
+}
+
+// generatedKeyAttributes adds the GeneratedKeyModel attributes of a keyType
+// nkey to the schema attributes of a resource, with rotated describing what
+// happens to what was issued to an nkey that is rotated.
+func generatedKeyAttributes(attrs map[string]schema.Attribute, keyType, rotated string) map[string]schema.Attribute {
+	attrs["public_key"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "Public key of the " + keyType + " nkey, the subject of the JWT",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+	attrs["seed"] = schema.StringAttribute{
+		Optional:            true,
+		Computed:            true,
+		Sensitive:           true,
+		MarkdownDescription: "Seed of the " + keyType + " nkey. A new nkey is generated when it is not set, and kept until `rotate_key` changes. Setting it to another seed issues the JWT again for that nkey. Conflicts with `rotate_key`",
+		Validators: []validator.String{
+			isSeedOfType(keyType),
+		},
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+	attrs["rotate_key"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "Arbitrary value that, when changed, generates a new " + keyType + " nkey and issues the JWT again for it. " + rotated + " Conflicts with `seed`",
+	}
+	return attrs
+}
+
+// generatedKeyConfigValidators returns the config validators of the
+// GeneratedKeyModel attributes.
+func generatedKeyConfigValidators() []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.Conflicting(
+			path.MatchRoot("seed"),
+			path.MatchRoot("rotate_key"),
+		),
+	}
+}
+
+// plannedSubject returns the public key of the configured seed, the subject
+// of the JWT, which is only known at plan time when the seed is configured.
+func (m *GeneratedKeyModel) plannedSubject() types.String {
+	if pubKey, _, err := publicKeyFromSeed([]byte(m.Seed.ValueString())); !m.Seed.IsUnknown() && err == nil {
+		return types.StringValue(pubKey)
+	}
+	return types.StringUnknown()
+}
+
+// planGeneratedKey plans the public key of the configured seed, and a new
+// nkey when the seed is generated and rotate_key changes.
+func planGeneratedKey(ctx context.Context, config tfsdk.Config, state tfsdk.State, plan *tfsdk.Plan) diag.Diagnostics {
+	if plan.Raw.IsNull() {
+		// The resource is being destroyed
+		return nil
+	}
+
+	var seed types.String
+	diags := config.GetAttribute(ctx, path.Root("seed"), &seed)
+	if diags.HasError() {
+		return diags
+	}
+	switch {
+	case seed.IsUnknown():
+		diags.Append(plan.SetAttribute(ctx, path.Root("public_key"), types.StringUnknown())...)
+	case !seed.IsNull():
+		pubKey, _, err := publicKeyFromSeed([]byte(seed.ValueString()))
+		if err != nil {
+			// Left for the attribute validator of seed
+			return diags
+		}
+		diags.Append(plan.SetAttribute(ctx, path.Root("public_key"), types.StringValue(pubKey))...)
+	case !state.Raw.IsNull():
+		var prior, planned types.String
+		diags.Append(state.GetAttribute(ctx, path.Root("rotate_key"), &prior)...)
+		diags.Append(plan.GetAttribute(ctx, path.Root("rotate_key"), &planned)...)
+		if diags.HasError() || prior.Equal(planned) {
+			return diags
+		}
+		diags.Append(plan.SetAttribute(ctx, path.Root("seed"), types.StringUnknown())...)
+		diags.Append(plan.SetAttribute(ctx, path.Root("public_key"), types.StringUnknown())...)
+	}
+	return diags
+}
+
+// setKey generates the keyType nkey when the seed is unknown, and derives the
+// public key of the seed when it is unknown.
+func (m *GeneratedKeyModel) setKey(prefix nkeys.PrefixByte, keyType string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Seed.IsUnknown() {
+		seed, pubKey, err := createBootstrapKey(prefix)
+		if err != nil {
+			diags.AddError("generating nkey", "The "+keyType+" nkey could not be generated: "+err.Error())
+			return diags
+		}
+		m.Seed = types.StringValue(seed)
+		m.PublicKey = types.StringValue(pubKey)
+		return diags
+	}
+	if m.PublicKey.IsUnknown() {
+		pubKey, _, err := publicKeyFromSeed([]byte(m.Seed.ValueString()))
+		if err != nil {
+			diags.AddAttributeError(path.Root("seed"), "invalid seed", err.Error())
+			return diags
+		}
+		m.PublicKey = types.StringValue(pubKey)
+	}
+	return diags
+}
+
+// checkKey checks that the stored seed of a keyType nkey still derives the
+// stored public key, and that the stored JWT is issued to it.
+func (m *GeneratedKeyModel) checkKey(keyType, subject string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	pubKey, _, err := publicKeyFromSeed([]byte(m.Seed.ValueString()))
+	if err != nil {
+		diags.AddAttributeError(path.Root("seed"), "corrupted "+keyType+" state", "The stored seed could not be decoded: "+err.Error())
+		return diags
+	}
+	if pubKey != m.PublicKey.ValueString() {
+		diags.AddAttributeError(path.Root("seed"), "corrupted "+keyType+" state", "The stored public key does not match the public key derived from the stored seed ("+pubKey+").")
+		return diags
+	}
+	if subject != pubKey {
+		diags.AddAttributeError(path.Root("jwt"), "corrupted "+keyType+" state", "The stored JWT is issued to "+subject+" rather than the stored public key.")
+	}
+	return diags
+}
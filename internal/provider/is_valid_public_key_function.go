@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &IsValidPublicKeyFunction{}
+
+func NewIsValidPublicKeyFunction() function.Function {
+	return &IsValidPublicKeyFunction{}
+}
+
+// IsValidPublicKeyFunction defines the function implementation.
+type IsValidPublicKeyFunction struct {
+}
+
+// anyKeyType is the role of is_valid_public_key that accepts every type.
+const anyKeyType = "any"
+
+func (f *IsValidPublicKeyFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "is_valid_public_key"
+}
+
+func (f *IsValidPublicKeyFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Check that a string is an nkey public key of a given type",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Returns whether `key` is a well formed nkey public key of the type `role`, checking its prefix, length and checksum. Anything else, including seeds, private keys, keys of another type and random strings, returns false rather than an error, so the function can be used in preconditions and validation blocks as is.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "key",
+				MarkdownDescription: "String to check",
+			},
+			function.StringParameter{
+				Name:                "role",
+				MarkdownDescription: "Expected type of the key. One of " + strings.Join(keyTypes, "|") + "|" + anyKeyType + ", where " + anyKeyType + " accepts public keys of all types. An unknown role is an error",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *IsValidPublicKeyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var key, role string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &key, &role))
+	if resp.Error != nil {
+		return
+	}
+
+	role = strings.ToLower(role)
+	if role != anyKeyType && !slices.Contains(keyTypes, role) {
+		resp.Error = function.NewArgumentFuncError(1, "the role must be one of "+strings.Join(keyTypes, "|")+"|"+anyKeyType)
+		return
+	}
+
+	parsed, err := parseKey(key)
+	valid := err == nil && parsed.kind == keyKindPublic && (role == anyKeyType || parsed.keyType == role)
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, valid))
+}
@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/nkeys"
+)
+
+// testKeys is a public key, seed and private key of one type.
+type testKeys struct {
+	keyType    string
+	publicKey  string
+	seed       string
+	privateKey string
+}
+
+// createTestKeys creates a key pair of every type of keyTypes.
+func createTestKeys(t *testing.T) []testKeys {
+	t.Helper()
+
+	var keys []testKeys
+	for _, keyType := range keyTypes {
+		prefix, err := keyTypePrefix(keyType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kp, err := nkeys.CreatePair(prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		publicKey, err := kp.PublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seed, err := kp.Seed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		privateKey, err := kp.PrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, testKeys{keyType: keyType, publicKey: publicKey, seed: string(seed), privateKey: string(privateKey)})
+	}
+	return keys
+}
+
+func TestIsValidPublicKeyFunction(t *testing.T) {
+	isValid := func(t *testing.T, key, role string) bool {
+		t.Helper()
+
+		got, funcErr := runFunction(t, &IsValidPublicKeyFunction{}, types.StringValue(key), types.StringValue(role))
+		if funcErr != nil {
+			t.Fatalf("is_valid_public_key(%q, %q) error = %v", key, role, funcErr)
+		}
+		return got.Equal(types.BoolValue(true))
+	}
+
+	roles := append([]string{anyKeyType}, keyTypes...)
+	for _, keys := range createTestKeys(t) {
+		t.Run(keys.keyType, func(t *testing.T) {
+			// Only the role of the prefix and any accept the public key
+			for _, role := range roles {
+				want := role == keys.keyType || role == anyKeyType
+				if got := isValid(t, keys.publicKey, role); got != want {
+					t.Errorf("is_valid_public_key(%s, %q) = %v, want %v", keys.publicKey, role, got, want)
+				}
+				if got := isValid(t, keys.publicKey, strings.ToUpper(role)); got != want {
+					t.Errorf("is_valid_public_key(%s, %q) = %v, want %v", keys.publicKey, strings.ToUpper(role), got, want)
+				}
+			}
+
+			// No role accepts the seed, the private key, or the public key
+			// with another checksum, truncated or in lowercase
+			last := "A"
+			if strings.HasSuffix(keys.publicKey, last) {
+				last = "B"
+			}
+			for _, key := range []string{
+				keys.seed,
+				keys.privateKey,
+				keys.publicKey[:len(keys.publicKey)-1] + last,
+				keys.publicKey[:len(keys.publicKey)-1],
+				keys.publicKey + "A",
+				strings.ToLower(keys.publicKey),
+				" " + keys.publicKey,
+			} {
+				for _, role := range roles {
+					if isValid(t, key, role) {
+						t.Errorf("is_valid_public_key(%q, %q) = true", key, role)
+					}
+				}
+			}
+		})
+	}
+
+	// Strings that are no key at all are false rather than an error
+	for _, key := range []string{"", "A", "not a key", "AAAAAAAA" + strings.Repeat("=", 48)} {
+		for _, role := range roles {
+			if isValid(t, key, role) {
+				t.Errorf("is_valid_public_key(%q, %q) = true", key, role)
+			}
+		}
+	}
+
+	for _, role := range []string{"", "seed", "private", "users", " user"} {
+		got, funcErr := runFunction(t, &IsValidPublicKeyFunction{}, types.StringValue(testUserPublicKey), types.StringValue(role))
+		if funcErr == nil || funcErr.FunctionArgument == nil || *funcErr.FunctionArgument != 1 {
+			t.Errorf("is_valid_public_key() of the role %q = %v, %v, want an error on the role", role, got, funcErr)
+		}
+	}
+}
@@ -0,0 +1,887 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// defaultExpiryWarning is the expiry_warning of the JWT resources when it is
+// not configured.
+const defaultExpiryWarning = "720h"
+
+// JWTModel describes the issued token and its lifetime shared by the JWT
+// resources.
+type JWTModel struct {
+	JWT           types.String `tfsdk:"jwt"`
+	Issuer        types.String `tfsdk:"issuer"`
+	IssuedAt      types.String `tfsdk:"issued_at"`
+	ClaimsHash    types.String `tfsdk:"claims_hash"`
+	ExpiresAt     types.String `tfsdk:"expires_at"`
+	ExpiresIn     types.String `tfsdk:"expires_in"`
+	NotBefore     types.String `tfsdk:"not_before"`
+	ExpiresAtUnix types.Int64  `tfsdk:"expires_at_unix"`
+	ExpiryWarning types.String `tfsdk:"expiry_warning"`
+	RenewBefore   types.String `tfsdk:"renew_before"`
+}
+
+// issueJWT validates claims and encodes them signed with seed into the
+// model, valid for the configured lifetime. Blocking validation issues are
+// errors, the others are warnings.
+func (m *JWTModel) issueJWT(claims jwt.Claims, seed string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	keys, err := signingKeys(seed)
+	if err != nil {
+		diags.AddAttributeError(path.Root("signing_seed"), "invalid signing seed", err.Error())
+		return diags
+	}
+	defer keys.Wipe()
+
+	diags.Append(m.setLifetime(claims.Claims(), time.Now())...)
+	if diags.HasError() {
+		return diags
+	}
+
+	token, encodeDiags := encodeJWT(claims, keys)
+	diags.Append(encodeDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := m.setToken(token, claims.Claims()); err != nil {
+		diags.AddAttributeError(path.Root("jwt"), "issuing JWT", err.Error())
+	}
+	return diags
+}
+
+// setToken stores token and the attributes derived from its claims in the
+// model.
+func (m *JWTModel) setToken(token string, claims *jwt.ClaimsData) error {
+	hash, err := claimsHash(token)
+	if err != nil {
+		return err
+	}
+
+	m.JWT = types.StringValue(token)
+	m.Issuer = types.StringValue(claims.Issuer)
+	m.IssuedAt = types.StringValue(time.Unix(claims.IssuedAt, 0).UTC().Format(time.RFC3339))
+	m.ClaimsHash = types.StringValue(hash)
+	m.ExpiresAtUnix = types.Int64Value(claims.Expires)
+	return nil
+}
+
+// claimsHash returns the hex SHA-256 of the claims of token without jti and
+// iat, which change whenever it is issued, so the hash only changes with the
+// content of the claims.
+func claimsHash(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("the JWT does not have three parts")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("the claims of the JWT are not base64url: %w", err)
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("the claims of the JWT are not JSON: %w", err)
+	}
+	delete(claims, "jti")
+	delete(claims, "iat")
+
+	// Maps are marshaled with sorted keys, so the hash does not depend on the
+	// order of the claims in the token
+	canonical, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// decodedClaims are the claims every NATS JWT shares, in version 1 and 2.
+type decodedClaims struct {
+	ID        string `json:"jti"`
+	IssuedAt  int64  `json:"iat"`
+	Issuer    string `json:"iss"`
+	Name      string `json:"name"`
+	Subject   string `json:"sub"`
+	Expires   int64  `json:"exp"`
+	NotBefore int64  `json:"nbf"`
+	// Type is the claim type of version 1 JWTs, which version 2 moved into
+	// the nats claims.
+	Type string `json:"type"`
+	Nats struct {
+		Type          string   `json:"type"`
+		IssuerAccount string   `json:"issuer_account"`
+		Tags          []string `json:"tags"`
+	} `json:"nats"`
+}
+
+// decodeClaims decodes the header and claims of a NATS JWT without verifying
+// its signature, returning the decoded claims JSON along with them.
+func decodeClaims(token string) ([]byte, *decodedClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, errors.New("the JWT does not have three parts")
+	}
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("the header of the JWT is not base64url: %w", err)
+	}
+	var h struct {
+		Type      string `json:"typ"`
+		Algorithm string `json:"alg"`
+	}
+	if err := json.Unmarshal(header, &h); err != nil {
+		return nil, nil, fmt.Errorf("the header of the JWT is not JSON: %w", err)
+	}
+	if !strings.EqualFold(h.Type, "JWT") || (h.Algorithm != jwt.AlgorithmNkey && h.Algorithm != jwt.AlgorithmNkeyOld) {
+		return nil, nil, fmt.Errorf("the header of the JWT is of type %q with algorithm %q rather than a NATS JWT signed with an nkey", h.Type, h.Algorithm)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("the claims of the JWT are not base64url: %w", err)
+	}
+	var claims decodedClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, nil, fmt.Errorf("the claims of the JWT are not JSON: %w", err)
+	}
+	if claims.Subject == "" || claims.Issuer == "" {
+		return nil, nil, errors.New("the claims of the JWT have no subject or issuer")
+	}
+	if claims.Nats.Type == "" && claims.Type == "" {
+		return nil, nil, errors.New("the claims of the JWT have no type")
+	}
+	return payload, &claims, nil
+}
+
+// claimType returns the claim type of version 2 JWTs, or of version 1 JWTs.
+func (c *decodedClaims) claimType() string {
+	if c.Nats.Type == "" {
+		return c.Type
+	}
+	return c.Nats.Type
+}
+
+// encodeJWT validates claims and encodes them signed with keys. Blocking
+// validation issues are errors, the others are warnings.
+func encodeJWT(claims jwt.Claims, keys nkeys.KeyPair) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	vr := jwt.ValidationResults{}
+	claims.Validate(&vr)
+	for _, issue := range vr.Issues {
+		if issue.Blocking {
+			diags.AddError("invalid claims", "The claims of the JWT are invalid: "+issue.Description+".")
+		} else {
+			diags.AddWarning("questionable claims", "nats-server may reject the JWT: "+issue.Description+".")
+		}
+	}
+	if diags.HasError() {
+		return "", diags
+	}
+
+	token, err := claims.Encode(keys)
+	if err != nil {
+		diags.AddError("issuing JWT", "The JWT could not be encoded: "+err.Error())
+		return "", diags
+	}
+	return token, diags
+}
+
+// reservedClaims are the claims set when a JWT is issued, which
+// additional_claims_json never sets.
+var reservedClaims = []string{"jti", "iat", "iss", "sub", "exp", "nbf", "nats.type", "nats.version", "nats.issuer_account"}
+
+// mergeClaims deep-merges the JSON object additional into claims: objects are
+// merged key by key, and any other value replaces the claim. The claims set by
+// the attributes, i.e. those that differ from defaults, the claims of the same
+// type issued without any attribute, are kept. It returns the sorted keys of
+// additional that collide with them or with reservedClaims, and fails on keys
+// that are not claims of the JWT library.
+func mergeClaims(claims, defaults jwt.Claims, additional string) ([]string, error) {
+	var extra map[string]interface{}
+	if err := json.Unmarshal([]byte(additional), &extra); err != nil {
+		return nil, err
+	}
+	built, err := claimsObject(claims)
+	if err != nil {
+		return nil, err
+	}
+	base, err := claimsObject(defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	var collisions []string
+	mergeObject(built, base, extra, "", &collisions)
+	merged, err := json.Marshal(built)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode into a zero value of the claims, so every claim comes from the
+	// merged object, then check that none of the keys of additional is lost.
+	target := reflect.ValueOf(claims).Elem()
+	fresh := reflect.New(target.Type())
+	if err := json.Unmarshal(merged, fresh.Interface()); err != nil {
+		return nil, err
+	}
+	target.Set(fresh.Elem())
+	decoded, err := claimsObject(claims)
+	if err != nil {
+		return nil, err
+	}
+	var unknown []string
+	lostKeys(decoded, extra, "", collisions, &unknown)
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return nil, errors.New("unknown claims " + strings.Join(unknown, ", "))
+	}
+
+	slices.Sort(collisions)
+	return collisions, nil
+}
+
+// claimsObject returns claims as the JSON object they are encoded to.
+func claimsObject(claims jwt.Claims) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]interface{}
+	err = json.Unmarshal(encoded, &object)
+	return object, err
+}
+
+// mergeObject merges extra into built at prefix, adding the keys that collide
+// with a reserved claim or with a value of built that differs from base to
+// collisions.
+func mergeObject(built, base, extra map[string]interface{}, prefix string, collisions *[]string) {
+	for key, value := range extra {
+		name := prefix + key
+		if slices.Contains(reservedClaims, name) {
+			*collisions = append(*collisions, name)
+			continue
+		}
+		builtValue, set := built[key]
+		baseValue := base[key]
+		builtObject, builtIsObject := builtValue.(map[string]interface{})
+		if object, ok := value.(map[string]interface{}); ok && (builtIsObject || !set) {
+			if !set {
+				builtObject = map[string]interface{}{}
+				built[key] = builtObject
+			}
+			baseObject, _ := baseValue.(map[string]interface{})
+			mergeObject(builtObject, baseObject, object, name+".", collisions)
+			continue
+		}
+		if set && !reflect.DeepEqual(builtValue, baseValue) {
+			*collisions = append(*collisions, name)
+			continue
+		}
+		built[key] = value
+	}
+}
+
+// lostKeys adds the keys of extra at prefix whose values are not in decoded
+// to lost, but for the collisions that were not merged.
+func lostKeys(decoded, extra map[string]interface{}, prefix string, collisions []string, lost *[]string) {
+	for key, value := range extra {
+		name := prefix + key
+		if slices.Contains(collisions, name) {
+			continue
+		}
+		decodedValue, ok := decoded[key]
+		if object, isObject := value.(map[string]interface{}); isObject {
+			decodedObject, _ := decodedValue.(map[string]interface{})
+			lostKeys(decodedObject, object, name+".", collisions, lost)
+			continue
+		}
+		if value == nil && !ok {
+			continue
+		}
+		if !reflect.DeepEqual(decodedValue, value) {
+			*lost = append(*lost, name)
+		}
+	}
+}
+
+// addAdditionalClaims merges additional_claims_json into claims before they
+// are signed. Collisions are errors at plan time, so any left here involve
+// values that were unknown then, and the attributes win with a warning.
+func addAdditionalClaims(claims, defaults jwt.Claims, additional types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if additional.IsNull() {
+		return diags
+	}
+	collisions, err := mergeClaims(claims, defaults, additional.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("additional_claims_json"), "invalid additional claims", "The additional_claims_json cannot be merged into the claims: "+err.Error()+".")
+		return diags
+	}
+	if len(collisions) > 0 {
+		diags.AddAttributeWarning(path.Root("additional_claims_json"), "colliding additional claims", "The attributes win over the keys of additional_claims_json that set the same claims: "+strings.Join(collisions, ", ")+".")
+	}
+	return diags
+}
+
+// checkAdditionalClaims reports at plan time the keys of
+// additional_claims_json that collide with the claims built from the
+// attributes, or that are not claims of the JWT.
+func checkAdditionalClaims(claims, defaults jwt.Claims, additional types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if additional.IsNull() || additional.IsUnknown() {
+		return diags
+	}
+	collisions, err := mergeClaims(claims, defaults, additional.ValueString())
+	switch {
+	case err != nil:
+		diags.AddAttributeError(path.Root("additional_claims_json"), "invalid additional claims", "The additional_claims_json cannot be merged into the claims: "+err.Error()+".")
+	case len(collisions) > 0:
+		diags.AddAttributeError(path.Root("additional_claims_json"), "colliding additional claims", "The additional_claims_json sets claims that attributes set, or that are set when the JWT is issued: "+strings.Join(collisions, ", ")+". Set them with the attributes instead.")
+	}
+	return diags
+}
+
+// claimsConfigKnown reports whether every attribute of config but the
+// write-only signing seed is known, so that the claims can be built at plan
+// time.
+func claimsConfigKnown(config tfsdk.Config) bool {
+	var attrs map[string]tftypes.Value
+	if err := config.Raw.As(&attrs); err != nil {
+		return false
+	}
+	for name, value := range attrs {
+		if name != "signing_seed" && !value.IsFullyKnown() {
+			return false
+		}
+	}
+	return true
+}
+
+// additionalClaimsAttribute returns the schema attribute of the claims merged
+// into a JWT that have no attribute of their own.
+func additionalClaimsAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "JSON object of claims without an attribute of their own, e.g. from `jsonencode`, deep-merged into the claims of the JWT before it is signed: objects are merged key by key, and any other value replaces the claim. The keys follow the encoded JWT, e.g. `nats.limits.subs` of an account JWT, and must be claims the JWT library knows. Keys that collide with claims an attribute sets to anything but its default, or with the claims set when the JWT is issued such as `sub` or `exp`, fail the plan",
+		Validators: []validator.String{
+			isJSONObject(),
+		},
+	}
+}
+
+// setLifetime sets the expiry and not before times of claims, with expires_in
+// counted from now.
+func (m *JWTModel) setLifetime(claims *jwt.ClaimsData, now time.Time) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !m.ExpiresAt.IsNull() {
+		expires, err := time.Parse(time.RFC3339, m.ExpiresAt.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("expires_at"), "invalid timestamp", err.Error())
+			return diags
+		}
+		claims.Expires = expires.Unix()
+	}
+	if !m.ExpiresIn.IsNull() {
+		lifetime, err := time.ParseDuration(m.ExpiresIn.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("expires_in"), "invalid duration", err.Error())
+			return diags
+		}
+		claims.Expires = now.Add(lifetime).Unix()
+	}
+
+	if !m.NotBefore.IsNull() {
+		notBefore, err := time.Parse(time.RFC3339, m.NotBefore.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("not_before"), "invalid timestamp", err.Error())
+			return diags
+		}
+		claims.NotBefore = notBefore.Unix()
+	}
+	if claims.Expires != 0 && claims.NotBefore >= claims.Expires {
+		diags.Append(errInvalidLifetime())
+	}
+	return diags
+}
+
+// validateLifetime reports an expires_at that is not after not_before when
+// both are known, so the mistake surfaces at plan time. Invalid timestamps are
+// left for the attribute validators.
+func (m *JWTModel) validateLifetime() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	diags.Append(m.validateRenewal()...)
+	if m.ExpiresAt.IsNull() || m.ExpiresAt.IsUnknown() || m.NotBefore.IsNull() || m.NotBefore.IsUnknown() {
+		return diags
+	}
+	expires, err := time.Parse(time.RFC3339, m.ExpiresAt.ValueString())
+	if err != nil {
+		return diags
+	}
+	notBefore, err := time.Parse(time.RFC3339, m.NotBefore.ValueString())
+	if err != nil {
+		return diags
+	}
+	if !notBefore.Before(expires) {
+		diags.Append(errInvalidLifetime())
+	}
+	return diags
+}
+
+// validateRenewal reports a renew_before that is not shorter than expires_in
+// when both are known, since the JWT would be renewed on every plan.
+func (m *JWTModel) validateRenewal() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.RenewBefore.IsNull() || m.RenewBefore.IsUnknown() || m.ExpiresIn.IsNull() || m.ExpiresIn.IsUnknown() {
+		return diags
+	}
+	renewBefore, err := time.ParseDuration(m.RenewBefore.ValueString())
+	if err != nil {
+		return diags
+	}
+	expiresIn, err := time.ParseDuration(m.ExpiresIn.ValueString())
+	if err != nil {
+		return diags
+	}
+	if renewBefore >= expiresIn {
+		diags.AddAttributeError(path.Root("renew_before"), "invalid renewal window", "The renew_before of "+m.RenewBefore.ValueString()+" is not shorter than the expires_in of "+m.ExpiresIn.ValueString()+", so the JWT would be issued again on every plan.")
+	}
+	return diags
+}
+
+// renewalDue reports whether a JWT expiring at expiresAtUnix, e.g. the prior
+// one in state, has expired or expires within renewBefore of now.
+func renewalDue(renewBefore types.String, expiresAtUnix types.Int64, now time.Time) bool {
+	if renewBefore.IsNull() || renewBefore.IsUnknown() || expiresAtUnix.ValueInt64() == 0 {
+		return false
+	}
+	threshold, err := time.ParseDuration(renewBefore.ValueString())
+	if err != nil {
+		return false
+	}
+	return !now.Add(threshold).Before(time.Unix(expiresAtUnix.ValueInt64(), 0))
+}
+
+// errInvalidLifetime returns the error of a JWT that expires before it becomes
+// valid.
+func errInvalidLifetime() diag.Diagnostic {
+	return diag.NewAttributeErrorDiagnostic(path.Root("not_before"), "invalid lifetime", "The JWT would expire before it becomes valid, not_before must be earlier than its expiry.")
+}
+
+// expiryWarnings warns when the JWT expires within expiry_warning of now, or
+// has already expired. A JWT due for renewal only warns that the next apply
+// issues it again.
+func (m *JWTModel) expiryWarnings(now time.Time) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.ExpiresAtUnix.ValueInt64() == 0 {
+		return diags
+	}
+	if renewalDue(m.RenewBefore, m.ExpiresAtUnix, now) {
+		expires := time.Unix(m.ExpiresAtUnix.ValueInt64(), 0).UTC()
+		diags.AddAttributeWarning(path.Root("jwt"), "JWT due for renewal", "The JWT expires at "+expires.Format(time.RFC3339)+", within the renew_before of "+m.RenewBefore.ValueString()+", so the next apply issues it again.")
+		return diags
+	}
+	threshold, err := time.ParseDuration(m.ExpiryWarning.ValueString())
+	if err != nil {
+		return diags
+	}
+
+	expires := time.Unix(m.ExpiresAtUnix.ValueInt64(), 0).UTC()
+	switch {
+	case !now.Before(expires):
+		diags.AddAttributeWarning(path.Root("jwt"), "JWT expired", "The JWT expired at "+expires.Format(time.RFC3339)+" and is rejected by nats-server. Change its expiry to issue it again.")
+	case expires.Sub(now) < threshold:
+		diags.AddAttributeWarning(path.Root("jwt"), "JWT expires soon", "The JWT expires at "+expires.Format(time.RFC3339)+", within the expiry_warning of "+m.ExpiryWarning.ValueString()+". Change its expiry to issue it again.")
+	}
+	return diags
+}
+
+// errSelfSigned is returned when the seed of a self-signed JWT does not belong
+// to its subject.
+var errSelfSigned = errors.New("the JWT is self-signed, so signing_seed must be the seed of subject")
+
+// checkSelfSigned returns errSelfSigned unless seed is the seed of subject.
+// Either one is left for the attribute validators to reject when invalid.
+func checkSelfSigned(seed, subject string) error {
+	pubKey, _, err := publicKeyFromSeed([]byte(seed))
+	if err != nil || pubKey == subject {
+		return nil
+	}
+	if _, err := parseKey(subject); err != nil {
+		return nil
+	}
+	return errSelfSigned
+}
+
+// checkCredsSeed returns the public key of seed, or an error naming both
+// public keys unless it is subject, the user a JWT is issued to, so that the
+// stale one of the two can be told apart.
+func checkCredsSeed(seed, subject string) (string, error) {
+	pubKey, _, err := publicKeyFromSeed([]byte(seed))
+	if err != nil {
+		return "", err
+	}
+	if pubKey != subject {
+		return "", errors.New("the seed belongs to the user " + pubKey + ", but the JWT is issued to the user " + subject + ", so either the seed or the JWT is stale")
+	}
+	return pubKey, nil
+}
+
+// parseCreds extracts the user JWT and the user seed from the contents of a
+// creds file the way the NATS clients do, returning the claims of the JWT.
+// The seed is not checked against the subject of the JWT, and the caller
+// wipes it.
+func parseCreds(creds string) (string, []byte, *jwt.UserClaims, error) {
+	// The library returns contents without armor as they are, taking them
+	// for a bare JWT
+	token, err := jwt.ParseDecoratedJWT([]byte(creds))
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if token == creds {
+		return "", nil, nil, errors.New("no user JWT block found in the creds")
+	}
+	claims, err := jwt.DecodeUserClaims(token)
+	if err != nil {
+		return "", nil, nil, errors.New("the JWT block of the creds is not a validly signed user JWT: " + err.Error())
+	}
+
+	keys, err := jwt.ParseDecoratedNKey([]byte(creds))
+	if err != nil {
+		return "", nil, nil, errors.New("no user seed block found in the creds: " + err.Error())
+	}
+	defer keys.Wipe()
+	seed, err := keys.Seed()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if prefix, _, _ := nkeys.DecodeSeed(seed); prefix != nkeys.PrefixByteUser {
+		wipe(seed)
+		return "", nil, nil, errors.New("the seed block of the creds holds an " + keyTypeNames[prefix] + " seed rather than a user seed")
+	}
+	return token, seed, claims, nil
+}
+
+// checkSigner returns an error unless signer is the identity nkey or one of
+// the signing keys of the entity that signs a JWT, e.g. the operator of an
+// account. With strict signing key usage, the identity nkey is rejected too.
+func checkSigner(signer, identity string, signingKeys []string, strict bool) error {
+	switch {
+	case signer == identity && strict:
+		return errors.New("strict signing key usage is enabled, so signing_seed must be one of the signing keys rather than the identity nkey " + identity)
+	case signer == identity:
+		return nil
+	case !slices.Contains(signingKeys, signer):
+		return errors.New("signing_seed belongs to " + signer + ", which is neither the identity nkey " + identity + " nor one of its signing keys")
+	}
+	return nil
+}
+
+// issuerAccount returns accountPublicKey when signingSeed is not the account
+// nkey itself, and "" otherwise: nats-server requires the JWT of a user or an
+// activation signed by a signing key to name its account, and nsc rejects a
+// JWT that names its own issuer.
+func issuerAccount(signingSeed, accountPublicKey string) string {
+	signer, _, err := publicKeyFromSeed([]byte(signingSeed))
+	if err != nil || accountPublicKey == signer {
+		return ""
+	}
+	return accountPublicKey
+}
+
+// planJWTReissue plans the issuer of the configured signing seed, and the
+// issued token as unknown when any attribute changes but expiry_warning,
+// renew_before and the given attributes that do not affect the claims. So the
+// token is issued again exactly when its claims or signer change, or when it
+// is due for renewal within renew_before of its expiry, and not merely because
+// time has passed since it was issued.
+func planJWTReissue(ctx context.Context, config tfsdk.Config, state tfsdk.State, plan *tfsdk.Plan, ignored ...string) diag.Diagnostics {
+	if plan.Raw.IsNull() {
+		// The resource is being destroyed
+		return nil
+	}
+
+	// The signing seed is write-only, so a new signer only shows in the plan
+	// through the issuer derived from it
+	var seed types.String
+	diags := config.GetAttribute(ctx, path.Root("signing_seed"), &seed)
+	issuer := types.StringUnknown()
+	if pubKey, _, err := publicKeyFromSeed([]byte(seed.ValueString())); !seed.IsUnknown() && err == nil {
+		issuer = types.StringValue(pubKey)
+	}
+	diags.Append(plan.SetAttribute(ctx, path.Root("issuer"), issuer)...)
+	if diags.HasError() || state.Raw.IsNull() {
+		// The resource is being created
+		return diags
+	}
+
+	// A JWT due for renewal is issued again with a fresh lifetime even when
+	// nothing changed
+	var renewBefore types.String
+	var expiresAtUnix types.Int64
+	diags.Append(plan.GetAttribute(ctx, path.Root("renew_before"), &renewBefore)...)
+	diags.Append(state.GetAttribute(ctx, path.Root("expires_at_unix"), &expiresAtUnix)...)
+	if diags.HasError() {
+		return diags
+	}
+	if renewalDue(renewBefore, expiresAtUnix, time.Now()) {
+		diags.Append(planJWTIssued(ctx, plan)...)
+		return diags
+	}
+
+	// Compare the plan with the prior values of the attributes that only
+	// affect the warnings when the state is read, or are only checked
+	compared := *plan
+	for _, name := range append([]string{"expiry_warning", "renew_before"}, ignored...) {
+		var prior attr.Value
+		diags.Append(state.GetAttribute(ctx, path.Root(name), &prior)...)
+		diags.Append(compared.SetAttribute(ctx, path.Root(name), prior)...)
+	}
+	if diags.HasError() || compared.Raw.Equal(state.Raw) {
+		return diags
+	}
+
+	diags.Append(planJWTIssued(ctx, plan)...)
+	return diags
+}
+
+// planJWTIssued plans the JWTModel attributes of a JWT that is issued again.
+func planJWTIssued(ctx context.Context, plan *tfsdk.Plan) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	diags.Append(plan.SetAttribute(ctx, path.Root("jwt"), types.StringUnknown())...)
+	diags.Append(plan.SetAttribute(ctx, path.Root("issued_at"), types.StringUnknown())...)
+	diags.Append(plan.SetAttribute(ctx, path.Root("claims_hash"), types.StringUnknown())...)
+	diags.Append(plan.SetAttribute(ctx, path.Root("expires_at_unix"), types.Int64Unknown())...)
+	return diags
+}
+
+// plannedClaimsHash returns the claims hash claims would have when signed by
+// issuer. They are encoded without a signature, which the hash ignores.
+func plannedClaimsHash(claims jwt.Claims, issuer string) (string, error) {
+	keys, err := nkeys.FromPublicKey(issuer)
+	if err != nil {
+		return "", err
+	}
+	token, err := claims.EncodeWithSigner(keys, func(string, []byte) ([]byte, error) { return nil, nil })
+	if err != nil {
+		return "", err
+	}
+	return claimsHash(token)
+}
+
+// planJWTUnchanged keeps the token of prior, the model of the state, when
+// planned, the model of the plan, is to be issued again but claims, the
+// claims of the plan without their lifetime, hash to the claims hash of prior.
+// So attribute changes that leave the claims as they are, e.g. setting an
+// attribute to its default, do not issue the JWT again. An unchanged
+// expires_in counts from when prior was issued, unless prior is due for
+// renewal.
+func planJWTUnchanged(ctx context.Context, plan *tfsdk.Plan, planned, prior *JWTModel, claims jwt.Claims) diag.Diagnostics {
+	if !planned.JWT.IsUnknown() || planned.Issuer.IsUnknown() || prior.JWT.IsNull() || !planned.ExpiresIn.Equal(prior.ExpiresIn) {
+		return nil
+	}
+	if renewalDue(planned.RenewBefore, prior.ExpiresAtUnix, time.Now()) {
+		return nil
+	}
+	if planned.setLifetime(claims.Claims(), time.Now()).HasError() {
+		// Left for issuing the JWT to report
+		return nil
+	}
+	if !planned.ExpiresIn.IsNull() {
+		claims.Claims().Expires = prior.ExpiresAtUnix.ValueInt64()
+	}
+	hash, err := plannedClaimsHash(claims, planned.Issuer.ValueString())
+	if err != nil || hash != prior.ClaimsHash.ValueString() {
+		return nil
+	}
+
+	diags := plan.SetAttribute(ctx, path.Root("jwt"), prior.JWT)
+	diags.Append(plan.SetAttribute(ctx, path.Root("issued_at"), prior.IssuedAt)...)
+	diags.Append(plan.SetAttribute(ctx, path.Root("claims_hash"), prior.ClaimsHash)...)
+	diags.Append(plan.SetAttribute(ctx, path.Root("expires_at_unix"), prior.ExpiresAtUnix)...)
+	return diags
+}
+
+// jwtConfigValidators returns the config validators of the JWTModel
+// attributes.
+func jwtConfigValidators() []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.Conflicting(
+			path.MatchRoot("expires_at"),
+			path.MatchRoot("expires_in"),
+		),
+	}
+}
+
+// jwtResourceAttributes adds the JWTModel attributes to the schema attributes
+// of a JWT resource.
+func jwtResourceAttributes(attrs map[string]schema.Attribute) map[string]schema.Attribute {
+	attrs["jwt"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "The encoded JWT. It is only issued again when one of the claims changes",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+	attrs["issuer"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "Public key of the nkey of `signing_seed` that signed the JWT",
+	}
+	attrs["claims_hash"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "Hex SHA-256 of the claims of the JWT without `jti` and `iat`, so it only changes when the content of the claims does",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+	attrs["issued_at"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "RFC 3339 timestamp of when `jwt` was issued",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+	attrs["expires_at"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "RFC 3339 timestamp at which the JWT expires. Conflicts with `expires_in`. The JWT never expires when neither is set",
+		Validators: []validator.String{
+			isRFC3339(),
+		},
+	}
+	attrs["expires_in"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "Positive duration such as `8760h` after which the JWT expires, counted from when it is issued. The expiry is only computed again when the JWT is issued again, not on every plan. Conflicts with `expires_at`",
+		Validators: []validator.String{
+			isPositiveDuration(),
+		},
+	}
+	attrs["not_before"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "RFC 3339 timestamp before which the JWT is not valid yet",
+		Validators: []validator.String{
+			isRFC3339(),
+		},
+	}
+	attrs["expires_at_unix"] = schema.Int64Attribute{
+		Computed:            true,
+		MarkdownDescription: "Unix time at which the JWT expires, or 0 when it never expires",
+		PlanModifiers: []planmodifier.Int64{
+			int64planmodifier.UseStateForUnknown(),
+		},
+	}
+	attrs["expiry_warning"] = schema.StringAttribute{
+		Optional:            true,
+		Computed:            true,
+		MarkdownDescription: "Duration such as `720h` before the expiry of the JWT from which refreshing the state warns that it expires soon. Changing it does not issue the JWT again. Defaults to `" + defaultExpiryWarning + "`",
+		Default:             stringdefault.StaticString(defaultExpiryWarning),
+		Validators: []validator.String{
+			isDuration(),
+		},
+	}
+	attrs["renew_before"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "Positive duration such as `720h` before the expiry of the JWT from which a plan issues it again with a fresh `expires_in`, like the early renewal of a certificate. An expired JWT is issued again too. Must be shorter than `expires_in`, which it requires. Changing it does not issue the JWT again by itself",
+		Validators: []validator.String{
+			isPositiveDuration(),
+			stringvalidator.AlsoRequires(path.MatchRoot("expires_in")),
+		},
+	}
+	return attrs
+}
+
+// signingSeedDescription describes the signing_seed attribute of the JWT
+// resources, after a sentence naming the seed.
+const signingSeedDescription = " The value is write-only and never stored, it is read whenever the JWT is issued. Requires Terraform 1.11 or later"
+
+// tagPattern matches tags that survive the trimming of the JWT library, so the
+// stored tags always match the configured ones ignoring case.
+var tagPattern = regexp.MustCompile(`^\S(.*\S)?$`)
+
+// jwtTagsAttribute returns the schema attribute of the tags of a JWT. Tags
+// are lowercased in the JWT, the way nsc does, so they are compared ignoring
+// case and stored in lowercase.
+func jwtTagsAttribute() schema.SetAttribute {
+	return schema.SetAttribute{
+		ElementType:         types.StringType,
+		Optional:            true,
+		Computed:            true,
+		MarkdownDescription: "Tags of the JWT, e.g. for inventory tooling. Tags are lowercased in the JWT, so changing only their case does not issue it again",
+		Default:             setdefault.StaticValue(types.SetValueMust(types.StringType, []attr.Value{})),
+		Validators: []validator.Set{
+			setvalidator.ValueStringsAre(stringvalidator.RegexMatches(tagPattern, "must not be empty or start or end with whitespace")),
+		},
+		PlanModifiers: []planmodifier.Set{
+			caseInsensitiveSet(),
+		},
+	}
+}
+
+// addTags adds tags to list in lowercase, sorted so that the token does not
+// depend on set order and decodes to the same tags it was encoded from.
+func addTags(tags types.Set, list *jwt.TagList) {
+	values, ok := lowercaseElements(tags)
+	if !ok {
+		return
+	}
+	sorted := slices.Sorted(maps.Keys(values))
+	list.Add(sorted...)
+}
+
+// canonicalTags returns tags in the lowercase form they have in the JWT. Null
+// tags are returned as an empty set.
+func canonicalTags(tags types.Set) types.Set {
+	values, ok := lowercaseElements(tags)
+	if !ok {
+		return tags
+	}
+	elements := make([]attr.Value, 0, len(values))
+	for value := range values {
+		elements = append(elements, types.StringValue(value))
+	}
+	return types.SetValueMust(types.StringType, elements)
+}
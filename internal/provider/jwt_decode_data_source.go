@@ -0,0 +1,203 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &JWTDecodeDataSource{}
+
+func NewJWTDecodeDataSource() datasource.DataSource {
+	return &JWTDecodeDataSource{}
+}
+
+// JWTDecodeDataSource defines the data source implementation.
+type JWTDecodeDataSource struct {
+}
+
+// JWTDecodeDataSourceModel describes the data source data model.
+type JWTDecodeDataSourceModel struct {
+	JWT           types.String `tfsdk:"jwt"`
+	Verify        types.Bool   `tfsdk:"verify"`
+	ClaimType     types.String `tfsdk:"type"`
+	ID            types.String `tfsdk:"id"`
+	Subject       types.String `tfsdk:"subject"`
+	Issuer        types.String `tfsdk:"issuer"`
+	IssuerAccount types.String `tfsdk:"issuer_account"`
+	Name          types.String `tfsdk:"name"`
+	IssuedAt      types.String `tfsdk:"issued_at"`
+	ExpiresAt     types.String `tfsdk:"expires_at"`
+	NotBefore     types.String `tfsdk:"not_before"`
+	Expired       types.Bool   `tfsdk:"expired"`
+	Tags          types.List   `tfsdk:"tags"`
+	Verified      types.Bool   `tfsdk:"verified"`
+	ClaimsJSON    types.String `tfsdk:"claims_json"`
+}
+
+func (d *JWTDecodeDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_jwt_decode"
+}
+
+func (d *JWTDecodeDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Decodes any NATS JWT, e.g. an operator, account, user or activation JWT, and exposes its claims, optionally verifying its signature. It fails on malformed tokens, and only warns on expired ones so that they can still be inspected.",
+
+		Attributes: map[string]schema.Attribute{
+			"jwt": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The encoded JWT",
+			},
+			"verify": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to verify the signature of the JWT against its issuer, and fail when it does not match. The chain of trust of the issuer is not verified. Defaults to false",
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Claim type of the JWT, such as `operator`, `account`, `user` or `activation`",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Unique ID of the JWT, its `jti` claim",
+			},
+			"subject": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Subject of the JWT, the public key it is issued to",
+			},
+			"issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the nkey that signed the JWT",
+			},
+			"issuer_account": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the account of a user or activation JWT signed by one of the signing keys of the account, or null otherwise",
+			},
+			"name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Name of the JWT, or null when it has none",
+			},
+			"issued_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp at which the JWT was issued",
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp at which the JWT expires, or null when it never expires",
+			},
+			"not_before": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp before which the JWT is not valid, or null when it is valid from when it was issued",
+			},
+			"expired": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the JWT has expired",
+			},
+			"tags": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Tags of the JWT, empty when it has none",
+			},
+			"verified": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the signature of the JWT was verified, i.e. whether `verify` is true",
+			},
+			"claims_json": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The full decoded claims of the JWT as JSON, e.g. for `jsondecode` to read the claims that have no attribute of their own",
+			},
+		},
+	}
+}
+
+func (d *JWTDecodeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data JWTDecodeDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	token := data.JWT.ValueString()
+	payload, claims, err := decodeClaims(token)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "malformed JWT", "The jwt is not a NATS JWT: "+err.Error()+".")
+		return
+	}
+
+	data.Verified = types.BoolValue(data.Verify.ValueBool())
+	if data.Verify.ValueBool() {
+		if !nkeys.IsValidPublicKey(claims.Issuer) {
+			resp.Diagnostics.AddAttributeError(path.Root("jwt"), "unverifiable JWT", "The issuer of the JWT is not an nkey public key, so its signature cannot be verified against it.")
+			return
+		}
+		// Decoding verifies the signature against the issuer of the token
+		if _, err := jwt.Decode(token); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("jwt"), "invalid JWT signature", "The JWT does not verify against its issuer "+claims.Issuer+": "+err.Error()+".")
+			return
+		}
+	}
+
+	data.ClaimType = types.StringValue(claims.claimType())
+	data.ID = types.StringValue(claims.ID)
+	data.Subject = types.StringValue(claims.Subject)
+	data.Issuer = types.StringValue(claims.Issuer)
+	data.IssuerAccount = types.StringNull()
+	if claims.Nats.IssuerAccount != "" {
+		data.IssuerAccount = types.StringValue(claims.Nats.IssuerAccount)
+	}
+	data.Name = types.StringNull()
+	if claims.Name != "" {
+		data.Name = types.StringValue(claims.Name)
+	}
+	data.IssuedAt = types.StringValue(time.Unix(claims.IssuedAt, 0).UTC().Format(time.RFC3339))
+	data.ExpiresAt = types.StringNull()
+	if claims.Expires != 0 {
+		data.ExpiresAt = types.StringValue(time.Unix(claims.Expires, 0).UTC().Format(time.RFC3339))
+	}
+	data.NotBefore = types.StringNull()
+	if claims.NotBefore != 0 {
+		data.NotBefore = types.StringValue(time.Unix(claims.NotBefore, 0).UTC().Format(time.RFC3339))
+	}
+	tags := claims.Nats.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	var diags diag.Diagnostics
+	data.Tags, diags = types.ListValueFrom(ctx, types.StringType, tags)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ClaimsJSON = types.StringValue(string(payload))
+
+	now := time.Now().Unix()
+	data.Expired = types.BoolValue(claims.Expires != 0 && now > claims.Expires)
+	if data.Expired.ValueBool() {
+		resp.Diagnostics.AddAttributeWarning(path.Root("jwt"), "JWT expired", "The JWT is well formed but expired at "+data.ExpiresAt.ValueString()+", so nats-server rejects it.")
+	}
+	tflog.Trace(ctx, "read JWT decode data source", map[string]interface{}{
+		"type":    data.ClaimType.ValueString(),
+		"subject": claims.Subject,
+	})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &JWTExpiresAtFunction{}
+
+func NewJWTExpiresAtFunction() function.Function {
+	return &JWTExpiresAtFunction{}
+}
+
+// JWTExpiresAtFunction defines the function implementation.
+type JWTExpiresAtFunction struct {
+}
+
+func (f *JWTExpiresAtFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "jwt_expires_at"
+}
+
+func (f *JWTExpiresAtFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Extract the expiry of a NATS JWT",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Returns the RFC 3339 timestamp at which `jwt` expires, or null when it has no `exp` claim and never expires. The claims are decoded without verifying the signature, and every NATS claim type is accepted. A structurally invalid JWT fails the call.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "jwt",
+				MarkdownDescription: "The encoded JWT",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *JWTExpiresAtFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var token string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &token))
+	if resp.Error != nil {
+		return
+	}
+
+	_, claims, err := decodeClaims(token)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	expiresAt := types.StringNull()
+	if claims.Expires != 0 {
+		expiresAt = types.StringValue(time.Unix(claims.Expires, 0).UTC().Format(time.RFC3339))
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, expiresAt))
+}
@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &JWTIsExpiredFunction{}
+
+func NewJWTIsExpiredFunction() function.Function {
+	return &JWTIsExpiredFunction{}
+}
+
+// JWTIsExpiredFunction defines the function implementation.
+type JWTIsExpiredFunction struct {
+}
+
+func (f *JWTIsExpiredFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "jwt_is_expired"
+}
+
+func (f *JWTIsExpiredFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Check whether a NATS JWT has expired",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Returns whether `jwt` has expired at the time the function is evaluated, e.g. at plan time, so the result of the same token changes once it expires. A JWT without an `exp` claim never expires. The claims are decoded without verifying the signature, and every NATS claim type is accepted. A structurally invalid JWT fails the call.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "jwt",
+				MarkdownDescription: "The encoded JWT",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *JWTIsExpiredFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var token string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &token))
+	if resp.Error != nil {
+		return
+	}
+
+	_, claims, err := decodeClaims(token)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, claims.Expires != 0 && time.Now().Unix() > claims.Expires))
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &JWTIssuerFunction{}
+
+func NewJWTIssuerFunction() function.Function {
+	return &JWTIssuerFunction{}
+}
+
+// JWTIssuerFunction defines the function implementation.
+type JWTIssuerFunction struct {
+}
+
+func (f *JWTIssuerFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "jwt_issuer"
+}
+
+func (f *JWTIssuerFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Extract the issuer of a NATS JWT",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Returns the issuer of `jwt`, the public key of the nkey that signed it, e.g. an operator signing key for an account JWT. The claims are decoded without verifying the signature, use the `nkey_jwt_validate` data source for that. Every NATS claim type is accepted, and a structurally invalid JWT fails the call.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "jwt",
+				MarkdownDescription: "The encoded JWT",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *JWTIssuerFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var token string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &token))
+	if resp.Error != nil {
+		return
+	}
+
+	_, claims, err := decodeClaims(token)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, claims.Issuer))
+}
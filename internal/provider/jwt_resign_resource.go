@@ -0,0 +1,391 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &JWTResign{}
+var _ resource.ResourceWithModifyPlan = &JWTResign{}
+var _ resource.ResourceWithValidateConfig = &JWTResign{}
+
+func NewJWTResign() resource.Resource {
+	return &JWTResign{}
+}
+
+// JWTResign defines the resource implementation.
+type JWTResign struct {
+}
+
+// JWTResignModel describes the resource data model.
+type JWTResignModel struct {
+	SourceJWT        types.String `tfsdk:"source_jwt"`
+	SigningSeed      types.String `tfsdk:"signing_seed"`
+	NewIssuerAccount types.String `tfsdk:"new_issuer_account"`
+	JWT              types.String `tfsdk:"jwt"`
+	ClaimType        types.String `tfsdk:"type"`
+	Subject          types.String `tfsdk:"subject"`
+	OldIssuer        types.String `tfsdk:"old_issuer"`
+	NewIssuer        types.String `tfsdk:"new_issuer"`
+	IssuerAccount    types.String `tfsdk:"issuer_account"`
+}
+
+// resignSignerTypes are the key types of the seeds that sign the claim types
+// that can be signed again.
+var resignSignerTypes = map[string]string{
+	jwt.AccountClaim:    "operator",
+	jwt.UserClaim:       "account",
+	jwt.ActivationClaim: "account",
+}
+
+func (r *JWTResign) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_jwt_resign"
+}
+
+func (r *JWTResign) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Signs an existing account, user or activation JWT again with another key, e.g. a new signing key during a key rotation, keeping its claims. Only the issuer, the issuer account of user and activation JWTs, and the `iat` and `jti` claims that change whenever a JWT is encoded differ from `source_jwt`. The JWT is kept in state and only signed again when `source_jwt`, `new_issuer_account` or the signing key changes.",
+
+		Attributes: map[string]schema.Attribute{
+			"source_jwt": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The JWT to sign again. Its signature must verify against its issuer. Changing it to a JWT of another subject replaces the resource",
+			},
+			"signing_seed": schema.StringAttribute{
+				Required:            true,
+				WriteOnly:           true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the new issuer, an operator nkey or one of its signing keys for account JWTs, or an account nkey or one of its signing keys for user and activation JWTs. Switching to another key signs the JWT again." + signingSeedDescription,
+				Validators: []validator.String{
+					isSeedFor(keyRoleSigning),
+				},
+			},
+			"new_issuer_account": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Public key of the account of a user or activation JWT, named as its issuer account when `signing_seed` is one of the signing keys of the account. Defaults to the issuer account of `source_jwt`, or to its issuer when that is the account nkey. Not allowed for account JWTs",
+				Validators: []validator.String{
+					isPublicKeyOfType("account"),
+				},
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The JWT signed again",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Claim type of the JWT, either `account`, `user` or `activation`",
+			},
+			"subject": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Subject of the JWT, which never changes",
+			},
+			"old_issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the nkey that signed `source_jwt`",
+			},
+			"new_issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the nkey of `signing_seed` that signed `jwt`",
+			},
+			"issuer_account": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the account named as the issuer account of `jwt`, or null when it names none, e.g. for account JWTs or when the account nkey signed it",
+			},
+		},
+	}
+}
+
+func (r *JWTResign) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data JWTResignModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.SourceJWT.IsUnknown() {
+		return
+	}
+
+	_, diags := data.checkSource()
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *JWTResign) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	if req.Plan.Raw.IsNull() {
+		// The resource is being destroyed
+		return
+	}
+
+	var plan JWTResignModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("signing_seed"), &plan.SigningSeed)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The signing seed is write-only, so a new signer only shows in the plan
+	// through the issuers derived from it
+	resp.Diagnostics.Append(plan.planIssuers()...)
+	plan.SigningSeed = types.StringNull()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !req.State.Raw.IsNull() {
+		var state JWTResignModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !plan.Subject.IsUnknown() && !plan.Subject.Equal(state.Subject) {
+			// A JWT of another subject is not the same JWT signed again
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("subject"))
+		}
+		if !plan.SourceJWT.Equal(state.SourceJWT) || !plan.NewIssuer.Equal(state.NewIssuer) || !plan.IssuerAccount.Equal(state.IssuerAccount) {
+			plan.JWT = types.StringUnknown()
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+func (r *JWTResign) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data JWTResignModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.resign(ctx, req.Config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created JWT resign resource", map[string]interface{}{
+		"old_issuer": data.OldIssuer.ValueString(),
+		"new_issuer": data.NewIssuer.ValueString(),
+	})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *JWTResign) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data JWTResignModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The JWT never changes outside of Terraform, so the only thing to check
+	// is that the stored JWT still decodes to the stored subject.
+	_, claims, err := decodeClaims(data.JWT.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted JWT state", "The stored JWT could not be decoded: "+err.Error()+".")
+		return
+	}
+	if claims.Subject != data.Subject.ValueString() {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted JWT state", "The stored JWT is issued to "+claims.Subject+" rather than the stored subject.")
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *JWTResign) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	// The JWT is signed again in place when ModifyPlan planned it as
+	// unknown, i.e. when the source JWT or the signer changed.
+	var plan JWTResignModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.JWT.IsUnknown() {
+		resp.Diagnostics.Append(plan.resign(ctx, req.Config)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *JWTResign) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed. Neither JWT is
+	// revoked.
+	tflog.Trace(ctx, "deleted JWT resign resource")
+}
+
+// checkSource decodes source_jwt without verifying it, and checks that it can
+// be signed again with the signing seed and new_issuer_account when they are
+// known.
+func (m *JWTResignModel) checkSource() (*decodedClaims, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	_, claims, err := decodeClaims(m.SourceJWT.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("source_jwt"), "malformed JWT", "The source_jwt is not a NATS JWT: "+err.Error()+".")
+		return nil, diags
+	}
+	signerType, ok := resignSignerTypes[claims.claimType()]
+	if !ok {
+		diags.AddAttributeError(path.Root("source_jwt"), "unsupported claim type", "The source_jwt is a JWT of type "+claims.claimType()+", but only account, user and activation JWTs can be signed again.")
+		return nil, diags
+	}
+	if claims.claimType() == jwt.AccountClaim && !m.NewIssuerAccount.IsNull() {
+		diags.AddAttributeError(path.Root("new_issuer_account"), "issuer account not allowed", "The source_jwt is an account JWT, which names no issuer account.")
+	}
+	if m.SigningSeed.IsUnknown() || m.SigningSeed.IsNull() {
+		return claims, diags
+	}
+	if _, keyType, err := publicKeyFromSeed([]byte(m.SigningSeed.ValueString())); err == nil && keyType != signerType {
+		diags.AddAttributeError(path.Root("signing_seed"), "invalid signing seed", "The signing seed is of type "+keyType+", but "+claims.claimType()+" JWTs are signed by "+signerType+" nkeys.")
+	}
+	return claims, diags
+}
+
+// planIssuers plans the attributes derived from source_jwt and the signing
+// seed read from config, leaving those unknown that depend on unknown values.
+func (m *JWTResignModel) planIssuers() diag.Diagnostics {
+	m.ClaimType = types.StringUnknown()
+	m.Subject = types.StringUnknown()
+	m.OldIssuer = types.StringUnknown()
+	m.NewIssuer = types.StringUnknown()
+	m.IssuerAccount = types.StringUnknown()
+	if m.SourceJWT.IsUnknown() {
+		return nil
+	}
+
+	claims, diags := m.checkSource()
+	if diags.HasError() {
+		return diags
+	}
+	m.ClaimType = types.StringValue(claims.claimType())
+	m.Subject = types.StringValue(claims.Subject)
+	m.OldIssuer = types.StringValue(claims.Issuer)
+
+	if m.SigningSeed.IsUnknown() || m.NewIssuerAccount.IsUnknown() {
+		return diags
+	}
+	pubKey, _, err := publicKeyFromSeed([]byte(m.SigningSeed.ValueString()))
+	if err != nil {
+		// Left for the attribute validator of signing_seed
+		return diags
+	}
+	m.NewIssuer = types.StringValue(pubKey)
+	m.IssuerAccount = m.issuerAccount(claims)
+	return diags
+}
+
+// issuerAccount returns the issuer account of the JWT signed again: the
+// account of new_issuer_account, of source_jwt or its issuer, unless the
+// signing seed is the account nkey itself.
+func (m *JWTResignModel) issuerAccount(claims *decodedClaims) types.String {
+	if claims.claimType() == jwt.AccountClaim {
+		return types.StringNull()
+	}
+	account := m.NewIssuerAccount.ValueString()
+	if account == "" {
+		account = claims.Nats.IssuerAccount
+	}
+	if account == "" {
+		account = claims.Issuer
+	}
+	if account := issuerAccount(m.SigningSeed.ValueString(), account); account != "" {
+		return types.StringValue(account)
+	}
+	return types.StringNull()
+}
+
+// resign decodes source_jwt with its claim type and encodes it again, signed
+// with the write-only signing seed read from config.
+func (m *JWTResignModel) resign(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	diags := config.GetAttribute(ctx, path.Root("signing_seed"), &m.SigningSeed)
+	if diags.HasError() {
+		return diags
+	}
+	defer func() { m.SigningSeed = types.StringNull() }()
+
+	// The checks in ValidateConfig only see values known at plan time
+	decoded, checkDiags := m.checkSource()
+	diags.Append(checkDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	// Decoding verifies the signature against the issuer of the JWT
+	claims, err := jwt.Decode(m.SourceJWT.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("source_jwt"), "invalid JWT signature", "The source_jwt does not verify against its issuer "+decoded.Issuer+": "+err.Error()+".")
+		return diags
+	}
+	issuerAccount := m.issuerAccount(decoded)
+	switch c := claims.(type) {
+	case *jwt.UserClaims:
+		c.IssuerAccount = issuerAccount.ValueString()
+	case *jwt.ActivationClaims:
+		c.IssuerAccount = issuerAccount.ValueString()
+	}
+
+	keys, err := signingKeys(m.SigningSeed.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("signing_seed"), "invalid signing seed", err.Error())
+		return diags
+	}
+	defer keys.Wipe()
+
+	token, encodeDiags := encodeJWT(claims, keys)
+	diags.Append(encodeDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	if claims.Claims().Subject != decoded.Subject {
+		diags.AddAttributeError(path.Root("jwt"), "subject changed", "The JWT signed again is issued to "+claims.Claims().Subject+" rather than the subject of source_jwt, "+decoded.Subject+".")
+		return diags
+	}
+
+	m.JWT = types.StringValue(token)
+	m.ClaimType = types.StringValue(decoded.claimType())
+	m.Subject = types.StringValue(decoded.Subject)
+	m.OldIssuer = types.StringValue(decoded.Issuer)
+	m.NewIssuer = types.StringValue(claims.Claims().Issuer)
+	m.IssuerAccount = issuerAccount
+	return diags
+}
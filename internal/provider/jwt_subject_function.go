@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &JWTSubjectFunction{}
+
+func NewJWTSubjectFunction() function.Function {
+	return &JWTSubjectFunction{}
+}
+
+// JWTSubjectFunction defines the function implementation.
+type JWTSubjectFunction struct {
+}
+
+func (f *JWTSubjectFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "jwt_subject"
+}
+
+func (f *JWTSubjectFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Extract the subject of a NATS JWT",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Returns the subject of `jwt`, the public key it is issued to, e.g. the account public key of an account JWT to key `for_each` by or to build a resolver path. The claims are decoded without verifying the signature, use the `nkey_jwt_validate` data source for that. Every NATS claim type is accepted, and a structurally invalid JWT fails the call.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "jwt",
+				MarkdownDescription: "The encoded JWT",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *JWTSubjectFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var token string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &token))
+	if resp.Error != nil {
+		return
+	}
+
+	_, claims, err := decodeClaims(token)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, claims.Subject))
+}
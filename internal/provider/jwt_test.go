@@ -0,0 +1,237 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/nats-io/jwt/v2"
+)
+
+func TestJWTStableIssuance(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "alice.creds")
+	config := `
+resource "nkey_keypair" "operator" {
+  type = "operator"
+}
+
+resource "nkey_keypair" "account" {
+  type = "account"
+}
+
+resource "nkey_keypair" "user" {
+  type = "user"
+}
+
+resource "nkey_operator_jwt" "test" {
+  subject      = nkey_keypair.operator.public_key
+  signing_seed = nkey_keypair.operator.seed
+  name         = "test"
+  tags         = ["env:prod", "team:a"]
+}
+
+resource "nkey_account_jwt" "test" {
+  subject      = nkey_keypair.account.public_key
+  signing_seed = nkey_keypair.operator.seed
+  name         = "test"
+  operator_jwt = nkey_operator_jwt.test.jwt
+  limits       = { max_connections = 10 }
+}
+
+resource "nkey_user_jwt" "test" {
+  subject      = nkey_keypair.user.public_key
+  signing_seed = nkey_keypair.account.seed
+  name         = "alice"
+  account_jwt  = nkey_account_jwt.test.jwt
+  permissions  = { publish = { allow = ["orders.>"] } }
+}
+
+resource "nkey_creds_file" "test" {
+  path = "` + filepath.ToSlash(name) + `"
+  jwt  = nkey_user_jwt.test.jwt
+  seed = nkey_keypair.user.seed
+}
+
+output "tokens" {
+  value = [nkey_operator_jwt.test.jwt, nkey_account_jwt.test.jwt, nkey_user_jwt.test.jwt]
+}
+`
+	addresses := []string{"nkey_operator_jwt.test", "nkey_account_jwt.test", "nkey_user_jwt.test"}
+	tokens := map[string]string{}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			{
+				Config: config,
+				Check: func(t *testing.T, state *testState) {
+					for _, address := range addresses {
+						tokens[address] = state.stringAttribute(t, address, "jwt")
+					}
+				},
+			},
+			// Applying the same configuration again, a second later than
+			// the JWTs were issued at, plans nothing at all, so neither the
+			// tokens nor anything referencing them change
+			{
+				PreConfig: func(t *testing.T) { time.Sleep(time.Second) },
+				Config:    config,
+				PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+					expectEmptyPlan(t, plan)
+				},
+				Check: func(t *testing.T, state *testState) {
+					for _, address := range addresses {
+						if got := state.stringAttribute(t, address, "jwt"); got != tokens[address] {
+							t.Errorf("the JWT of %s was issued again", address)
+						}
+					}
+				},
+			},
+		},
+	})
+}
+
+func TestJWTSetOrder(t *testing.T) {
+	// config returns two of each JWT with the same claims, whose sets are
+	// written in the order of a for "a" and of b for "b", "forward" or
+	// "reverse"
+	config := func(a, b string) string {
+		// sets renders the sets in order
+		sets := func(order string) map[string]string {
+			set := func(values ...string) string {
+				if order == "reverse" {
+					slices.Reverse(values)
+				}
+				return "[" + strings.Join(values, ", ") + "]"
+			}
+			return map[string]string{
+				"operator_tags":    set(`"env:prod"`, `"team:a"`, `"region:eu"`),
+				"operator_keys":    set("nkey_keypair.operator_signing_1.public_key", "nkey_keypair.operator_signing_2.public_key"),
+				"operator_urls":    set(`"tls://b.example.com:4222"`, `"tls://a.example.com:4222"`),
+				"account_tags":     set(`"team:a"`, `"env:prod"`),
+				"account_keys":     set(`"`+testAccountSigningPublicKey+`"`, `"`+testAccountScopedPublicKey+`"`),
+				"exports":          set(`{ name = "orders", subject = "orders.>", type = "stream" }`, `{ name = "charge", subject = "billing.charge", type = "service" }`),
+				"publish_allow":    set(`"orders.>"`, `"billing.*.new"`, `"_INBOX.>"`),
+				"subscribe_deny":   set(`"secret.>"`, `"admin.>"`),
+				"connection_types": set(`"WEBSOCKET"`, `"STANDARD"`),
+				"source_networks":  set(`"192.168.0.0/16"`, `"10.0.0.1/32"`, `"2001:db8::/32"`),
+				"user_tags":        set(`"role:b"`, `"role:a"`),
+				"usage_windows":    set(`{ start = "18:00:00", end = "20:00:00" }`, `{ start = "08:00:00", end = "12:00:00" }`),
+			}
+		}
+		var hcl strings.Builder
+		hcl.WriteString(`
+resource "nkey_keypair" "operator_signing_1" {
+  type = "operator"
+}
+
+resource "nkey_keypair" "operator_signing_2" {
+  type = "operator"
+}
+`)
+		for name, order := range map[string]string{"a": a, "b": b} {
+			sets := sets(order)
+			hcl.WriteString(`
+resource "nkey_operator_jwt" "` + name + `" {
+  subject               = "` + testOperatorPublicKey + `"
+  signing_seed          = "` + testOperatorSeed + `"
+  name                  = "test"
+  tags                  = ` + sets["operator_tags"] + `
+  signing_keys          = ` + sets["operator_keys"] + `
+  operator_service_urls = ` + sets["operator_urls"] + `
+}
+
+resource "nkey_account_jwt" "` + name + `" {
+  subject      = "` + testAccountPublicKey + `"
+  signing_seed = "` + testOperatorSeed + `"
+  name         = "test"
+  tags         = ` + sets["account_tags"] + `
+  signing_keys = ` + sets["account_keys"] + `
+  exports      = ` + sets["exports"] + `
+}
+
+resource "nkey_user_jwt" "` + name + `" {
+  subject      = "` + testUserPublicKey + `"
+  signing_seed = "` + testAccountSeed + `"
+  name         = "alice"
+  permissions = {
+    publish   = { allow = ` + sets["publish_allow"] + ` }
+    subscribe = { deny = ` + sets["subscribe_deny"] + ` }
+  }
+  allowed_connection_types = ` + sets["connection_types"] + `
+  source_networks          = ` + sets["source_networks"] + `
+  tags                     = ` + sets["user_tags"] + `
+  usage_times = {
+    windows = ` + sets["usage_windows"] + `
+  }
+}
+`)
+		}
+		return hcl.String()
+	}
+	// decoders decode the JWTs of each resource type
+	decoders := map[string]func(token string) (jwt.Claims, error){
+		"nkey_operator_jwt": func(token string) (jwt.Claims, error) { return jwt.DecodeOperatorClaims(token) },
+		"nkey_account_jwt":  func(token string) (jwt.Claims, error) { return jwt.DecodeAccountClaims(token) },
+		"nkey_user_jwt":     func(token string) (jwt.Claims, error) { return jwt.DecodeUserClaims(token) },
+	}
+	// payload returns the payload of the JWT of the resource at address
+	// without the time it was issued at and the ID, the hash of the rest
+	payload := func(t *testing.T, state *testState, address string) string {
+		claims, err := decoders[strings.Split(address, ".")[0]](state.stringAttribute(t, address, "jwt"))
+		if err != nil {
+			t.Fatalf("decoding the JWT of %s: %v", address, err)
+		}
+		claims.Claims().IssuedAt = 0
+		claims.Claims().ID = ""
+		encoded, err := json.Marshal(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(encoded)
+	}
+	tokens := map[string]string{}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			// Sets written in either order are encoded in the same order, so
+			// the tokens only differ in when they were issued
+			{
+				Config: config("forward", "reverse"),
+				Check: func(t *testing.T, state *testState) {
+					for resourceType := range decoders {
+						a, b := payload(t, state, resourceType+".a"), payload(t, state, resourceType+".b")
+						if a != b {
+							t.Errorf("the JWTs of %s differ by the order of their sets:\n%s\n%s", resourceType, a, b)
+						}
+					}
+					for resourceType := range decoders {
+						for _, name := range []string{"a", "b"} {
+							tokens[resourceType+"."+name] = state.stringAttribute(t, resourceType+"."+name, "jwt")
+						}
+					}
+				},
+			},
+			// And reordering them plans nothing and keeps the very same tokens
+			{
+				Config: config("reverse", "forward"),
+				PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+					expectEmptyPlan(t, plan)
+				},
+				Check: func(t *testing.T, state *testState) {
+					for address, token := range tokens {
+						if got := state.stringAttribute(t, address, "jwt"); got != token {
+							t.Errorf("the JWT of %s changed", address)
+						}
+					}
+				},
+			},
+		},
+	})
+}
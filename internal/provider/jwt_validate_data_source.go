@@ -0,0 +1,259 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &JWTValidateDataSource{}
+var _ datasource.DataSourceWithConfigValidators = &JWTValidateDataSource{}
+
+func NewJWTValidateDataSource() datasource.DataSource {
+	return &JWTValidateDataSource{}
+}
+
+// JWTValidateDataSource defines the data source implementation.
+type JWTValidateDataSource struct {
+}
+
+// JWTValidateDataSourceModel describes the data source data model.
+type JWTValidateDataSourceModel struct {
+	UserJWT            types.String `tfsdk:"user_jwt"`
+	AccountJWT         types.String `tfsdk:"account_jwt"`
+	OperatorJWT        types.String `tfsdk:"operator_jwt"`
+	FailOnInvalid      types.Bool   `tfsdk:"fail_on_invalid"`
+	Valid              types.Bool   `tfsdk:"valid"`
+	SignaturesValid    types.Bool   `tfsdk:"signatures_valid"`
+	TimesValid         types.Bool   `tfsdk:"times_valid"`
+	UserIssuerValid    types.Bool   `tfsdk:"user_issuer_valid"`
+	IssuerAccountValid types.Bool   `tfsdk:"issuer_account_valid"`
+	UserNotRevoked     types.Bool   `tfsdk:"user_not_revoked"`
+	AccountIssuerValid types.Bool   `tfsdk:"account_issuer_valid"`
+	Problems           types.List   `tfsdk:"problems"`
+}
+
+func (d *JWTValidateDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_jwt_validate"
+}
+
+func (d *JWTValidateDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Validates the chain of trust from a user JWT to its account JWT and from the account JWT to its operator JWT the way nats-server does, so a plan fails before a configuration that nats-server rejects is deployed. Any part of the chain may be left out for a partial check, and the checks that need it are null.",
+
+		Attributes: map[string]schema.Attribute{
+			"user_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The user JWT, checked against `account_jwt`",
+			},
+			"account_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The account JWT, checked against `operator_jwt`",
+			},
+			"operator_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The operator JWT",
+			},
+			"fail_on_invalid": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Fail with an error listing the problems when the chain is not valid. When false the outcome is only reported in `valid`, the checks and `problems`. Defaults to true",
+			},
+			"valid": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether every check that applies passes",
+			},
+			"signatures_valid": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the JWTs are of their claim type and signed by their issuer. The other checks only apply to the JWTs that are",
+			},
+			"times_valid": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the JWTs are neither expired nor before their `not_before` at the current time",
+			},
+			"user_issuer_valid": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the user JWT is signed by the account nkey or one of its signing keys, or null without the user or account JWT",
+			},
+			"issuer_account_valid": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the user JWT names the account as its issuer account when a signing key of the account signed it, and names no other account, or null without the user or account JWT",
+			},
+			"user_not_revoked": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the account JWT does not revoke the user JWT, or null without the user or account JWT",
+			},
+			"account_issuer_valid": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the account JWT is signed by the operator nkey or one of its signing keys, as strict signing key usage allows, or null without the account or operator JWT",
+			},
+			"problems": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Why the failed checks failed, empty when the chain is valid",
+			},
+		},
+	}
+}
+
+func (d *JWTValidateDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.AtLeastOneOf(
+			path.MatchRoot("user_jwt"),
+			path.MatchRoot("account_jwt"),
+			path.MatchRoot("operator_jwt"),
+		),
+	}
+}
+
+// chainCheck collects the outcomes of the checks of a chain of JWTs.
+type chainCheck struct {
+	problems []string
+}
+
+// fail records the problem of a failed check, returning its outcome.
+func (c *chainCheck) fail(problem string) types.Bool {
+	c.problems = append(c.problems, problem)
+	return types.BoolValue(false)
+}
+
+// checkTimes checks that the claims of a JWT are valid at now.
+func (c *chainCheck) checkTimes(name string, claims *jwt.ClaimsData, now time.Time) bool {
+	switch {
+	case claims.Expires != 0 && now.Unix() > claims.Expires:
+		c.problems = append(c.problems, "The "+name+" JWT expired at "+time.Unix(claims.Expires, 0).UTC().Format(time.RFC3339)+".")
+		return false
+	case claims.NotBefore != 0 && now.Unix() < claims.NotBefore:
+		c.problems = append(c.problems, "The "+name+" JWT is not valid before "+time.Unix(claims.NotBefore, 0).UTC().Format(time.RFC3339)+".")
+		return false
+	}
+	return true
+}
+
+func (d *JWTValidateDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data JWTValidateDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Decoding verifies the claim type and the signature against the issuer
+	var c chainCheck
+	var user *jwt.UserClaims
+	var account *jwt.AccountClaims
+	var operator *jwt.OperatorClaims
+	var times []*jwt.ClaimsData
+	var names []string
+	signatures := true
+	if !data.UserJWT.IsNull() {
+		var err error
+		if user, err = jwt.DecodeUserClaims(data.UserJWT.ValueString()); err != nil {
+			signatures = c.fail("The user_jwt is not a validly signed user JWT: " + err.Error() + ".").ValueBool()
+		} else {
+			times, names = append(times, &user.ClaimsData), append(names, "user")
+		}
+	}
+	if !data.AccountJWT.IsNull() {
+		var err error
+		if account, err = jwt.DecodeAccountClaims(data.AccountJWT.ValueString()); err != nil {
+			signatures = c.fail("The account_jwt is not a validly signed account JWT: " + err.Error() + ".").ValueBool()
+		} else {
+			times, names = append(times, &account.ClaimsData), append(names, "account")
+		}
+	}
+	if !data.OperatorJWT.IsNull() {
+		var err error
+		if operator, err = jwt.DecodeOperatorClaims(data.OperatorJWT.ValueString()); err != nil {
+			signatures = c.fail("The operator_jwt is not a validly signed operator JWT: " + err.Error() + ".").ValueBool()
+		} else {
+			times, names = append(times, &operator.ClaimsData), append(names, "operator")
+		}
+	}
+	data.SignaturesValid = types.BoolValue(signatures && (user != nil || account != nil || operator != nil))
+
+	now := time.Now()
+	timesValid := true
+	for i, claims := range times {
+		timesValid = c.checkTimes(names[i], claims, now) && timesValid
+	}
+	data.TimesValid = types.BoolNull()
+	if len(times) > 0 {
+		data.TimesValid = types.BoolValue(timesValid)
+	}
+
+	data.UserIssuerValid = types.BoolNull()
+	data.IssuerAccountValid = types.BoolNull()
+	data.UserNotRevoked = types.BoolNull()
+	if user != nil && account != nil {
+		data.UserIssuerValid = types.BoolValue(true)
+		if checkSigner(user.Issuer, account.Subject, account.SigningKeys.Keys(), false) != nil {
+			data.UserIssuerValid = c.fail("The user JWT is signed by " + user.Issuer + ", which is neither the account " + account.Subject + " nor one of its signing keys.")
+		}
+
+		data.IssuerAccountValid = types.BoolValue(true)
+		switch {
+		case user.IssuerAccount != "" && user.IssuerAccount != account.Subject:
+			data.IssuerAccountValid = c.fail("The user JWT names the issuer account " + user.IssuerAccount + " rather than the account " + account.Subject + ".")
+		case user.IssuerAccount == "" && user.Issuer != account.Subject:
+			data.IssuerAccountValid = c.fail("The user JWT is signed by " + user.Issuer + " rather than the account nkey, but does not name the account " + account.Subject + " as its issuer account.")
+		}
+
+		data.UserNotRevoked = types.BoolValue(true)
+		if account.IsClaimRevoked(user) {
+			data.UserNotRevoked = c.fail("The account JWT revokes the user " + user.Subject + " for JWTs issued at " + time.Unix(user.IssuedAt, 0).UTC().Format(time.RFC3339) + ".")
+		}
+	}
+
+	data.AccountIssuerValid = types.BoolNull()
+	if account != nil && operator != nil {
+		data.AccountIssuerValid = types.BoolValue(true)
+		switch {
+		case account.Issuer == operator.Subject && operator.StrictSigningKeyUsage:
+			data.AccountIssuerValid = c.fail("The account JWT is signed by the operator nkey " + operator.Subject + ", but the operator sets strict signing key usage, so only its signing keys may sign accounts.")
+		case checkSigner(account.Issuer, operator.Subject, operator.SigningKeys, false) != nil:
+			data.AccountIssuerValid = c.fail("The account JWT is signed by " + account.Issuer + ", which is neither the operator " + operator.Subject + " nor one of its signing keys.")
+		}
+	}
+
+	data.Valid = types.BoolValue(data.SignaturesValid.ValueBool() && len(c.problems) == 0)
+	problems := c.problems
+	if problems == nil {
+		problems = []string{}
+	}
+	var diags diag.Diagnostics
+	data.Problems, diags = types.ListValueFrom(ctx, types.StringType, problems)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Valid.ValueBool() && (data.FailOnInvalid.IsNull() || data.FailOnInvalid.ValueBool()) {
+		resp.Diagnostics.AddError("invalid JWT chain", "nats-server would reject the JWT chain:\n\n- "+strings.Join(problems, "\n- "))
+		return
+	}
+	tflog.Trace(ctx, "read JWT validate data source", map[string]interface{}{
+		"valid": data.Valid.ValueBool(),
+	})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &KeyTypeFunction{}
+
+func NewKeyTypeFunction() function.Function {
+	return &KeyTypeFunction{}
+}
+
+// KeyTypeFunction defines the function implementation.
+type KeyTypeFunction struct {
+}
+
+func (f *KeyTypeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "key_type"
+}
+
+func (f *KeyTypeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Report the type of an nkey public key, seed or private key",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Returns the type of `key`, decoded from its prefix byte after checking its encoding and checksum, so strings that merely start with the right letter are an error. The result is one of:\n\n" +
+			"- `user`, `account`, `server`, `cluster`, `operator` or `curve` for public keys\n" +
+			"- `seed:<type>` for seeds, e.g. `seed:account`\n" +
+			"- `private` for private keys, which carry no type\n\n" +
+			"Anything else is an error, so wrap the call in `try()` to branch on invalid input.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "key",
+				MarkdownDescription: "Public key, seed or private key",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *KeyTypeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var key string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &key))
+	if resp.Error != nil {
+		return
+	}
+
+	parsed, err := parseKey(key)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	keyType := parsed.keyType
+	switch parsed.kind {
+	case keyKindSeed:
+		keyType = "seed:" + parsed.keyType
+	case keyKindPrivate:
+		keyType = keyKindPrivate
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, keyType))
+}
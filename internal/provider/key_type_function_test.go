@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestKeyTypeFunction(t *testing.T) {
+	var definitionResp function.DefinitionResponse
+	(&KeyTypeFunction{}).Definition(context.Background(), function.DefinitionRequest{}, &definitionResp)
+	description := definitionResp.Definition.MarkdownDescription
+
+	keyType := func(t *testing.T, key string) (string, *function.FuncError) {
+		t.Helper()
+
+		got, funcErr := runFunction(t, &KeyTypeFunction{}, types.StringValue(key))
+		if funcErr != nil {
+			return "", funcErr
+		}
+		return got.(types.String).ValueString(), nil
+	}
+
+	for _, keys := range createTestKeys(t) {
+		t.Run(keys.keyType, func(t *testing.T) {
+			for key, want := range map[string]string{
+				keys.publicKey:  keys.keyType,
+				keys.seed:       "seed:" + keys.keyType,
+				keys.privateKey: "private",
+			} {
+				got, funcErr := keyType(t, key)
+				if funcErr != nil || got != want {
+					t.Errorf("key_type(%q) = %q, %v, want %q", key, got, funcErr, want)
+				}
+			}
+			// The documentation lists every result module authors switch on
+			for _, want := range []string{"`" + keys.keyType + "`", "`seed:<type>`", "`private`"} {
+				if !strings.Contains(description, want) {
+					t.Errorf("the description does not document %s", want)
+				}
+			}
+		})
+	}
+
+	// Strings that only look like keys are errors rather than a type
+	for _, key := range []string{
+		"",
+		"not a key",
+		testUserPublicKey[:55] + "A",
+		testUserPublicKey[:55],
+		"U" + strings.Repeat("A", 55),
+		"SU" + strings.Repeat("A", 56),
+		strings.ToLower(testAccountPublicKey),
+	} {
+		if got, funcErr := keyType(t, key); funcErr == nil || funcErr.FunctionArgument == nil || *funcErr.FunctionArgument != 0 {
+			t.Errorf("key_type(%q) = %q, %v, want an error on the key", key, got, funcErr)
+		}
+	}
+}
@@ -0,0 +1,456 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Keypair{}
+var _ resource.ResourceWithConfigure = &Keypair{}
+var _ resource.ResourceWithModifyPlan = &Keypair{}
+var _ resource.ResourceWithImportState = &Keypair{}
+var _ resource.ResourceWithUpgradeState = &Keypair{}
+
+func NewKeypair() resource.Resource {
+	return &Keypair{}
+}
+
+// Keypair defines the resource implementation.
+type Keypair struct {
+	// defaultKeyType is the type generated when type is omitted.
+	defaultKeyType string
+}
+
+// KeypairModel describes the resource data model.
+type KeypairModel struct {
+	KeyModel
+	VanityModel
+	SeedEncryptionModel
+	Keepers       types.Map    `tfsdk:"keepers"`
+	SeedWO        types.String `tfsdk:"seed_wo"`
+	SeedWOVersion types.Int64  `tfsdk:"seed_wo_version"`
+}
+
+func (r *Keypair) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_keypair"
+}
+
+func (r *Keypair) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// Bump the version together with a new entry in UpgradeState whenever
+		// attributes are renamed or change meaning.
+		Version: 1,
+
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A keypair is an ed25519 key pair formatted for use with NATS. The key pair is generated once and kept in state, so it stays stable across applies.",
+
+		Attributes: keyResourceAttributes(seedEncryptionResourceAttributes(vanityResourceAttributes(map[string]schema.Attribute{
+			"type": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The type of nkey to generate. Must be one of user|account|server|cluster|operator|curve. Defaults to the type of `seed_wo` when given, otherwise to the provider `default_key_type` or account",
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive(keyTypes...),
+				},
+				PlanModifiers: []planmodifier.String{
+					caseInsensitive(),
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"seed_wo": schema.StringAttribute{
+				Optional:  true,
+				WriteOnly: true,
+				Sensitive: true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("vanity_prefix")),
+				},
+				MarkdownDescription: "Seed of the nkey, usually taken from the ephemeral `nkey_nkey` resource. When set, only the public parts of the key are stored in state and `private_key`, `seed` and the other sensitive attributes stay null. The value is only read when the resource is created, so bump `seed_wo_version` to regenerate the key. Requires Terraform 1.11 or later",
+			},
+			"seed_wo_version": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Version marker for `seed_wo`. Changing it replaces the resource with the key derived from the current `seed_wo`, so it acts as the trigger for minting a fresh seed",
+				Validators: []validator.Int64{
+					int64validator.AlsoRequires(path.MatchRoot("seed_wo")),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"keepers": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Arbitrary map of values that, when changed, will trigger recreation of the nkey. Adding, removing or changing any entry generates a new key pair",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+		}))),
+	}
+}
+
+func (r *Keypair) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.defaultKeyType = configuredDefaultKeyType(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *Keypair) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	resp.Diagnostics.Append(planIncludePrivateKey(ctx, req.State, &resp.Plan)...)
+	resp.Diagnostics.Append(planFingerprintLength(ctx, req.State, &resp.Plan)...)
+	resp.Diagnostics.Append(planSeedEncryption(ctx, req.State, &resp.Plan)...)
+
+	// The type of a given seed is detected when the resource is created
+	var seed types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("seed_wo"), &seed)...)
+	if resp.Diagnostics.HasError() || !seed.IsNull() {
+		return
+	}
+
+	resp.Diagnostics.Append(planDefaultKeyType(ctx, req.Config, &resp.Plan, r.defaultKeyType)...)
+}
+
+func (r *Keypair) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data KeypairModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Write-only values are only available in the configuration
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("seed_wo"), &data.SeedWO)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.SeedWO.IsNull() {
+		if data.KeyType.IsUnknown() {
+			data.KeyType = types.StringValue(r.defaultKeyType)
+		}
+		var err error
+		if data.hasVanityPrefix() {
+			err = data.generateVanityKeys(ctx, &data.KeyModel)
+		} else {
+			err = data.generateKeys()
+		}
+		if err != nil {
+			addKeyError(&resp.Diagnostics, err)
+			return
+		}
+	} else {
+		configuredType := data.KeyType
+		if err := data.setKeysFromSeed([]byte(data.SeedWO.ValueString())); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("seed_wo"), "invalid seed", "The seed could not be decoded: "+err.Error())
+			return
+		}
+		if !configuredType.IsUnknown() && !strings.EqualFold(configuredType.ValueString(), data.KeyType.ValueString()) {
+			resp.Diagnostics.AddAttributeError(path.Root("type"), "seed type mismatch", "The seed is of type "+data.KeyType.ValueString()+" but type is set to "+configuredType.ValueString()+".")
+			return
+		}
+		if !configuredType.IsUnknown() {
+			data.KeyType = configuredType
+		}
+	}
+	resp.Diagnostics.Append(data.encryptSeed(ctx, req.Config, data.Seed)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !data.SeedWO.IsNull() {
+		// Only the public key is kept in state
+		data.clearSecrets()
+		data.SeedWO = types.StringNull()
+	}
+	resp.Diagnostics.Append(data.warnings()...)
+	tflog.Trace(ctx, "created keypair resource", data.logFields())
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Keypair) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data KeypairModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A type configured in another case is stored as configured when the
+	// resource is created, so store the canonical form from now on
+	data.KeyType = canonicalKeyType(data.KeyType)
+
+	// The key material never changes outside of Terraform, so the only thing
+	// to check is that the stored seed still derives the stored keys.
+	if data.Seed.IsNull() {
+		// The seed was given write-only and there is nothing to check against
+		if data.IncludePrivateKey.IsNull() {
+			data.IncludePrivateKey = types.BoolValue(true)
+		}
+		if err := data.setPublicAttributes(); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("public_key"), "corrupted keypair state", err.Error())
+			return
+		}
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+	keys, err := nkeys.FromSeed([]byte(data.Seed.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "corrupted keypair state", "The stored seed could not be decoded: "+err.Error())
+		return
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "corrupted keypair state", "The public key could not be derived from the stored seed: "+err.Error())
+		return
+	}
+	if pubKey != data.PublicKey.ValueString() {
+		resp.Diagnostics.AddAttributeError(path.Root("public_key"), "corrupted keypair state", "The stored public key does not match the public key derived from the stored seed ("+pubKey+").")
+		return
+	}
+
+	// Fill in attributes derived from the seed that older states lack
+	if err := data.setKeys(keys); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "corrupted keypair state", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Keypair) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Every configurable attribute but include_private_key,
+	// fingerprint_length and encryption_passphrase_wo_version requires
+	// replacement, so the private key encodings, fingerprint and
+	// seed_encrypted are the only things to update in place.
+	var plan KeypairModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.Seed.IsNull() {
+		// The seed was given write-only and there is no private key to set
+		plan.clearPrivateKeys()
+		if err := plan.setPublicAttributes(); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("public_key"), "updating keypair", err.Error())
+			return
+		}
+	} else if err := plan.deriveKeys(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "updating keypair", err.Error())
+		return
+	}
+	if plan.SeedEncrypted.IsUnknown() {
+		resp.Diagnostics.Append(plan.encryptSeed(ctx, req.Config, plan.Seed)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *Keypair) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed
+	tflog.Trace(ctx, "deleted keypair resource")
+}
+
+func (r *Keypair) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// The import ID is the seed, everything else is derived from it
+	data := KeypairModel{
+		VanityModel: VanityModel{
+			VanityPrefix:  types.StringNull(),
+			VanityWorkers: types.Int64Null(),
+			VanityTimeout: types.StringNull(),
+		},
+		Keepers:       types.MapNull(types.StringType),
+		SeedWO:        types.StringNull(),
+		SeedWOVersion: types.Int64Null(),
+	}
+	if err := data.setKeysFromSeed([]byte(strings.TrimSpace(req.ID))); err != nil {
+		resp.Diagnostics.AddError("importing keypair", "The import ID must be an nkey seed: "+err.Error())
+		return
+	}
+	resp.Diagnostics.Append(data.warnings()...)
+	tflog.Trace(ctx, "imported keypair resource")
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Keypair) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema:   &keypairSchemaV0,
+			StateUpgrader: upgradeKeypairStateV0,
+		},
+	}
+}
+
+// KeypairModelV0 describes the resource data model of version 0.
+type KeypairModelV0 struct {
+	KeyType                       types.String `tfsdk:"type"`
+	PublicKey                     types.String `tfsdk:"public_key"`
+	PrivateKey                    types.String `tfsdk:"private_key"`
+	Seed                          types.String `tfsdk:"seed"`
+	PublicKeyRawBase64            types.String `tfsdk:"public_key_raw_base64"`
+	PublicKeyRawHex               types.String `tfsdk:"public_key_raw_hex"`
+	PrivateKeyRawBase64           types.String `tfsdk:"private_key_raw_base64"`
+	PublicKeyPEM                  types.String `tfsdk:"public_key_pem"`
+	PrivateKeyPEM                 types.String `tfsdk:"private_key_pem"`
+	PublicKeyOpenSSH              types.String `tfsdk:"public_key_openssh"`
+	PrivateKeyOpenSSH             types.String `tfsdk:"private_key_openssh"`
+	PublicKeyJWK                  types.String `tfsdk:"public_key_jwk"`
+	PrivateKeyJWK                 types.String `tfsdk:"private_key_jwk"`
+	IncludePrivateKey             types.Bool   `tfsdk:"include_private_key"`
+	Fingerprint                   types.String `tfsdk:"fingerprint"`
+	FingerprintShort              types.String `tfsdk:"fingerprint_short"`
+	FingerprintLength             types.Int64  `tfsdk:"fingerprint_length"`
+	VanityPrefix                  types.String `tfsdk:"vanity_prefix"`
+	VanityWorkers                 types.Int64  `tfsdk:"vanity_workers"`
+	VanityTimeout                 types.String `tfsdk:"vanity_timeout"`
+	EncryptionPassphraseWO        types.String `tfsdk:"encryption_passphrase_wo"`
+	EncryptionPassphraseWOVersion types.Int64  `tfsdk:"encryption_passphrase_wo_version"`
+	SeedEncrypted                 types.String `tfsdk:"seed_encrypted"`
+	Keepers                       types.Map    `tfsdk:"keepers"`
+	SeedWO                        types.String `tfsdk:"seed_wo"`
+	SeedWOVersion                 types.Int64  `tfsdk:"seed_wo_version"`
+}
+
+// keypairSchemaV0 is the schema of version 0, frozen so that later changes to
+// the current schema cannot change how version 0 states are read. It predates
+// is_curve.
+var keypairSchemaV0 = schema.Schema{
+	Version: 0,
+	Attributes: map[string]schema.Attribute{
+		"type":                             schema.StringAttribute{Optional: true, Computed: true},
+		"public_key":                       schema.StringAttribute{Computed: true},
+		"private_key":                      schema.StringAttribute{Computed: true, Sensitive: true},
+		"seed":                             schema.StringAttribute{Computed: true, Sensitive: true},
+		"public_key_raw_base64":            schema.StringAttribute{Computed: true},
+		"public_key_raw_hex":               schema.StringAttribute{Computed: true},
+		"private_key_raw_base64":           schema.StringAttribute{Computed: true, Sensitive: true},
+		"public_key_pem":                   schema.StringAttribute{Computed: true},
+		"private_key_pem":                  schema.StringAttribute{Computed: true, Sensitive: true},
+		"public_key_openssh":               schema.StringAttribute{Computed: true},
+		"private_key_openssh":              schema.StringAttribute{Computed: true, Sensitive: true},
+		"public_key_jwk":                   schema.StringAttribute{Computed: true},
+		"private_key_jwk":                  schema.StringAttribute{Computed: true, Sensitive: true},
+		"include_private_key":              schema.BoolAttribute{Optional: true, Computed: true},
+		"fingerprint":                      schema.StringAttribute{Computed: true},
+		"fingerprint_short":                schema.StringAttribute{Computed: true},
+		"fingerprint_length":               schema.Int64Attribute{Optional: true},
+		"vanity_prefix":                    schema.StringAttribute{Optional: true},
+		"vanity_workers":                   schema.Int64Attribute{Optional: true},
+		"vanity_timeout":                   schema.StringAttribute{Optional: true},
+		"encryption_passphrase_wo":         schema.StringAttribute{Optional: true, Sensitive: true, WriteOnly: true},
+		"encryption_passphrase_wo_version": schema.Int64Attribute{Optional: true},
+		"seed_encrypted":                   schema.StringAttribute{Computed: true},
+		"keepers":                          schema.MapAttribute{Optional: true, ElementType: types.StringType},
+		"seed_wo":                          schema.StringAttribute{Optional: true, Sensitive: true, WriteOnly: true},
+		"seed_wo_version":                  schema.Int64Attribute{Optional: true},
+	},
+}
+
+// upgradeKeypairStateV0 backfills the type of states written before the type
+// was normalized, and their is_curve. The type is taken from the prefix of the
+// seed, or of the public key when the seed was given write-only.
+func upgradeKeypairStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var prior KeypairModelV0
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data := KeypairModel{
+		KeyModel: KeyModel{
+			KeyType:             prior.KeyType,
+			PublicKey:           prior.PublicKey,
+			PrivateKey:          prior.PrivateKey,
+			Seed:                prior.Seed,
+			PublicKeyRawBase64:  prior.PublicKeyRawBase64,
+			PublicKeyRawHex:     prior.PublicKeyRawHex,
+			PrivateKeyRawBase64: prior.PrivateKeyRawBase64,
+			PublicKeyPEM:        prior.PublicKeyPEM,
+			PrivateKeyPEM:       prior.PrivateKeyPEM,
+			PublicKeyOpenSSH:    prior.PublicKeyOpenSSH,
+			PrivateKeyOpenSSH:   prior.PrivateKeyOpenSSH,
+			PublicKeyJWK:        prior.PublicKeyJWK,
+			PrivateKeyJWK:       prior.PrivateKeyJWK,
+			IncludePrivateKey:   prior.IncludePrivateKey,
+			Fingerprint:         prior.Fingerprint,
+			FingerprintShort:    prior.FingerprintShort,
+			FingerprintLength:   prior.FingerprintLength,
+			IsCurve:             types.BoolValue(nkeys.Prefix(prior.PublicKey.ValueString()) == nkeys.PrefixByteCurve),
+		},
+		VanityModel: VanityModel{
+			VanityPrefix:  prior.VanityPrefix,
+			VanityWorkers: prior.VanityWorkers,
+			VanityTimeout: prior.VanityTimeout,
+		},
+		SeedEncryptionModel: SeedEncryptionModel{
+			EncryptionPassphraseWO:        prior.EncryptionPassphraseWO,
+			EncryptionPassphraseWOVersion: prior.EncryptionPassphraseWOVersion,
+			SeedEncrypted:                 prior.SeedEncrypted,
+		},
+		Keepers:       prior.Keepers,
+		SeedWO:        prior.SeedWO,
+		SeedWOVersion: prior.SeedWOVersion,
+	}
+
+	if data.KeyType.IsNull() || data.KeyType.ValueString() == "" {
+		attr, key := path.Root("seed"), data.Seed
+		if key.IsNull() {
+			attr, key = path.Root("public_key"), data.PublicKey
+		}
+		parsed, err := parseKey(key.ValueString())
+		if err == nil && parsed.keyType == "" {
+			err = errUnsupportedKeyType
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(attr, "upgrading keypair state", "The type could not be derived from the stored key: "+err.Error())
+			return
+		}
+		data.KeyType = types.StringValue(parsed.keyType)
+	}
+	data.KeyType = canonicalKeyType(data.KeyType)
+	tflog.Trace(ctx, "upgraded keypair resource state", map[string]interface{}{"from_version": 0, "type": data.KeyType.ValueString()})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
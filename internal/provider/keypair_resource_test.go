@@ -0,0 +1,304 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/nats-io/nkeys"
+)
+
+func TestKeypairResource(t *testing.T) {
+	const config = `
+resource "nkey_keypair" "test" {
+  type = "user"
+}
+`
+	var publicKey, seed string
+	unitTest(t, testCase{
+		Steps: []testStep{
+			{
+				Config: config,
+				PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+					expectActions(t, plan, "nkey_keypair.test", tfjson.ActionCreate)
+				},
+				Check: func(t *testing.T, state *testState) {
+					publicKey = state.stringAttribute(t, "nkey_keypair.test", "public_key")
+					seed = state.stringAttribute(t, "nkey_keypair.test", "seed")
+					if !nkeys.IsValidPublicUserKey(publicKey) {
+						t.Errorf("public_key %s is not a user public key", publicKey)
+					}
+					keys, err := nkeys.FromSeed([]byte(seed))
+					if err != nil {
+						t.Fatalf("seed: %s", err)
+					}
+					if derived, _ := keys.PublicKey(); derived != publicKey {
+						t.Errorf("the seed derives %s rather than the public_key %s", derived, publicKey)
+					}
+				},
+			},
+			// Applying the same configuration again keeps the key
+			{
+				Config: config,
+				PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+					expectActions(t, plan, "nkey_keypair.test", tfjson.ActionNoop)
+				},
+				Check: func(t *testing.T, state *testState) {
+					if got := state.stringAttribute(t, "nkey_keypair.test", "public_key"); got != publicKey {
+						t.Errorf("public_key changed from %s to %s", publicKey, got)
+					}
+					if got := state.stringAttribute(t, "nkey_keypair.test", "seed"); got != seed {
+						t.Error("seed changed on the second apply")
+					}
+				},
+			},
+		},
+	})
+}
+
+func TestKeypairResourceSeedWriteOnly(t *testing.T) {
+	config := func(seed []byte, version int) string {
+		return `
+resource "nkey_keypair" "test" {
+  seed_wo         = "` + string(seed) + `"
+  seed_wo_version = ` + strconv.Itoa(version) + `
+}
+`
+	}
+	keys := make([]nkeys.KeyPair, 2)
+	for i := range keys {
+		var err error
+		if keys[i], err = nkeys.CreateUser(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// checkKey checks that only the public parts of keys are in state
+	checkKey := func(keys nkeys.KeyPair) func(t *testing.T, state *testState) {
+		return func(t *testing.T, state *testState) {
+			publicKey, _ := keys.PublicKey()
+			if got := state.stringAttribute(t, "nkey_keypair.test", "public_key"); got != publicKey {
+				t.Errorf("public_key = %s, want %s", got, publicKey)
+			}
+			for _, name := range []string{"seed", "seed_wo", "private_key", "private_key_raw_base64", "private_key_pem", "private_key_openssh", "private_key_jwk"} {
+				if value := state.attribute(t, "nkey_keypair.test", name); value != nil {
+					t.Errorf("%s is in state", name)
+				}
+			}
+
+			// No encoding of the seed bytes may be in the state file
+			if !bytes.Contains(state.Raw, []byte(publicKey)) {
+				t.Fatal("the state file does not contain the public key")
+			}
+			seed, _ := keys.Seed()
+			_, entropy, _ := nkeys.DecodeSeed(seed)
+			for name, secret := range map[string]string{
+				"seed":               string(seed),
+				"entropy base64":     base64.StdEncoding.EncodeToString(entropy),
+				"entropy hex":        hex.EncodeToString(entropy),
+				"private key base64": base64.StdEncoding.EncodeToString(ed25519.NewKeyFromSeed(entropy)),
+			} {
+				if bytes.Contains(state.Raw, []byte(secret)) {
+					t.Errorf("the state file contains the %s", name)
+				}
+			}
+		}
+	}
+	seeds := make([][]byte, len(keys))
+	for i, k := range keys {
+		seeds[i], _ = k.Seed()
+	}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			{
+				Config: config(seeds[0], 1),
+				Check:  checkKey(keys[0]),
+			},
+			// A new seed is only read when the version changes
+			{
+				Config: config(seeds[1], 1),
+				PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+					expectActions(t, plan, "nkey_keypair.test", tfjson.ActionNoop)
+				},
+				Check: checkKey(keys[0]),
+			},
+			{
+				Config: config(seeds[1], 2),
+				PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+					expectActions(t, plan, "nkey_keypair.test", tfjson.ActionDelete, tfjson.ActionCreate)
+				},
+				Check: checkKey(keys[1]),
+			},
+		},
+	})
+}
+
+func TestKeypairResourceIncludePrivateKey(t *testing.T) {
+	config := func(include bool) string {
+		return `
+resource "nkey_keypair" "test" {
+  type                = "user"
+  include_private_key = ` + strconv.FormatBool(include) + `
+}
+`
+	}
+	var publicKey string
+	// step toggles include_private_key in place, and checks that every
+	// private key encoding follows it while the key stays the same
+	step := func(include bool) testStep {
+		return testStep{
+			Config: config(include),
+			PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+				if publicKey == "" {
+					expectActions(t, plan, "nkey_keypair.test", tfjson.ActionCreate)
+				} else {
+					expectActions(t, plan, "nkey_keypair.test", tfjson.ActionUpdate)
+				}
+			},
+			Check: func(t *testing.T, state *testState) {
+				for _, name := range privateKeyAttributes {
+					if value := state.attribute(t, "nkey_keypair.test", name); (value != nil) != include {
+						t.Errorf("include_private_key = %v: %s is %v", include, name, value)
+					}
+				}
+				if state.attribute(t, "nkey_keypair.test", "seed") == nil {
+					t.Error("seed is null")
+				}
+				got := state.stringAttribute(t, "nkey_keypair.test", "public_key")
+				if publicKey != "" && got != publicKey {
+					t.Errorf("public_key changed from %s to %s", publicKey, got)
+				}
+				publicKey = got
+			},
+		}
+	}
+	unitTest(t, testCase{
+		Steps: []testStep{
+			step(true),
+			step(false),
+			step(true),
+		},
+	})
+}
+
+func TestKeypairResourceUpgradeStateV0(t *testing.T) {
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schemaResp resource.SchemaResponse
+	NewKeypair().Schema(context.Background(), resource.SchemaRequest{}, &schemaResp)
+	stateType := schemaResp.Schema.Type().TerraformType(context.Background())
+
+	// The keys of the derived key tests, written by version 0 of the schema
+	tests := []struct {
+		name      string
+		state     string
+		wantType  string
+		wantCurve bool
+		wantErr   string
+	}{
+		{
+			name: "type from the seed",
+			state: `{
+  "type": "",
+  "public_key": "UBQZX3JGNLK4AFEBVVLUJ4QVERLUES2PKJTHJGSIPVRK3ZXYNVFV7XAC",
+  "seed": "SUADZAA7BCUPJYTYHIYS5WCM74ONODEVTMLFVJKXXJDB2ZEHXXBQERYMPQ",
+  "include_private_key": false,
+  "fingerprint_length": null,
+  "keepers": {"rotation": "1"},
+  "seed_wo": null,
+  "seed_wo_version": null
+}`,
+			wantType: "user",
+		},
+		{
+			name: "type from the public key",
+			state: `{
+  "type": null,
+  "public_key": "ABQZX3JGNLK4AFEBVVLUJ4QVERLUES2PKJTHJGSIPVRK3ZXYNVFV6TT5",
+  "seed": null,
+  "seed_wo_version": 1
+}`,
+			wantType: "account",
+		},
+		{
+			name: "curve",
+			state: `{
+  "public_key": "XBNHJLFBPML4T6EDIOOZRPNCOZ6ARG5ACGJ2PUFBJHTJPKYURCQUVN2C",
+  "seed": null,
+  "seed_wo_version": 1
+}`,
+			wantType:  "curve",
+			wantCurve: true,
+		},
+		{
+			name: "configured type",
+			state: `{
+  "type": "User",
+  "public_key": "UBQZX3JGNLK4AFEBVVLUJ4QVERLUES2PKJTHJGSIPVRK3ZXYNVFV7XAC",
+  "seed": "SUADZAA7BCUPJYTYHIYS5WCM74ONODEVTMLFVJKXXJDB2ZEHXXBQERYMPQ"
+}`,
+			wantType: "user",
+		},
+		{
+			name: "invalid public key",
+			state: `{
+  "public_key": "UBQZX3JGNLK4AFEBVVLUJ4QVERLUES2PKJTHJGSIPVRK3ZXYNVFV7XAD",
+  "seed": null
+}`,
+			wantErr: "The type could not be derived from the stored key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.UpgradeResourceState(context.Background(), &tfprotov6.UpgradeResourceStateRequest{
+				TypeName: "nkey_keypair",
+				Version:  0,
+				RawState: &tfprotov6.RawState{JSON: []byte(tt.state)},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" {
+				if len(resp.Diagnostics) != 1 || !strings.Contains(resp.Diagnostics[0].Detail, tt.wantErr) {
+					t.Fatalf("UpgradeResourceState() diagnostics = %v, want %q", resp.Diagnostics, tt.wantErr)
+				}
+				return
+			}
+			for _, diag := range resp.Diagnostics {
+				t.Fatalf("UpgradeResourceState() diagnostic: %s: %s", diag.Summary, diag.Detail)
+			}
+
+			upgraded, err := resp.UpgradedState.Unmarshal(stateType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var attributes map[string]tftypes.Value
+			if err := upgraded.As(&attributes); err != nil {
+				t.Fatal(err)
+			}
+			var keyType string
+			var isCurve bool
+			if err := attributes["type"].As(&keyType); err != nil || keyType != tt.wantType {
+				t.Errorf("type = %s, want %s", attributes["type"], tt.wantType)
+			}
+			if err := attributes["is_curve"].As(&isCurve); err != nil || isCurve != tt.wantCurve {
+				t.Errorf("is_curve = %s, want %v", attributes["is_curve"], tt.wantCurve)
+			}
+		})
+	}
+}
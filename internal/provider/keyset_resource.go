@@ -0,0 +1,406 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Keyset{}
+var _ resource.ResourceWithConfigure = &Keyset{}
+var _ resource.ResourceWithModifyPlan = &Keyset{}
+
+func NewKeyset() resource.Resource {
+	return &Keyset{}
+}
+
+// Keyset defines the resource implementation.
+type Keyset struct {
+	// defaultKeyType is the type generated when type is omitted.
+	defaultKeyType string
+}
+
+// KeysetModel describes the resource data model.
+type KeysetModel struct {
+	KeyType    types.String `tfsdk:"type"`
+	CountKeys  types.Int64  `tfsdk:"count_keys"`
+	Names      types.Set    `tfsdk:"names"`
+	Keys       types.Map    `tfsdk:"keys"`
+	PublicKeys types.Map    `tfsdk:"public_keys"`
+
+	IncludePrivateKey types.Bool  `tfsdk:"include_private_key"`
+	Parallelism       types.Int64 `tfsdk:"parallelism"`
+}
+
+// KeysetKeyModel describes a single key of the keys attribute.
+type KeysetKeyModel struct {
+	PublicKey  types.String `tfsdk:"public_key"`
+	PrivateKey types.String `tfsdk:"private_key"`
+	Seed       types.String `tfsdk:"seed"`
+}
+
+// keysetKeyType is the object type of a single key of the keys attribute.
+var keysetKeyType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"public_key":  types.StringType,
+		"private_key": types.StringType,
+		"seed":        types.StringType,
+	},
+}
+
+func (r *Keyset) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_keyset"
+}
+
+func (r *Keyset) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A keyset generates many nkeys of the same type in a single resource. Keys are kept in state, adding a name only generates the new key and removing a name only drops that key.",
+
+		Attributes: map[string]schema.Attribute{
+			"type": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The type of nkeys to generate. Must be one of user|account|server|cluster|operator|curve. Defaults to the provider `default_key_type`, or account",
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive(keyTypes...),
+				},
+				PlanModifiers: []planmodifier.String{
+					caseInsensitive(),
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"count_keys": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Number of keys to generate, keyed by their index. Conflicts with `names`",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+					int64validator.ExactlyOneOf(path.MatchRoot("names")),
+				},
+			},
+			"names": schema.SetAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Names of the keys to generate. Conflicts with `count_keys`",
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"keys": schema.MapAttribute{
+				ElementType:         keysetKeyType,
+				Computed:            true,
+				MarkdownDescription: "Generated keys by index or name, each with a `public_key`, `private_key` and `seed`",
+				Sensitive:           true,
+			},
+			"include_private_key": schema.BoolAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+				MarkdownDescription: "Whether to set the `private_key` of the keys. The NATS clients only need the `seed`, so set this to false to keep the expanded private keys out of state and outputs. When false `private_key` is null, so any reference to it must be switched to `seed` first. Defaults to true",
+			},
+			"parallelism": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Number of goroutines generating keys. Defaults to the number of CPUs. Changing it does not regenerate any keys",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"public_keys": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Public keys of the generated keys by index or name",
+			},
+		},
+	}
+}
+
+func (r *Keyset) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.defaultKeyType = configuredDefaultKeyType(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *Keyset) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	resp.Diagnostics.Append(planDefaultKeyType(ctx, req.Config, &resp.Plan, r.defaultKeyType)...)
+	if resp.Diagnostics.HasError() || req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state KeysetModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The keys are marked unknown as soon as the configuration differs from
+	// state, even when the plan modifiers then planned the prior state, e.g.
+	// for a type that only changed in case, or when only parallelism changed.
+	// No keys are generated then.
+	if plan.KeyType.Equal(state.KeyType) && plan.CountKeys.Equal(state.CountKeys) && plan.Names.Equal(state.Names) && plan.IncludePrivateKey.Equal(state.IncludePrivateKey) {
+		plan.Keys = state.Keys
+		plan.PublicKeys = state.PublicKeys
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	}
+}
+
+func (r *Keyset) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data KeysetModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.generateKeys(ctx, nil)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created keyset resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Keyset) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data KeysetModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A type configured in another case is stored as configured when the
+	// resource is created, so store the canonical form from now on
+	data.KeyType = canonicalKeyType(data.KeyType)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Keyset) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var plan, state KeysetModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Keep the keys that are still wanted and only generate the new ones
+	prior := map[string]KeysetKeyModel{}
+	resp.Diagnostics.Append(state.Keys.ElementsAs(ctx, &prior, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(plan.generateKeys(ctx, prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "updated keyset resource")
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *Keyset) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed
+	tflog.Trace(ctx, "deleted keyset resource")
+}
+
+// keyNames returns the names of the configured keys.
+func (m *KeysetModel) keyNames(ctx context.Context) ([]string, diag.Diagnostics) {
+	var names []string
+
+	if !m.Names.IsNull() {
+		diags := m.Names.ElementsAs(ctx, &names, false)
+		return names, diags
+	}
+	for i := int64(0); i < m.CountKeys.ValueInt64(); i++ {
+		names = append(names, strconv.FormatInt(i, 10))
+	}
+	return names, nil
+}
+
+// generateKeys fills in the keys of the model, reusing the keys in prior and
+// generating the missing ones in parallel.
+func (m *KeysetModel) generateKeys(ctx context.Context, prior map[string]KeysetKeyModel) diag.Diagnostics {
+	names, diags := m.keyNames(ctx)
+	if diags.HasError() {
+		return diags
+	}
+
+	var missing []string
+	for _, name := range names {
+		if _, ok := prior[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	workers := runtime.GOMAXPROCS(0)
+	if !m.Parallelism.IsNull() {
+		workers = int(m.Parallelism.ValueInt64())
+	}
+	generated, err := generateKeysetKeys(ctx, m.KeyType.ValueString(), m.IncludePrivateKey.ValueBool(), missing, workers)
+	if err != nil {
+		addKeyError(&diags, err)
+		return diags
+	}
+
+	keys := make(map[string]KeysetKeyModel, len(names))
+	publicKeys := make(map[string]string, len(names))
+	for _, name := range names {
+		key, ok := prior[name]
+		if !ok {
+			key = generated[name]
+		} else if m.IncludePrivateKey.ValueBool() != !key.PrivateKey.IsNull() {
+			// include_private_key changed, set or clear the kept private key
+			derived := KeyModel{Seed: key.Seed, IncludePrivateKey: m.IncludePrivateKey}
+			if err := derived.deriveKeys(); err != nil {
+				diags.AddAttributeError(path.Root("keys").AtMapKey(name), "corrupted keyset state", err.Error())
+				return diags
+			}
+			key.PrivateKey = derived.PrivateKey
+		}
+		keys[name] = key
+		publicKeys[name] = key.PublicKey.ValueString()
+	}
+
+	var d diag.Diagnostics
+	m.Keys, d = types.MapValueFrom(ctx, keysetKeyType, keys)
+	diags.Append(d...)
+	m.PublicKeys, d = types.MapValueFrom(ctx, types.StringType, publicKeys)
+	diags.Append(d...)
+
+	return diags
+}
+
+// generateKeysetKeys generates a key of keyType for each of names on workers
+// goroutines. The keys are collected by name, so the result does not depend on
+// the order the workers finish in. Generation stops at the first error or when
+// ctx is done, e.g. when the apply is interrupted.
+func generateKeysetKeys(ctx context.Context, keyType string, includePrivateKey bool, names []string, workers int) (map[string]KeysetKeyModel, error) {
+	prefix, err := keyTypePrefix(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	failed := make(chan error, workers)
+	generated := make([]KeysetKeyModel, len(names))
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(workers, len(names)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				key, err := generateKeysetKey(prefix, includePrivateKey)
+				if err != nil {
+					failed <- err
+					cancel()
+					return
+				}
+				generated[j] = key
+			}
+		}()
+	}
+
+feed:
+	for j := range names {
+		select {
+		case jobs <- j:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	select {
+	case err := <-failed:
+		return nil, err
+	default:
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]KeysetKeyModel, len(names))
+	for j, name := range names {
+		keys[name] = generated[j]
+	}
+	return keys, nil
+}
+
+// generateKeysetKey generates a single key of a keyset. Only the encodings kept
+// by the keyset are computed.
+func generateKeysetKey(prefix nkeys.PrefixByte, includePrivateKey bool) (KeysetKeyModel, error) {
+	keys, err := nkeys.CreatePair(prefix)
+	if err != nil {
+		return KeysetKeyModel{}, err
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		return KeysetKeyModel{}, err
+	}
+	seed, err := keys.Seed()
+	if err != nil {
+		return KeysetKeyModel{}, err
+	}
+	defer wipe(seed)
+	privKey := types.StringNull()
+	if includePrivateKey {
+		raw, err := keys.PrivateKey()
+		if err != nil {
+			return KeysetKeyModel{}, err
+		}
+		privKey = types.StringValue(string(raw))
+		wipe(raw)
+	}
+
+	return KeysetKeyModel{
+		PublicKey:  types.StringValue(pubKey),
+		PrivateKey: privKey,
+		Seed:       types.StringValue(string(seed)),
+	}, nil
+}
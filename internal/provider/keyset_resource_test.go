@@ -0,0 +1,200 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/nats-io/nkeys"
+)
+
+func TestKeysetResource(t *testing.T) {
+	config := func(names ...string) string {
+		return `
+resource "nkey_keyset" "test" {
+  type  = "user"
+  names = ["` + strings.Join(names, `", "`) + `"]
+}
+`
+	}
+	publicKeys := map[string]interface{}{}
+	// check checks that the keyset holds a user key for each of names, and
+	// that the keys of kept are unchanged
+	check := func(names []string, kept ...string) func(t *testing.T, state *testState) {
+		return func(t *testing.T, state *testState) {
+			got, _ := state.attribute(t, "nkey_keyset.test", "public_keys").(map[string]interface{})
+			if len(got) != len(names) {
+				t.Fatalf("public_keys = %v, want keys %v", got, names)
+			}
+			for _, name := range names {
+				if publicKey, _ := got[name].(string); !nkeys.IsValidPublicUserKey(publicKey) {
+					t.Errorf("public_keys[%q] = %v, want a user public key", name, got[name])
+				}
+			}
+			for _, name := range kept {
+				if got[name] != publicKeys[name] {
+					t.Errorf("public_keys[%q] changed from %v to %v", name, publicKeys[name], got[name])
+				}
+			}
+			publicKeys = got
+		}
+	}
+	updated := func(t *testing.T, plan *tfjson.Plan) {
+		expectActions(t, plan, "nkey_keyset.test", tfjson.ActionUpdate)
+	}
+	unitTest(t, testCase{
+		Steps: []testStep{
+			{
+				Config: config("a", "b"),
+				Check:  check([]string{"a", "b"}),
+			},
+			// Adding a name only generates its key
+			{
+				Config:    config("a", "b", "c"),
+				PlanCheck: updated,
+				Check:     check([]string{"a", "b", "c"}, "a", "b"),
+			},
+			// Removing a name only drops its key
+			{
+				Config:    config("b", "c"),
+				PlanCheck: updated,
+				Check:     check([]string{"b", "c"}, "b", "c"),
+			},
+		},
+	})
+}
+
+func TestGenerateKeysetKeysThousand(t *testing.T) {
+	names := make([]string, 1000)
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+	}
+
+	start := time.Now()
+	keys, err := generateKeysetKeys(context.Background(), "user", true, names, 4)
+	if err != nil {
+		t.Fatalf("generateKeysetKeys() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("generating 1000 keys took %s", elapsed)
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, name := range names {
+		key, ok := keys[name]
+		if !ok {
+			t.Fatalf("no key was generated for %q", name)
+		}
+		publicKey := key.PublicKey.ValueString()
+		if !nkeys.IsValidPublicUserKey(publicKey) || seen[publicKey] {
+			t.Fatalf("key %q has the public key %s, which is invalid or generated twice", name, publicKey)
+		}
+		seen[publicKey] = true
+	}
+}
+
+func TestGenerateKeysetKeysConcurrent(t *testing.T) {
+	names := make([]string, 200)
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+	}
+
+	// Several keysets generated at once, as when Terraform applies them in
+	// parallel, each on more workers than there are names to share
+	results := make([]map[string]KeysetKeyModel, 4)
+	errs := make([]error, len(results))
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = generateKeysetKeys(context.Background(), "account", i%2 == 0, names, 16)
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, len(results)*len(names))
+	for i, keys := range results {
+		if errs[i] != nil {
+			t.Fatalf("generateKeysetKeys() error = %v", errs[i])
+		}
+		if len(keys) != len(names) {
+			t.Fatalf("generateKeysetKeys() returned %d keys, want %d", len(keys), len(names))
+		}
+		for name, key := range keys {
+			publicKey := key.PublicKey.ValueString()
+			if !nkeys.IsValidPublicAccountKey(publicKey) || seen[publicKey] {
+				t.Fatalf("key %q has the public key %s, which is invalid or generated twice", name, publicKey)
+			}
+			seen[publicKey] = true
+			if key.PrivateKey.IsNull() != (i%2 != 0) {
+				t.Errorf("key %q has the private key %s", name, key.PrivateKey)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := generateKeysetKeys(ctx, "account", true, names, 16); !errors.Is(err, context.Canceled) {
+		t.Errorf("generateKeysetKeys() of a canceled context error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func BenchmarkKeysetGenerate(b *testing.B) {
+	names := make([]string, 5000)
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+	}
+	for _, bm := range []struct {
+		name    string
+		workers int
+	}{
+		{name: "serial", workers: 1},
+		{name: "parallel", workers: runtime.GOMAXPROCS(0)},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := generateKeysetKeys(context.Background(), "user", false, names, bm.workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestKeysetResourceThousandKeys(t *testing.T) {
+	var start time.Time
+	unitTest(t, testCase{
+		Steps: []testStep{
+			{
+				PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+					start = time.Now()
+				},
+				Config: `
+resource "nkey_keyset" "test" {
+  type       = "user"
+  count_keys = 1000
+}
+`,
+				Check: func(t *testing.T, state *testState) {
+					if elapsed := time.Since(start); elapsed > time.Minute {
+						t.Errorf("applying 1000 keys took %s", elapsed)
+					}
+					got, _ := state.attribute(t, "nkey_keyset.test", "public_keys").(map[string]interface{})
+					if len(got) != 1000 || got["0"] == nil || got["999"] == nil {
+						t.Errorf("public_keys has %d keys, want 0 to 999", len(got))
+					}
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// keystoreDirMode and keystoreFileMode are the permissions nsc uses for its
+// keystore, so that the keys stay readable by their owner only.
+const (
+	keystoreDirMode  fs.FileMode = 0o700
+	keystoreFileMode fs.FileMode = 0o600
+)
+
+// defaultKeystoreDir is the keystore root nsc uses unless NKEYS_PATH is set.
+const defaultKeystoreDir = "~/.nkeys"
+
+// keystoreKeyPath returns the path of the seed file of publicKey relative to
+// the keystore root, in the nsc layout keys/<prefix>/<shard>/<public key>.nk
+// where shard is the two characters following the prefix.
+func keystoreKeyPath(publicKey string) string {
+	return filepath.Join("keys", publicKey[:1], publicKey[1:3], publicKey+".nk")
+}
+
+// keystoreCredsPath returns the path of a creds file relative to the keystore
+// root, in the nsc layout creds/<operator>/<account>/<user>.creds.
+func keystoreCredsPath(operator, account, user string) string {
+	return filepath.Join("creds", operator, account, user+".creds")
+}
+
+// keystoreRootDir expands a leading ~ in the keystore root to the home
+// directory of the user running the provider.
+func keystoreRootDir(root string) (string, error) {
+	if root != "~" && !strings.HasPrefix(root, "~/") {
+		return root, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(root, "~")), nil
+}
+
+// openKeystore opens the keystore root, creating it when create is set. All
+// file access goes through the returned root, which refuses to follow
+// symlinks that point outside of it.
+func openKeystore(root string, create bool) (*os.Root, error) {
+	dir, err := keystoreRootDir(root)
+	if err != nil {
+		return nil, err
+	}
+	if create {
+		if err := os.MkdirAll(dir, keystoreDirMode); err != nil {
+			return nil, err
+		}
+	}
+	return os.OpenRoot(dir)
+}
+
+// writeKeystoreFile writes contents to name below the keystore root, creating
+// the parent directories as needed.
+func writeKeystoreFile(root, name string, contents []byte) error {
+	r, err := openKeystore(root, true)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+
+	dir := ""
+	for _, elem := range strings.Split(filepath.Dir(name), string(filepath.Separator)) {
+		dir = filepath.Join(dir, elem)
+		if err := r.Mkdir(dir, keystoreDirMode); err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+	}
+
+	f, err := r.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, keystoreFileMode)
+	if err != nil {
+		return err
+	}
+	// An existing file keeps its permissions on open, so tighten them
+	if err := f.Chmod(keystoreFileMode); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if _, err := f.Write(contents); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readKeystoreFile reads name below the keystore root and returns its
+// contents and permissions. A missing keystore or file is reported as
+// fs.ErrNotExist.
+func readKeystoreFile(root, name string) ([]byte, fs.FileMode, error) {
+	r, err := openKeystore(root, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = r.Close() }()
+
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	contents, err := io.ReadAll(f)
+	if err != nil {
+		return nil, 0, err
+	}
+	return contents, info.Mode().Perm(), nil
+}
+
+// removeKeystoreFile removes name below the keystore root. Files that are
+// already gone are not an error.
+func removeKeystoreFile(root, name string) error {
+	r, err := openKeystore(root, false)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+
+	if err := r.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
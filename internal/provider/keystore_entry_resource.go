@@ -0,0 +1,320 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &KeystoreEntry{}
+
+func NewKeystoreEntry() resource.Resource {
+	return &KeystoreEntry{}
+}
+
+// KeystoreEntry defines the resource implementation.
+type KeystoreEntry struct {
+}
+
+// KeystoreEntryModel describes the resource data model.
+type KeystoreEntryModel struct {
+	KeystoreRoot types.String             `tfsdk:"keystore_root"`
+	Seed         types.String             `tfsdk:"seed"`
+	Creds        *KeystoreEntryCredsModel `tfsdk:"creds"`
+	PublicKey    types.String             `tfsdk:"public_key"`
+	KeyType      types.String             `tfsdk:"type"`
+	KeyPath      types.String             `tfsdk:"key_path"`
+	CredsPath    types.String             `tfsdk:"creds_path"`
+}
+
+// KeystoreEntryCredsModel describes the creds attribute.
+type KeystoreEntryCredsModel struct {
+	Operator types.String `tfsdk:"operator"`
+	Account  types.String `tfsdk:"account"`
+	User     types.String `tfsdk:"user"`
+	Contents types.String `tfsdk:"contents"`
+}
+
+// path returns the path of the creds file relative to the keystore root.
+func (m *KeystoreEntryCredsModel) path() string {
+	return keystoreCredsPath(m.Operator.ValueString(), m.Account.ValueString(), m.User.ValueString())
+}
+
+// keystoreNameValidators ensure a creds path element names a single directory
+// or file below the keystore root.
+var keystoreNameValidators = []validator.String{
+	stringvalidator.LengthAtLeast(1),
+	stringvalidator.RegexMatches(regexp.MustCompile(`^[^/\\]+$`), "must not contain path separators"),
+	stringvalidator.NoneOf(".", ".."),
+}
+
+// setKeys derives the public key, type and paths from the seed.
+func (m *KeystoreEntryModel) setKeys() error {
+	keys, keyType, err := parseSeed([]byte(m.Seed.ValueString()))
+	if err != nil {
+		return err
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	m.PublicKey = types.StringValue(pubKey)
+	m.KeyType = types.StringValue(keyType)
+	m.KeyPath = types.StringValue(keystoreKeyPath(pubKey))
+	m.CredsPath = types.StringNull()
+	if m.Creds != nil {
+		m.CredsPath = types.StringValue(m.Creds.path())
+	}
+	return nil
+}
+
+// writeCreds writes the creds file, if any, to the keystore.
+func (m *KeystoreEntryModel) writeCreds() error {
+	if m.Creds == nil {
+		return nil
+	}
+	return writeKeystoreFile(m.KeystoreRoot.ValueString(), m.CredsPath.ValueString(), []byte(m.Creds.Contents.ValueString()))
+}
+
+// keystoreFileDrifted reports whether name below the keystore root is missing
+// or no longer holds want.
+func keystoreFileDrifted(root, name, want string) (bool, error) {
+	got, _, err := readKeystoreFile(root, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer wipe(got)
+	return string(got) != want, nil
+}
+
+func (r *KeystoreEntry) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_keystore_entry"
+}
+
+func (r *KeystoreEntry) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A keystore entry writes the seed of an nkey into an nsc compatible keystore, so that keys generated by Terraform can be used with the nsc CLI. The seed is written to `keys/<prefix>/<shard>/<public key>.nk`, where shard is the two characters following the prefix, and optionally a creds file to `creds/<operator>/<account>/<user>.creds`, all readable by the owner only. Files that go missing or are changed outside of Terraform are written again by the next apply, and symlinks that point outside of the keystore root are never followed.",
+
+		Attributes: map[string]schema.Attribute{
+			"keystore_root": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Root directory of the keystore, e.g. `~/.nkeys`. A leading `~` is expanded to the home directory of the user running Terraform. The directory is created when missing",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"seed": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the nkey to write, e.g. from an `nkey` resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"creds": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Creds file to place next to the key. Changing it rewrites the creds file in place",
+				Attributes: map[string]schema.Attribute{
+					"operator": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Name of the operator directory",
+						Validators:          keystoreNameValidators,
+					},
+					"account": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Name of the account directory",
+						Validators:          keystoreNameValidators,
+					},
+					"user": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "Name of the user, the creds file is named `<user>.creds`",
+						Validators:          keystoreNameValidators,
+					},
+					"contents": schema.StringAttribute{
+						Required:            true,
+						Sensitive:           true,
+						MarkdownDescription: "Contents of the creds file",
+					},
+				},
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the seed",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The type of the seed. One of user|account|server|cluster|operator|curve",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"key_path": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Path of the seed file relative to `keystore_root`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"creds_path": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Path of the creds file relative to `keystore_root`, null without `creds`",
+			},
+		},
+	}
+}
+
+func (r *KeystoreEntry) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data KeystoreEntryModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := data.setKeys(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "invalid seed", "The seed could not be decoded: "+err.Error())
+		return
+	}
+	if err := writeKeystoreFile(data.KeystoreRoot.ValueString(), data.KeyPath.ValueString(), []byte(data.Seed.ValueString())); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("keystore_root"), "writing keystore entry", err.Error())
+		return
+	}
+	if err := data.writeCreds(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("creds"), "writing keystore entry", err.Error())
+		return
+	}
+	tflog.Trace(ctx, "created keystore entry resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeystoreEntry) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data KeystoreEntryModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Drift shows up as a changed seed, which writes the entry again by
+	// replacing it, or as changed creds, which are rewritten in place
+	drifted, err := keystoreFileDrifted(data.KeystoreRoot.ValueString(), data.KeyPath.ValueString(), data.Seed.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("keystore_root"), "reading keystore entry", err.Error())
+		return
+	}
+	if drifted {
+		tflog.Debug(ctx, "keystore seed file is missing or changed", map[string]interface{}{
+			"key_path": data.KeyPath.ValueString(),
+		})
+		data.Seed = types.StringNull()
+	}
+	if data.Creds != nil {
+		drifted, err := keystoreFileDrifted(data.KeystoreRoot.ValueString(), data.CredsPath.ValueString(), data.Creds.Contents.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("creds"), "reading keystore entry", err.Error())
+			return
+		}
+		if drifted {
+			tflog.Debug(ctx, "keystore creds file is missing or changed", map[string]interface{}{
+				"creds_path": data.CredsPath.ValueString(),
+			})
+			data.Creds.Contents = types.StringNull()
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *KeystoreEntry) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var plan, state KeystoreEntryModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only the creds change in place
+	if err := plan.setKeys(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "invalid seed", "The seed could not be decoded: "+err.Error())
+		return
+	}
+	if state.Creds != nil && plan.CredsPath.ValueString() != state.CredsPath.ValueString() {
+		if err := removeKeystoreFile(state.KeystoreRoot.ValueString(), state.CredsPath.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("creds"), "updating keystore entry", err.Error())
+			return
+		}
+	}
+	if err := plan.writeCreds(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("creds"), "updating keystore entry", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *KeystoreEntry) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data KeystoreEntryModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := removeKeystoreFile(data.KeystoreRoot.ValueString(), data.KeyPath.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("keystore_root"), "deleting keystore entry", err.Error())
+		return
+	}
+	if data.Creds != nil {
+		if err := removeKeystoreFile(data.KeystoreRoot.ValueString(), data.CredsPath.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("creds"), "deleting keystore entry", err.Error())
+			return
+		}
+	}
+	tflog.Trace(ctx, "deleted keystore entry resource")
+}
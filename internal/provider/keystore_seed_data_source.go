@@ -0,0 +1,151 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &KeystoreSeedDataSource{}
+
+func NewKeystoreSeedDataSource() datasource.DataSource {
+	return &KeystoreSeedDataSource{}
+}
+
+// KeystoreSeedDataSource defines the data source implementation.
+type KeystoreSeedDataSource struct {
+}
+
+// KeystoreSeedDataSourceModel describes the data source data model.
+type KeystoreSeedDataSourceModel struct {
+	KeystoreDir types.String `tfsdk:"keystore_dir"`
+	PublicKey   types.String `tfsdk:"public_key"`
+	Seed        types.String `tfsdk:"seed"`
+	KeyType     types.String `tfsdk:"type"`
+	KeyPath     types.String `tfsdk:"key_path"`
+}
+
+func (d *KeystoreSeedDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_keystore_seed"
+}
+
+func (d *KeystoreSeedDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Reads the seed of an nkey managed by nsc from its keystore, e.g. an operator signing key kept on the runner. The seed is looked up by public key at `keys/<prefix>/<shard>/<public key>.nk`, the layout the `nkey_keystore_entry` resource writes.",
+
+		Attributes: map[string]schema.Attribute{
+			"keystore_dir": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Root directory of the keystore. A leading `~` is expanded to the home directory of the user running Terraform. Defaults to `NKEYS_PATH` when set, or `" + defaultKeystoreDir + "` like nsc",
+			},
+			"public_key": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key of the seed to read",
+			},
+			"seed": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed read from the keystore",
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The type of the seed. One of user|account|server|cluster|operator|curve",
+			},
+			"key_path": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Path of the seed file that was read",
+			},
+		},
+	}
+}
+
+func (d *KeystoreSeedDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data KeystoreSeedDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	parsed, err := parseKey(data.PublicKey.ValueString())
+	if err == nil && parsed.kind != keyKindPublic {
+		err = errInvalidPublicKey
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("public_key"), "invalid public key", err.Error())
+		return
+	}
+
+	root := data.KeystoreDir.ValueString()
+	if data.KeystoreDir.IsNull() {
+		root = os.Getenv("NKEYS_PATH")
+		if root == "" {
+			root = defaultKeystoreDir
+		}
+	}
+	dir, err := keystoreRootDir(root)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("keystore_dir"), "reading keystore", err.Error())
+		return
+	}
+	name := keystoreKeyPath(parsed.publicKey)
+	keyPath := filepath.Join(dir, name)
+
+	seed, mode, err := readKeystoreFile(dir, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddAttributeError(path.Root("public_key"), "seed not found", fmt.Sprintf("There is no seed for %s in the keystore, searched %s. Check that keystore_dir points to the nsc keystore and that the key was generated or imported there.", parsed.publicKey, keyPath))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("keystore_dir"), "reading keystore", err.Error())
+		return
+	}
+	defer wipe(seed)
+	if mode&^keystoreFileMode != 0 {
+		resp.Diagnostics.AddAttributeWarning(path.Root("public_key"), "seed file permissions too open", fmt.Sprintf("The seed file %s has permissions %04o, more permissive than %04o. Restrict them with chmod 600.", keyPath, mode, keystoreFileMode))
+	}
+
+	// Seed files edited by hand often end in a newline
+	keys, keyType, err := parseSeed(bytes.TrimSpace(seed))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("public_key"), "invalid seed", fmt.Sprintf("The seed file %s could not be decoded: %s", keyPath, err))
+		return
+	}
+	defer keys.Wipe()
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("public_key"), "invalid seed", err.Error())
+		return
+	}
+	if pubKey != parsed.publicKey {
+		resp.Diagnostics.AddAttributeError(path.Root("public_key"), "seed mismatch", fmt.Sprintf("The seed file %s holds the seed of %s instead of %s.", keyPath, pubKey, parsed.publicKey))
+		return
+	}
+
+	data.Seed = types.StringValue(string(bytes.TrimSpace(seed)))
+	data.KeyType = types.StringValue(keyType)
+	data.KeyPath = types.StringValue(keyPath)
+	tflog.Trace(ctx, "read keystore seed data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,182 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &KubernetesSecretDataSource{}
+
+func NewKubernetesSecretDataSource() datasource.DataSource {
+	return &KubernetesSecretDataSource{}
+}
+
+var (
+	// kubernetesNamePattern matches the DNS subdomain names of Kubernetes
+	// objects.
+	kubernetesNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	// kubernetesNamespacePattern matches the DNS label names of Kubernetes
+	// namespaces.
+	kubernetesNamespacePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// kubernetesSecretKeyPattern matches the keys Kubernetes accepts in the
+	// data of a Secret.
+	kubernetesSecretKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+)
+
+// KubernetesSecretDataSource defines the data source implementation.
+type KubernetesSecretDataSource struct {
+}
+
+// KubernetesSecretDataSourceModel describes the data source data model.
+type KubernetesSecretDataSourceModel struct {
+	Name      types.String `tfsdk:"name"`
+	Namespace types.String `tfsdk:"namespace"`
+	Data      types.Map    `tfsdk:"data"`
+	YAML      types.String `tfsdk:"yaml"`
+	JSON      types.String `tfsdk:"json"`
+}
+
+// kubernetesSecret is the manifest of a v1 Secret. The fields are in the
+// order kubectl prints them, and the encoders sort the keys of the maps.
+type kubernetesSecret struct {
+	APIVersion string                   `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string                   `json:"kind" yaml:"kind"`
+	Metadata   kubernetesSecretMetadata `json:"metadata" yaml:"metadata"`
+	Type       string                   `json:"type" yaml:"type"`
+	Data       map[string]string        `json:"data" yaml:"data"`
+}
+
+// kubernetesSecretMetadata is the metadata of a kubernetesSecret.
+type kubernetesSecretMetadata struct {
+	Name      string `json:"name" yaml:"name"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+func (d *KubernetesSecretDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kubernetes_secret"
+}
+
+func (d *KubernetesSecretDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Renders the manifest of an `Opaque` v1 Kubernetes Secret holding creds, seeds or JWTs, e.g. for the `kubernetes_manifest` resource or `kubectl apply`. The values are base64 encoded and the keys sorted, so the manifest only changes when its contents do.",
+
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the Secret",
+				Validators: []validator.String{
+					stringvalidator.LengthAtMost(253),
+					stringvalidator.RegexMatches(kubernetesNamePattern, "must be a lowercase DNS subdomain name"),
+				},
+			},
+			"namespace": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Namespace of the Secret. When not set the manifest has no namespace, and it is created in the namespace it is applied to",
+				Validators: []validator.String{
+					stringvalidator.LengthAtMost(63),
+					stringvalidator.RegexMatches(kubernetesNamespacePattern, "must be a lowercase DNS label name"),
+				},
+			},
+			"data": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Contents of the Secret by file name, such as `user.creds`, as they are mounted. The values are base64 encoded in the manifest",
+				Validators: []validator.Map{
+					mapvalidator.NoNullValues(),
+					mapvalidator.KeysAre(
+						stringvalidator.LengthAtMost(253),
+						stringvalidator.RegexMatches(kubernetesSecretKeyPattern, "must consist of alphanumeric characters, '-', '_' or '.'"),
+					),
+				},
+			},
+			"yaml": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The manifest of the Secret in YAML",
+			},
+			"json": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The manifest of the Secret in JSON",
+			},
+		},
+	}
+}
+
+func (d *KubernetesSecretDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data KubernetesSecretDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var values map[string]string
+	resp.Diagnostics.Append(data.Data.ElementsAs(ctx, &values, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	secret := kubernetesSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: kubernetesSecretMetadata{
+			Name:      data.Name.ValueString(),
+			Namespace: data.Namespace.ValueString(),
+		},
+		Type: "Opaque",
+		Data: make(map[string]string, len(values)),
+	}
+	for key, value := range values {
+		secret.Data[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+
+	manifest, err := json.MarshalIndent(secret, "", "  ")
+	if err != nil {
+		resp.Diagnostics.AddError("rendering Secret", "The Secret could not be rendered in JSON: "+err.Error())
+		return
+	}
+	data.JSON = types.StringValue(string(manifest) + "\n")
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(secret); err != nil {
+		resp.Diagnostics.AddError("rendering Secret", "The Secret could not be rendered in YAML: "+err.Error())
+		return
+	}
+	if err := enc.Close(); err != nil {
+		resp.Diagnostics.AddError("rendering Secret", "The Secret could not be rendered in YAML: "+err.Error())
+		return
+	}
+	data.YAML = types.StringValue(buf.String())
+	tflog.Trace(ctx, "read kubernetes secret data source", map[string]interface{}{
+		"name": secret.Metadata.Name,
+	})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
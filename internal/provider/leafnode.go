@@ -0,0 +1,210 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// leafnodeConnectionTypes are the connection types of users with a leafnode
+// remote, over plain connections and websockets.
+var leafnodeConnectionTypes = []string{
+	jwt.ConnectionTypeLeafnode,
+	jwt.ConnectionTypeLeafnodeWS,
+}
+
+// LeafnodeRemoteModel describes the remote a leafnode server connects to the
+// account with as a user.
+type LeafnodeRemoteModel struct {
+	URLs            types.List   `tfsdk:"urls"`
+	CredentialsPath types.String `tfsdk:"credentials_path"`
+	Account         types.String `tfsdk:"account"`
+}
+
+// leafnodeRemoteAttribute returns the attribute of the leafnode remote of a
+// user.
+func leafnodeRemoteAttribute() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: "Remote the leafnode servers connect to the account with as the user, rendered into `leafnode_config`. The user is restricted to the `LEAFNODE` and `LEAFNODE_WS` connection types unless `allowed_connection_types` is set, in which case it may only hold those. Adding or removing it issues the JWT again when it changes the connection types, and otherwise it only changes `leafnode_config`",
+		Attributes: map[string]schema.Attribute{
+			"urls": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				MarkdownDescription: "URLs of the leafnode listeners of the hub servers, such as `nats-leaf://hub.example.com:7422`, or `wss://` for websockets",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(isURL("nats-leaf", "nats", "tls", "ws", "wss")),
+				},
+			},
+			"credentials_path": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Path of the creds file on the leafnode servers, where `creds` is written. nats-server only reads the creds of a remote from a file",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"account": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name or public key of the account of the leafnode servers that the remote binds to. nats-server binds it to the global account when not set",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+		},
+	}
+}
+
+// leafnodeConfigAttribute returns the attribute of the rendered leafnode
+// remote.
+func leafnodeConfigAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "The `leafnodes` block of the nats-server configuration of the leafnode servers with the remote of `leafnode_remote`, or null when it is not set. It holds no secrets, only the path of the creds file",
+	}
+}
+
+// checkLeafnodeConnectionTypes checks that configured connection types of a
+// user with a leafnode remote only hold leafnode connection types.
+func checkLeafnodeConnectionTypes(remote types.Object, connectionTypes types.Set) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if remote.IsNull() || connectionTypes.IsNull() || connectionTypes.IsUnknown() {
+		return diags
+	}
+	for _, element := range connectionTypes.Elements() {
+		value, ok := element.(types.String)
+		if !ok || value.IsUnknown() {
+			continue
+		}
+		if !slices.Contains(leafnodeConnectionTypes, strings.ToUpper(value.ValueString())) {
+			diags.AddAttributeError(path.Root("allowed_connection_types"), "conflicting connection types", "The user has a leafnode_remote, so allowed_connection_types may only hold "+strings.Join(leafnodeConnectionTypes, " and ")+", but it holds "+value.ValueString()+".")
+		}
+	}
+	return diags
+}
+
+// leafnodeClaims returns the claims to issue for a user with the given
+// leafnode remote, restricted to leafnode connection types when the remote is
+// set and the connection types are not.
+func leafnodeClaims(claims UserClaimsModel, remote types.Object) UserClaimsModel {
+	if remote.IsNull() || !claims.AllowedConnectionTypes.IsNull() {
+		return claims
+	}
+	elements := make([]attr.Value, 0, len(leafnodeConnectionTypes))
+	for _, connectionType := range leafnodeConnectionTypes {
+		elements = append(elements, types.StringValue(connectionType))
+	}
+	claims.AllowedConnectionTypes = types.SetValueMust(types.StringType, elements)
+	return claims
+}
+
+// planLeafnodeRemote plans the leafnode config of the planned leafnode
+// remote, and the JWT as issued again when adding or removing the remote
+// changes the connection types of the user.
+func planLeafnodeRemote(ctx context.Context, state tfsdk.State, plan *tfsdk.Plan) diag.Diagnostics {
+	var remote types.Object
+	diags := plan.GetAttribute(ctx, path.Root("leafnode_remote"), &remote)
+	if diags.HasError() {
+		return diags
+	}
+	config, d := leafnodeConfig(ctx, remote)
+	diags.Append(d...)
+	diags.Append(plan.SetAttribute(ctx, path.Root("leafnode_config"), config)...)
+	if diags.HasError() || state.Raw.IsNull() {
+		return diags
+	}
+
+	var prior types.Object
+	var connectionTypes types.Set
+	diags.Append(state.GetAttribute(ctx, path.Root("leafnode_remote"), &prior)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("allowed_connection_types"), &connectionTypes)...)
+	if diags.HasError() || !connectionTypes.IsNull() || prior.IsNull() == remote.IsNull() {
+		return diags
+	}
+	diags.Append(planJWTIssued(ctx, plan)...)
+	return diags
+}
+
+// leafnodeConfig renders the leafnodes block of the nats-server configuration
+// with a leafnode remote, which is null without a remote and unknown until the
+// remote is known.
+func leafnodeConfig(ctx context.Context, remote types.Object) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if remote.IsNull() {
+		return types.StringNull(), diags
+	}
+	value, err := remote.ToTerraformValue(ctx)
+	if err != nil || !value.IsFullyKnown() {
+		return types.StringUnknown(), diags
+	}
+	var data LeafnodeRemoteModel
+	diags.Append(remote.As(ctx, &data, basetypes.ObjectAsOptions{})...)
+	var urls []string
+	diags.Append(data.URLs.ElementsAs(ctx, &urls, false)...)
+	if diags.HasError() {
+		return types.StringUnknown(), diags
+	}
+
+	quoted := make([]string, 0, len(urls))
+	for _, u := range urls {
+		quoted = append(quoted, confString(u))
+	}
+	var b strings.Builder
+	b.WriteString("leafnodes {\n")
+	b.WriteString("  remotes = [\n")
+	b.WriteString("    {\n")
+	fmt.Fprintf(&b, "      urls = [%s]\n", strings.Join(quoted, ", "))
+	fmt.Fprintf(&b, "      credentials = %s\n", confString(data.CredentialsPath.ValueString()))
+	if !data.Account.IsNull() {
+		fmt.Fprintf(&b, "      account = %s\n", confString(data.Account.ValueString()))
+	}
+	b.WriteString("    }\n")
+	b.WriteString("  ]\n")
+	b.WriteString("}\n")
+	return types.StringValue(b.String()), diags
+}
+
+// confString quotes s as a string of the nats-server configuration, whose
+// double quoted strings only know the escapes \t, \n, \r, \", \\ and \xXX.
+func confString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
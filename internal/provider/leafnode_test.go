@@ -0,0 +1,133 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/nats-server/v2/conf"
+)
+
+func TestLeafnodeConfig(t *testing.T) {
+	remoteType := leafnodeRemoteAttribute().GetType().(types.ObjectType)
+	// remote returns the known leafnode remote of the given attributes, with
+	// no account when account is empty
+	remote := func(urls []string, credentialsPath, account string) types.Object {
+		elements := make([]attr.Value, len(urls))
+		for i, u := range urls {
+			elements[i] = types.StringValue(u)
+		}
+		accountValue := types.StringNull()
+		if account != "" {
+			accountValue = types.StringValue(account)
+		}
+		return types.ObjectValueMust(remoteType.AttrTypes, map[string]attr.Value{
+			"urls":             types.ListValueMust(types.StringType, elements),
+			"credentials_path": types.StringValue(credentialsPath),
+			"account":          accountValue,
+		})
+	}
+
+	tests := []struct {
+		name            string
+		urls            []string
+		credentialsPath string
+		account         string
+		want            string
+	}{
+		{
+			name:            "remote",
+			urls:            []string{"nats-leaf://hub.example.com:7422"},
+			credentialsPath: "/etc/nats/leaf.creds",
+			want: `leafnodes {
+  remotes = [
+    {
+      urls = ["nats-leaf://hub.example.com:7422"]
+      credentials = "/etc/nats/leaf.creds"
+    }
+  ]
+}
+`,
+		},
+		{
+			name:            "account",
+			urls:            []string{"nats-leaf://a.example.com:7422", "wss://b.example.com:443/leafnode"},
+			credentialsPath: "/etc/nats/leaf.creds",
+			account:         testAccountPublicKey,
+			want: `leafnodes {
+  remotes = [
+    {
+      urls = ["nats-leaf://a.example.com:7422", "wss://b.example.com:443/leafnode"]
+      credentials = "/etc/nats/leaf.creds"
+      account = "` + testAccountPublicKey + `"
+    }
+  ]
+}
+`,
+		},
+		// Characters the configuration would read as the end of the
+		// string, an escape, a variable, a comment or a new key
+		{
+			name:            "escapes",
+			urls:            []string{"nats-leaf://hub.example.com:7422"},
+			credentialsPath: `C:\nats\"leaf" $HOME`,
+			account:         "edge # \t\n}",
+		},
+		{
+			name:            "control characters",
+			urls:            []string{"nats-leaf://hub.example.com:7422"},
+			credentialsPath: "/etc/nats/le\x01af\x7f.creds\r",
+			account:         "ünïcödé",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, diags := leafnodeConfig(context.Background(), remote(tt.urls, tt.credentialsPath, tt.account))
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+			if tt.want != "" && config.ValueString() != tt.want {
+				t.Errorf("leafnodeConfig() = %s, want %s", config.ValueString(), tt.want)
+			}
+
+			// nats-server reads the remote back as configured
+			parsed, err := conf.Parse(config.ValueString())
+			if err != nil {
+				t.Fatalf("the nats-server configuration parser rejects %s: %v", config.ValueString(), err)
+			}
+			urls := make([]interface{}, len(tt.urls))
+			for i, u := range tt.urls {
+				urls[i] = u
+			}
+			wantRemote := map[string]interface{}{
+				"urls":        urls,
+				"credentials": tt.credentialsPath,
+			}
+			if tt.account != "" {
+				wantRemote["account"] = tt.account
+			}
+			want := map[string]interface{}{
+				"leafnodes": map[string]interface{}{
+					"remotes": []interface{}{wantRemote},
+				},
+			}
+			if !reflect.DeepEqual(parsed, want) {
+				t.Errorf("the nats-server configuration parser reads %#v, want %#v", parsed, want)
+			}
+		})
+	}
+
+	config, diags := leafnodeConfig(context.Background(), types.ObjectNull(remoteType.AttrTypes))
+	if diags.HasError() || !config.IsNull() {
+		t.Errorf("leafnodeConfig() without a remote = %s, %v, want null", config, diags)
+	}
+	config, diags = leafnodeConfig(context.Background(), types.ObjectUnknown(remoteType.AttrTypes))
+	if diags.HasError() || !config.IsUnknown() {
+		t.Errorf("leafnodeConfig() of an unknown remote = %s, %v, want unknown", config, diags)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// redactedLogValue replaces a secret in log fields, the same as tflog masking.
+const redactedLogValue = "***"
+
+// secretLogFields are the attribute names whose values are masked in log
+// fields. JWTs are masked too, as a user JWT is a bearer token when
+// bearer_token is set and creds hold a seed.
+var secretLogFields = []string{
+	"seed",
+	"seed_wo",
+	"master_seed_wo",
+	"private_key",
+	"private_key_raw_base64",
+	"private_key_pem",
+	"private_key_openssh",
+	"private_key_jwk",
+	"secret_shares",
+	"entropy",
+	"encryption_passphrase_wo",
+	"passphrase",
+	"plaintext",
+	"recipient_seed",
+	"signing_seed",
+	"operator_seed",
+	"system_account_seed",
+	"system_user_seed",
+	"system_user_creds",
+	"jwt",
+	"creds",
+}
+
+// secretLogRegexps match encoded seeds and private keys anywhere in a log
+// message or field value, e.g. when a whole model is logged.
+var secretLogRegexps = []*regexp.Regexp{
+	// 2 prefix bytes, 32 byte raw seed and 2 byte checksum
+	regexp.MustCompile(`S[A-Z][A-Z2-7]{56}`),
+	// 1 prefix byte, 64 byte private key and 2 byte checksum
+	regexp.MustCompile(`P[A-Z2-7]{107}`),
+	// base64url header, claims and signature of a JWT, whose header always
+	// starts with {"
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+}
+
+// redactSecrets returns a context whose tflog calls mask seeds, private keys
+// and JWTs, both in the secret attribute fields and wherever an encoded seed,
+// private key or JWT shows up in a message or field. Every request handling key
+// material logs through it.
+func redactSecrets(ctx context.Context) context.Context {
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, secretLogFields...)
+	return tflog.MaskLogRegexes(ctx, secretLogRegexps...)
+}
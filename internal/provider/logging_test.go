@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// expectNoSecrets fails when any of secrets shows up in the log output.
+func expectNoSecrets(t *testing.T, output string, secrets map[string]string) {
+	t.Helper()
+
+	for name, secret := range secrets {
+		if secret == "" {
+			t.Fatalf("the %s to look for is empty", name)
+		}
+		if strings.Contains(output, secret) {
+			t.Errorf("the %s shows up in the log output:\n%s", name, output)
+		}
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	var output bytes.Buffer
+	ctx := redactSecrets(tflogtest.RootLogger(context.Background(), &output))
+
+	m, _ := generateTestKeys(t, "user")
+	account, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.NewUserClaims(m.PublicKey.ValueString()).Encode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := map[string]string{
+		"seed":        m.Seed.ValueString(),
+		"private key": m.PrivateKey.ValueString(),
+		"JWT":         token,
+	}
+
+	// Secrets in their own fields, in fields of other names and in messages
+	tflog.Trace(ctx, "model", map[string]interface{}{
+		"seed":        m.Seed.ValueString(),
+		"private_key": m.PrivateKey.ValueString(),
+		"jwt":         token,
+		"model":       fmt.Sprintf("%+v", m),
+	})
+	tflog.Trace(ctx, "seed "+m.Seed.ValueString())
+	tflog.Debug(ctx, "private key "+m.PrivateKey.ValueString())
+	tflog.Info(ctx, "user JWT "+token, map[string]interface{}{"token": token})
+
+	entries, err := tflogtest.MultilineJSONDecode(bytes.NewReader(output.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("%d log entries, want 4:\n%s", len(entries), output.String())
+	}
+	expectNoSecrets(t, output.String(), secrets)
+	if got := entries[0]["seed"]; got != redactedLogValue {
+		t.Errorf("seed field = %v, want %s", got, redactedLogValue)
+	}
+	if !strings.Contains(output.String(), m.PublicKey.ValueString()) {
+		t.Error("the public key is masked too")
+	}
+}
+
+func TestNkeyEphemeralOpenLogging(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	result, diags := openEphemeral(ctx, t, &NkeyEphemeral{defaultKeyType: "account"}, map[string]tftypes.Value{
+		"type": tftypes.NewValue(tftypes.String, "user"),
+	})
+	if diags.HasError() {
+		t.Fatalf("Open() diagnostics = %v", diags)
+	}
+
+	var seed, privateKey types.String
+	diags.Append(result.GetAttribute(ctx, path.Root("seed"), &seed)...)
+	diags.Append(result.GetAttribute(ctx, path.Root("private_key"), &privateKey)...)
+	if diags.HasError() || seed.IsNull() || privateKey.IsNull() {
+		t.Fatalf("Open() returned the seed %s and private key %s: %v", seed, privateKey, diags)
+	}
+	if !strings.Contains(output.String(), "opened ephemeral nkey resource") {
+		t.Fatalf("Open() did not log at trace level:\n%s", output.String())
+	}
+	expectNoSecrets(t, output.String(), map[string]string{
+		"seed":        seed.ValueString(),
+		"private key": privateKey.ValueString(),
+	})
+}
@@ -0,0 +1,476 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/nkeys"
+)
+
+// entropyLen is the number of bytes of entropy in an nkey seed.
+const entropyLen = 32
+
+// fingerprintShortLen is the length of the fingerprint_short attribute.
+const fingerprintShortLen = 8
+
+// defaultKeyType is the type of nkey generated when none is configured.
+const defaultKeyType = "account"
+
+// keyTypes lists the values accepted by the type attribute.
+var keyTypes = []string{"user", "account", "server", "cluster", "operator", "curve"}
+
+// errUnsupportedKeyType is returned when an nkey of an unknown type is requested.
+var errUnsupportedKeyType = errors.New("unsupported nkey type")
+
+// KeyModel describes the key material shared by the nkey resources.
+type KeyModel struct {
+	KeyType             types.String `tfsdk:"type"`
+	PublicKey           types.String `tfsdk:"public_key"`
+	PrivateKey          types.String `tfsdk:"private_key"`
+	Seed                types.String `tfsdk:"seed"`
+	PublicKeyRawBase64  types.String `tfsdk:"public_key_raw_base64"`
+	PublicKeyRawHex     types.String `tfsdk:"public_key_raw_hex"`
+	PrivateKeyRawBase64 types.String `tfsdk:"private_key_raw_base64"`
+	PublicKeyPEM        types.String `tfsdk:"public_key_pem"`
+	PrivateKeyPEM       types.String `tfsdk:"private_key_pem"`
+	PublicKeyOpenSSH    types.String `tfsdk:"public_key_openssh"`
+	PrivateKeyOpenSSH   types.String `tfsdk:"private_key_openssh"`
+	PublicKeyJWK        types.String `tfsdk:"public_key_jwk"`
+	PrivateKeyJWK       types.String `tfsdk:"private_key_jwk"`
+	IncludePrivateKey   types.Bool   `tfsdk:"include_private_key"`
+	Fingerprint         types.String `tfsdk:"fingerprint"`
+	FingerprintShort    types.String `tfsdk:"fingerprint_short"`
+	FingerprintLength   types.Int64  `tfsdk:"fingerprint_length"`
+	IsCurve             types.Bool   `tfsdk:"is_curve"`
+}
+
+// logFields returns the model as tflog fields. Secrets are never part of
+// them, their fields only tell whether the secret is set.
+func (m *KeyModel) logFields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"type":                m.KeyType.ValueString(),
+		"public_key":          m.PublicKey.ValueString(),
+		"fingerprint":         m.Fingerprint.ValueString(),
+		"include_private_key": m.includePrivateKey(),
+	}
+	secrets := map[string]types.String{
+		"seed":                   m.Seed,
+		"private_key":            m.PrivateKey,
+		"private_key_raw_base64": m.PrivateKeyRawBase64,
+		"private_key_pem":        m.PrivateKeyPEM,
+		"private_key_openssh":    m.PrivateKeyOpenSSH,
+		"private_key_jwk":        m.PrivateKeyJWK,
+	}
+	for name, v := range secrets {
+		if !v.IsNull() && !v.IsUnknown() {
+			fields[name] = redactedLogValue
+		}
+	}
+	return fields
+}
+
+// includePrivateKey reports whether the private_key attribute is to be set.
+// It defaults to true.
+func (m *KeyModel) includePrivateKey() bool {
+	return m.IncludePrivateKey.IsNull() || m.IncludePrivateKey.ValueBool()
+}
+
+// generateKeys creates a new key pair of the configured type and stores its
+// encoded parts in the model.
+func (m *KeyModel) generateKeys() error {
+	return m.generateKeysWithRand(rand.Reader)
+}
+
+// generateKeysWithRand is like generateKeys but reads the key entropy from rr.
+func (m *KeyModel) generateKeysWithRand(rr io.Reader) error {
+	prefix, err := keyTypePrefix(m.KeyType.ValueString())
+	if err != nil {
+		return err
+	}
+
+	keys, err := nkeys.CreatePairWithRand(prefix, rr)
+	if err != nil {
+		return err
+	}
+
+	// Only the encoded strings in the model outlive the key pair
+	err = m.setKeys(keys)
+	keys.Wipe()
+	return err
+}
+
+// setKeys stores the encoded parts of keys in the model. The private key
+// encodings are only derived from keys when include_private_key is set, and
+// are null otherwise.
+func (m *KeyModel) setKeys(keys nkeys.KeyPair) error {
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		return err
+	}
+	seed, err := keys.Seed()
+	if err != nil {
+		return err
+	}
+	defer wipe(seed)
+
+	std, err := newStdKeys(seed)
+	if err != nil {
+		return err
+	}
+	defer std.wipe()
+	pubPEM, err := std.publicPEM()
+	if err != nil {
+		return err
+	}
+	pubJWK, privJWK, err := std.jwks(pubKey)
+	if err != nil {
+		return err
+	}
+	pubSSH := types.StringNull()
+	if !std.curve {
+		pub, err := std.publicOpenSSH()
+		if err != nil {
+			return err
+		}
+		pubSSH = types.StringValue(pub)
+	}
+
+	m.PublicKey = types.StringValue(pubKey)
+	m.IncludePrivateKey = types.BoolValue(m.includePrivateKey())
+	m.Seed = types.StringValue(string(seed))
+	m.PublicKeyRawBase64 = types.StringValue(base64.StdEncoding.EncodeToString(std.rawPublic()))
+	m.PublicKeyRawHex = types.StringValue(hex.EncodeToString(std.rawPublic()))
+	m.PublicKeyPEM = types.StringValue(pubPEM)
+	m.PublicKeyOpenSSH = pubSSH
+	m.PublicKeyJWK = types.StringValue(pubJWK)
+	if !m.includePrivateKey() {
+		m.clearPrivateKeys()
+		return m.setPublicAttributes()
+	}
+
+	raw, err := keys.PrivateKey()
+	if err != nil {
+		return err
+	}
+	m.PrivateKey = types.StringValue(string(raw))
+	wipe(raw)
+	privPEM, err := std.privatePEM()
+	if err != nil {
+		return err
+	}
+	privSSH := types.StringNull()
+	if !std.curve {
+		priv, err := std.privateOpenSSH()
+		if err != nil {
+			return err
+		}
+		privSSH = types.StringValue(priv)
+	}
+	m.PrivateKeyRawBase64 = types.StringValue(base64.StdEncoding.EncodeToString(std.rawPrivate()))
+	m.PrivateKeyPEM = types.StringValue(privPEM)
+	m.PrivateKeyOpenSSH = privSSH
+	m.PrivateKeyJWK = types.StringValue(privJWK)
+
+	return m.setPublicAttributes()
+}
+
+// setPublicAttributes stores the attributes that only depend on the public key
+// in the model: the fingerprint, truncated to the configured fingerprint
+// length, and whether it is a curve key.
+func (m *KeyModel) setPublicAttributes() error {
+	fp, err := fingerprint(m.PublicKey.ValueString())
+	if err != nil {
+		return err
+	}
+	m.IsCurve = types.BoolValue(nkeys.Prefix(m.PublicKey.ValueString()) == nkeys.PrefixByteCurve)
+
+	m.FingerprintShort = types.StringValue(fp[:fingerprintShortLen])
+	if !m.FingerprintLength.IsNull() {
+		fp = fp[:m.FingerprintLength.ValueInt64()]
+	}
+	m.Fingerprint = types.StringValue(fp)
+	return nil
+}
+
+// deriveKeys refreshes the attributes derived from the stored seed.
+func (m *KeyModel) deriveKeys() error {
+	keys, err := nkeys.FromSeed([]byte(m.Seed.ValueString()))
+	if err != nil {
+		return seedError(err)
+	}
+	defer keys.Wipe()
+
+	return m.setKeys(keys)
+}
+
+// wipe overwrites b with zeros.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// privateKeyAttributes lists the attributes holding an encoding of the
+// private key, which include_private_key leaves null when false.
+var privateKeyAttributes = []string{"private_key", "private_key_raw_base64", "private_key_pem", "private_key_openssh", "private_key_jwk"}
+
+// clearPrivateKeys nulls every private key encoding of the model.
+func (m *KeyModel) clearPrivateKeys() {
+	m.PrivateKey = types.StringNull()
+	m.PrivateKeyRawBase64 = types.StringNull()
+	m.PrivateKeyPEM = types.StringNull()
+	m.PrivateKeyOpenSSH = types.StringNull()
+	m.PrivateKeyJWK = types.StringNull()
+}
+
+// clearSecrets nulls every sensitive attribute of the model.
+func (m *KeyModel) clearSecrets() {
+	m.clearPrivateKeys()
+	m.Seed = types.StringNull()
+}
+
+// warnings returns diagnostics about attributes the key type leaves null.
+func (m *KeyModel) warnings() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if strings.EqualFold(m.KeyType.ValueString(), "curve") {
+		diags.AddWarning("no OpenSSH encoding for curve keys", "Curve keys are x25519 keys, which OpenSSH does not support, so public_key_openssh and private_key_openssh are null.")
+	}
+	return diags
+}
+
+// addKeyError adds a diagnostic for an error returned while generating keys,
+// pointing at the type attribute when the type was to blame.
+func addKeyError(diags *diag.Diagnostics, err error) {
+	if errors.Is(err, errUnsupportedKeyType) {
+		diags.AddAttributeError(path.Root("type"), "generating nkey", err.Error())
+		return
+	}
+	diags.AddError("generating nkey", err.Error())
+}
+
+// fingerprint returns the hex encoded SHA-256 of the raw public key bytes.
+// For example the account key generated from 32 zero bytes of entropy,
+// AA5WUJ54Z23KILLCUOUNAKTPBVZWKMQVO4O6EQ5GHLAERIMLLHNCS47C, has the fingerprint
+// 139e3940e64b5491722088d9a0d741628fc826e09475d341a780acde3c4b8070.
+func fingerprint(publicKey string) (string, error) {
+	raw, err := nkeys.Decode(nkeys.Prefix(publicKey), []byte(publicKey))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// keyTypeNames maps the nkey prefix bytes to the values of the type attribute.
+var keyTypeNames = map[nkeys.PrefixByte]string{
+	nkeys.PrefixByteUser:     "user",
+	nkeys.PrefixByteAccount:  "account",
+	nkeys.PrefixByteServer:   "server",
+	nkeys.PrefixByteCluster:  "cluster",
+	nkeys.PrefixByteOperator: "operator",
+	nkeys.PrefixByteCurve:    "curve",
+}
+
+// keyTypePrefix returns the prefix byte of the named key type.
+func keyTypePrefix(keyType string) (nkeys.PrefixByte, error) {
+	for prefix, name := range keyTypeNames {
+		if strings.EqualFold(name, keyType) {
+			return prefix, nil
+		}
+	}
+	return nkeys.PrefixByteUnknown, fmt.Errorf("%w %q, must be one of %s", errUnsupportedKeyType, keyType, strings.Join(keyTypes, "|"))
+}
+
+// canonicalKeyType returns keyType in its canonical lowercase form.
+func canonicalKeyType(keyType types.String) types.String {
+	if keyType.IsNull() || keyType.IsUnknown() {
+		return keyType
+	}
+	return types.StringValue(strings.ToLower(keyType.ValueString()))
+}
+
+// setKeysFromSeed decodes seed and stores its type and encoded parts in the
+// model. Errors never include the seed itself.
+func (m *KeyModel) setKeysFromSeed(seed []byte) error {
+	keys, keyType, err := parseSeed(seed)
+	if err != nil {
+		return err
+	}
+	defer keys.Wipe()
+
+	if err := m.setKeys(keys); err != nil {
+		return err
+	}
+	m.KeyType = types.StringValue(keyType)
+
+	return nil
+}
+
+// parseSeed decodes seed into a key pair and the name of its type. Errors
+// never include the seed itself.
+func parseSeed(seed []byte) (nkeys.KeyPair, string, error) {
+	prefix, _, err := nkeys.DecodeSeed(seed)
+	if err != nil {
+		return nil, "", seedError(err)
+	}
+	keyType, ok := keyTypeNames[prefix]
+	if !ok {
+		return nil, "", fmt.Errorf("%w with prefix %q", errUnsupportedKeyType, prefix)
+	}
+
+	keys, err := nkeys.FromSeed(seed)
+	if err != nil {
+		return nil, "", seedError(err)
+	}
+
+	return keys, keyType, nil
+}
+
+// publicKeyFromSeed returns the public key and the name of the type of seed.
+// Errors never include the seed itself.
+func publicKeyFromSeed(seed []byte) (string, string, error) {
+	keys, keyType, err := parseSeed(seed)
+	if err != nil {
+		return "", "", err
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		return "", "", seedError(err)
+	}
+	return pubKey, keyType, nil
+}
+
+// signingKeys decodes seed into a key pair that can sign, so curve seeds are
+// rejected with errCurveCannotSign. Errors never include the seed itself.
+func signingKeys(seed string) (nkeys.KeyPair, error) {
+	parsed, err := parseKey(seed)
+	if err == nil && parsed.kind != keyKindSeed {
+		err = errNotASeed
+	}
+	if err == nil {
+		err = parsed.checkRole(keyRoleSigning)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := nkeys.FromSeed([]byte(seed))
+	if err != nil {
+		return nil, seedError(err)
+	}
+	return keys, nil
+}
+
+// seedError explains why a seed could not be decoded. The seed is never part
+// of the message.
+func seedError(err error) error {
+	var corrupt base32.CorruptInputError
+
+	switch {
+	case errors.Is(err, nkeys.ErrInvalidChecksum):
+		return errors.New("the seed checksum does not match, the seed is mistyped or truncated")
+	case errors.Is(err, nkeys.ErrInvalidEncoding), errors.As(err, &corrupt):
+		return errors.New("the seed is not valid base32, check for stray characters or truncation")
+	case errors.Is(err, nkeys.ErrInvalidSeedLen):
+		return errors.New("the seed has the wrong length, it is probably truncated")
+	case errors.Is(err, nkeys.ErrInvalidSeed):
+		return errors.New("the value is not an nkey seed, seeds start with S")
+	}
+	return err
+}
+
+// decodeEntropy decodes base64 encoded seed entropy. Errors never include the
+// entropy itself.
+func decodeEntropy(encoded string) ([]byte, error) {
+	entropy, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("the entropy is not valid base64")
+	}
+	if len(entropy) != entropyLen {
+		return nil, fmt.Errorf("the entropy must be exactly %d bytes once decoded, got %d", entropyLen, len(entropy))
+	}
+	return entropy, nil
+}
+
+// planDefaultKeyType plans the type attribute as defaultType when it is
+// neither configured nor known from state, so the plan shows the type that
+// will be generated.
+func planDefaultKeyType(ctx context.Context, config tfsdk.Config, plan *tfsdk.Plan, defaultType string) diag.Diagnostics {
+	if plan.Raw.IsNull() {
+		// The resource is being destroyed
+		return nil
+	}
+
+	var configured, planned types.String
+	diags := config.GetAttribute(ctx, path.Root("type"), &configured)
+	diags.Append(plan.GetAttribute(ctx, path.Root("type"), &planned)...)
+	if diags.HasError() || !configured.IsNull() || !planned.IsUnknown() {
+		return diags
+	}
+
+	diags.Append(plan.SetAttribute(ctx, path.Root("type"), defaultType)...)
+	return diags
+}
+
+// planIncludePrivateKey plans the private key encodings as unknown when
+// include_private_key changes on an existing resource, so that they are
+// updated in place, and warns when they are about to become null.
+func planIncludePrivateKey(ctx context.Context, state tfsdk.State, plan *tfsdk.Plan) diag.Diagnostics {
+	if state.Raw.IsNull() || plan.Raw.IsNull() {
+		// The resource is being created or destroyed
+		return nil
+	}
+
+	var prior, planned KeyModel
+	diags := state.GetAttribute(ctx, path.Root("include_private_key"), &prior.IncludePrivateKey)
+	diags.Append(plan.GetAttribute(ctx, path.Root("include_private_key"), &planned.IncludePrivateKey)...)
+	if diags.HasError() || planned.IncludePrivateKey.IsUnknown() || prior.includePrivateKey() == planned.includePrivateKey() {
+		return diags
+	}
+
+	if !planned.includePrivateKey() {
+		diags.AddAttributeWarning(path.Root("include_private_key"), "private keys become null", "With include_private_key set to false the private_key, private_key_raw_base64, private_key_pem, private_key_openssh and private_key_jwk attributes become null. References to them will fail or receive null, use the seed attribute instead.")
+	}
+	for _, name := range privateKeyAttributes {
+		diags.Append(plan.SetAttribute(ctx, path.Root(name), types.StringUnknown())...)
+	}
+	return diags
+}
+
+// planFingerprintLength plans fingerprint as unknown when fingerprint_length
+// changes on an existing resource, so that it is updated in place.
+func planFingerprintLength(ctx context.Context, state tfsdk.State, plan *tfsdk.Plan) diag.Diagnostics {
+	if state.Raw.IsNull() || plan.Raw.IsNull() {
+		// The resource is being created or destroyed
+		return nil
+	}
+
+	var prior, planned types.Int64
+	diags := state.GetAttribute(ctx, path.Root("fingerprint_length"), &prior)
+	diags.Append(plan.GetAttribute(ctx, path.Root("fingerprint_length"), &planned)...)
+	if diags.HasError() || prior.Equal(planned) {
+		return diags
+	}
+
+	diags.Append(plan.SetAttribute(ctx, path.Root("fingerprint"), types.StringUnknown())...)
+	return diags
+}
@@ -0,0 +1,148 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	ephemeralschema "github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// keyAttribute describes a computed KeyModel attribute.
+type keyAttribute struct {
+	description string
+	sensitive   bool
+}
+
+// keyAttributes lists the computed KeyModel attributes by name. The type
+// attribute is left to each resource since its defaults differ.
+var keyAttributes = map[string]keyAttribute{
+	"public_key": {
+		description: "Public key of the nkey to be given in config to the nats server",
+	},
+	"private_key": {
+		description: "Private key of the nkey to be given to the client for authentication",
+		sensitive:   true,
+	},
+	"seed": {
+		description: "Seed of the nkey to be given to the client for authentication",
+		sensitive:   true,
+	},
+	"public_key_raw_base64": {
+		description: "Raw 32 byte public key, base64 encoded. For curve keys this is the x25519 public key",
+	},
+	"public_key_raw_hex": {
+		description: "Raw 32 byte public key, hex encoded. For curve keys this is the x25519 public key",
+	},
+	"private_key_raw_base64": {
+		description: "Raw 64 byte ed25519 private key (seed followed by public key), base64 encoded. For curve keys this is the 32 byte x25519 private key",
+		sensitive:   true,
+	},
+	"public_key_pem": {
+		description: "Public key in PKIX PEM format. For curve keys this is an x25519 key",
+	},
+	"private_key_pem": {
+		description: "Private key in PKCS#8 PEM format. For curve keys this is an x25519 key",
+		sensitive:   true,
+	},
+	"public_key_openssh": {
+		description: "Public key in OpenSSH authorized_keys format. Null for curve keys",
+	},
+	"private_key_openssh": {
+		description: "Private key in OpenSSH PEM format. Null for curve keys",
+		sensitive:   true,
+	},
+	"public_key_jwk": {
+		description: "Public key as an RFC 8037 OKP JSON Web Key with the `public_key` as `kid`. The curve is Ed25519, or X25519 for curve keys",
+	},
+	"private_key_jwk": {
+		description: "Private key as an RFC 8037 OKP JSON Web Key with the `public_key` as `kid`. The curve is Ed25519, or X25519 for curve keys",
+		sensitive:   true,
+	},
+	"fingerprint": {
+		description: "Hex encoded SHA-256 of the raw public key bytes, truncated to `fingerprint_length` characters. For example the account key `AA5WUJ54Z23KILLCUOUNAKTPBVZWKMQVO4O6EQ5GHLAERIMLLHNCS47C` has the fingerprint `139e3940e64b5491722088d9a0d741628fc826e09475d341a780acde3c4b8070`",
+	},
+	"fingerprint_short": {
+		description: "First 8 characters of the full fingerprint, for log correlation",
+	},
+}
+
+// fingerprintLengthDescription describes the fingerprint_length attribute.
+const fingerprintLengthDescription = "Number of hex characters `fingerprint` is truncated to, between 8 and 64. Defaults to the full 64"
+
+// isCurveDescription describes the is_curve attribute.
+const isCurveDescription = "Whether the nkey is a curve (xkey) key. Curve keys are x25519 keys that seal payloads with `nkey_xkey_seal` and cannot sign JWTs, nonces or payloads"
+
+// includePrivateKeyDescription describes the include_private_key attribute.
+const includePrivateKeyDescription = "Whether to set `private_key` and the other private key encodings, `private_key_raw_base64`, `private_key_pem`, `private_key_openssh` and `private_key_jwk`. The NATS clients only need the `seed`, so set this to false to keep the expanded private key out of state and outputs. When false all of them are null, so any reference to them must be switched to `seed` first. Defaults to true"
+
+// keyResourceAttributes adds the KeyModel attributes other than type to the schema
+// attributes of a managed nkey resource.
+func keyResourceAttributes(attrs map[string]schema.Attribute) map[string]schema.Attribute {
+	for name, attr := range keyAttributes {
+		attrs[name] = schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: attr.description,
+			Sensitive:           attr.sensitive,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		}
+	}
+	attrs["include_private_key"] = schema.BoolAttribute{
+		Optional:            true,
+		Computed:            true,
+		Default:             booldefault.StaticBool(true),
+		MarkdownDescription: includePrivateKeyDescription + ". Changing it updates the private key encodings in place without generating a new key",
+	}
+	attrs["is_curve"] = schema.BoolAttribute{
+		Computed:            true,
+		MarkdownDescription: isCurveDescription,
+		PlanModifiers: []planmodifier.Bool{
+			boolplanmodifier.UseStateForUnknown(),
+		},
+	}
+	attrs["fingerprint_length"] = schema.Int64Attribute{
+		Optional:            true,
+		MarkdownDescription: fingerprintLengthDescription,
+		Validators: []validator.Int64{
+			int64validator.Between(fingerprintShortLen, 64),
+		},
+	}
+	return attrs
+}
+
+// keyEphemeralAttributes adds the KeyModel attributes other than type to the schema
+// attributes of an ephemeral nkey resource.
+func keyEphemeralAttributes(attrs map[string]ephemeralschema.Attribute) map[string]ephemeralschema.Attribute {
+	for name, attr := range keyAttributes {
+		attrs[name] = ephemeralschema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: attr.description,
+			Sensitive:           attr.sensitive,
+		}
+	}
+	attrs["include_private_key"] = ephemeralschema.BoolAttribute{
+		Optional:            true,
+		Computed:            true,
+		MarkdownDescription: includePrivateKeyDescription,
+	}
+	attrs["is_curve"] = ephemeralschema.BoolAttribute{
+		Computed:            true,
+		MarkdownDescription: isCurveDescription,
+	}
+	attrs["fingerprint_length"] = ephemeralschema.Int64Attribute{
+		Optional:            true,
+		MarkdownDescription: fingerprintLengthDescription,
+		Validators: []validator.Int64{
+			int64validator.Between(fingerprintShortLen, 64),
+		},
+	}
+	return attrs
+}
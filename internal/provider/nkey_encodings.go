@@ -0,0 +1,207 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+
+	"github.com/nats-io/nkeys"
+	"golang.org/x/crypto/ssh"
+)
+
+// errCurveNotSSH is returned when an OpenSSH encoding of a curve key is requested.
+var errCurveNotSSH = errors.New("curve keys are x25519 keys and have no OpenSSH encoding")
+
+// stdKeys holds the standard library form of an nkey: ed25519 for signing
+// keys and x25519 for curve keys.
+type stdKeys struct {
+	public  crypto.PublicKey
+	private crypto.PrivateKey
+	curve   bool
+}
+
+// newStdKeys converts the encoded seed into its standard library form.
+func newStdKeys(seed []byte) (stdKeys, error) {
+	prefix, rawSeed, err := nkeys.DecodeSeed(seed)
+	if err != nil {
+		return stdKeys{}, seedError(err)
+	}
+	defer wipe(rawSeed)
+
+	if prefix == nkeys.PrefixByteCurve {
+		priv, err := ecdh.X25519().NewPrivateKey(rawSeed)
+		if err != nil {
+			return stdKeys{}, err
+		}
+		return stdKeys{public: priv.PublicKey(), private: priv, curve: true}, nil
+	}
+
+	priv := ed25519.NewKeyFromSeed(rawSeed)
+	return stdKeys{public: priv.Public(), private: priv}, nil
+}
+
+// wipe overwrites the ed25519 private key with zeros. The x25519 private key
+// cannot be wiped, its scalar is not exposed.
+func (k stdKeys) wipe() {
+	if priv, ok := k.private.(ed25519.PrivateKey); ok {
+		wipe(priv)
+	}
+}
+
+// rawPublic returns the raw 32 byte public key.
+func (k stdKeys) rawPublic() []byte {
+	switch pub := k.public.(type) {
+	case ed25519.PublicKey:
+		return pub
+	case *ecdh.PublicKey:
+		return pub.Bytes()
+	}
+	return nil
+}
+
+// rawPrivate returns the raw private key, the full 64 byte form for ed25519
+// keys and the 32 byte scalar for x25519 keys.
+func (k stdKeys) rawPrivate() []byte {
+	switch priv := k.private.(type) {
+	case ed25519.PrivateKey:
+		return priv
+	case *ecdh.PrivateKey:
+		return priv.Bytes()
+	}
+	return nil
+}
+
+// publicPEM returns the public key PEM encoded in PKIX form.
+func (k stdKeys) publicPEM() (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(k.public)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// privatePEM returns the private key PEM encoded in PKCS#8 form.
+func (k stdKeys) privatePEM() (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.private)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// publicOpenSSH returns the public key as an authorized_keys line.
+func (k stdKeys) publicOpenSSH() (string, error) {
+	if k.curve {
+		return "", errCurveNotSSH
+	}
+	pub, err := ssh.NewPublicKey(k.public)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(pub)), "\n"), nil
+}
+
+// privateOpenSSH returns the private key PEM encoded in OpenSSH form. Unlike
+// ssh.MarshalPrivateKey the check bytes are derived from the key rather than
+// random, so the same key always encodes to the same text.
+func (k stdKeys) privateOpenSSH() (string, error) {
+	priv, ok := k.private.(ed25519.PrivateKey)
+	if !ok || k.curve {
+		return "", errCurveNotSSH
+	}
+	pub := priv[ed25519.SeedSize:]
+	check := binary.BigEndian.Uint32(pub)
+
+	key := struct {
+		Check1  uint32
+		Check2  uint32
+		KeyType string
+		Pub     []byte
+		Priv    []byte
+		Comment string
+		Pad     []byte `ssh:"rest"`
+	}{
+		Check1:  check,
+		Check2:  check,
+		KeyType: ssh.KeyAlgoED25519,
+		Pub:     pub,
+		Priv:    priv,
+	}
+	// The private section is padded to the cipher block size of 8 with the
+	// bytes 1, 2, 3, ...
+	for i := 0; (len(ssh.Marshal(key)))%8 != 0; i++ {
+		key.Pad = append(key.Pad, byte(i+1))
+	}
+
+	sshPub, err := ssh.NewPublicKey(k.public)
+	if err != nil {
+		return "", err
+	}
+	envelope := struct {
+		CipherName string
+		KdfName    string
+		KdfOpts    string
+		NumKeys    uint32
+		PubKey     []byte
+		PrivKey    []byte
+	}{
+		CipherName: "none",
+		KdfName:    "none",
+		NumKeys:    1,
+		PubKey:     sshPub.Marshal(),
+		PrivKey:    ssh.Marshal(key),
+	}
+
+	block := &pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: append([]byte("openssh-key-v1\x00"), ssh.Marshal(envelope)...),
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+// jwk is an RFC 8037 OKP JSON Web Key. The struct fixes the field order so
+// the encoding is stable.
+type jwk struct {
+	KeyType string `json:"kty"`
+	Curve   string `json:"crv"`
+	KeyID   string `json:"kid"`
+	X       string `json:"x"`
+	D       string `json:"d,omitempty"`
+}
+
+// jwks returns the public and private JSON Web Keys, identified by kid.
+func (k stdKeys) jwks(kid string) (string, string, error) {
+	key := jwk{
+		KeyType: "OKP",
+		Curve:   "Ed25519",
+		KeyID:   kid,
+		X:       base64.RawURLEncoding.EncodeToString(k.rawPublic()),
+	}
+	d := k.rawPrivate()
+	if k.curve {
+		key.Curve = "X25519"
+	} else {
+		d = d[:ed25519.SeedSize]
+	}
+
+	pub, err := json.Marshal(key)
+	if err != nil {
+		return "", "", err
+	}
+	key.D = base64.RawURLEncoding.EncodeToString(d)
+	priv, err := json.Marshal(key)
+	if err != nil {
+		return "", "", err
+	}
+	return string(pub), string(priv), nil
+}
@@ -0,0 +1,184 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/nkeys"
+	"golang.org/x/crypto/ssh"
+)
+
+// generateTestKeys returns a generated key of keyType and the key pair of its
+// seed.
+func generateTestKeys(t *testing.T, keyType string) (KeyModel, nkeys.KeyPair) {
+	t.Helper()
+
+	m := KeyModel{KeyType: types.StringValue(keyType)}
+	if err := m.generateKeys(); err != nil {
+		t.Fatalf("generateKeys() error = %v", err)
+	}
+	keys, err := nkeys.FromSeed([]byte(m.Seed.ValueString()))
+	if err != nil {
+		t.Fatalf("FromSeed() error = %v", err)
+	}
+	return m, keys
+}
+
+// decodePEM returns the bytes of the single PEM block of encoded, which must
+// be of blockType.
+func decodePEM(t *testing.T, encoded, blockType string) []byte {
+	t.Helper()
+
+	block, rest := pem.Decode([]byte(encoded))
+	if block == nil || block.Type != blockType || len(bytes.TrimSpace(rest)) != 0 {
+		t.Fatalf("%q is not a single %s PEM block", encoded, blockType)
+	}
+	return block.Bytes
+}
+
+func TestPEMRoundTrip(t *testing.T) {
+	for _, keyType := range []string{"user", "account", "server", "cluster", "operator"} {
+		t.Run(keyType, func(t *testing.T) {
+			m, keys := generateTestKeys(t, keyType)
+			rawPublic, err := nkeys.Decode(nkeys.Prefix(m.PublicKey.ValueString()), []byte(m.PublicKey.ValueString()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, err := x509.ParsePKCS8PrivateKey(decodePEM(t, m.PrivateKeyPEM.ValueString(), "PRIVATE KEY"))
+			if err != nil {
+				t.Fatalf("ParsePKCS8PrivateKey() error = %v", err)
+			}
+			priv, ok := parsed.(ed25519.PrivateKey)
+			if !ok {
+				t.Fatalf("private_key_pem holds a %T, not an ed25519 key", parsed)
+			}
+			parsed, err = x509.ParsePKIXPublicKey(decodePEM(t, m.PublicKeyPEM.ValueString(), "PUBLIC KEY"))
+			if err != nil {
+				t.Fatalf("ParsePKIXPublicKey() error = %v", err)
+			}
+			pub, ok := parsed.(ed25519.PublicKey)
+			if !ok {
+				t.Fatalf("public_key_pem holds a %T, not an ed25519 key", parsed)
+			}
+			if !pub.Equal(ed25519.PublicKey(rawPublic)) || !pub.Equal(priv.Public()) {
+				t.Fatal("the PEM keys do not match the nkey")
+			}
+
+			// Signatures of the nkey verify with the parsed key and the other
+			// way around, ed25519 signatures being deterministic
+			message := []byte("nonce")
+			signature, err := keys.Sign(message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ed25519.Verify(pub, message, signature) {
+				t.Error("the nkey signature does not verify with public_key_pem")
+			}
+			if err := keys.Verify(message, ed25519.Sign(priv, message)); err != nil {
+				t.Errorf("the private_key_pem signature does not verify with the nkey: %v", err)
+			}
+		})
+	}
+}
+
+func TestPEMRoundTripCurve(t *testing.T) {
+	m, keys := generateTestKeys(t, "curve")
+	publicKey, err := keys.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawPublic, err := nkeys.Decode(nkeys.PrefixByteCurve, []byte(publicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(decodePEM(t, m.PrivateKeyPEM.ValueString(), "PRIVATE KEY"))
+	if err != nil {
+		t.Fatalf("ParsePKCS8PrivateKey() error = %v", err)
+	}
+	priv, ok := parsed.(*ecdh.PrivateKey)
+	if !ok || priv.Curve() != ecdh.X25519() {
+		t.Fatalf("private_key_pem holds a %T, not an x25519 key", parsed)
+	}
+	parsed, err = x509.ParsePKIXPublicKey(decodePEM(t, m.PublicKeyPEM.ValueString(), "PUBLIC KEY"))
+	if err != nil {
+		t.Fatalf("ParsePKIXPublicKey() error = %v", err)
+	}
+	pub, ok := parsed.(*ecdh.PublicKey)
+	if !ok || pub.Curve() != ecdh.X25519() {
+		t.Fatalf("public_key_pem holds a %T, not an x25519 key", parsed)
+	}
+	if !bytes.Equal(pub.Bytes(), rawPublic) || !pub.Equal(priv.PublicKey()) {
+		t.Fatal("the PEM keys do not match the nkey")
+	}
+}
+
+func TestOpenSSH(t *testing.T) {
+	m, keys := generateTestKeys(t, "user")
+	rawPublic, err := nkeys.Decode(nkeys.PrefixByteUser, []byte(m.PublicKey.ValueString()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, comment, options, rest, err := ssh.ParseAuthorizedKey([]byte(m.PublicKeyOpenSSH.ValueString()))
+	if err != nil {
+		t.Fatalf("ParseAuthorizedKey() error = %v", err)
+	}
+	if parsed.Type() != ssh.KeyAlgoED25519 || comment != "" || options != nil || len(rest) != 0 {
+		t.Errorf("public_key_openssh is %q", m.PublicKeyOpenSSH.ValueString())
+	}
+	pub, ok := parsed.(ssh.CryptoPublicKey).CryptoPublicKey().(ed25519.PublicKey)
+	if !ok || !bytes.Equal(pub, rawPublic) {
+		t.Fatal("public_key_openssh does not hold the public key bytes of the nkey")
+	}
+
+	raw, err := ssh.ParseRawPrivateKey([]byte(m.PrivateKeyOpenSSH.ValueString()))
+	if err != nil {
+		t.Fatalf("ParseRawPrivateKey() error = %v", err)
+	}
+	priv, ok := raw.(*ed25519.PrivateKey)
+	if !ok {
+		t.Fatalf("private_key_openssh holds a %T, not an ed25519 key", raw)
+	}
+	message := []byte("nonce")
+	if err := keys.Verify(message, ed25519.Sign(*priv, message)); err != nil {
+		t.Errorf("the private_key_openssh signature does not verify with the nkey: %v", err)
+	}
+
+	// The encoding is stable, so it does not show a diff on each refresh
+	again, err := newStdKeys([]byte(m.Seed.ValueString()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded, err := again.privateOpenSSH(); err != nil || encoded != m.PrivateKeyOpenSSH.ValueString() {
+		t.Errorf("private_key_openssh changed when encoded again: %v", err)
+	}
+}
+
+func TestOpenSSHCurve(t *testing.T) {
+	m, _ := generateTestKeys(t, "curve")
+	if !m.PublicKeyOpenSSH.IsNull() || !m.PrivateKeyOpenSSH.IsNull() {
+		t.Errorf("the OpenSSH encodings of a curve key are %s and %s, want null", m.PublicKeyOpenSSH, m.PrivateKeyOpenSSH)
+	}
+	diags := m.warnings()
+	if diags.WarningsCount() != 1 || diags.HasError() {
+		t.Fatalf("warnings() = %v, want a single warning", diags)
+	}
+	if summary := diags[0].Summary(); summary != "no OpenSSH encoding for curve keys" {
+		t.Errorf("warning summary = %q", summary)
+	}
+
+	user, _ := generateTestKeys(t, "user")
+	if diags := user.warnings(); len(diags) != 0 {
+		t.Errorf("warnings() of a user key = %v, want none", diags)
+	}
+}
@@ -4,19 +4,23 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
-
-	"github.com/nats-io/nkeys"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ ephemeral.EphemeralResource = &NkeyEphemeral{}
+var _ ephemeral.EphemeralResourceWithConfigure = &NkeyEphemeral{}
 
 func NewNkeyEphemeral() ephemeral.EphemeralResource {
 	return &NkeyEphemeral{}
@@ -24,14 +28,16 @@ func NewNkeyEphemeral() ephemeral.EphemeralResource {
 
 // NkeyEphemeral defines the ephemeral resource implementation.
 type NkeyEphemeral struct {
+	// defaultKeyType is the type generated when type is omitted.
+	defaultKeyType string
 }
 
 // NkeyEphemeralModel describes the ephemeral resource data model.
 type NkeyEphemeralModel struct {
-	KeyType    types.String `tfsdk:"type"`
-	PublicKey  types.String `tfsdk:"public_key"`
-	PrivateKey types.String `tfsdk:"private_key"`
-	Seed       types.String `tfsdk:"seed"`
+	KeyModel
+	VanityModel
+	Keepers types.Map    `tfsdk:"keepers"`
+	Entropy types.String `tfsdk:"entropy"`
 }
 
 func (r *NkeyEphemeral) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
@@ -43,31 +49,39 @@ func (r *NkeyEphemeral) Schema(ctx context.Context, req ephemeral.SchemaRequest,
 		// This description is used by the documentation generator and the language server.
 		MarkdownDescription: "An ephemeral nkey is an ed25519 key pair formatted for use with NATS. The key pair is generated during plan/apply and is not persisted to state.",
 
-		Attributes: map[string]schema.Attribute{
+		Attributes: keyEphemeralAttributes(vanityEphemeralAttributes(map[string]schema.Attribute{
 			"type": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
-				Description: "The type of nkey to generate. Must be one of user|account|server|cluster|operator|curve",
-			},
-			"public_key": schema.StringAttribute{
-				Computed:            true,
-				MarkdownDescription: "Public key of the nkey to be given in config to the nats server",
+				Description: "The type of nkey to generate. Must be one of user|account|server|cluster|operator|curve. Defaults to the provider `default_key_type`, or account",
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive(keyTypes...),
+				},
 			},
-			"private_key": schema.StringAttribute{
-				Computed:            true,
-				MarkdownDescription: "Private key of the nkey to be given to the client for authentication",
-				Sensitive:           true,
+			"keepers": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Arbitrary map of values that is echoed back unchanged, so rotation signals can be wired through modules the same way as for the managed resources",
 			},
-			"seed": schema.StringAttribute{
-				Computed:            true,
-				MarkdownDescription: "Seed of the nkey to be given to the client for authentication",
+			"entropy": schema.StringAttribute{
+				Optional:            true,
 				Sensitive:           true,
+				MarkdownDescription: "Base64 encoded 32 bytes used as the raw seed instead of random entropy. The same entropy and type always yield the same key pair",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("vanity_prefix")),
+				},
 			},
-		},
+		})),
 	}
 }
 
+func (r *NkeyEphemeral) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	r.defaultKeyType = configuredDefaultKeyType(req.ProviderData, &resp.Diagnostics)
+}
+
 func (r *NkeyEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	ctx = redactSecrets(ctx)
+
 	var data NkeyEphemeralModel
 
 	// Read Terraform configuration data into the model
@@ -77,11 +91,37 @@ func (r *NkeyEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, res
 		return
 	}
 
-	if err := data.generateKeys(); err != nil {
-		resp.Diagnostics.AddError("generating nkey", err.Error())
+	// Ephemeral resources cannot declare schema defaults, so fill in the
+	// computed type here to make the generated key type explicit. A configured
+	// type is returned in its canonical lowercase form.
+	if data.KeyType.IsNull() {
+		data.KeyType = types.StringValue(r.defaultKeyType)
+	}
+	data.KeyType = types.StringValue(strings.ToLower(data.KeyType.ValueString()))
+
+	rr := rand.Reader
+	if !data.Entropy.IsNull() {
+		entropy, err := decodeEntropy(data.Entropy.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("entropy"), "invalid entropy", err.Error())
+			return
+		}
+		defer wipe(entropy)
+		rr = bytes.NewReader(entropy)
+	}
+
+	var err error
+	if data.hasVanityPrefix() {
+		err = data.generateVanityKeys(ctx, &data.KeyModel)
+	} else {
+		err = data.generateKeysWithRand(rr)
+	}
+	if err != nil {
+		addKeyError(&resp.Diagnostics, err)
 		return
 	}
-	tflog.Trace(ctx, "opened ephemeral nkey resource")
+	resp.Diagnostics.Append(data.warnings()...)
+	tflog.Trace(ctx, "opened ephemeral nkey resource", data.logFields())
 
 	// Save data into Terraform ephemeral result
 	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
@@ -91,46 +131,3 @@ func (r *NkeyEphemeral) Close(ctx context.Context, req ephemeral.CloseRequest, r
 	// No cleanup needed for nkeys as they are just generated values
 	tflog.Trace(ctx, "closed ephemeral nkey resource")
 }
-
-func (m *NkeyEphemeralModel) generateKeys() (err error) {
-	var keys nkeys.KeyPair
-
-	switch strings.ToLower(m.KeyType.ValueString()) {
-	case "user":
-		keys, err = nkeys.CreateUser()
-	case "account":
-		keys, err = nkeys.CreateAccount()
-	case "server":
-		keys, err = nkeys.CreateServer()
-	case "cluster":
-		keys, err = nkeys.CreateCluster()
-	case "operator":
-		keys, err = nkeys.CreateOperator()
-	case "curve":
-		keys, err = nkeys.CreateCurveKeys()
-	default:
-		keys, err = nkeys.CreateAccount()
-	}
-	if err != nil {
-		return err
-	}
-
-	pubKey, err := keys.PublicKey()
-	if err != nil {
-		return err
-	}
-	privKey, err := keys.PrivateKey()
-	if err != nil {
-		return err
-	}
-	seed, err := keys.Seed()
-	if err != nil {
-		return err
-	}
-
-	m.PublicKey = types.StringValue(pubKey)
-	m.PrivateKey = types.StringValue(string(privKey))
-	m.Seed = types.StringValue(string(seed))
-
-	return nil
-}
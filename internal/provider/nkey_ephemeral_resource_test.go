@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/base64"
+	"regexp"
+	"testing"
+
+	"github.com/nats-io/nkeys"
+)
+
+func TestNkeyEphemeralType(t *testing.T) {
+	// The ephemeral key only reaches state through the public key of a
+	// keypair created from its seed
+	config := func(keyType string) string {
+		return `
+ephemeral "nkey_nkey" "test" {
+  type = "` + keyType + `"
+}
+
+resource "nkey_keypair" "test" {
+  seed_wo         = ephemeral.nkey_nkey.test.seed
+  seed_wo_version = 1
+}
+`
+	}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			{
+				Config: config("User"),
+				Check: func(t *testing.T, state *testState) {
+					if got := state.stringAttribute(t, "nkey_keypair.test", "type"); got != "user" {
+						t.Errorf("type = %q, want user", got)
+					}
+					if publicKey := state.stringAttribute(t, "nkey_keypair.test", "public_key"); !nkeys.IsValidPublicUserKey(publicKey) {
+						t.Errorf("public_key %s is not a user public key", publicKey)
+					}
+				},
+			},
+			{
+				Config:      config(""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Attribute type value must be one of`),
+			},
+			{
+				Config:      config("usr"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Attribute type value must be one of: .*got: "usr"`),
+			},
+		},
+	})
+}
+
+func TestNkeyEphemeralEntropy(t *testing.T) {
+	config := func(entropy string) string {
+		return `
+ephemeral "nkey_nkey" "test" {
+  type    = "account"
+  entropy = "` + entropy + `"
+}
+
+resource "nkey_keypair" "test" {
+  seed_wo         = ephemeral.nkey_nkey.test.seed
+  seed_wo_version = 1
+}
+`
+	}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			{
+				Config: config(base64.StdEncoding.EncodeToString(make([]byte, 32))),
+				Check: func(t *testing.T, state *testState) {
+					const want = "AA5WUJ54Z23KILLCUOUNAKTPBVZWKMQVO4O6EQ5GHLAERIMLLHNCS47C"
+					if got := state.stringAttribute(t, "nkey_keypair.test", "public_key"); got != want {
+						t.Errorf("public_key = %s, want %s", got, want)
+					}
+				},
+			},
+			{
+				Config:      config(base64.StdEncoding.EncodeToString(make([]byte, 16))),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`the entropy must be exactly 32 bytes once decoded, got 16`),
+			},
+		},
+	})
+}
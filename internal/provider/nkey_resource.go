@@ -5,22 +5,23 @@ package provider
 
 import (
 	"context"
-	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
-
-	"github.com/nats-io/nkeys"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &Nkey{}
+var _ resource.ResourceWithConfigure = &Nkey{}
+var _ resource.ResourceWithModifyPlan = &Nkey{}
 var _ resource.ResourceWithImportState = &Nkey{}
 
 func NewNkey() resource.Resource {
@@ -29,14 +30,16 @@ func NewNkey() resource.Resource {
 
 // Nkey defines the resource implementation.
 type Nkey struct {
+	// defaultKeyType is the type generated when type is omitted.
+	defaultKeyType string
 }
 
 // NkeyModel describes the resource data model.
 type NkeyModel struct {
-	KeyType    types.String `tfsdk:"type"`
-	PublicKey  types.String `tfsdk:"public_key"`
-	PrivateKey types.String `tfsdk:"private_key"`
-	Seed       types.String `tfsdk:"seed"`
+	KeyModel
+	VanityModel
+	SeedEncryptionModel
+	Keepers types.Map `tfsdk:"keepers"`
 }
 
 func (r *Nkey) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -48,39 +51,48 @@ func (r *Nkey) Schema(ctx context.Context, req resource.SchemaRequest, resp *res
 		// This description is used by the documentation generator and the language server.
 		MarkdownDescription: "An nkey is an ed25519 key pair formatted for use with NATS.",
 
-		Attributes: map[string]schema.Attribute{
+		Attributes: keyResourceAttributes(seedEncryptionResourceAttributes(vanityResourceAttributes(map[string]schema.Attribute{
 			"type": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
-				Default:     stringdefault.StaticString("account"),
-				Description: "The type of nkey to generate. Must be one of user|account|server|cluster|operator|curve",
+				Description: "The type of nkey to generate. Must be one of user|account|server|cluster|operator|curve. Defaults to the provider `default_key_type`, or account",
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive(keyTypes...),
+				},
 				PlanModifiers: []planmodifier.String{
+					caseInsensitive(),
+					stringplanmodifier.UseStateForUnknown(),
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
-			"public_key": schema.StringAttribute{
-				Computed:            true,
-				MarkdownDescription: "Public key of the nkey to be given in config to the nats server",
-			},
-			"private_key": schema.StringAttribute{
-				Computed:            true,
-				MarkdownDescription: "Private key of the nkey to be given to the client for authentication",
-				Sensitive:           true,
-			},
-			"seed": schema.StringAttribute{
-				Computed:            true,
-				MarkdownDescription: "Seed of the nkey to be given to the client for authentication",
-				Sensitive:           true,
+			"keepers": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Arbitrary map of values that, when changed, will trigger recreation of the nkey. Adding, removing or changing any entry generates a new key pair",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
 			},
-		},
+		}))),
 	}
 }
 
 func (r *Nkey) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Nothing to do here as nkeys are simply generated
+	r.defaultKeyType = configuredDefaultKeyType(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *Nkey) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	resp.Diagnostics.Append(planDefaultKeyType(ctx, req.Config, &resp.Plan, r.defaultKeyType)...)
+	resp.Diagnostics.Append(planIncludePrivateKey(ctx, req.State, &resp.Plan)...)
+	resp.Diagnostics.Append(planFingerprintLength(ctx, req.State, &resp.Plan)...)
+	resp.Diagnostics.Append(planSeedEncryption(ctx, req.State, &resp.Plan)...)
 }
 
 func (r *Nkey) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
 	var data NkeyModel
 
 	// Read Terraform plan data into the model
@@ -90,17 +102,30 @@ func (r *Nkey) Create(ctx context.Context, req resource.CreateRequest, resp *res
 		return
 	}
 
-	if err := data.generateKeys(); err != nil {
-		resp.Diagnostics.AddError("generating nkey", err.Error())
+	var err error
+	if data.hasVanityPrefix() {
+		err = data.generateVanityKeys(ctx, &data.KeyModel)
+	} else {
+		err = data.generateKeys()
+	}
+	if err != nil {
+		addKeyError(&resp.Diagnostics, err)
 		return
 	}
-	tflog.Trace(ctx, "created nkey resource")
+	resp.Diagnostics.Append(data.encryptSeed(ctx, req.Config, data.Seed)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(data.warnings()...)
+	tflog.Trace(ctx, "created nkey resource", data.logFields())
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *Nkey) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
 	var data NkeyModel
 
 	// Read Terraform prior state data into the model
@@ -110,17 +135,43 @@ func (r *Nkey) Read(ctx context.Context, req resource.ReadRequest, resp *resourc
 		return
 	}
 
+	// A type configured in another case is stored as configured when the
+	// resource is created, so store the canonical form from now on
+	data.KeyType = canonicalKeyType(data.KeyType)
+
+	// Fill in attributes derived from the seed that older states lack
+	if err := data.deriveKeys(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "reading nkey", err.Error())
+		return
+	}
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *Nkey) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
 	var plan NkeyModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	// Only include_private_key, fingerprint_length and
+	// encryption_passphrase_wo_version change in place, so rederive the
+	// private key encodings and fingerprint and encrypt the seed again
+	if err := plan.deriveKeys(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "updating nkey", err.Error())
+		return
+	}
+	if plan.SeedEncrypted.IsUnknown() {
+		resp.Diagnostics.Append(plan.encryptSeed(ctx, req.Config, plan.Seed)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
@@ -129,51 +180,12 @@ func (r *Nkey) Update(ctx context.Context, req resource.UpdateRequest, resp *res
 }
 
 func (r *Nkey) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed
+	tflog.Trace(ctx, "deleted nkey resource")
 }
 
 func (r *Nkey) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
-
-func (m *NkeyModel) generateKeys() (err error) {
-	var keys nkeys.KeyPair
-
-	switch strings.ToLower(m.KeyType.ValueString()) {
-	case "user":
-		keys, err = nkeys.CreateUser()
-	case "account":
-		keys, err = nkeys.CreateAccount()
-	case "server":
-		keys, err = nkeys.CreateServer()
-	case "cluster":
-		keys, err = nkeys.CreateCluster()
-	case "operator":
-		keys, err = nkeys.CreateOperator()
-	case "curve":
-		keys, err = nkeys.CreateCurveKeys()
-	default:
-		keys, err = nkeys.CreateAccount()
-	}
-	if err != nil {
-		return err
-	}
-
-	pubKey, err := keys.PublicKey()
-	if err != nil {
-		return err
-	}
-	privKey, err := keys.PrivateKey()
-	if err != nil {
-		return err
-	}
-	seed, err := keys.Seed()
-	if err != nil {
-		return err
-	}
-
-	m.PublicKey = types.StringValue(pubKey)
-	m.PrivateKey = types.StringValue(string(privKey))
-	m.Seed = types.StringValue(string(seed))
-
-	return nil
-}
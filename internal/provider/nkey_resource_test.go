@@ -0,0 +1,111 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestResourceKeepers(t *testing.T) {
+	for _, resourceType := range []string{"nkey_nkey", "nkey_keypair"} {
+		t.Run(resourceType, func(t *testing.T) {
+			address := resourceType + ".test"
+			config := func(keepers string) string {
+				return `
+resource "` + resourceType + `" "test" {
+  type = "user"
+
+  keepers = {` + keepers + `}
+}
+`
+			}
+			var publicKey string
+			// step checks whether the key is replaced, and that the public key
+			// changes exactly when it is
+			step := func(keepers string, replaced bool) testStep {
+				return testStep{
+					Config: config(keepers),
+					PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+						switch {
+						case publicKey == "":
+							expectActions(t, plan, address, tfjson.ActionCreate)
+						case replaced:
+							expectActions(t, plan, address, tfjson.ActionDelete, tfjson.ActionCreate)
+						default:
+							expectActions(t, plan, address, tfjson.ActionNoop)
+						}
+					},
+					Check: func(t *testing.T, state *testState) {
+						got := state.stringAttribute(t, address, "public_key")
+						if (got != publicKey) != replaced {
+							t.Errorf("public_key changed from %s to %s, expected the key to be replaced: %v", publicKey, got, replaced)
+						}
+						publicKey = got
+					},
+				}
+			}
+			unitTest(t, testCase{
+				Steps: []testStep{
+					step(`a = "1", c = "3"`, true),
+					// Adding an entry
+					step(`a = "1", b = "2", c = "3"`, true),
+					// Reordering the entries
+					step(`c = "3", b = "2", a = "1"`, false),
+					// Removing an entry
+					step(`a = "1", b = "2"`, true),
+					// Changing a value
+					step(`a = "1", b = "20"`, true),
+				},
+			})
+		})
+	}
+}
+
+func TestNkeyResourceFingerprintLength(t *testing.T) {
+	config := func(length int) string {
+		return `
+resource "nkey_nkey" "test" {
+  fingerprint_length = ` + strconv.Itoa(length) + `
+}
+`
+	}
+	var fp string
+	// step checks that the fingerprint is truncated to length, in place
+	step := func(length int) testStep {
+		return testStep{
+			Config: config(length),
+			Check: func(t *testing.T, state *testState) {
+				got := state.stringAttribute(t, "nkey_nkey.test", "fingerprint")
+				short := state.stringAttribute(t, "nkey_nkey.test", "fingerprint_short")
+				if len(got) != length || len(short) != fingerprintShortLen || got[:fingerprintShortLen] != short {
+					t.Errorf("fingerprint_length = %d: fingerprint = %q, fingerprint_short = %q", length, got, short)
+				}
+				if fp != "" && got[:fingerprintShortLen] != fp[:fingerprintShortLen] {
+					t.Errorf("fingerprint changed from %q to %q", fp, got)
+				}
+				fp = got
+			},
+		}
+	}
+	unitTest(t, testCase{
+		Steps: []testStep{
+			step(8),
+			step(64),
+			{
+				Config:      config(7),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Attribute fingerprint_length value must be between 8 and 64, got: 7`),
+			},
+			{
+				Config:      config(65),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Attribute fingerprint_length value must be between 8 and 64, got: 65`),
+			},
+		},
+	})
+}
@@ -0,0 +1,187 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/nkeys"
+)
+
+func TestGenerateKeysType(t *testing.T) {
+	tests := []struct {
+		keyType string
+		prefix  nkeys.PrefixByte
+		wantErr bool
+	}{
+		{keyType: "user", prefix: nkeys.PrefixByteUser},
+		{keyType: "User", prefix: nkeys.PrefixByteUser},
+		{keyType: "ACCOUNT", prefix: nkeys.PrefixByteAccount},
+		{keyType: "sErVeR", prefix: nkeys.PrefixByteServer},
+		{keyType: "Cluster", prefix: nkeys.PrefixByteCluster},
+		{keyType: "OPERATOR", prefix: nkeys.PrefixByteOperator},
+		{keyType: "Curve", prefix: nkeys.PrefixByteCurve},
+		{keyType: "", wantErr: true},
+		{keyType: "usr", wantErr: true},
+		{keyType: " user", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keyType, func(t *testing.T) {
+			m := KeyModel{KeyType: types.StringValue(tt.keyType)}
+			err := m.generateKeys()
+			if tt.wantErr {
+				if !errors.Is(err, errUnsupportedKeyType) {
+					t.Fatalf("generateKeys() error = %v, want %v", err, errUnsupportedKeyType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("generateKeys() error = %v", err)
+			}
+			if got := nkeys.Prefix(m.PublicKey.ValueString()); got != tt.prefix {
+				t.Errorf("public key prefix = %v, want %v", got, tt.prefix)
+			}
+		})
+	}
+}
+
+func TestGenerateKeysWithEntropy(t *testing.T) {
+	entropy := make([]byte, entropyLen)
+	generate := func(keyType string) string {
+		t.Helper()
+		m := KeyModel{KeyType: types.StringValue(keyType)}
+		if err := m.generateKeysWithRand(bytes.NewReader(entropy)); err != nil {
+			t.Fatalf("generateKeysWithRand() error = %v", err)
+		}
+		return m.PublicKey.ValueString()
+	}
+
+	const want = "AA5WUJ54Z23KILLCUOUNAKTPBVZWKMQVO4O6EQ5GHLAERIMLLHNCS47C"
+	if got := generate("account"); got != want {
+		t.Errorf("public key = %s, want %s", got, want)
+	}
+	if got := generate("ACCOUNT"); got != want {
+		t.Errorf("public key of the same entropy = %s, want %s", got, want)
+	}
+	if got := generate("user"); got == want || !nkeys.IsValidPublicUserKey(got) {
+		t.Errorf("public key of a user = %s", got)
+	}
+}
+
+func TestDecodeEntropy(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr string
+	}{
+		{name: "valid", encoded: base64.StdEncoding.EncodeToString(make([]byte, 32))},
+		{name: "short", encoded: base64.StdEncoding.EncodeToString(make([]byte, 31)), wantErr: "must be exactly 32 bytes once decoded, got 31"},
+		{name: "long", encoded: base64.StdEncoding.EncodeToString(make([]byte, 64)), wantErr: "must be exactly 32 bytes once decoded, got 64"},
+		{name: "empty", encoded: "", wantErr: "must be exactly 32 bytes once decoded, got 0"},
+		{name: "not base64", encoded: "not base64!", wantErr: "not valid base64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entropy, err := decodeEntropy(tt.encoded)
+			if tt.wantErr == "" {
+				if err != nil || len(entropy) != entropyLen {
+					t.Fatalf("decodeEntropy() = %d bytes, %v", len(entropy), err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("decodeEntropy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetKeysIncludePrivateKey(t *testing.T) {
+	for _, keyType := range []string{"user", "curve"} {
+		t.Run(keyType, func(t *testing.T) {
+			for _, include := range []bool{true, false} {
+				m := KeyModel{KeyType: types.StringValue(keyType), IncludePrivateKey: types.BoolValue(include)}
+				if err := m.generateKeys(); err != nil {
+					t.Fatalf("generateKeys() error = %v", err)
+				}
+				private := map[string]types.String{
+					"private_key":            m.PrivateKey,
+					"private_key_raw_base64": m.PrivateKeyRawBase64,
+					"private_key_pem":        m.PrivateKeyPEM,
+					"private_key_jwk":        m.PrivateKeyJWK,
+				}
+				if keyType != "curve" {
+					private["private_key_openssh"] = m.PrivateKeyOpenSSH
+				} else if !m.PrivateKeyOpenSSH.IsNull() {
+					t.Error("private_key_openssh of a curve key is set")
+				}
+				for name, value := range private {
+					if value.IsNull() == include {
+						t.Errorf("include_private_key = %v: %s is %s", include, name, value)
+					}
+				}
+				if m.Seed.IsNull() || m.PublicKey.IsNull() || m.PublicKeyPEM.IsNull() || m.PublicKeyJWK.IsNull() {
+					t.Errorf("include_private_key = %v: the seed and public encodings must be set", include)
+				}
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	// The keys of 32 zero bytes of entropy, whose fingerprints are the SHA-256
+	// of the raw public key bytes
+	tests := []struct {
+		publicKey string
+		want      string
+	}{
+		{
+			publicKey: "AA5WUJ54Z23KILLCUOUNAKTPBVZWKMQVO4O6EQ5GHLAERIMLLHNCS47C",
+			want:      "139e3940e64b5491722088d9a0d741628fc826e09475d341a780acde3c4b8070",
+		},
+		// The fingerprint only depends on the raw key, not on its prefix
+		{
+			publicKey: "UA5WUJ54Z23KILLCUOUNAKTPBVZWKMQVO4O6EQ5GHLAERIMLLHNCTYM5",
+			want:      "139e3940e64b5491722088d9a0d741628fc826e09475d341a780acde3c4b8070",
+		},
+		{
+			publicKey: "XAX6K7NDI7GWEQYVFDNKYX53FEDTB77WQSX4JT6C5WIJSX2YZM5XIVNJ",
+			want:      "233ced774423c7a7f91254bd2346d889e2ccab33cb6c98947844f1ef6c4f493f",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.publicKey, func(t *testing.T) {
+			got, err := fingerprint(tt.publicKey)
+			if err != nil || got != tt.want {
+				t.Fatalf("fingerprint() = %q, %v, want %q", got, err, tt.want)
+			}
+
+			for _, length := range []int64{8, 20, 64} {
+				m := KeyModel{PublicKey: types.StringValue(tt.publicKey), FingerprintLength: types.Int64Value(length)}
+				if err := m.setPublicAttributes(); err != nil {
+					t.Fatalf("setPublicAttributes() error = %v", err)
+				}
+				if got := m.Fingerprint.ValueString(); got != tt.want[:length] {
+					t.Errorf("fingerprint_length = %d: fingerprint = %q, want %q", length, got, tt.want[:length])
+				}
+				if got := m.FingerprintShort.ValueString(); got != tt.want[:8] {
+					t.Errorf("fingerprint_short = %q, want %q", got, tt.want[:8])
+				}
+			}
+			m := KeyModel{PublicKey: types.StringValue(tt.publicKey), FingerprintLength: types.Int64Null()}
+			if err := m.setPublicAttributes(); err != nil || m.Fingerprint.ValueString() != tt.want {
+				t.Errorf("fingerprint without fingerprint_length = %q, %v, want %q", m.Fingerprint.ValueString(), err, tt.want)
+			}
+		})
+	}
+
+	if _, err := fingerprint("AA5WUJ54Z23KILLCUOUNAKTPBVZWKMQVO4O6EQ5GHLAERIMLLHNCS47D"); err == nil {
+		t.Error("fingerprint() of a public key with a bad checksum succeeded")
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &OpenXkeyFunction{}
+
+func NewOpenXkeyFunction() function.Function {
+	return &OpenXkeyFunction{}
+}
+
+// OpenXkeyFunction defines the function implementation.
+type OpenXkeyFunction struct {
+}
+
+func (f *OpenXkeyFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "open_xkey"
+}
+
+func (f *OpenXkeyFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Decrypt a payload sealed to a curve (xkey) key",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Decrypts a payload sealed to the curve key of `recipient_seed` by `sender_public_key`, e.g. with the `nkey_xkey_seal` ephemeral resource or the nkeys package. Function results cannot be marked sensitive, so wrap the result in `sensitive()` or use the `nkey_xkey_open` data source, whose `plaintext` is sensitive.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "ciphertext_base64",
+				MarkdownDescription: "Base64 encoded sealed payload",
+			},
+			function.StringParameter{
+				Name:                "recipient_seed",
+				MarkdownDescription: "Curve seed of the recipient, starting with `SX`",
+			},
+			function.StringParameter{
+				Name:                "sender_public_key",
+				MarkdownDescription: "Curve public key of the sender, starting with `X`",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *OpenXkeyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var ciphertext, seed, sender string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &ciphertext, &seed, &sender))
+	if resp.Error != nil {
+		return
+	}
+
+	plaintext, err := openXkey(ciphertext, seed, sender)
+	if err != nil {
+		var openErr *xkeyOpenError
+		if errors.As(err, &openErr) {
+			resp.Error = function.NewArgumentFuncError(int64(openErr.arg), openErr.Error())
+			return
+		}
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+	defer wipe(plaintext)
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, string(plaintext)))
+}
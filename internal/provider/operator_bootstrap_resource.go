@@ -0,0 +1,405 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &OperatorBootstrap{}
+var _ resource.ResourceWithModifyPlan = &OperatorBootstrap{}
+
+func NewOperatorBootstrap() resource.Resource {
+	return &OperatorBootstrap{}
+}
+
+// OperatorBootstrap defines the resource implementation.
+type OperatorBootstrap struct {
+}
+
+// OperatorBootstrapModel describes the resource data model.
+type OperatorBootstrapModel struct {
+	CredsSealModel
+	Name                   types.String `tfsdk:"name"`
+	SystemAccountName      types.String `tfsdk:"system_account_name"`
+	SystemUserName         types.String `tfsdk:"system_user_name"`
+	RotateOperator         types.String `tfsdk:"rotate_operator"`
+	RotateSystemAccount    types.String `tfsdk:"rotate_system_account"`
+	RotateSystemUser       types.String `tfsdk:"rotate_system_user"`
+	OperatorSeed           types.String `tfsdk:"operator_seed"`
+	OperatorPublicKey      types.String `tfsdk:"operator_public_key"`
+	OperatorJWT            types.String `tfsdk:"operator_jwt"`
+	SystemAccountSeed      types.String `tfsdk:"system_account_seed"`
+	SystemAccountPublicKey types.String `tfsdk:"system_account_public_key"`
+	SystemAccountJWT       types.String `tfsdk:"system_account_jwt"`
+	SystemUserSeed         types.String `tfsdk:"system_user_seed"`
+	SystemUserPublicKey    types.String `tfsdk:"system_user_public_key"`
+	SystemUserJWT          types.String `tfsdk:"system_user_jwt"`
+	SystemUserCreds        types.String `tfsdk:"system_user_creds"`
+	ResolverPreload        types.Map    `tfsdk:"resolver_preload"`
+}
+
+// bootstrapDependents maps each configurable attribute to the computed
+// attributes that are generated again when it changes. A new key also means a
+// new JWT for it and for everything it signs, so only the pieces that depend
+// on a change are replaced.
+var bootstrapDependents = map[string][]string{
+	"name":                  {"operator_jwt"},
+	"system_account_name":   {"system_account_jwt", "resolver_preload"},
+	"system_user_name":      {"system_user_jwt", "system_user_creds"},
+	"rotate_operator":       {"operator_seed", "operator_public_key", "operator_jwt", "system_account_jwt", "resolver_preload"},
+	"rotate_system_account": {"system_account_seed", "system_account_public_key", "system_account_jwt", "operator_jwt", "system_user_jwt", "system_user_creds", "resolver_preload"},
+	"rotate_system_user":    {"system_user_seed", "system_user_public_key", "system_user_jwt", "system_user_creds"},
+}
+
+func (r *OperatorBootstrap) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_operator_bootstrap"
+}
+
+func (r *OperatorBootstrap) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "An operator bootstrap generates everything a NATS deployment in operator mode starts with: the operator nkey and JWT, the system account nkey and JWT signed by the operator, and a system user nkey, JWT and creds file signed by the system account. Each piece is a separate attribute, so it can be stored in a different secret backend, and each key can be rotated on its own with the `rotate_*` attributes, which issues again only the JWTs that depend on it.",
+
+		Attributes: credsSealResourceAttributes(map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the operator",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"system_account_name": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Name of the system account. Defaults to `SYS`",
+				Default:             stringdefault.StaticString("SYS"),
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"system_user_name": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Name of the system user. Defaults to `sys`",
+				Default:             stringdefault.StaticString("sys"),
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"rotate_operator": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Arbitrary value that, when changed, generates a new operator nkey. The operator and system account JWTs are issued again",
+			},
+			"rotate_system_account": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Arbitrary value that, when changed, generates a new system account nkey. The operator, system account and system user JWTs are issued again",
+			},
+			"rotate_system_user": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Arbitrary value that, when changed, generates a new system user nkey, JWT and creds file",
+			},
+			"operator_seed":             bootstrapSeedAttribute("operator"),
+			"operator_public_key":       bootstrapComputedAttribute("Public key of the operator nkey"),
+			"operator_jwt":              bootstrapComputedAttribute("Self-signed operator JWT, with the system account set"),
+			"system_account_seed":       bootstrapSeedAttribute("system account"),
+			"system_account_public_key": bootstrapComputedAttribute("Public key of the system account nkey"),
+			"system_account_jwt":        bootstrapComputedAttribute("System account JWT signed by the operator nkey"),
+			"system_user_seed":          bootstrapSeedAttribute("system user"),
+			"system_user_public_key":    bootstrapComputedAttribute("Public key of the system user nkey"),
+			"system_user_jwt":           bootstrapComputedAttribute("System user JWT signed by the system account nkey"),
+			"system_user_creds": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Creds file of the system user, with its JWT and seed, e.g. for `nats --creds`. Null when `seal_only` is set",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"resolver_preload": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Account JWTs by public key for the `resolver_preload` block of the nats-server configuration of a memory resolver",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+		}, "Whether to null `system_user_creds`, so that the creds of the system user are only output sealed in `creds_sealed`. The seeds are still stored in state."),
+	}
+}
+
+// bootstrapSeedAttribute returns the schema attribute of the seed of the
+// named nkey of the bootstrap.
+func bootstrapSeedAttribute(name string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Computed:            true,
+		Sensitive:           true,
+		MarkdownDescription: "Seed of the " + name + " nkey",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+}
+
+// bootstrapComputedAttribute returns the schema attribute of a public part of
+// the bootstrap.
+func bootstrapComputedAttribute(description string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: description,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+}
+
+func (r *OperatorBootstrap) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	if req.Plan.Raw.IsNull() {
+		// The resource is being destroyed
+		return
+	}
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(planBootstrapDependents(ctx, req.State, &resp.Plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.Diagnostics.Append(planSealedCreds(ctx, req.State, &resp.Plan, path.Root("system_user_jwt"), path.Root("system_user_creds"))...)
+}
+
+// planBootstrapDependents plans the computed attributes that depend on a
+// changed attribute as unknown.
+func planBootstrapDependents(ctx context.Context, state tfsdk.State, plan *tfsdk.Plan) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for attribute, dependents := range bootstrapDependents {
+		var prior, planned types.String
+		diags.Append(state.GetAttribute(ctx, path.Root(attribute), &prior)...)
+		diags.Append(plan.GetAttribute(ctx, path.Root(attribute), &planned)...)
+		if diags.HasError() {
+			return diags
+		}
+		if prior.Equal(planned) {
+			continue
+		}
+
+		for _, dependent := range dependents {
+			var unknown attr.Value = types.StringUnknown()
+			if dependent == "resolver_preload" {
+				unknown = types.MapUnknown(types.StringType)
+			}
+			diags.Append(plan.SetAttribute(ctx, path.Root(dependent), unknown)...)
+		}
+	}
+	return diags
+}
+
+func (r *OperatorBootstrap) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data OperatorBootstrapModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.bootstrap(ctx)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created operator bootstrap resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *OperatorBootstrap) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data OperatorBootstrapModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The keys never change outside of Terraform, so the only thing to check
+	// is that the stored seeds still derive the stored public keys.
+	for seedAttribute, pair := range map[string][2]types.String{
+		"operator_seed":       {data.OperatorSeed, data.OperatorPublicKey},
+		"system_account_seed": {data.SystemAccountSeed, data.SystemAccountPublicKey},
+		"system_user_seed":    {data.SystemUserSeed, data.SystemUserPublicKey},
+	} {
+		pubKey, _, err := publicKeyFromSeed([]byte(pair[0].ValueString()))
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root(seedAttribute), "corrupted operator bootstrap state", "The stored seed could not be decoded: "+err.Error())
+			continue
+		}
+		if pubKey != pair[1].ValueString() {
+			resp.Diagnostics.AddAttributeError(path.Root(seedAttribute), "corrupted operator bootstrap state", "The stored public key does not match the public key derived from the stored seed ("+pubKey+").")
+		}
+	}
+}
+
+func (r *OperatorBootstrap) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	// ModifyPlan planned the pieces that depend on a change as unknown, and
+	// those are the only ones generated again.
+	var plan OperatorBootstrapModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(plan.bootstrap(ctx)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *OperatorBootstrap) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed
+	tflog.Trace(ctx, "deleted operator bootstrap resource")
+}
+
+// bootstrap generates the keys and issues the JWTs that are unknown in the
+// model, keys first so that the JWTs are signed with the current ones.
+func (m *OperatorBootstrapModel) bootstrap(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, key := range []struct {
+		prefix          nkeys.PrefixByte
+		seed, publicKey *types.String
+	}{
+		{nkeys.PrefixByteOperator, &m.OperatorSeed, &m.OperatorPublicKey},
+		{nkeys.PrefixByteAccount, &m.SystemAccountSeed, &m.SystemAccountPublicKey},
+		{nkeys.PrefixByteUser, &m.SystemUserSeed, &m.SystemUserPublicKey},
+	} {
+		if !key.seed.IsUnknown() {
+			continue
+		}
+		seed, pubKey, err := createBootstrapKey(key.prefix)
+		if err != nil {
+			diags.AddError("generating nkey", "The "+keyTypeNames[key.prefix]+" nkey could not be generated: "+err.Error())
+			return diags
+		}
+		*key.seed = types.StringValue(seed)
+		*key.publicKey = types.StringValue(pubKey)
+	}
+
+	if m.OperatorJWT.IsUnknown() {
+		claims := jwt.NewOperatorClaims(m.OperatorPublicKey.ValueString())
+		claims.Name = m.Name.ValueString()
+		claims.SystemAccount = m.SystemAccountPublicKey.ValueString()
+		diags.Append(signBootstrapJWT(claims, m.OperatorSeed, &m.OperatorJWT)...)
+	}
+	if m.SystemAccountJWT.IsUnknown() {
+		claims := jwt.NewAccountClaims(m.SystemAccountPublicKey.ValueString())
+		claims.Name = m.SystemAccountName.ValueString()
+		diags.Append(signBootstrapJWT(claims, m.OperatorSeed, &m.SystemAccountJWT)...)
+	}
+	if m.SystemUserJWT.IsUnknown() {
+		claims := jwt.NewUserClaims(m.SystemUserPublicKey.ValueString())
+		claims.Name = m.SystemUserName.ValueString()
+		diags.Append(signBootstrapJWT(claims, m.SystemAccountSeed, &m.SystemUserJWT)...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	if m.SystemUserCreds.IsUnknown() || m.CredsSealed.IsUnknown() {
+		creds, err := jwt.FormatUserConfig(m.SystemUserJWT.ValueString(), []byte(m.SystemUserSeed.ValueString()))
+		if err != nil {
+			diags.AddAttributeError(path.Root("system_user_creds"), "formatting creds", err.Error())
+			return diags
+		}
+		diags.Append(m.seal(creds)...)
+		m.SystemUserCreds = types.StringValue(string(creds))
+		if m.sealOnly() {
+			m.SystemUserCreds = types.StringNull()
+		}
+		wipe(creds)
+		if diags.HasError() {
+			return diags
+		}
+	}
+	if m.ResolverPreload.IsUnknown() {
+		preload, preloadDiags := types.MapValueFrom(ctx, types.StringType, map[string]string{
+			m.SystemAccountPublicKey.ValueString(): m.SystemAccountJWT.ValueString(),
+		})
+		diags.Append(preloadDiags...)
+		m.ResolverPreload = preload
+	}
+	return diags
+}
+
+// createBootstrapKey generates a new nkey with prefix and returns its seed and
+// public key.
+func createBootstrapKey(prefix nkeys.PrefixByte) (string, string, error) {
+	keys, err := nkeys.CreatePair(prefix)
+	if err != nil {
+		return "", "", err
+	}
+	defer keys.Wipe()
+
+	seed, err := keys.Seed()
+	if err != nil {
+		return "", "", err
+	}
+	defer wipe(seed)
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		return "", "", err
+	}
+	return string(seed), pubKey, nil
+}
+
+// signBootstrapJWT encodes claims signed with seed into token.
+func signBootstrapJWT(claims jwt.Claims, seed types.String, token *types.String) diag.Diagnostics {
+	keys, err := nkeys.FromSeed([]byte(seed.ValueString()))
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("issuing JWT", "The signing seed could not be decoded: "+seedError(err).Error())
+		return diags
+	}
+	defer keys.Wipe()
+
+	encoded, diags := encodeJWT(claims, keys)
+	if !diags.HasError() {
+		*token = types.StringValue(encoded)
+	}
+	return diags
+}
@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/nats-io/jwt/v2"
+)
+
+func TestOperatorBootstrapResourceSealCreds(t *testing.T) {
+	config := func(body string) string {
+		return `
+resource "nkey_operator_bootstrap" "test" {
+  name = "test"
+` + body + `
+}
+`
+	}
+	var sealed string
+	// step checks the planned action and the creds of the system user, which
+	// are only sealed again when sealedAgain is set.
+	step := func(body string, action tfjson.Action, sealOnly, sealedAgain bool) testStep {
+		return testStep{
+			Config: config(body),
+			PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+				expectActions(t, plan, "nkey_operator_bootstrap.test", action)
+			},
+			Check: func(t *testing.T, state *testState) {
+				const address = "nkey_operator_bootstrap.test"
+				creds, err := jwt.FormatUserConfig(state.stringAttribute(t, address, "system_user_jwt"), []byte(state.stringAttribute(t, address, "system_user_seed")))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got, _ := state.attribute(t, address, "system_user_creds").(string)
+				if sealOnly != (got == "") || (!sealOnly && got != string(creds)) {
+					t.Errorf("seal_only = %v: system_user_creds = %q", sealOnly, got)
+				}
+
+				got, _ = state.attribute(t, address, "creds_sealed").(string)
+				if (got != sealed) != sealedAgain {
+					t.Errorf("creds_sealed changed from %q to %q, expected it to change: %v", sealed, got, sealedAgain)
+				}
+				sealed = got
+				if sealed == "" {
+					return
+				}
+				opened, err := openXkeyEnvelope(sealed, testCurveSeed)
+				if err != nil || string(opened) != string(creds) {
+					t.Errorf("creds_sealed opens to %q, %v, want the creds", opened, err)
+				}
+			},
+		}
+	}
+	const sealTo = `  seal_to_xkey = "` + testCurvePublicKey + `"`
+	unitTest(t, testCase{
+		Steps: []testStep{
+			step(sealTo+"\n  seal_only    = true", tfjson.ActionCreate, true, true),
+			// Outputting the plaintext creds too does not seal them again
+			step(sealTo, tfjson.ActionUpdate, false, false),
+			// Neither does issuing the operator JWT again
+			step(sealTo+"\n  rotate_operator = \"1\"", tfjson.ActionUpdate, false, false),
+			// A new system user comes with new creds, which are sealed again
+			step(sealTo+"\n  rotate_operator = \"1\"\n  rotate_system_user = \"1\"", tfjson.ActionUpdate, false, true),
+			step("", tfjson.ActionUpdate, false, true),
+		},
+	})
+}
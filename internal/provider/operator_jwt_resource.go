@@ -0,0 +1,335 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &OperatorJWT{}
+var _ resource.ResourceWithModifyPlan = &OperatorJWT{}
+var _ resource.ResourceWithValidateConfig = &OperatorJWT{}
+var _ resource.ResourceWithConfigValidators = &OperatorJWT{}
+
+func NewOperatorJWT() resource.Resource {
+	return &OperatorJWT{}
+}
+
+// OperatorJWT defines the resource implementation.
+type OperatorJWT struct {
+}
+
+// OperatorJWTModel describes the resource data model.
+type OperatorJWTModel struct {
+	JWTModel
+	Subject               types.String `tfsdk:"subject"`
+	SigningSeed           types.String `tfsdk:"signing_seed"`
+	Name                  types.String `tfsdk:"name"`
+	Tags                  types.Set    `tfsdk:"tags"`
+	SigningKeys           types.Set    `tfsdk:"signing_keys"`
+	SystemAccount         types.String `tfsdk:"system_account"`
+	AccountServerURL      types.String `tfsdk:"account_server_url"`
+	OperatorServiceURLs   types.Set    `tfsdk:"operator_service_urls"`
+	StrictSigningKeyUsage types.Bool   `tfsdk:"strict_signing_key_usage"`
+	AdditionalClaimsJSON  types.String `tfsdk:"additional_claims_json"`
+}
+
+func (r *OperatorJWT) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_operator_jwt"
+}
+
+func (r *OperatorJWT) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "An operator JWT is the self-signed token that makes an operator nkey the root of trust of a NATS deployment. The token is kept in state and only issued again when one of its claims changes.",
+
+		Attributes: jwtResourceAttributes(map[string]schema.Attribute{
+			"subject": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key of the operator nkey. Changing it replaces the resource",
+				Validators: []validator.String{
+					isPublicKeyOfType("operator"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"signing_seed": schema.StringAttribute{
+				Required:            true,
+				WriteOnly:           true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the operator nkey of `subject`, which signs its own JWT." + signingSeedDescription,
+				Validators: []validator.String{
+					isSeedOfType("operator"),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the operator",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"tags": jwtTagsAttribute(),
+			"signing_keys": schema.SetAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Public keys of the operator signing keys, which sign the account JWTs so the operator nkey itself can be kept offline. Changing them issues the JWT again in place",
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(isPublicKeyOfType("operator")),
+				},
+			},
+			"system_account": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Public key of the system account, which nats-server uses for monitoring and account updates",
+				Validators: []validator.String{
+					isPublicKeyOfType("account"),
+				},
+			},
+			"account_server_url": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "URL of the account server that tools push account JWTs to and fetch them from, e.g. `nats://host:4222`",
+				Validators: []validator.String{
+					isURL(),
+				},
+			},
+			"operator_service_urls": schema.SetAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "URLs of the servers of the operator that tools connect to, each a `nats://` or `tls://` URL",
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(isURL("nats", "tls")),
+				},
+			},
+			"strict_signing_key_usage": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether nats-server only accepts account and user JWTs signed by signing keys rather than identity keys. Defaults to false",
+			},
+			"additional_claims_json": additionalClaimsAttribute(),
+		}),
+	}
+}
+
+func (r *OperatorJWT) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return jwtConfigValidators()
+}
+
+func (r *OperatorJWT) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data OperatorJWTModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.validateLifetime()...)
+	if !data.AdditionalClaimsJSON.IsNull() && claimsConfigKnown(req.Config) {
+		claims, diags := data.claims(ctx)
+		resp.Diagnostics.Append(diags...)
+		if !diags.HasError() {
+			resp.Diagnostics.Append(checkAdditionalClaims(claims, jwt.NewOperatorClaims(data.Subject.ValueString()), data.AdditionalClaimsJSON)...)
+		}
+	}
+	if data.Subject.IsUnknown() || data.Subject.IsNull() {
+		return
+	}
+	for _, key := range data.SigningKeys.Elements() {
+		if key.Equal(data.Subject) {
+			resp.Diagnostics.AddAttributeWarning(path.Root("signing_keys"), "operator key used as signing key", "The operator nkey "+data.Subject.ValueString()+" is also listed as a signing key. Signing keys exist so that the operator nkey can be kept offline, which this defeats.")
+		}
+	}
+	if data.StrictSigningKeyUsage.ValueBool() && !data.SigningKeys.IsUnknown() && len(data.SigningKeys.Elements()) == 0 {
+		resp.Diagnostics.AddAttributeWarning(path.Root("strict_signing_key_usage"), "no signing keys", "Strict signing key usage is enabled but signing_keys is empty, so no account JWT signed by this operator will be accepted.")
+	}
+	if data.SigningSeed.IsUnknown() || data.SigningSeed.IsNull() {
+		return
+	}
+	if err := checkSelfSigned(data.SigningSeed.ValueString(), data.Subject.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("signing_seed"), "signing seed mismatch", "The seed does not belong to "+data.Subject.ValueString()+": "+err.Error()+".")
+	}
+}
+
+func (r *OperatorJWT) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	resp.Diagnostics.Append(planJWTReissue(ctx, req.Config, req.State, &resp.Plan)...)
+	if resp.Diagnostics.HasError() || req.State.Raw.IsNull() || resp.Plan.Raw.IsNull() || !claimsConfigKnown(req.Config) {
+		return
+	}
+
+	var plan, state OperatorJWTModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Claims that cannot be built are reported when the JWT is issued
+	if claims, diags := plan.issuedClaims(ctx); !diags.HasError() {
+		resp.Diagnostics.Append(planJWTUnchanged(ctx, &resp.Plan, &plan.JWTModel, &state.JWTModel, claims)...)
+	}
+}
+
+func (r *OperatorJWT) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data OperatorJWTModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.issue(ctx, req.Config, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created operator JWT resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *OperatorJWT) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data OperatorJWTModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The token never changes outside of Terraform, so the only thing to
+	// check is that the stored token still decodes to the stored subject.
+	claims, err := jwt.DecodeOperatorClaims(data.JWT.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted operator JWT state", "The stored JWT could not be decoded: "+err.Error())
+		return
+	}
+	if claims.Subject != data.Subject.ValueString() {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted operator JWT state", "The stored JWT is issued to "+claims.Subject+" rather than the stored subject.")
+		return
+	}
+
+	// Tags configured in another case are stored as configured when the JWT
+	// is issued, so store the lowercase form from now on
+	data.Tags = canonicalTags(data.Tags)
+	if data.ExpiryWarning.IsNull() {
+		data.ExpiryWarning = types.StringValue(defaultExpiryWarning)
+	}
+	if err := data.setToken(data.JWT.ValueString(), &claims.ClaimsData); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted operator JWT state", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(data.expiryWarnings(time.Now())...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *OperatorJWT) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Every change but one of subject or expiry_warning is a change of the
+	// claims, so the token is issued again in place when ModifyPlan planned it
+	// as unknown.
+	var plan OperatorJWTModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.JWT.IsUnknown() {
+		resp.Diagnostics.Append(r.issue(ctx, req.Config, &plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *OperatorJWT) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed
+	tflog.Trace(ctx, "deleted operator JWT resource")
+}
+
+// issue encodes the claims of data into its JWT, signed with the write-only
+// signing seed read from config.
+func (r *OperatorJWT) issue(ctx context.Context, config tfsdk.Config, data *OperatorJWTModel) diag.Diagnostics {
+	diags := config.GetAttribute(ctx, path.Root("signing_seed"), &data.SigningSeed)
+	if diags.HasError() {
+		return diags
+	}
+	defer func() { data.SigningSeed = types.StringNull() }()
+
+	if err := checkSelfSigned(data.SigningSeed.ValueString(), data.Subject.ValueString()); err != nil {
+		diags.AddAttributeError(path.Root("signing_seed"), "signing seed mismatch", "The seed does not belong to "+data.Subject.ValueString()+": "+err.Error()+".")
+		return diags
+	}
+
+	claims, claimsDiags := data.issuedClaims(ctx)
+	diags.Append(claimsDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	diags.Append(data.issueJWT(claims, data.SigningSeed.ValueString())...)
+	return diags
+}
+
+// claims returns the claims of the attributes of the model.
+func (m *OperatorJWTModel) claims(ctx context.Context) (*jwt.OperatorClaims, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	claims := jwt.NewOperatorClaims(m.Subject.ValueString())
+	claims.Name = m.Name.ValueString()
+	addTags(m.Tags, &claims.Tags)
+	diags.Append(addSorted(ctx, m.SigningKeys, &claims.SigningKeys)...)
+	diags.Append(addSorted(ctx, m.OperatorServiceURLs, &claims.OperatorServiceURLs)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+	claims.SystemAccount = m.SystemAccount.ValueString()
+	claims.AccountServerURL = m.AccountServerURL.ValueString()
+	claims.StrictSigningKeyUsage = m.StrictSigningKeyUsage.ValueBool()
+	return claims, diags
+}
+
+// issuedClaims returns the claims the JWT is issued with, the claims of the
+// attributes with the additional claims, but for its lifetime.
+func (m *OperatorJWTModel) issuedClaims(ctx context.Context) (*jwt.OperatorClaims, diag.Diagnostics) {
+	claims, diags := m.claims(ctx)
+	if diags.HasError() {
+		return nil, diags
+	}
+	diags.Append(addAdditionalClaims(claims, jwt.NewOperatorClaims(m.Subject.ValueString()), m.AdditionalClaimsJSON)...)
+	return claims, diags
+}
@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ParseCredsFunction{}
+
+func NewParseCredsFunction() function.Function {
+	return &ParseCredsFunction{}
+}
+
+// ParseCredsFunction defines the function implementation.
+type ParseCredsFunction struct {
+}
+
+// parseCredsAttrTypes are the attribute types of the parse_creds result.
+var parseCredsAttrTypes = map[string]attr.Type{
+	"jwt":  types.StringType,
+	"seed": types.StringType,
+}
+
+func (f *ParseCredsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_creds"
+}
+
+func (f *ParseCredsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Extract the user JWT and seed from a creds file",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Parses the contents of a creds file the way the NATS clients do, tolerating variations of the armor and whitespace, and returns an object with the user `jwt` and the user `seed`, e.g. to issue the JWT of an existing user again while keeping its nkey. Fails when the creds lack either block, when the JWT is not a validly signed user JWT, or when the seed does not belong to its subject. Function results cannot be marked sensitive, so wrap the seed in `sensitive()` or only use it in sensitive attributes.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "creds",
+				MarkdownDescription: "Contents of the creds file, e.g. from `file()`",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: parseCredsAttrTypes,
+		},
+	}
+}
+
+func (f *ParseCredsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var creds string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &creds))
+	if resp.Error != nil {
+		return
+	}
+
+	token, seed, claims, err := parseCreds(creds)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	defer wipe(seed)
+	if _, err := checkCredsSeed(string(seed), claims.Subject); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "the seed block of the creds does not fit its JWT: "+err.Error())
+		return
+	}
+
+	result, diags := types.ObjectValue(parseCredsAttrTypes, map[string]attr.Value{
+		"jwt":  types.StringValue(token),
+		"seed": types.StringValue(string(seed)),
+	})
+	resp.Error = function.FuncErrorFromDiags(ctx, diags)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, result))
+}
@@ -0,0 +1,253 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/nkeys"
+	"golang.org/x/crypto/curve25519"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ParseDataSource{}
+
+func NewParseDataSource() datasource.DataSource {
+	return &ParseDataSource{}
+}
+
+// ParseDataSource defines the data source implementation.
+type ParseDataSource struct {
+}
+
+// ParseDataSourceModel describes the data source data model.
+type ParseDataSourceModel struct {
+	Key       types.String `tfsdk:"key"`
+	Strict    types.Bool   `tfsdk:"strict"`
+	Valid     types.Bool   `tfsdk:"valid"`
+	Kind      types.String `tfsdk:"kind"`
+	KeyType   types.String `tfsdk:"type"`
+	PublicKey types.String `tfsdk:"public_key"`
+}
+
+// The kinds of nkey strings.
+const (
+	keyKindPublic  = "public"
+	keyKindSeed    = "seed"
+	keyKindPrivate = "private"
+)
+
+// errInvalidPublicKey is returned when a public key is expected but a seed or
+// private key was given.
+var errInvalidPublicKey = errors.New("the key is a seed or private key, not a public key")
+
+// errNotASeed is returned when a seed is expected but a public or private key
+// was given.
+var errNotASeed = errors.New("the key is a public or private key, not a seed")
+
+// parsedKey describes a classified nkey string.
+type parsedKey struct {
+	kind string
+	// keyType is empty for private keys, which carry no type.
+	keyType string
+	// publicKey is empty for private keys.
+	publicKey string
+}
+
+// keyRole is what a key is used for, which decides whether curve keys fit.
+type keyRole string
+
+const (
+	// keyRoleSigning keys are ed25519 keys, which sign JWTs, nonces and
+	// payloads.
+	keyRoleSigning keyRole = "signing"
+	// keyRoleSealing keys are x25519 curve keys, which seal and open payloads.
+	keyRoleSealing keyRole = "sealing"
+)
+
+// errCurveCannotSign is returned when a curve key is given where a key has to
+// sign.
+var errCurveCannotSign = errors.New("curve keys are x25519 keys for sealing payloads and cannot sign JWTs, nonces or payloads, use an operator, account or user key instead")
+
+// checkRole returns an error explaining why the key cannot be used for role.
+func (k parsedKey) checkRole(role keyRole) error {
+	switch {
+	case role == keyRoleSigning && k.keyType == "curve":
+		return errCurveCannotSign
+	case role == keyRoleSealing && k.keyType != "curve":
+		return fmt.Errorf("only curve keys, starting with X, can seal and open payloads, the key is of type %s", k.keyType)
+	}
+	return nil
+}
+
+// parseKey classifies key by its prefix byte and checks its encoding and
+// checksum. Errors never include the key itself, since it may be a seed.
+func parseKey(key string) (parsedKey, error) {
+	if key == "" {
+		return parsedKey{}, errors.New("the key is empty")
+	}
+
+	prefix := nkeys.PrefixByte(base32Prefix(key))
+	switch prefix {
+	case nkeys.PrefixByteSeed:
+		keys, keyType, err := parseSeed([]byte(key))
+		if err != nil {
+			return parsedKey{}, err
+		}
+		defer keys.Wipe()
+
+		pubKey, err := keys.PublicKey()
+		if err != nil {
+			return parsedKey{}, seedError(err)
+		}
+		return parsedKey{kind: keyKindSeed, keyType: keyType, publicKey: pubKey}, nil
+	case nkeys.PrefixBytePrivate:
+		raw, err := nkeys.Decode(nkeys.PrefixBytePrivate, []byte(key))
+		if err != nil {
+			return parsedKey{}, keyError("private key", err)
+		}
+		defer wipe(raw)
+		// Curve private keys hold the x25519 scalar rather than an ed25519 key
+		if len(raw) != ed25519.PrivateKeySize && len(raw) != curve25519.ScalarSize {
+			return parsedKey{}, fmt.Errorf("the private key has %d bytes instead of %d, or %d for curve keys", len(raw), ed25519.PrivateKeySize, curve25519.ScalarSize)
+		}
+		if !isCanonicalKey(prefix, raw, key) {
+			return parsedKey{}, keyError("private key", nkeys.ErrInvalidEncoding)
+		}
+		return parsedKey{kind: keyKindPrivate}, nil
+	}
+
+	keyType, ok := keyTypeNames[prefix]
+	if !ok {
+		return parsedKey{}, errors.New("the key does not start with the prefix of an nkey public key, seed or private key")
+	}
+	raw, err := nkeys.Decode(prefix, []byte(key))
+	if err != nil {
+		return parsedKey{}, keyError("public key", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return parsedKey{}, fmt.Errorf("the public key has %d bytes instead of %d", len(raw), ed25519.PublicKeySize)
+	}
+	if !isCanonicalKey(prefix, raw, key) {
+		return parsedKey{}, keyError("public key", nkeys.ErrInvalidEncoding)
+	}
+	return parsedKey{kind: keyKindPublic, keyType: keyType, publicKey: key}, nil
+}
+
+// isCanonicalKey reports whether key is the encoding of raw with prefix.
+// Decoding ignores a trailing character that holds less than a byte, so a key
+// with a stray character appended decodes like the key itself.
+func isCanonicalKey(prefix nkeys.PrefixByte, raw []byte, key string) bool {
+	encoded, err := nkeys.Encode(prefix, raw)
+	defer wipe(encoded)
+	return err == nil && string(encoded) == key
+}
+
+// base32Prefix returns the prefix byte encoded in the first character of an
+// nkey string, which only depends on the upper 5 bits.
+func base32Prefix(key string) byte {
+	c := key[0]
+	switch {
+	case c >= 'A' && c <= 'Z':
+		return (c - 'A') << 3
+	case c >= '2' && c <= '7':
+		return (c - '2' + 26) << 3
+	}
+	return byte(nkeys.PrefixByteUnknown)
+}
+
+// keyError explains why an nkey string of the given kind could not be
+// decoded. The key is never part of the message.
+func keyError(kind string, err error) error {
+	if errors.Is(err, nkeys.ErrInvalidChecksum) {
+		return fmt.Errorf("the %s checksum does not match, the key is mistyped or truncated", kind)
+	}
+	return fmt.Errorf("the %s is not a valid nkey encoding, check for stray characters or truncation", kind)
+}
+
+func (d *ParseDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_parse"
+}
+
+func (d *ParseDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Classifies and validates an nkey public key, seed or private key by its prefix byte and checksum.",
+
+		Attributes: map[string]schema.Attribute{
+			"key": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The nkey string to parse. It may be a seed, so it is treated as sensitive",
+				Sensitive:           true,
+			},
+			"strict": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Fail with an error when the key is invalid instead of setting `valid` to false. Defaults to false",
+			},
+			"valid": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the key is a well formed nkey with a matching checksum",
+			},
+			"kind": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The kind of the key. One of public|seed|private, null when the key is invalid",
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The type of the key. One of user|account|server|cluster|operator|curve, null for private keys, which carry no type, and invalid keys",
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The public key, derived for seeds. Null for private keys and invalid keys",
+			},
+		},
+	}
+}
+
+func (d *ParseDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data ParseDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Valid = types.BoolValue(false)
+	data.Kind = types.StringNull()
+	data.KeyType = types.StringNull()
+	data.PublicKey = types.StringNull()
+
+	parsed, err := parseKey(data.Key.ValueString())
+	switch {
+	case err != nil && data.Strict.ValueBool():
+		resp.Diagnostics.AddAttributeError(path.Root("key"), "invalid nkey", err.Error())
+		return
+	case err == nil:
+		data.Valid = types.BoolValue(true)
+		data.Kind = types.StringValue(parsed.kind)
+		if parsed.keyType != "" {
+			data.KeyType = types.StringValue(parsed.keyType)
+		}
+		if parsed.publicKey != "" {
+			data.PublicKey = types.StringValue(parsed.publicKey)
+		}
+	}
+	tflog.Trace(ctx, "read parse data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
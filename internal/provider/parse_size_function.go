@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ParseSizeFunction{}
+
+func NewParseSizeFunction() function.Function {
+	return &ParseSizeFunction{}
+}
+
+// ParseSizeFunction defines the function implementation.
+type ParseSizeFunction struct {
+}
+
+func (f *ParseSizeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_size"
+}
+
+func (f *ParseSizeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Convert a size such as 10MB to a number of bytes",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Returns the number of bytes of `size`, a whole number optionally followed by a unit, for the byte limits of the JWT resources. Units are case-insensitive and mean what they mean in the nats-server configuration:\n\n" +
+			"- `K`, `M`, `G` and `T` are powers of 1000, e.g. `1K` is 1000\n" +
+			"- `KB`, `MB`, `GB` and `TB` are powers of 1024, as are `KiB`, `MiB`, `GiB` and `TiB`, e.g. `1MB` and `1MiB` are 1048576\n" +
+			"- `B` or no unit are bytes\n\n" +
+			"Negative sizes are an error, so write `-1` directly for an unlimited limit.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "size",
+				MarkdownDescription: "Size such as `10MB` or `1GiB`",
+			},
+		},
+		Return: function.Int64Return{},
+	}
+}
+
+func (f *ParseSizeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var size string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &size))
+	if resp.Error != nil {
+		return
+	}
+
+	bytes, err := parseSize(size)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, bytes))
+}
+
+// sizePattern matches a size, capturing its number and unit.
+var sizePattern = regexp.MustCompile(`^\s*(\d+)\s*([A-Za-z]*)\s*$`)
+
+// sizeUnits are the factors of the units of sizes, lowercased, the way the
+// nats-server configuration reads them.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"m":   1000 * 1000,
+	"g":   1000 * 1000 * 1000,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"kb":  1 << 10,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"mb":  1 << 20,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"gb":  1 << 30,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"tb":  1 << 40,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+}
+
+// parseSize returns the number of bytes of size, such as "10MB".
+func parseSize(size string) (int64, error) {
+	if strings.HasPrefix(strings.TrimSpace(size), "-") {
+		return 0, fmt.Errorf("the size %q is negative, write -1 directly for an unlimited limit", size)
+	}
+	match := sizePattern.FindStringSubmatch(size)
+	if match == nil {
+		return 0, fmt.Errorf("the size %q is not a whole number followed by an optional unit such as MB", size)
+	}
+	factor, ok := sizeUnits[strings.ToLower(match[2])]
+	if !ok {
+		return 0, fmt.Errorf("the unit %q is not one of B, K, KB, KiB, M, MB, MiB, G, GB, GiB, T, TB or TiB", match[2])
+	}
+	number, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil || number > math.MaxInt64/factor {
+		return 0, fmt.Errorf("the size %q does not fit in 64 bits", size)
+	}
+	return number * factor, nil
+}
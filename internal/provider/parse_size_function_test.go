@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestParseSizeFunction(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    int64
+		wantErr string
+	}{
+		{size: "0", want: 0},
+		{size: "512", want: 512},
+		{size: "512B", want: 512},
+		{size: "1K", want: 1000},
+		{size: "1KB", want: 1024},
+		{size: "1KiB", want: 1024},
+		{size: "1ki", want: 1024},
+		{size: "10MB", want: 10 << 20},
+		{size: "10mb", want: 10 << 20},
+		{size: "10M", want: 10 * 1000 * 1000},
+		{size: "1GiB", want: 1 << 30},
+		{size: "1gib", want: 1 << 30},
+		{size: "2T", want: 2 * 1000 * 1000 * 1000 * 1000},
+		{size: "1TiB", want: 1 << 40},
+		{size: " 10 MB ", want: 10 << 20},
+		// The largest sizes that fit in 64 bits
+		{size: "9223372036854775807", want: math.MaxInt64},
+		{size: "8388607TiB", want: 8388607 << 40},
+		{size: "8388608TiB", wantErr: "does not fit in 64 bits"},
+		{size: "9223372036854775808", wantErr: "does not fit in 64 bits"},
+		{size: "99999999999999999999B", wantErr: "does not fit in 64 bits"},
+		{size: "-1", wantErr: "write -1 directly"},
+		{size: " -10MB", wantErr: "write -1 directly"},
+		{size: "", wantErr: "not a whole number"},
+		{size: "MB", wantErr: "not a whole number"},
+		{size: "1.5MB", wantErr: "not a whole number"},
+		{size: "1e6", wantErr: "not a whole number"},
+		{size: "10XB", wantErr: `the unit "XB"`},
+		{size: "10MBs", wantErr: `the unit "MBs"`},
+		{size: "10 M B", wantErr: "not a whole number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, funcErr := runFunction(t, &ParseSizeFunction{}, types.StringValue(tt.size))
+			if tt.wantErr != "" {
+				if funcErr == nil || funcErr.FunctionArgument == nil || *funcErr.FunctionArgument != 0 || !strings.Contains(funcErr.Text, tt.wantErr) {
+					t.Fatalf("parse_size(%q) = %v, %v, want an error on the size containing %q", tt.size, got, funcErr, tt.wantErr)
+				}
+				return
+			}
+			if funcErr != nil || !got.Equal(types.Int64Value(tt.want)) {
+				t.Fatalf("parse_size(%q) = %v, %v, want %d", tt.size, got, funcErr, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,586 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	ephemeralschema "github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/nats-io/jwt/v2"
+)
+
+// PermissionsModel describes the publish and subscribe permissions of users,
+// e.g. the template of a scoped signing key.
+type PermissionsModel struct {
+	Publish   types.Object `tfsdk:"publish"`
+	Subscribe types.Object `tfsdk:"subscribe"`
+	Response  types.Object `tfsdk:"response"`
+}
+
+// ResponsePermissionModel describes the response attribute of
+// PermissionsModel.
+type ResponsePermissionModel struct {
+	Max types.Int64  `tfsdk:"max"`
+	TTL types.String `tfsdk:"ttl"`
+}
+
+// SubjectPermissionModel describes the publish and subscribe attributes of
+// PermissionsModel.
+type SubjectPermissionModel struct {
+	Allow types.Set `tfsdk:"allow"`
+	Deny  types.Set `tfsdk:"deny"`
+}
+
+// UserLimitsModel describes the limits of users.
+type UserLimitsModel struct {
+	MaxSubscriptions types.Int64 `tfsdk:"max_subscriptions"`
+	MaxData          types.Int64 `tfsdk:"max_data"`
+	MaxPayload       types.Int64 `tfsdk:"max_payload"`
+}
+
+// UsageTimesModel describes the times of day users may connect at.
+type UsageTimesModel struct {
+	Windows types.Set    `tfsdk:"windows"`
+	Locale  types.String `tfsdk:"locale"`
+}
+
+// UsageWindowModel describes a time of day window users may connect in.
+type UsageWindowModel struct {
+	Start types.String `tfsdk:"start"`
+	End   types.String `tfsdk:"end"`
+}
+
+// defaultResponseMax and defaultResponseTTL are the response permissions
+// nats-server applies when they are 0, written into the JWT when they are not
+// set so that it says what the users get.
+const (
+	defaultResponseMax = 1
+	defaultResponseTTL = 2 * time.Minute
+)
+
+// connectionTypes are the connection types users can be restricted to.
+var connectionTypes = []string{
+	jwt.ConnectionTypeStandard,
+	jwt.ConnectionTypeWebsocket,
+	jwt.ConnectionTypeLeafnode,
+	jwt.ConnectionTypeLeafnodeWS,
+	jwt.ConnectionTypeMqtt,
+	jwt.ConnectionTypeMqttWS,
+	jwt.ConnectionTypeInProcess,
+}
+
+// Descriptions of the PermissionsModel attributes, shared by the managed and
+// ephemeral resources and the data sources.
+const (
+	publishDescription     = "Subjects users may publish to"
+	subscribeDescription   = "Subjects users may subscribe to, each optionally followed by a queue group as in `orders.> workers`"
+	responseDescription    = "Allows users to respond to the reply subject of the requests they receive, even when they may not publish to it. Without a publish allow list, nats-server then only lets users publish responses rather than to any subject"
+	responseMaxDescription = "Maximum number of responses to a request, or -1 for unlimited. Defaults to 1"
+	responseTTLDescription = "How long users may respond to a request, as a duration such as `5s`. Defaults to 2 minutes"
+	allowDescription       = "Subjects that are allowed, which may contain wildcards. All subjects are allowed when not set, and an empty list also allows every subject rather than none. Deny every subject with `deny = [\">\"]` instead"
+	denyDescription        = "Subjects that are denied even when allowed, which may contain wildcards"
+)
+
+// permissionsAttribute returns the attribute of a PermissionsModel.
+func permissionsAttribute(description string) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: description,
+		Attributes: map[string]schema.Attribute{
+			"publish":   subjectPermissionAttribute(publishDescription, isSubject()),
+			"subscribe": subjectPermissionAttribute(subscribeDescription, isSubscribeSubject()),
+			"response": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: responseDescription,
+				Attributes: map[string]schema.Attribute{
+					"max": schema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: responseMaxDescription,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(jwt.NoLimit),
+						},
+					},
+					"ttl": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: responseTTLDescription,
+						Validators: []validator.String{
+							isPositiveDuration(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// subjectPermissionAttribute returns the attribute of a
+// SubjectPermissionModel, with subjects validated by subject.
+func subjectPermissionAttribute(description string, subject validator.String) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: description,
+		Attributes: map[string]schema.Attribute{
+			"allow": schema.SetAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: allowDescription,
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(subject),
+				},
+			},
+			"deny": schema.SetAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: denyDescription,
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(subject),
+				},
+			},
+		},
+	}
+}
+
+// permissionsEphemeralAttribute returns the attribute of a PermissionsModel in
+// an ephemeral resource.
+func permissionsEphemeralAttribute(description string) ephemeralschema.SingleNestedAttribute {
+	return ephemeralschema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: description,
+		Attributes: map[string]ephemeralschema.Attribute{
+			"publish":   subjectPermissionEphemeralAttribute(publishDescription, isSubject()),
+			"subscribe": subjectPermissionEphemeralAttribute(subscribeDescription, isSubscribeSubject()),
+			"response": ephemeralschema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: responseDescription,
+				Attributes: map[string]ephemeralschema.Attribute{
+					"max": ephemeralschema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: responseMaxDescription,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(jwt.NoLimit),
+						},
+					},
+					"ttl": ephemeralschema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: responseTTLDescription,
+						Validators: []validator.String{
+							isPositiveDuration(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// subjectPermissionEphemeralAttribute returns the attribute of a
+// SubjectPermissionModel in an ephemeral resource, with subjects validated by
+// subject.
+func subjectPermissionEphemeralAttribute(description string, subject validator.String) ephemeralschema.SingleNestedAttribute {
+	return ephemeralschema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: description,
+		Attributes: map[string]ephemeralschema.Attribute{
+			"allow": ephemeralschema.SetAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: allowDescription,
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(subject),
+				},
+			},
+			"deny": ephemeralschema.SetAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: denyDescription,
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(subject),
+				},
+			},
+		},
+	}
+}
+
+// permissionsDataSourceAttribute returns the attribute of a PermissionsModel
+// in a data source.
+func permissionsDataSourceAttribute(description string) datasourceschema.SingleNestedAttribute {
+	return datasourceschema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: description,
+		Attributes: map[string]datasourceschema.Attribute{
+			"publish":   subjectPermissionDataSourceAttribute(publishDescription, isSubject()),
+			"subscribe": subjectPermissionDataSourceAttribute(subscribeDescription, isSubscribeSubject()),
+			"response": datasourceschema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: responseDescription,
+				Attributes: map[string]datasourceschema.Attribute{
+					"max": datasourceschema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: responseMaxDescription,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(jwt.NoLimit),
+						},
+					},
+					"ttl": datasourceschema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: responseTTLDescription,
+						Validators: []validator.String{
+							isPositiveDuration(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// subjectPermissionDataSourceAttribute returns the attribute of a
+// SubjectPermissionModel in a data source, with subjects validated by
+// subject.
+func subjectPermissionDataSourceAttribute(description string, subject validator.String) datasourceschema.SingleNestedAttribute {
+	return datasourceschema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: description,
+		Attributes: map[string]datasourceschema.Attribute{
+			"allow": datasourceschema.SetAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: allowDescription,
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(subject),
+				},
+			},
+			"deny": datasourceschema.SetAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: denyDescription,
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(subject),
+				},
+			},
+		},
+	}
+}
+
+// userLimitsAttribute returns the attribute of a UserLimitsModel.
+func userLimitsAttribute(description string) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: description,
+		Attributes: map[string]schema.Attribute{
+			"max_subscriptions": limitAttribute("Maximum number of subscriptions"),
+			"max_data":          limitAttribute("Maximum number of bytes, e.g. `provider::nkey::parse_size(\"1GiB\")`"),
+			"max_payload":       limitAttribute("Maximum message payload in bytes, e.g. `provider::nkey::parse_size(\"1MB\")`"),
+		},
+	}
+}
+
+// connectionTypesAttribute returns the attribute of the connection types users
+// are restricted to. Connection types are uppercase in the JWT, so they are
+// compared ignoring case.
+func connectionTypesAttribute() schema.SetAttribute {
+	return schema.SetAttribute{
+		ElementType:         types.StringType,
+		Optional:            true,
+		MarkdownDescription: "Connection types users may connect with, out of `STANDARD`, `WEBSOCKET`, `LEAFNODE`, `LEAFNODE_WS`, `MQTT`, `MQTT_WS` and `IN_PROCESS`, in any case. Any connection type is allowed when not set or empty",
+		Validators: []validator.Set{
+			setvalidator.ValueStringsAre(stringvalidator.OneOfCaseInsensitive(connectionTypes...)),
+		},
+		PlanModifiers: []planmodifier.Set{
+			caseInsensitiveSet(),
+		},
+	}
+}
+
+// usageTimesAttribute returns the attribute of the times of day users may
+// connect at.
+func usageTimesAttribute() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: "Times of day users may connect at. nats-server disconnects users when their window ends. Users may connect at any time when not set",
+		Attributes: map[string]schema.Attribute{
+			"windows": schema.SetNestedAttribute{
+				Required:            true,
+				MarkdownDescription: "Windows users may connect in, in any order",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"start": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Start of the window, in the 24-hour format `HH:MM:SS` such as `09:00:00`",
+							Validators: []validator.String{
+								isTimeOfDay(),
+							},
+						},
+						"end": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "End of the window, in the same format. A window whose end is before its start wraps midnight, e.g. from `22:00:00` to `06:00:00`, and one that ends at `00:00:00` lasts until midnight",
+							Validators: []validator.String{
+								isTimeOfDay(),
+							},
+						},
+					},
+				},
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"locale": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "IANA time zone of the windows such as `Europe/Madrid` or `UTC`. Defaults to the local time zone of each nats-server",
+				Validators: []validator.String{
+					isTimeZone(),
+				},
+			},
+		},
+	}
+}
+
+// setPermissions sets the publish and subscribe permissions of permissions.
+func (m *PermissionsModel) setPermissions(ctx context.Context, permissions *jwt.Permissions) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, p := range []struct {
+		value      types.Object
+		permission *jwt.Permission
+	}{
+		{m.Publish, &permissions.Pub},
+		{m.Subscribe, &permissions.Sub},
+	} {
+		if p.value.IsNull() {
+			continue
+		}
+		var subjects SubjectPermissionModel
+		diags.Append(p.value.As(ctx, &subjects, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return diags
+		}
+		diags.Append(addSorted(ctx, subjects.Allow, &p.permission.Allow)...)
+		diags.Append(addSorted(ctx, subjects.Deny, &p.permission.Deny)...)
+	}
+	if !m.Response.IsNull() {
+		var response ResponsePermissionModel
+		diags.Append(m.Response.As(ctx, &response, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return diags
+		}
+		permissions.Resp = &jwt.ResponsePermission{
+			MaxMsgs: defaultResponseMax,
+			Expires: defaultResponseTTL,
+		}
+		if !response.Max.IsNull() {
+			permissions.Resp.MaxMsgs = int(response.Max.ValueInt64())
+		}
+		if !response.TTL.IsNull() {
+			ttl, err := time.ParseDuration(response.TTL.ValueString())
+			if err != nil {
+				diags.AddError("invalid response ttl", "The response ttl could not be parsed: "+err.Error())
+				return diags
+			}
+			permissions.Resp.Expires = ttl
+		}
+	}
+	return diags
+}
+
+// checkResponse warns about response permissions without a publish allow
+// list, which nats-server turns from allowing every subject into allowing
+// none but the responses. attribute is the path of the permissions.
+func (m *PermissionsModel) checkResponse(ctx context.Context, attribute path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Response.IsNull() || m.Response.IsUnknown() || m.Publish.IsUnknown() {
+		return diags
+	}
+	var publish SubjectPermissionModel
+	if !m.Publish.IsNull() {
+		diags.Append(m.Publish.As(ctx, &publish, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() || publish.Allow.IsUnknown() {
+			return diags
+		}
+	}
+	if len(publish.Allow.Elements()) == 0 {
+		diags.AddAttributeWarning(attribute.AtName("response"), "publishing limited to responses", "With response permissions and no publish allow list, nats-server only lets users publish responses to the requests they receive. Set publish.allow to the other subjects they publish to, or to \">\" for any subject.")
+	}
+	return diags
+}
+
+// setLimits sets the limits of users, which are unlimited unless set.
+func (m *UserLimitsModel) setLimits(limits *jwt.NatsLimits) {
+	limits.Subs = limitValue(m.MaxSubscriptions)
+	limits.Data = limitValue(m.MaxData)
+	limits.Payload = limitValue(m.MaxPayload)
+}
+
+// checkWindows checks that no usage window of m is empty, since nats-server
+// never lets users connect during one that starts when it ends.
+func (m *UsageTimesModel) checkWindows(ctx context.Context, attribute path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Windows.IsUnknown() {
+		return diags
+	}
+	for _, element := range m.Windows.Elements() {
+		object, ok := element.(types.Object)
+		if !ok || object.IsUnknown() {
+			continue
+		}
+		var window UsageWindowModel
+		asDiags := object.As(ctx, &window, basetypes.ObjectAsOptions{})
+		diags.Append(asDiags...)
+		if asDiags.HasError() {
+			continue
+		}
+		if !window.Start.IsUnknown() && !window.End.IsUnknown() && window.Start.Equal(window.End) {
+			diags.AddAttributeError(attribute.AtName("windows").AtSetValue(element).AtName("end"), "empty usage window", "The window starts and ends at "+window.Start.ValueString()+", so users could never connect in it. Windows that wrap midnight end before they start.")
+		}
+	}
+	return diags
+}
+
+// setTimes sets the usage windows and their time zone of users, sorted so
+// that the token does not depend on set order.
+func (m *UsageTimesModel) setTimes(ctx context.Context, limits *jwt.UserLimits) diag.Diagnostics {
+	var windows []UsageWindowModel
+	diags := m.Windows.ElementsAs(ctx, &windows, false)
+	if diags.HasError() {
+		return diags
+	}
+	for _, window := range windows {
+		limits.Times = append(limits.Times, jwt.TimeRange{
+			Start: window.Start.ValueString(),
+			End:   window.End.ValueString(),
+		})
+	}
+	slices.SortFunc(limits.Times, func(a, b jwt.TimeRange) int {
+		return cmp.Or(strings.Compare(a.Start, b.Start), strings.Compare(a.End, b.End))
+	})
+	limits.Locale = m.Locale.ValueString()
+	return diags
+}
+
+// sourceNetworksAttribute returns the attribute of the networks users are
+// restricted to connect from.
+func sourceNetworksAttribute() schema.SetAttribute {
+	return schema.SetAttribute{
+		ElementType:         types.StringType,
+		Optional:            true,
+		MarkdownDescription: "Networks users may connect from, in CIDR notation such as `10.0.0.0/8` or `2001:db8::/32`. A single IP address stands for its own network, `/32` for IPv4 and `/128` for IPv6, and is stored that way. Users may connect from anywhere when not set or empty",
+		Validators: []validator.Set{
+			setvalidator.ValueStringsAre(isNetwork()),
+		},
+		PlanModifiers: []planmodifier.Set{
+			networkSet(),
+		},
+	}
+}
+
+// addConnectionTypes adds the connection types of set to list in uppercase,
+// the form nats-server compares them in, sorted so that the token does not
+// depend on set order.
+func addConnectionTypes(ctx context.Context, set types.Set, list *jwt.StringList) diag.Diagnostics {
+	if set.IsNull() {
+		return nil
+	}
+	var values []string
+	diags := set.ElementsAs(ctx, &values, false)
+	if diags.HasError() {
+		return diags
+	}
+	for i, value := range values {
+		values[i] = strings.ToUpper(value)
+	}
+	slices.Sort(values)
+	list.Add(values...)
+	return diags
+}
+
+// canonicalConnectionTypes returns connection types in the uppercase form
+// they have in the JWT.
+func canonicalConnectionTypes(connectionTypes types.Set) types.Set {
+	if connectionTypes.IsNull() || connectionTypes.IsUnknown() {
+		return connectionTypes
+	}
+	elements := make([]attr.Value, 0, len(connectionTypes.Elements()))
+	for _, element := range connectionTypes.Elements() {
+		value, ok := element.(types.String)
+		if !ok || value.IsUnknown() {
+			return connectionTypes
+		}
+		elements = append(elements, types.StringValue(strings.ToUpper(value.ValueString())))
+	}
+	return types.SetValueMust(types.StringType, elements)
+}
+
+// addNetworks adds the networks of set to list in CIDR notation, sorted so
+// that the token does not depend on set order.
+func addNetworks(ctx context.Context, set types.Set, list *jwt.CIDRList) diag.Diagnostics {
+	if set.IsNull() {
+		return nil
+	}
+	var values []string
+	diags := set.ElementsAs(ctx, &values, false)
+	if diags.HasError() {
+		return diags
+	}
+	for i, value := range values {
+		network, err := parseNetwork(value)
+		if err != nil {
+			diags.AddError("invalid network", "The network "+value+" could not be parsed: "+err.Error())
+			return diags
+		}
+		values[i] = network.String()
+	}
+	slices.Sort(values)
+	list.Add(values...)
+	return diags
+}
+
+// canonicalNetworks returns networks in the CIDR notation they have in the
+// JWT.
+func canonicalNetworks(networks types.Set) types.Set {
+	if networks.IsNull() || networks.IsUnknown() {
+		return networks
+	}
+	values, ok := networkElements(networks)
+	if !ok {
+		return networks
+	}
+	elements := make([]attr.Value, 0, len(values))
+	for value := range values {
+		elements = append(elements, types.StringValue(value))
+	}
+	return types.SetValueMust(types.StringType, elements)
+}
+
+// addSorted adds the strings of set to list, sorted so that the token does
+// not depend on set order.
+func addSorted(ctx context.Context, set types.Set, list *jwt.StringList) diag.Diagnostics {
+	if set.IsNull() {
+		return nil
+	}
+	var values []string
+	diags := set.ElementsAs(ctx, &values, false)
+	if diags.HasError() {
+		return diags
+	}
+	slices.Sort(values)
+	list.Add(values...)
+	return diags
+}
@@ -0,0 +1,161 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure plan modifiers fully satisfy framework interfaces.
+var _ planmodifier.String = caseInsensitiveModifier{}
+var _ planmodifier.Set = caseInsensitiveSetModifier{}
+var _ planmodifier.Set = networkSetModifier{}
+
+// caseInsensitiveModifier plans the prior state value when the configured value
+// only differs from it in case.
+type caseInsensitiveModifier struct{}
+
+// caseInsensitive returns a plan modifier which treats values that only differ
+// in case as equal, so that e.g. changing type from "user" to "User" neither
+// shows a diff nor replaces the resource. It has to come before RequiresReplace.
+// Unknown values are left alone until they are known.
+func caseInsensitive() caseInsensitiveModifier {
+	return caseInsensitiveModifier{}
+}
+
+func (m caseInsensitiveModifier) Description(ctx context.Context) string {
+	return "a value that only differs from the prior state in case keeps the prior state value"
+}
+
+func (m caseInsensitiveModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m caseInsensitiveModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.StateValue.IsNull() || req.StateValue.IsUnknown() || req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if strings.EqualFold(req.ConfigValue.ValueString(), req.StateValue.ValueString()) {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// caseInsensitiveSetModifier plans the prior state value when the configured
+// set of strings only differs from it in case.
+type caseInsensitiveSetModifier struct{}
+
+// caseInsensitiveSet returns a plan modifier which treats sets of strings that
+// hold the same values ignoring case as equal, so that e.g. changing tags from
+// ["prod"] to ["Prod"] does not show a diff. Unknown values are left alone until
+// they are known.
+func caseInsensitiveSet() caseInsensitiveSetModifier {
+	return caseInsensitiveSetModifier{}
+}
+
+func (m caseInsensitiveSetModifier) Description(ctx context.Context) string {
+	return "a set that only differs from the prior state in case keeps the prior state value"
+}
+
+func (m caseInsensitiveSetModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m caseInsensitiveSetModifier) PlanModifySet(ctx context.Context, req planmodifier.SetRequest, resp *planmodifier.SetResponse) {
+	if req.StateValue.IsNull() || req.StateValue.IsUnknown() || req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	configured, ok := lowercaseElements(req.ConfigValue)
+	if !ok {
+		return
+	}
+	prior, ok := lowercaseElements(req.StateValue)
+	if !ok || len(configured) != len(prior) {
+		return
+	}
+	for value := range configured {
+		if !prior[value] {
+			return
+		}
+	}
+	resp.PlanValue = req.StateValue
+}
+
+// lowercaseElements returns the distinct lowercase values of a set of strings,
+// or false when an element is not a known string.
+func lowercaseElements(set types.Set) (map[string]bool, bool) {
+	values := make(map[string]bool, len(set.Elements()))
+	for _, element := range set.Elements() {
+		value, ok := element.(types.String)
+		if !ok || value.IsNull() || value.IsUnknown() {
+			return nil, false
+		}
+		values[strings.ToLower(value.ValueString())] = true
+	}
+	return values, true
+}
+
+// networkSetModifier plans the prior state value when the configured set of
+// networks holds the same networks as it, however they are written.
+type networkSetModifier struct{}
+
+// networkSet returns a plan modifier which treats sets of networks that hold
+// the same networks as equal, so that e.g. changing source_networks from
+// ["10.0.0.1/32"] to ["10.0.0.1"] does not show a diff. Unknown values are left
+// alone until they are known.
+func networkSet() networkSetModifier {
+	return networkSetModifier{}
+}
+
+func (m networkSetModifier) Description(ctx context.Context) string {
+	return "a set that holds the same networks as the prior state keeps the prior state value"
+}
+
+func (m networkSetModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m networkSetModifier) PlanModifySet(ctx context.Context, req planmodifier.SetRequest, resp *planmodifier.SetResponse) {
+	if req.StateValue.IsNull() || req.StateValue.IsUnknown() || req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	configured, ok := networkElements(req.ConfigValue)
+	if !ok {
+		return
+	}
+	prior, ok := networkElements(req.StateValue)
+	if !ok || len(configured) != len(prior) {
+		return
+	}
+	for value := range configured {
+		if !prior[value] {
+			return
+		}
+	}
+	resp.PlanValue = req.StateValue
+}
+
+// networkElements returns the distinct networks of a set of strings in CIDR
+// notation, or false when an element is not a known network.
+func networkElements(set types.Set) (map[string]bool, bool) {
+	values := make(map[string]bool, len(set.Elements()))
+	for _, element := range set.Elements() {
+		value, ok := element.(types.String)
+		if !ok || value.IsNull() || value.IsUnknown() {
+			return nil, false
+		}
+		network, err := parseNetwork(value.ValueString())
+		if err != nil {
+			return nil, false
+		}
+		values[network.String()] = true
+	}
+	return values, true
+}
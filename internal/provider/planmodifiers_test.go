@@ -0,0 +1,171 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCaseInsensitive(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  types.String
+		config types.String
+		want   types.String
+	}{
+		{name: "same case", state: types.StringValue("user"), config: types.StringValue("user"), want: types.StringValue("user")},
+		{name: "other case", state: types.StringValue("user"), config: types.StringValue("USER"), want: types.StringValue("user")},
+		{name: "mixed case", state: types.StringValue("user"), config: types.StringValue("User"), want: types.StringValue("user")},
+		{name: "other value", state: types.StringValue("user"), config: types.StringValue("Account"), want: types.StringValue("Account")},
+		{name: "no state", state: types.StringNull(), config: types.StringValue("User"), want: types.StringValue("User")},
+		// Unknown values are planned as they are until they are known
+		{name: "unknown config", state: types.StringValue("user"), config: types.StringUnknown(), want: types.StringUnknown()},
+		{name: "null config", state: types.StringValue("user"), config: types.StringNull(), want: types.StringNull()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := planmodifier.StringRequest{StateValue: tt.state, ConfigValue: tt.config, PlanValue: tt.config}
+			resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+			caseInsensitive().PlanModifyString(context.Background(), req, resp)
+			if !resp.PlanValue.Equal(tt.want) {
+				t.Errorf("PlanValue = %s, want %s", resp.PlanValue, tt.want)
+			}
+		})
+	}
+}
+
+// stringSet returns the known set of values.
+func stringSet(values ...string) types.Set {
+	elements := make([]attr.Value, len(values))
+	for i, value := range values {
+		elements[i] = types.StringValue(value)
+	}
+	return types.SetValueMust(types.StringType, elements)
+}
+
+func TestCaseInsensitiveSet(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  types.Set
+		config types.Set
+		want   types.Set
+	}{
+		{name: "same tags", state: stringSet("env:prod", "team:a"), config: stringSet("team:a", "env:prod"), want: stringSet("env:prod", "team:a")},
+		{name: "other case", state: stringSet("env:prod", "team:a"), config: stringSet("Team:A", "ENV:PROD"), want: stringSet("env:prod", "team:a")},
+		{name: "same tag twice", state: stringSet("env:prod"), config: stringSet("env:prod", "Env:Prod"), want: stringSet("env:prod")},
+		{name: "tag added", state: stringSet("env:prod"), config: stringSet("env:prod", "team:a"), want: stringSet("env:prod", "team:a")},
+		{name: "tag removed", state: stringSet("env:prod", "team:a"), config: stringSet("Env:Prod"), want: stringSet("Env:Prod")},
+		{name: "tag changed", state: stringSet("env:prod"), config: stringSet("env:dev"), want: stringSet("env:dev")},
+		{name: "no state", state: types.SetNull(types.StringType), config: stringSet("Env:Prod"), want: stringSet("Env:Prod")},
+		{name: "empty", state: stringSet("env:prod"), config: stringSet(), want: stringSet()},
+		// Unknown values are planned as they are until they are known
+		{name: "unknown config", state: stringSet("env:prod"), config: types.SetUnknown(types.StringType), want: types.SetUnknown(types.StringType)},
+		{
+			name:   "unknown element",
+			state:  stringSet("env:prod"),
+			config: types.SetValueMust(types.StringType, []attr.Value{types.StringUnknown()}),
+			want:   types.SetValueMust(types.StringType, []attr.Value{types.StringUnknown()}),
+		},
+		{name: "null config", state: stringSet("env:prod"), config: types.SetNull(types.StringType), want: types.SetNull(types.StringType)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := planmodifier.SetRequest{StateValue: tt.state, ConfigValue: tt.config, PlanValue: tt.config}
+			resp := &planmodifier.SetResponse{PlanValue: req.PlanValue}
+			caseInsensitiveSet().PlanModifySet(context.Background(), req, resp)
+			if !resp.PlanValue.Equal(tt.want) {
+				t.Errorf("PlanValue = %s, want %s", resp.PlanValue, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetworkSet(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  types.Set
+		config types.Set
+		want   types.Set
+	}{
+		{name: "same networks", state: stringSet("10.0.0.1/32", "2001:db8::/32"), config: stringSet("2001:db8::/32", "10.0.0.1/32"), want: stringSet("10.0.0.1/32", "2001:db8::/32")},
+		{name: "addresses", state: stringSet("10.0.0.1/32", "2001:db8::1/128"), config: stringSet("10.0.0.1", "2001:DB8::1"), want: stringSet("10.0.0.1/32", "2001:db8::1/128")},
+		{name: "host bits", state: stringSet("10.0.0.0/8"), config: stringSet("10.1.2.3/8"), want: stringSet("10.0.0.0/8")},
+		{name: "same network twice", state: stringSet("10.0.0.1/32"), config: stringSet("10.0.0.1", "10.0.0.1/32"), want: stringSet("10.0.0.1/32")},
+		{name: "other prefix length", state: stringSet("10.0.0.0/8"), config: stringSet("10.0.0.0/16"), want: stringSet("10.0.0.0/16")},
+		{name: "network added", state: stringSet("10.0.0.1/32"), config: stringSet("10.0.0.1", "10.0.0.2"), want: stringSet("10.0.0.1", "10.0.0.2")},
+		{name: "network removed", state: stringSet("10.0.0.1/32", "10.0.0.2/32"), config: stringSet("10.0.0.1"), want: stringSet("10.0.0.1")},
+		{name: "no state", state: types.SetNull(types.StringType), config: stringSet("10.0.0.1"), want: stringSet("10.0.0.1")},
+		{name: "empty", state: stringSet("10.0.0.1/32"), config: stringSet(), want: stringSet()},
+		// Invalid networks are planned as they are for the validator to reject
+		{name: "invalid network", state: stringSet("10.0.0.1/32"), config: stringSet("10.0.0.1/33"), want: stringSet("10.0.0.1/33")},
+		// Unknown values are planned as they are until they are known
+		{name: "unknown config", state: stringSet("10.0.0.1/32"), config: types.SetUnknown(types.StringType), want: types.SetUnknown(types.StringType)},
+		{
+			name:   "unknown element",
+			state:  stringSet("10.0.0.1/32"),
+			config: types.SetValueMust(types.StringType, []attr.Value{types.StringUnknown()}),
+			want:   types.SetValueMust(types.StringType, []attr.Value{types.StringUnknown()}),
+		},
+		{name: "null config", state: stringSet("10.0.0.1/32"), config: types.SetNull(types.StringType), want: types.SetNull(types.StringType)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := planmodifier.SetRequest{StateValue: tt.state, ConfigValue: tt.config, PlanValue: tt.config}
+			resp := &planmodifier.SetResponse{PlanValue: req.PlanValue}
+			networkSet().PlanModifySet(context.Background(), req, resp)
+			if !resp.PlanValue.Equal(tt.want) {
+				t.Errorf("PlanValue = %s, want %s", resp.PlanValue, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeCase(t *testing.T) {
+	for _, resourceType := range []string{"nkey_nkey", "nkey_keypair", "nkey_keyset"} {
+		t.Run(resourceType, func(t *testing.T) {
+			address := resourceType + ".test"
+			config := func(keyType string) string {
+				config := `
+resource "` + resourceType + `" "test" {
+  type = "` + keyType + `"
+`
+				if resourceType == "nkey_keyset" {
+					config += "  count_keys = 1\n"
+				}
+				return config + "}\n"
+			}
+			// step checks the planned actions, and that the key is of keyType
+			step := func(keyType string, actions ...tfjson.Action) testStep {
+				return testStep{
+					Config: config(keyType),
+					PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+						expectActions(t, plan, address, actions...)
+					},
+					Check: func(t *testing.T, state *testState) {
+						if got := state.stringAttribute(t, address, "type"); !strings.EqualFold(got, keyType) {
+							t.Errorf("type = %q, want %q", got, keyType)
+						}
+					},
+				}
+			}
+			unitTest(t, testCase{
+				Steps: []testStep{
+					step("user", tfjson.ActionCreate),
+					// Changing only the case shows no diff
+					step("User", tfjson.ActionNoop),
+					step("USER", tfjson.ActionNoop),
+					// Changing the type replaces the key
+					step("Account", tfjson.ActionDelete, tfjson.ActionCreate),
+				},
+			})
+		})
+	}
+}
@@ -5,13 +5,20 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure NatsNkeyProvider satisfies various provider interfaces.
@@ -29,6 +36,14 @@ type NatsNkeyProvider struct {
 
 // NatsNkeyProviderModel describes the provider data model.
 type NatsNkeyProviderModel struct {
+	DefaultKeyType types.String `tfsdk:"default_key_type"`
+}
+
+// providerData is handed to the resources and ephemeral resources when the
+// provider is configured.
+type providerData struct {
+	// defaultKeyType is the type of nkey generated when a resource omits type.
+	defaultKeyType string
 }
 
 func (p *NatsNkeyProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -37,7 +52,17 @@ func (p *NatsNkeyProvider) Metadata(ctx context.Context, req provider.MetadataRe
 }
 
 func (p *NatsNkeyProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
-	resp.Schema = schema.Schema{}
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"default_key_type": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The type of nkey generated by resources that omit `type`. Must be one of user|account|server|cluster|operator|curve. Defaults to account. Changing it does not replace existing keys, since their type is kept in state",
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive(keyTypes...),
+				},
+			},
+		},
+	}
 }
 
 func (p *NatsNkeyProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
@@ -49,25 +74,107 @@ func (p *NatsNkeyProvider) Configure(ctx context.Context, req provider.Configure
 		return
 	}
 
+	if data.DefaultKeyType.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(path.Root("default_key_type"), "unknown default key type", "The default_key_type must be known when the provider is configured, it cannot depend on values only known after apply.")
+		return
+	}
+
+	pd := &providerData{defaultKeyType: defaultKeyType}
+	if !data.DefaultKeyType.IsNull() {
+		if _, err := keyTypePrefix(data.DefaultKeyType.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("default_key_type"), "invalid default key type", "The default_key_type is not a valid key type: "+err.Error())
+			return
+		}
+		pd.defaultKeyType = strings.ToLower(data.DefaultKeyType.ValueString())
+	}
+	resp.ResourceData = pd
+	resp.EphemeralResourceData = pd
+}
+
+// configuredDefaultKeyType returns the default key type from the provider
+// data handed to a resource in Configure. The provider data is nil until the
+// provider is configured, in which case the documented default is used.
+func configuredDefaultKeyType(data any, diags *diag.Diagnostics) string {
+	if data == nil {
+		return defaultKeyType
+	}
+	pd, ok := data.(*providerData)
+	if !ok {
+		diags.AddError("unexpected provider data", fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", data))
+		return defaultKeyType
+	}
+	return pd.defaultKeyType
 }
 
 func (p *NatsNkeyProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewNkey,
+		NewKeypair,
+		NewKeyset,
+		NewRotatingKeypair,
+		NewKeystoreEntry,
+		NewDerivedKey,
+		NewSeedShares,
+		NewOperatorJWT,
+		NewOperatorBootstrap,
+		NewAccountJWT,
+		NewAccount,
+		NewUserJWT,
+		NewUser,
+		NewActivationJWT,
+		NewJWTResign,
+		NewCredsFile,
 	}
 }
 
 func (p *NatsNkeyProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewPublicKeyDataSource,
+		NewParseDataSource,
+		NewCheckDataSource,
+		NewSeedFromSharesDataSource,
+		NewKeystoreSeedDataSource,
+		NewXkeyOpenDataSource,
+		NewActivationJWTDataSource,
+		NewJWTDecodeDataSource,
+		NewJWTValidateDataSource,
+		NewCredsFileDataSource,
+		NewKubernetesSecretDataSource,
+		NewEnvFileDataSource,
+		NewCLIContextDataSource,
+		NewResolverPreloadDataSource,
+		NewAuthorizationDataSource,
+	}
 }
 
 func (p *NatsNkeyProvider) Functions(ctx context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		NewOpenXkeyFunction,
+		NewVerifySignatureFunction,
+		NewSignNonceFunction,
+		NewPublicFromSeedFunction,
+		NewIsValidPublicKeyFunction,
+		NewKeyTypeFunction,
+		NewParseSizeFunction,
+		NewValidateSeedFunction,
+		NewJWTSubjectFunction,
+		NewJWTIssuerFunction,
+		NewJWTExpiresAtFunction,
+		NewJWTIsExpiredFunction,
+		NewFormatCredsFunction,
+		NewParseCredsFunction,
+	}
 }
 
 func (p *NatsNkeyProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
 	return []func() ephemeral.EphemeralResource{
 		NewNkeyEphemeral,
+		NewFromSeedEphemeral,
+		NewDecryptedSeedEphemeral,
+		NewXkeySealEphemeral,
+		NewSignatureEphemeral,
+		NewUserJWTEphemeral,
+		NewCredsEphemeral,
 	}
 }
 
@@ -0,0 +1,453 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// testProviderAddress is the address the provider under test is reattached
+// as, which configurations must require it by.
+const testProviderAddress = "registry.terraform.io/holoplot/nkey"
+
+// testProviderConfig is prepended to the configuration of every step.
+const testProviderConfig = `
+terraform {
+  required_providers {
+    nkey = {
+      source = "` + testProviderAddress + `"
+    }
+  }
+}
+`
+
+// testCase is a series of steps applied to the same working directory, in the
+// manner of resource.UnitTest. The provider is served in-process and the
+// Terraform CLI given by TF_ACC_TERRAFORM_PATH, or found on the PATH, is
+// reattached to it.
+type testCase struct {
+	// SkipBelow skips the test on Terraform versions older than it.
+	SkipBelow *version.Version
+	Steps     []testStep
+}
+
+// testStep applies a configuration.
+type testStep struct {
+	// PreConfig is called before the step is planned, e.g. to make something
+	// drift outside of Terraform.
+	PreConfig func(t *testing.T)
+	Config    string
+	// ExpectError makes the step pass only when the plan or apply fails with
+	// an error matching it. Runs of whitespace in the error are collapsed to a
+	// single space, so messages wrapped by Terraform still match.
+	ExpectError *regexp.Regexp
+	// PlanCheck is called with the plan before it is applied.
+	PlanCheck func(t *testing.T, plan *tfjson.Plan)
+	// Check is called with the state after apply.
+	Check func(t *testing.T, state *testState)
+	// ExpectNonEmptyPlan allows the plan after apply to have changes, which
+	// otherwise fail the step.
+	ExpectNonEmptyPlan bool
+	// PlanOnly only plans the configuration, so that ExpectError matches the
+	// errors found at plan time.
+	PlanOnly bool
+}
+
+// testState is the state after a step was applied.
+type testState struct {
+	*tfjson.State
+	// Raw is the state file as Terraform wrote it.
+	Raw []byte
+}
+
+var (
+	tfVersion1_8  = version.Must(version.NewVersion("1.8.0"))
+	tfVersion1_10 = version.Must(version.NewVersion("1.10.0"))
+	tfVersion1_11 = version.Must(version.NewVersion("1.11.0"))
+)
+
+// whitespacePattern matches the runs of whitespace collapsed in errors.
+var whitespacePattern = regexp.MustCompile(`\s+`)
+
+func unitTest(t *testing.T, c testCase) {
+	t.Helper()
+
+	// Every test case has its own working directory and provider server
+	t.Parallel()
+
+	execPath := os.Getenv("TF_ACC_TERRAFORM_PATH")
+	if execPath == "" {
+		var err error
+		if execPath, err = exec.LookPath("terraform"); err != nil {
+			t.Skip("the Terraform CLI was not found, set TF_ACC_TERRAFORM_PATH to run")
+		}
+	}
+	dir := t.TempDir()
+	tf, err := tfexec.NewTerraform(dir, execPath)
+	if err != nil {
+		t.Fatalf("setting up Terraform: %s", err)
+	}
+	ctx := context.Background()
+	if c.SkipBelow != nil {
+		v, _, err := tf.Version(ctx, false)
+		if err != nil {
+			t.Fatalf("reading the Terraform version: %s", err)
+		}
+		if v.LessThan(c.SkipBelow) {
+			t.Skipf("Terraform %s is older than %s", v, c.SkipBelow)
+		}
+	}
+
+	// Resources are destroyed with the last configuration that was applied,
+	// since later steps may be invalid on purpose
+	reattach := serveTestProvider(t)
+	var applied string
+	t.Cleanup(func() {
+		if applied == "" {
+			return
+		}
+		if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(applied), 0o600); err != nil {
+			t.Errorf("writing the configuration to destroy: %s", err)
+			return
+		}
+		if err := tf.Destroy(ctx, reattach); err != nil {
+			t.Errorf("destroying: %s", err)
+		}
+	})
+
+	for i, step := range c.Steps {
+		config := testProviderConfig + step.Config
+		if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(config), 0o600); err != nil {
+			t.Fatalf("step %d: writing the configuration: %s", i+1, err)
+		}
+		if i == 0 {
+			if err := tf.Init(ctx, reattach); err != nil {
+				t.Fatalf("step %d: init: %s", i+1, err)
+			}
+		}
+
+		if step.PreConfig != nil {
+			step.PreConfig(t)
+		}
+		err := runTestStep(ctx, t, tf, reattach, step)
+		if step.ExpectError != nil {
+			if err == nil {
+				t.Fatalf("step %d: expected an error matching %s", i+1, step.ExpectError)
+			}
+			if !step.ExpectError.MatchString(whitespacePattern.ReplaceAllString(err.Error(), " ")) {
+				t.Fatalf("step %d: expected an error matching %s, got: %s", i+1, step.ExpectError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("step %d: %s", i+1, err)
+		}
+		if step.PlanOnly {
+			continue
+		}
+		applied = config
+
+		if step.Check != nil {
+			state, err := tf.Show(ctx, reattach)
+			if err != nil {
+				t.Fatalf("step %d: showing the state: %s", i+1, err)
+			}
+			raw, err := os.ReadFile(filepath.Join(dir, "terraform.tfstate"))
+			if err != nil {
+				t.Fatalf("step %d: reading the state: %s", i+1, err)
+			}
+			step.Check(t, &testState{State: state, Raw: raw})
+		}
+
+		if !step.ExpectNonEmptyPlan {
+			if _, err := tf.Plan(ctx, reattach, tfexec.Out("tfplan")); err != nil {
+				t.Fatalf("step %d: planning after apply: %s", i+1, err)
+			}
+			plan, err := tf.ShowPlanFile(ctx, "tfplan", reattach)
+			if err != nil {
+				t.Fatalf("step %d: showing the plan after apply: %s", i+1, err)
+			}
+			for _, change := range plan.ResourceChanges {
+				if !change.Change.Actions.NoOp() && !change.Change.Actions.Read() {
+					t.Errorf("step %d: the plan after apply is not empty: %s %v", i+1, change.Address, change.Change.Actions)
+				}
+			}
+			if t.Failed() {
+				t.FailNow()
+			}
+		}
+	}
+}
+
+// runTestStep plans the configuration of step, checks the plan and applies it
+// unless the step is PlanOnly.
+func runTestStep(ctx context.Context, t *testing.T, tf *tfexec.Terraform, reattach *tfexec.ReattachOption, step testStep) error {
+	t.Helper()
+
+	if _, err := tf.Plan(ctx, reattach, tfexec.Out("tfplan")); err != nil {
+		return err
+	}
+	if step.PlanCheck != nil {
+		plan, err := tf.ShowPlanFile(ctx, "tfplan", reattach)
+		if err != nil {
+			t.Fatalf("showing the plan: %s", err)
+		}
+		step.PlanCheck(t, plan)
+	}
+	if step.PlanOnly {
+		return nil
+	}
+	return tf.Apply(ctx, reattach, tfexec.DirOrPlan("tfplan"))
+}
+
+// serveTestProvider serves the provider until the test ends and returns the
+// option reattaching Terraform to it.
+func serveTestProvider(t *testing.T) *tfexec.ReattachOption {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	configCh := make(chan *plugin.ReattachConfig)
+	closeCh := make(chan struct{})
+	go func() {
+		err := tf6server.Serve(testProviderAddress, providerserver.NewProtocol6(New("test")()),
+			tf6server.WithDebug(ctx, configCh, closeCh),
+			tf6server.WithGoPluginLogger(hclog.NewNullLogger()),
+			tf6server.WithLoggingSink(t),
+		)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			t.Errorf("serving the provider: %s", err)
+		}
+	}()
+
+	var config *plugin.ReattachConfig
+	select {
+	case config = <-configCh:
+	case <-time.After(10 * time.Second):
+		cancel()
+		t.Fatal("timed out waiting for the provider to start")
+	}
+	t.Cleanup(func() {
+		cancel()
+		<-closeCh
+	})
+
+	return tfexec.Reattach(tfexec.ReattachInfo{
+		testProviderAddress: tfexec.ReattachConfig{
+			Protocol:        string(config.Protocol),
+			ProtocolVersion: config.ProtocolVersion,
+			Pid:             config.Pid,
+			Test:            config.Test,
+			Addr: tfexec.ReattachConfigAddr{
+				Network: config.Addr.Network(),
+				String:  config.Addr.String(),
+			},
+		},
+	})
+}
+
+// resource returns the resource or data source at address.
+func (s *testState) resource(t *testing.T, address string) *tfjson.StateResource {
+	t.Helper()
+
+	if s.Values != nil {
+		for _, r := range s.Values.RootModule.Resources {
+			if r.Address == address {
+				return r
+			}
+		}
+	}
+	t.Fatalf("%s is not in the state", address)
+	return nil
+}
+
+// attribute returns the value of the attribute name of the resource at
+// address.
+func (s *testState) attribute(t *testing.T, address, name string) interface{} {
+	t.Helper()
+
+	return s.resource(t, address).AttributeValues[name]
+}
+
+// stringAttribute returns the attribute name of the resource at address,
+// failing the test unless it is a string.
+func (s *testState) stringAttribute(t *testing.T, address, name string) string {
+	t.Helper()
+
+	value, ok := s.attribute(t, address, name).(string)
+	if !ok {
+		t.Fatalf("%s.%s is %v, not a string", address, name, s.attribute(t, address, name))
+	}
+	return value
+}
+
+// output returns the value of the output name.
+func (s *testState) output(t *testing.T, name string) interface{} {
+	t.Helper()
+
+	if s.Values == nil || s.Values.Outputs[name] == nil {
+		t.Fatalf("output %s is not in the state", name)
+	}
+	return s.Values.Outputs[name].Value
+}
+
+// stringOutput returns the output name, failing the test unless it is a
+// string.
+func (s *testState) stringOutput(t *testing.T, name string) string {
+	t.Helper()
+
+	value, ok := s.output(t, name).(string)
+	if !ok {
+		t.Fatalf("output %s is %v, not a string", name, s.output(t, name))
+	}
+	return value
+}
+
+// resourceActions returns the planned actions of the resource at address.
+func resourceActions(t *testing.T, plan *tfjson.Plan, address string) tfjson.Actions {
+	t.Helper()
+
+	for _, change := range plan.ResourceChanges {
+		if change.Address == address {
+			return change.Change.Actions
+		}
+	}
+	t.Fatalf("%s is not in the plan", address)
+	return nil
+}
+
+// plannedUnknown reports whether the attribute name of the resource at address
+// is only known after apply, e.g. whether a JWT is planned to be issued again.
+func plannedUnknown(t *testing.T, plan *tfjson.Plan, address, name string) bool {
+	t.Helper()
+
+	for _, change := range plan.ResourceChanges {
+		if change.Address == address {
+			unknown, _ := change.Change.AfterUnknown.(map[string]interface{})
+			return unknown[name] == true
+		}
+	}
+	t.Fatalf("%s is not in the plan", address)
+	return false
+}
+
+// jwtStep returns a step that applies config with action, issuing the JWT of
+// the resource at address again when reissued is set, and checks the claims
+// that decode decodes from it.
+func jwtStep[C any](config, address string, action tfjson.Action, reissued bool, decode func(token string) (C, error), check func(t *testing.T, state *testState, claims C)) testStep {
+	return testStep{
+		Config: config,
+		PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+			expectActions(t, plan, address, action)
+			if got := plannedUnknown(t, plan, address, "jwt"); got != reissued {
+				t.Errorf("the JWT is planned to be issued again: %v, expected %v", got, reissued)
+			}
+		},
+		Check: func(t *testing.T, state *testState) {
+			claims, err := decode(state.stringAttribute(t, address, "jwt"))
+			if err != nil {
+				t.Fatalf("decoding the JWT of %s: %v", address, err)
+			}
+			check(t, state, claims)
+		},
+	}
+}
+
+// expectActions fails the test unless the resource at address is planned with
+// actions.
+func expectActions(t *testing.T, plan *tfjson.Plan, address string, actions ...tfjson.Action) {
+	t.Helper()
+
+	if got := resourceActions(t, plan, address); !slices.Equal(got, tfjson.Actions(actions)) {
+		t.Errorf("%s is planned with %v, expected %v", address, got, actions)
+	}
+}
+
+// expectEmptyPlan fails the test unless plan changes nothing at all: no
+// resource is planned with an action or has drifted, and no output changes.
+func expectEmptyPlan(t *testing.T, plan *tfjson.Plan) {
+	t.Helper()
+
+	for _, change := range plan.ResourceChanges {
+		if !change.Change.Actions.NoOp() {
+			t.Errorf("%s is planned with %v, expected an empty plan", change.Address, change.Change.Actions)
+		}
+	}
+	for _, change := range plan.ResourceDrift {
+		t.Errorf("%s changed outside of Terraform, expected an empty plan", change.Address)
+	}
+	for name, change := range plan.OutputChanges {
+		if !change.Actions.NoOp() {
+			t.Errorf("output %s is planned with %v, expected an empty plan", name, change.Actions)
+		}
+	}
+}
+
+// openEphemeral opens the ephemeral resource r in-process with the configured
+// attributes of config, all others being null, e.g. to check values that
+// Terraform never lets reach state.
+func openEphemeral(ctx context.Context, t *testing.T, r ephemeral.EphemeralResource, config map[string]tftypes.Value) (tfsdk.EphemeralResultData, diag.Diagnostics) {
+	t.Helper()
+
+	var schemaResp ephemeral.SchemaResponse
+	r.Schema(ctx, ephemeral.SchemaRequest{}, &schemaResp)
+	objectType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	if !ok {
+		t.Fatal("the ephemeral resource schema is not an object")
+	}
+	attributes := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+	}
+	for name, value := range config {
+		if _, ok := attributes[name]; !ok {
+			t.Fatalf("the ephemeral resource has no attribute %s", name)
+		}
+		attributes[name] = value
+	}
+
+	req := ephemeral.OpenRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, attributes)}}
+	resp := &ephemeral.OpenResponse{Result: tfsdk.EphemeralResultData{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)}}
+	r.Open(ctx, req, resp)
+	return resp.Result, resp.Diagnostics
+}
+
+// runFunction runs the provider function f in-process with the arguments
+// args, returning its result or its error.
+func runFunction(t *testing.T, f function.Function, args ...attr.Value) (attr.Value, *function.FuncError) {
+	t.Helper()
+
+	ctx := context.Background()
+	var definitionResp function.DefinitionResponse
+	f.Definition(ctx, function.DefinitionRequest{}, &definitionResp)
+	result, funcErr := definitionResp.Definition.Return.NewResultData(ctx)
+	if funcErr != nil {
+		t.Fatalf("the function result cannot be created: %v", funcErr)
+	}
+
+	resp := &function.RunResponse{Result: result}
+	f.Run(ctx, function.RunRequest{Arguments: function.NewArgumentsData(args)}, resp)
+	return resp.Result.Value(), resp.Error
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &PublicFromSeedFunction{}
+
+func NewPublicFromSeedFunction() function.Function {
+	return &PublicFromSeedFunction{}
+}
+
+// PublicFromSeedFunction defines the function implementation.
+type PublicFromSeedFunction struct {
+}
+
+func (f *PublicFromSeedFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "public_from_seed"
+}
+
+func (f *PublicFromSeedFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Derive the public key of an nkey seed",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Returns the public key of `seed`, like the `nkey_public_key` data source but usable in `for` expressions and `for_each`. Seeds of every type are accepted, including curve seeds. An invalid seed fails the call, and Terraform shows the value of the `for` variable it came from, so declare the seeds sensitive to keep them out of the error.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "seed",
+				MarkdownDescription: "Seed of the nkey",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *PublicFromSeedFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var seed string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &seed))
+	if resp.Error != nil {
+		return
+	}
+
+	pubKey, _, err := publicKeyFromSeed([]byte(seed))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, pubKey))
+}
@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &PublicKeyDataSource{}
+
+func NewPublicKeyDataSource() datasource.DataSource {
+	return &PublicKeyDataSource{}
+}
+
+// PublicKeyDataSource defines the data source implementation.
+type PublicKeyDataSource struct {
+}
+
+// PublicKeyDataSourceModel describes the data source data model.
+type PublicKeyDataSourceModel struct {
+	Seed      types.String `tfsdk:"seed"`
+	PublicKey types.String `tfsdk:"public_key"`
+	KeyType   types.String `tfsdk:"type"`
+}
+
+func (d *PublicKeyDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_public_key"
+}
+
+func (d *PublicKeyDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Derives the public key and type of an existing nkey from its seed.",
+
+		Attributes: map[string]schema.Attribute{
+			"seed": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Seed of the nkey",
+				Sensitive:           true,
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the nkey",
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The type of the nkey. One of user|account|server|cluster|operator|curve",
+			},
+		},
+	}
+}
+
+func (d *PublicKeyDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data PublicKeyDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	pubKey, keyType, err := publicKeyFromSeed([]byte(data.Seed.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "invalid seed", err.Error())
+		return
+	}
+
+	data.PublicKey = types.StringValue(pubKey)
+	data.KeyType = types.StringValue(keyType)
+	tflog.Trace(ctx, "read public key data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
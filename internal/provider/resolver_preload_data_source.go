@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ResolverPreloadDataSource{}
+
+func NewResolverPreloadDataSource() datasource.DataSource {
+	return &ResolverPreloadDataSource{}
+}
+
+// ResolverPreloadDataSource defines the data source implementation.
+type ResolverPreloadDataSource struct {
+}
+
+// ResolverPreloadDataSourceModel describes the data source data model.
+type ResolverPreloadDataSourceModel struct {
+	OperatorJWT   types.String `tfsdk:"operator_jwt"`
+	SystemAccount types.String `tfsdk:"system_account"`
+	AccountJWTs   types.Set    `tfsdk:"account_jwts"`
+	Config        types.String `tfsdk:"config"`
+}
+
+func (d *ResolverPreloadDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_resolver_preload"
+}
+
+func (d *ResolverPreloadDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Renders the `operator`, `system_account`, `resolver` and `resolver_preload` settings of the nats-server configuration of a memory resolver, preloading the account JWTs of an operator. Every account JWT must be validly signed by the operator nkey or one of its signing keys, as strict signing key usage allows, so the plan fails before nats-server rejects the configuration. The accounts are sorted by public key, so the configuration only changes when the JWTs do.",
+
+		Attributes: map[string]schema.Attribute{
+			"operator_jwt": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The operator JWT",
+			},
+			"system_account": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Public key of the system account. Defaults to the system account of the operator JWT, and is left out of the configuration when neither is set",
+				Validators: []validator.String{
+					isPublicKeyOfType("account"),
+				},
+			},
+			"account_jwts": schema.SetAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				MarkdownDescription: "The account JWTs to preload, at most one per account",
+			},
+			"config": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The nats-server configuration settings, to include in the server configuration",
+			},
+		},
+	}
+}
+
+func (d *ResolverPreloadDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data ResolverPreloadDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Decoding verifies the claim type and the signature against the issuer
+	operator, err := jwt.DecodeOperatorClaims(data.OperatorJWT.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("operator_jwt"), "invalid operator JWT", "The operator_jwt is not a validly signed operator JWT: "+err.Error()+".")
+		return
+	}
+	systemAccount := operator.SystemAccount
+	if !data.SystemAccount.IsNull() {
+		if systemAccount != "" && systemAccount != data.SystemAccount.ValueString() {
+			resp.Diagnostics.AddAttributeError(path.Root("system_account"), "system account mismatch", "The operator JWT names the system account "+systemAccount+" rather than "+data.SystemAccount.ValueString()+".")
+			return
+		}
+		systemAccount = data.SystemAccount.ValueString()
+	}
+
+	var tokens []string
+	resp.Diagnostics.Append(data.AccountJWTs.ElementsAs(ctx, &tokens, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	preload := make(map[string]string, len(tokens))
+	for _, token := range tokens {
+		at := path.Root("account_jwts").AtSetValue(types.StringValue(token))
+		account, err := jwt.DecodeAccountClaims(token)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(at, "invalid account JWT", "The JWT is not a validly signed account JWT: "+err.Error()+".")
+			continue
+		}
+		switch {
+		case account.Issuer == operator.Subject && operator.StrictSigningKeyUsage:
+			resp.Diagnostics.AddAttributeError(at, "invalid account JWT issuer", "The account JWT of "+account.Subject+" is signed by the operator nkey "+operator.Subject+", but the operator sets strict signing key usage, so only its signing keys may sign accounts.")
+			continue
+		case account.Issuer != operator.Subject && !slices.Contains(operator.SigningKeys, account.Issuer):
+			resp.Diagnostics.AddAttributeError(at, "invalid account JWT issuer", "The account JWT of "+account.Subject+" is signed by "+account.Issuer+", which is neither the operator "+operator.Subject+" nor one of its signing keys.")
+			continue
+		}
+		if _, ok := preload[account.Subject]; ok {
+			resp.Diagnostics.AddAttributeError(at, "duplicate account JWT", "There is more than one JWT of the account "+account.Subject+", so the memory resolver could only preload one of them.")
+			continue
+		}
+		preload[account.Subject] = token
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// JWTs and public keys need no quoting in the configuration
+	var b strings.Builder
+	b.WriteString("operator: " + data.OperatorJWT.ValueString() + "\n")
+	if systemAccount != "" {
+		b.WriteString("system_account: " + systemAccount + "\n")
+	}
+	b.WriteString("resolver: MEMORY\n")
+	b.WriteString("resolver_preload: {\n")
+	keys := slices.Sorted(maps.Keys(preload))
+	for _, key := range keys {
+		b.WriteString("  " + key + ": " + preload[key] + "\n")
+	}
+	b.WriteString("}\n")
+	data.Config = types.StringValue(b.String())
+	tflog.Trace(ctx, "read resolver preload data source", map[string]interface{}{
+		"accounts": len(keys),
+	})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/nats-server/v2/conf"
+)
+
+func TestResolverPreloadDataSource(t *testing.T) {
+	// The test operator signs the test account, and its signing key the
+	// system account. Another operator signs the test account too, and the
+	// test operator signs it twice.
+	const resources = `
+resource "nkey_keypair" "operator_signing" {
+  type = "operator"
+}
+
+resource "nkey_keypair" "other_operator" {
+  type = "operator"
+}
+
+resource "nkey_keypair" "system" {
+  type = "account"
+}
+
+resource "nkey_operator_jwt" "test" {
+  subject        = "` + testOperatorPublicKey + `"
+  signing_seed   = "` + testOperatorSeed + `"
+  name           = "test"
+  signing_keys   = [nkey_keypair.operator_signing.public_key]
+  system_account = nkey_keypair.system.public_key
+}
+
+resource "nkey_operator_jwt" "strict" {
+  subject                  = "` + testOperatorPublicKey + `"
+  signing_seed             = "` + testOperatorSeed + `"
+  name                     = "strict"
+  signing_keys             = [nkey_keypair.operator_signing.public_key]
+  strict_signing_key_usage = true
+}
+
+resource "nkey_account_jwt" "test" {
+  subject      = "` + testAccountPublicKey + `"
+  signing_seed = "` + testOperatorSeed + `"
+  name         = "test"
+}
+
+resource "nkey_account_jwt" "system" {
+  subject      = nkey_keypair.system.public_key
+  signing_seed = nkey_keypair.operator_signing.seed
+  name         = "system"
+}
+
+resource "nkey_account_jwt" "foreign" {
+  subject      = "` + testAccountPublicKey + `"
+  signing_seed = nkey_keypair.other_operator.seed
+  name         = "foreign"
+}
+
+resource "nkey_account_jwt" "again" {
+  subject      = "` + testAccountPublicKey + `"
+  signing_seed = "` + testOperatorSeed + `"
+  name         = "again"
+}
+`
+	// preload returns the resources with the preload of the accounts of
+	// operator and the attributes of body
+	preload := func(operator, accounts, body string) string {
+		return resources + `
+data "nkey_resolver_preload" "test" {
+  operator_jwt = nkey_operator_jwt.` + operator + `.jwt
+  account_jwts = [` + accounts + `]
+` + body + `
+}
+`
+	}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			{
+				Config: resources,
+			},
+			{
+				Config: preload("test", "nkey_account_jwt.test.jwt, nkey_account_jwt.system.jwt", ""),
+				Check: func(t *testing.T, state *testState) {
+					config := state.stringAttribute(t, "data.nkey_resolver_preload.test", "config")
+					system := state.stringAttribute(t, "nkey_keypair.system", "public_key")
+					accounts := map[string]interface{}{
+						testAccountPublicKey: state.stringAttribute(t, "nkey_account_jwt.test", "jwt"),
+						system:               state.stringAttribute(t, "nkey_account_jwt.system", "jwt"),
+					}
+					// nats-server reads the settings back as rendered
+					parsed, err := conf.Parse(config)
+					if err != nil {
+						t.Fatalf("the nats-server configuration parser rejects %s: %v", config, err)
+					}
+					want := map[string]interface{}{
+						"operator":         state.stringAttribute(t, "nkey_operator_jwt.test", "jwt"),
+						"system_account":   system,
+						"resolver":         "MEMORY",
+						"resolver_preload": accounts,
+					}
+					if !reflect.DeepEqual(parsed, want) {
+						t.Errorf("the nats-server configuration parser reads %#v, want %#v", parsed, want)
+					}
+					// The accounts are sorted by public key
+					first, second := testAccountPublicKey, system
+					if second < first {
+						first, second = second, first
+					}
+					if strings.Index(config, "  "+first+": ") > strings.Index(config, "  "+second+": ") {
+						t.Errorf("the accounts are not sorted by public key:\n%s", config)
+					}
+				},
+			},
+			{
+				Config:      preload("test", "nkey_account_jwt.foreign.jwt", ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`The account JWT of ` + testAccountPublicKey + ` is signed by O[A-Z2-7]{55}, which is neither the operator ` + testOperatorPublicKey + ` nor one of its signing keys`),
+			},
+			{
+				Config:      preload("strict", "nkey_account_jwt.system.jwt, nkey_account_jwt.test.jwt", ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`The account JWT of ` + testAccountPublicKey + ` is signed by the operator nkey ` + testOperatorPublicKey + `, but the operator sets strict signing key usage`),
+			},
+			{
+				Config:      preload("test", "nkey_account_jwt.test.jwt, nkey_account_jwt.again.jwt", ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`There is more than one JWT of the account ` + testAccountPublicKey),
+			},
+			{
+				Config:      preload("test", "nkey_account_jwt.test.jwt", `system_account = "`+testAccountPublicKey+`"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`The operator JWT names the system account A[A-Z2-7]{55} rather than ` + testAccountPublicKey),
+			},
+		},
+	})
+}
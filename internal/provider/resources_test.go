@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestResourcesSecondApply(t *testing.T) {
+	// The files the resources write go to a directory of the test
+	dir := filepath.ToSlash(t.TempDir())
+	// The configuration of each resource type, with any resources it needs
+	tests := map[string]string{
+		"nkey_nkey": `
+resource "nkey_nkey" "test" {
+  type = "user"
+}
+`,
+		"nkey_keypair": `
+resource "nkey_keypair" "test" {
+  type                = "account"
+  include_private_key = true
+}
+`,
+		"nkey_keyset": `
+resource "nkey_keyset" "test" {
+  type  = "user"
+  names = ["billing", "orders"]
+}
+`,
+		"nkey_rotating_keypair": `
+resource "nkey_rotating_keypair" "test" {
+  type          = "account"
+  rotation_days = 90
+}
+`,
+		"nkey_keystore_entry": `
+resource "nkey_keystore_entry" "test" {
+  keystore_root = "` + dir + `/nkeys"
+  seed          = "` + testUserSeed + `"
+}
+`,
+		"nkey_derived_key": `
+resource "nkey_derived_key" "test" {
+  type           = "user"
+  master_seed_wo = "0123456789abcdef0123456789abcdef"
+  path           = "tenants/acme/device-042"
+}
+`,
+		"nkey_seed_shares": `
+resource "nkey_seed_shares" "test" {
+  shares    = 3
+  threshold = 2
+}
+`,
+		"nkey_operator_jwt": `
+resource "nkey_operator_jwt" "test" {
+  subject      = "` + testOperatorPublicKey + `"
+  signing_seed = "` + testOperatorSeed + `"
+  name         = "test"
+}
+`,
+		"nkey_operator_bootstrap": `
+resource "nkey_operator_bootstrap" "test" {
+  name = "test"
+}
+`,
+		"nkey_account_jwt": accountJWTConfig(`limits = { max_connections = 10 }`),
+		"nkey_account": `
+resource "nkey_account" "test" {
+  signing_seed = "` + testOperatorSeed + `"
+  name         = "test"
+}
+`,
+		"nkey_user_jwt": userJWTConfig(`name = "alice"`),
+		"nkey_user": `
+resource "nkey_user" "test" {
+  signing_seed = "` + testAccountSeed + `"
+  name         = "alice"
+}
+`,
+		"nkey_activation_jwt": accountJWTConfig(`exports = [{ name = "charge", subject = "billing.charge", type = "service", token_required = true }]`) + `
+resource "nkey_activation_jwt" "test" {
+  signing_seed     = "` + testAccountSeed + `"
+  account_jwt      = nkey_account_jwt.test.jwt
+  export_subject   = "billing.charge"
+  export_type      = "service"
+  importer_account = "` + testAccountSigningPublicKey + `"
+}
+`,
+		"nkey_jwt_resign": userJWTConfig("") + `
+resource "nkey_jwt_resign" "test" {
+  source_jwt         = nkey_user_jwt.test.jwt
+  signing_seed       = "` + testAccountSigningSeed + `"
+  new_issuer_account = "` + testAccountPublicKey + `"
+}
+`,
+		"nkey_creds_file": userJWTConfig("") + `
+resource "nkey_creds_file" "test" {
+  path = "` + dir + `/test.creds"
+  jwt  = nkey_user_jwt.test.jwt
+  seed = "` + testUserSeed + `"
+}
+`,
+	}
+	for resourceType, config := range tests {
+		t.Run(resourceType, func(t *testing.T) {
+			var applied []tfjson.StateResource
+			unitTest(t, testCase{
+				SkipBelow: tfVersion1_11,
+				Steps: []testStep{
+					{
+						Config: config,
+						Check: func(t *testing.T, state *testState) {
+							for _, r := range state.Values.RootModule.Resources {
+								applied = append(applied, *r)
+							}
+						},
+					},
+					// Applying the same configuration again a second later
+					// plans nothing, and leaves every attribute as it was
+					{
+						PreConfig: func(t *testing.T) { time.Sleep(time.Second) },
+						Config:    config,
+						PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+							expectActions(t, plan, resourceType+".test", tfjson.ActionNoop)
+							expectEmptyPlan(t, plan)
+						},
+						Check: func(t *testing.T, state *testState) {
+							for _, r := range applied {
+								if got := state.resource(t, r.Address).AttributeValues; !reflect.DeepEqual(got, r.AttributeValues) {
+									t.Errorf("%s changed on the second apply from %v to %v", r.Address, r.AttributeValues, got)
+								}
+							}
+						},
+					},
+				},
+			})
+		})
+	}
+}
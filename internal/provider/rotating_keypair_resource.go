@@ -0,0 +1,284 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &RotatingKeypair{}
+var _ resource.ResourceWithConfigure = &RotatingKeypair{}
+var _ resource.ResourceWithModifyPlan = &RotatingKeypair{}
+
+func NewRotatingKeypair() resource.Resource {
+	return &RotatingKeypair{}
+}
+
+// RotatingKeypair defines the resource implementation.
+type RotatingKeypair struct {
+	// defaultKeyType is the type generated when type is omitted.
+	defaultKeyType string
+}
+
+// RotatingKeypairModel describes the resource data model.
+type RotatingKeypairModel struct {
+	KeyModel
+	SeedEncryptionModel
+	RotationDays    types.Int64  `tfsdk:"rotation_days"`
+	RotationRFC3339 types.String `tfsdk:"rotation_rfc3339"`
+	ForceRotate     types.String `tfsdk:"force_rotate"`
+	RotatedAt       types.String `tfsdk:"rotated_at"`
+	NextRotation    types.String `tfsdk:"next_rotation"`
+}
+
+// nextRotation returns when the key pair is due for rotation, computed from
+// the time it was generated so that changing the rotation does not reset the
+// clock.
+func (m *RotatingKeypairModel) nextRotation() (time.Time, error) {
+	if !m.RotationRFC3339.IsNull() {
+		return time.Parse(time.RFC3339, m.RotationRFC3339.ValueString())
+	}
+
+	rotatedAt, err := time.Parse(time.RFC3339, m.RotatedAt.ValueString())
+	if err != nil {
+		return time.Time{}, err
+	}
+	return rotatedAt.AddDate(0, 0, int(m.RotationDays.ValueInt64())), nil
+}
+
+// rotationDue reports whether the key pair is due for rotation at now. A
+// fixed rotation_rfc3339 only rotates key pairs generated before it, so the
+// replacement is not rotated again.
+func (m *RotatingKeypairModel) rotationDue(now time.Time) (bool, error) {
+	next, err := m.nextRotation()
+	if err != nil {
+		return false, err
+	}
+	rotatedAt, err := time.Parse(time.RFC3339, m.RotatedAt.ValueString())
+	if err != nil {
+		return false, err
+	}
+	return !now.Before(next) && rotatedAt.Before(next), nil
+}
+
+// setNextRotation stores the next rotation time in the model.
+func (m *RotatingKeypairModel) setNextRotation() error {
+	next, err := m.nextRotation()
+	if err != nil {
+		return err
+	}
+	m.NextRotation = types.StringValue(next.UTC().Format(time.RFC3339))
+	return nil
+}
+
+func (r *RotatingKeypair) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rotating_keypair"
+}
+
+func (r *RotatingKeypair) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A rotating keypair is an nkey that is replaced by a fresh key pair once its rotation time has passed. The rotation is checked whenever a plan is made, so the key pair is rotated by the first apply after the rotation time.",
+
+		Attributes: keyResourceAttributes(seedEncryptionResourceAttributes(map[string]schema.Attribute{
+			"type": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The type of nkey to generate. Must be one of user|account|server|cluster|operator|curve. Defaults to the provider `default_key_type`, or account",
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive(keyTypes...),
+				},
+				PlanModifiers: []planmodifier.String{
+					caseInsensitive(),
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rotation_days": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Number of days after `rotated_at` the key pair is rotated. Changing it moves `next_rotation` relative to the original `rotated_at` rather than resetting the clock. Conflicts with `rotation_rfc3339`",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+					int64validator.ExactlyOneOf(path.MatchRoot("rotation_rfc3339")),
+				},
+			},
+			"rotation_rfc3339": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "RFC 3339 timestamp at which a key pair generated before it is rotated. Conflicts with `rotation_days`",
+				Validators: []validator.String{
+					isRFC3339(),
+				},
+			},
+			"force_rotate": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Arbitrary value that, when changed, rotates the key pair immediately, e.g. for an emergency rotation",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rotated_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp of when the key pair was generated",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"next_rotation": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp of when the key pair is due for rotation",
+			},
+		})),
+	}
+}
+
+func (r *RotatingKeypair) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.defaultKeyType = configuredDefaultKeyType(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *RotatingKeypair) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	resp.Diagnostics.Append(planDefaultKeyType(ctx, req.Config, &resp.Plan, r.defaultKeyType)...)
+	resp.Diagnostics.Append(planIncludePrivateKey(ctx, req.State, &resp.Plan)...)
+	resp.Diagnostics.Append(planFingerprintLength(ctx, req.State, &resp.Plan)...)
+	resp.Diagnostics.Append(planSeedEncryption(ctx, req.State, &resp.Plan)...)
+	if resp.Diagnostics.HasError() || req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		// The rotation is only due for existing key pairs
+		return
+	}
+
+	var plan RotatingKeypairModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.RotationDays.IsUnknown() || plan.RotationRFC3339.IsUnknown() {
+		return
+	}
+
+	due, err := plan.rotationDue(time.Now())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("rotated_at"), "planning rotation", err.Error())
+		return
+	}
+	if due {
+		// Terraform only replaces resources whose planned values change
+		tflog.Debug(ctx, "rotating keypair is due for rotation")
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("rotated_at"), types.StringUnknown())...)
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("rotated_at"))
+		return
+	}
+
+	if err := plan.setNextRotation(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("rotated_at"), "planning rotation", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("next_rotation"), plan.NextRotation)...)
+}
+
+func (r *RotatingKeypair) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data RotatingKeypairModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := data.generateKeys(); err != nil {
+		addKeyError(&resp.Diagnostics, err)
+		return
+	}
+	data.RotatedAt = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+	if err := data.setNextRotation(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("rotation_rfc3339"), "generating rotating keypair", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(data.encryptSeed(ctx, req.Config, data.Seed)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(data.warnings()...)
+	tflog.Trace(ctx, "created rotating keypair resource", data.logFields())
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RotatingKeypair) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data RotatingKeypairModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A type configured in another case is stored as configured when the
+	// resource is created, so store the canonical form from now on
+	data.KeyType = canonicalKeyType(data.KeyType)
+
+	// Fill in attributes derived from the seed that older states lack
+	if err := data.deriveKeys(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "reading rotating keypair", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RotatingKeypair) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var plan RotatingKeypairModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The rotation settings, include_private_key, fingerprint_length and
+	// encryption_passphrase_wo_version change in place, so rederive
+	// everything but the key pair itself
+	if err := plan.deriveKeys(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "updating rotating keypair", err.Error())
+		return
+	}
+	if err := plan.setNextRotation(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("rotation_rfc3339"), "updating rotating keypair", err.Error())
+		return
+	}
+	if plan.SeedEncrypted.IsUnknown() {
+		resp.Diagnostics.Append(plan.encryptSeed(ctx, req.Config, plan.Seed)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *RotatingKeypair) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed
+	tflog.Trace(ctx, "deleted rotating keypair resource")
+}
@@ -0,0 +1,197 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// sealedSeedScheme identifies the encrypted seed format: the seed sealed with
+// NaCl secretbox under a key derived from the passphrase with scrypt, encoded
+// as nkey-seed-v1:<base64url salt>:<base64url nonce and box>.
+const sealedSeedScheme = "nkey-seed-v1"
+
+// The scrypt parameters of sealedSeedScheme, the ones recommended for
+// interactive use.
+const (
+	sealedSeedScryptN = 1 << 15
+	sealedSeedScryptR = 8
+	sealedSeedScryptP = 1
+	sealedSeedSaltLen = 16
+)
+
+// errWrongPassphrase is returned when an encrypted seed fails authentication.
+var errWrongPassphrase = errors.New("the passphrase is wrong or the encrypted seed was altered")
+
+// sealSeed encrypts seed with passphrase, reading the salt and nonce from rr.
+func sealSeed(seed, passphrase []byte, rr io.Reader) (string, error) {
+	salt := make([]byte, sealedSeedSaltLen)
+	if _, err := io.ReadFull(rr, salt); err != nil {
+		return "", err
+	}
+	var nonce [24]byte
+	if _, err := io.ReadFull(rr, nonce[:]); err != nil {
+		return "", err
+	}
+	key, err := sealedSeedKey(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(key[:])
+
+	box := secretbox.Seal(nonce[:], seed, &nonce, key)
+	return strings.Join([]string{
+		sealedSeedScheme,
+		base64.RawURLEncoding.EncodeToString(salt),
+		base64.RawURLEncoding.EncodeToString(box),
+	}, ":"), nil
+}
+
+// openSealedSeed decrypts an encrypted seed made by sealSeed. A wrong
+// passphrase fails authentication rather than returning garbage.
+func openSealedSeed(sealed string, passphrase []byte) ([]byte, error) {
+	parts := strings.Split(strings.TrimSpace(sealed), ":")
+	if len(parts) != 3 || parts[0] != sealedSeedScheme {
+		return nil, fmt.Errorf("the encrypted seed is not an %s envelope", sealedSeedScheme)
+	}
+	salt, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(salt) != sealedSeedSaltLen {
+		return nil, errors.New("the salt of the encrypted seed is corrupted, check for stray characters or truncation")
+	}
+	box, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(box) < 24+secretbox.Overhead {
+		return nil, errors.New("the encrypted seed is corrupted, check for stray characters or truncation")
+	}
+
+	key, err := sealedSeedKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key[:])
+
+	var nonce [24]byte
+	copy(nonce[:], box)
+	seed, ok := secretbox.Open(nil, box[24:], &nonce, key)
+	if !ok {
+		return nil, errWrongPassphrase
+	}
+	return seed, nil
+}
+
+// sealedSeedKey derives the secretbox key from passphrase and salt.
+func sealedSeedKey(passphrase, salt []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key(passphrase, salt, sealedSeedScryptN, sealedSeedScryptR, sealedSeedScryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(derived)
+
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
+
+// SeedEncryptionModel describes the attributes that encrypt the seed of a
+// managed nkey resource with a passphrase.
+type SeedEncryptionModel struct {
+	EncryptionPassphraseWO        types.String `tfsdk:"encryption_passphrase_wo"`
+	EncryptionPassphraseWOVersion types.Int64  `tfsdk:"encryption_passphrase_wo_version"`
+	SeedEncrypted                 types.String `tfsdk:"seed_encrypted"`
+}
+
+// encryptSeed stores seed encrypted with the configured passphrase, or clears
+// seed_encrypted when no passphrase is configured. The passphrase is
+// write-only, so it is read from config.
+func (e *SeedEncryptionModel) encryptSeed(ctx context.Context, config tfsdk.Config, seed types.String) diag.Diagnostics {
+	diags := config.GetAttribute(ctx, path.Root("encryption_passphrase_wo"), &e.EncryptionPassphraseWO)
+	if diags.HasError() {
+		return diags
+	}
+	defer func() { e.EncryptionPassphraseWO = types.StringNull() }()
+
+	if e.EncryptionPassphraseWO.IsNull() {
+		e.SeedEncrypted = types.StringNull()
+		return diags
+	}
+	if seed.IsNull() {
+		diags.AddAttributeError(path.Root("encryption_passphrase_wo_version"), "encrypting seed", "The seed was given write-only and is not kept in state, so it cannot be encrypted again. Bump seed_wo_version to encrypt the seed with the new passphrase.")
+		return diags
+	}
+
+	sealed, err := sealSeed([]byte(seed.ValueString()), []byte(e.EncryptionPassphraseWO.ValueString()), rand.Reader)
+	if err != nil {
+		diags.AddAttributeError(path.Root("encryption_passphrase_wo"), "encrypting seed", err.Error())
+		return diags
+	}
+	e.SeedEncrypted = types.StringValue(sealed)
+	return diags
+}
+
+// planSeedEncryption plans seed_encrypted as unknown when
+// encryption_passphrase_wo_version changes on an existing resource, so that
+// the seed is encrypted again in place.
+func planSeedEncryption(ctx context.Context, state tfsdk.State, plan *tfsdk.Plan) diag.Diagnostics {
+	if state.Raw.IsNull() || plan.Raw.IsNull() {
+		// The resource is being created or destroyed
+		return nil
+	}
+
+	var prior, planned types.Int64
+	diags := state.GetAttribute(ctx, path.Root("encryption_passphrase_wo_version"), &prior)
+	diags.Append(plan.GetAttribute(ctx, path.Root("encryption_passphrase_wo_version"), &planned)...)
+	if diags.HasError() || prior.Equal(planned) {
+		return diags
+	}
+
+	diags.Append(plan.SetAttribute(ctx, path.Root("seed_encrypted"), types.StringUnknown())...)
+	return diags
+}
+
+// seedEncryptionResourceAttributes adds the SeedEncryptionModel attributes to
+// the schema attributes of a managed nkey resource.
+func seedEncryptionResourceAttributes(attrs map[string]schema.Attribute) map[string]schema.Attribute {
+	attrs["encryption_passphrase_wo"] = schema.StringAttribute{
+		Optional:            true,
+		WriteOnly:           true,
+		Sensitive:           true,
+		MarkdownDescription: "Passphrase to encrypt the seed with into `seed_encrypted`, e.g. to hand the seed to another team. The value is only read when the resource is created, so bump `encryption_passphrase_wo_version` to encrypt with a new passphrase. Requires Terraform 1.11 or later",
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(8),
+		},
+	}
+	attrs["encryption_passphrase_wo_version"] = schema.Int64Attribute{
+		Optional:            true,
+		MarkdownDescription: "Version marker for `encryption_passphrase_wo`. Changing it encrypts the seed again with the current passphrase, without replacing the key",
+		Validators: []validator.Int64{
+			int64validator.AlsoRequires(path.MatchRoot("encryption_passphrase_wo")),
+		},
+	}
+	attrs["seed_encrypted"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "The seed encrypted with `encryption_passphrase_wo`, of the form `" + sealedSeedScheme + ":<salt>:<box>`: NaCl secretbox under a key derived with scrypt. Decrypt it with the `nkey_decrypted_seed` ephemeral resource. Null without a passphrase",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+	return attrs
+}
@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &SeedFromSharesDataSource{}
+
+func NewSeedFromSharesDataSource() datasource.DataSource {
+	return &SeedFromSharesDataSource{}
+}
+
+// SeedFromSharesDataSource defines the data source implementation.
+type SeedFromSharesDataSource struct {
+}
+
+// SeedFromSharesDataSourceModel describes the data source data model.
+type SeedFromSharesDataSourceModel struct {
+	Shares    types.List   `tfsdk:"shares"`
+	Seed      types.String `tfsdk:"seed"`
+	PublicKey types.String `tfsdk:"public_key"`
+	KeyType   types.String `tfsdk:"type"`
+}
+
+func (d *SeedFromSharesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_seed_from_shares"
+}
+
+func (d *SeedFromSharesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Recombines an nkey seed from a quorum of the Shamir secret shares made by the `nkey_seed_shares` resource, for disaster recovery. Like every data source result the seed ends up in state, so only use it while recovering.",
+
+		Attributes: map[string]schema.Attribute{
+			"shares": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "At least `threshold` distinct shares of the same seed, in any order",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"seed": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The recombined seed",
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the recombined seed",
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The type of the recombined seed. One of user|account|server|cluster|operator|curve",
+			},
+		},
+	}
+}
+
+func (d *SeedFromSharesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data SeedFromSharesDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var encoded []string
+	resp.Diagnostics.Append(data.Shares.ElementsAs(ctx, &encoded, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	shares := make([]share, len(encoded))
+	for i, e := range encoded {
+		s, err := parseShare(e)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("shares").AtListIndex(i), "invalid share", err.Error())
+			return
+		}
+		shares[i] = s
+	}
+
+	seed, err := combineShares(shares)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("shares"), "recombining seed", err.Error())
+		return
+	}
+	defer wipe(seed)
+
+	// A corrupted share recombines to garbage, which the seed checksum or the
+	// fingerprint in the share set catches
+	keys, keyType, err := parseSeed(seed)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("shares"), "recombining seed", "The shares do not recombine to a valid seed, one of them is corrupted: "+err.Error())
+		return
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("shares"), "recombining seed", err.Error())
+		return
+	}
+	fp, err := fingerprint(pubKey)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("shares"), "recombining seed", err.Error())
+		return
+	}
+	if fp[:fingerprintShortLen] != shares[0].set {
+		resp.Diagnostics.AddAttributeError(path.Root("shares"), "recombining seed", fmt.Sprintf("The shares recombine to the public key %s, which does not match the share set %s, one of the shares is corrupted.", pubKey, shares[0].set))
+		return
+	}
+
+	data.Seed = types.StringValue(string(seed))
+	data.PublicKey = types.StringValue(pubKey)
+	data.KeyType = types.StringValue(keyType)
+	tflog.Trace(ctx, "read seed from shares data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
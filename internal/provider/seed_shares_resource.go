@@ -0,0 +1,283 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &SeedShares{}
+var _ resource.ResourceWithValidateConfig = &SeedShares{}
+
+func NewSeedShares() resource.Resource {
+	return &SeedShares{}
+}
+
+// SeedShares defines the resource implementation.
+type SeedShares struct {
+}
+
+// SeedSharesModel describes the resource data model.
+type SeedSharesModel struct {
+	KeyType       types.String `tfsdk:"type"`
+	SeedWO        types.String `tfsdk:"seed_wo"`
+	SeedWOVersion types.Int64  `tfsdk:"seed_wo_version"`
+	Shares        types.Int64  `tfsdk:"shares"`
+	Threshold     types.Int64  `tfsdk:"threshold"`
+	PublicKey     types.String `tfsdk:"public_key"`
+	SecretShares  types.List   `tfsdk:"secret_shares"`
+}
+
+// defaultSharedKeyType is the type of nkey generated for sharing when no seed
+// is given, since sharing is meant for the operator root of trust.
+const defaultSharedKeyType = "operator"
+
+func (r *SeedShares) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_seed_shares"
+}
+
+func (r *SeedShares) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Splits an nkey seed into Shamir secret shares, of which any `threshold` recombine to the seed with the `nkey_seed_from_shares` data source. The seed itself is never stored in state, but all shares are, so hand the shares out and keep the state as protected as the seed.",
+
+		Attributes: map[string]schema.Attribute{
+			"type": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The type of nkey to generate and split. Must be one of user|account|server|cluster|operator|curve. Defaults to operator, or to the type of `seed_wo` when given",
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive(keyTypes...),
+				},
+				PlanModifiers: []planmodifier.String{
+					caseInsensitive(),
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"seed_wo": schema.StringAttribute{
+				Optional:            true,
+				WriteOnly:           true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed to split. When not set a fresh key is generated. The value is only read when the resource is created, so bump `seed_wo_version` to split a new seed. Requires Terraform 1.11 or later",
+			},
+			"seed_wo_version": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Version marker for `seed_wo`. Changing it replaces the resource with shares of the current `seed_wo`",
+				Validators: []validator.Int64{
+					int64validator.AlsoRequires(path.MatchRoot("seed_wo")),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"shares": schema.Int64Attribute{
+				Required:            true,
+				MarkdownDescription: fmt.Sprintf("Number of shares to split the seed into, at most %d", maxShares),
+				Validators: []validator.Int64{
+					int64validator.Between(2, maxShares),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"threshold": schema.Int64Attribute{
+				Required:            true,
+				MarkdownDescription: "Number of shares needed to recombine the seed, at least 2 and at most `shares`",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(2),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the split nkey",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"secret_shares": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The shares, each of the form `" + shareScheme + ":<set>:<threshold>:<index>:<data>`. The set is the first 8 characters of the public key fingerprint, so shares of different seeds are told apart",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *SeedShares) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data SeedSharesModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Shares.IsUnknown() || data.Threshold.IsUnknown() {
+		return
+	}
+	if data.Threshold.ValueInt64() > data.Shares.ValueInt64() {
+		resp.Diagnostics.AddAttributeError(path.Root("threshold"), "threshold exceeds shares", fmt.Sprintf("The threshold of %d is larger than the %d shares, so the seed could never be recombined.", data.Threshold.ValueInt64(), data.Shares.ValueInt64()))
+	}
+}
+
+func (r *SeedShares) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data SeedSharesModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Write-only values are only available in the configuration
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("seed_wo"), &data.SeedWO)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var keys nkeys.KeyPair
+	if data.SeedWO.IsNull() {
+		if data.KeyType.IsUnknown() {
+			data.KeyType = types.StringValue(defaultSharedKeyType)
+		}
+		prefix, err := keyTypePrefix(data.KeyType.ValueString())
+		if err != nil {
+			addKeyError(&resp.Diagnostics, err)
+			return
+		}
+		if keys, err = nkeys.CreatePair(prefix); err != nil {
+			addKeyError(&resp.Diagnostics, err)
+			return
+		}
+	} else {
+		var keyType string
+		var err error
+		keys, keyType, err = parseSeed([]byte(data.SeedWO.ValueString()))
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("seed_wo"), "invalid seed", "The seed could not be decoded: "+err.Error())
+			return
+		}
+		if !data.KeyType.IsUnknown() && !strings.EqualFold(data.KeyType.ValueString(), keyType) {
+			keys.Wipe()
+			resp.Diagnostics.AddAttributeError(path.Root("type"), "seed type mismatch", "The seed is of type "+keyType+" but type is set to "+data.KeyType.ValueString()+".")
+			return
+		}
+		if data.KeyType.IsUnknown() {
+			data.KeyType = types.StringValue(keyType)
+		}
+		data.SeedWO = types.StringNull()
+	}
+	defer keys.Wipe()
+
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		addKeyError(&resp.Diagnostics, err)
+		return
+	}
+	seed, err := keys.Seed()
+	if err != nil {
+		addKeyError(&resp.Diagnostics, err)
+		return
+	}
+	defer wipe(seed)
+	fp, err := fingerprint(pubKey)
+	if err != nil {
+		addKeyError(&resp.Diagnostics, err)
+		return
+	}
+
+	shares, err := splitSecret(seed, int(data.Shares.ValueInt64()), int(data.Threshold.ValueInt64()), fp[:fingerprintShortLen], rand.Reader)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("threshold"), "splitting seed", err.Error())
+		return
+	}
+	encoded := make([]string, len(shares))
+	for i, s := range shares {
+		encoded[i] = s.String()
+	}
+
+	data.PublicKey = types.StringValue(pubKey)
+	var diags diag.Diagnostics
+	data.SecretShares, diags = types.ListValueFrom(ctx, types.StringType, encoded)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created seed shares resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SeedShares) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data SeedSharesModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A type configured in another case is stored as configured when the
+	// resource is created, so store the canonical form from now on
+	data.KeyType = canonicalKeyType(data.KeyType)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SeedShares) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Every configurable attribute requires replacement, so there is nothing
+	// to update in place.
+	var plan SeedSharesModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *SeedShares) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed
+	tflog.Trace(ctx, "deleted seed shares resource")
+}
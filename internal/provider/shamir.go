@@ -0,0 +1,174 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// shareScheme identifies the share format and the secret sharing scheme: Shamir
+// secret sharing over GF(2^8) with the AES reduction polynomial, one random
+// polynomial per secret byte, evaluated at the share index.
+const shareScheme = "nkey-shamir-v1"
+
+// maxShares is the maximum number of shares, since the share index is a
+// non-zero element of GF(2^8).
+const maxShares = 255
+
+// share is a single Shamir share of a seed.
+type share struct {
+	// set identifies the shares split from the same seed. It is the
+	// fingerprint_short of the public key of the seed.
+	set       string
+	threshold int
+	index     int
+	data      []byte
+}
+
+// String encodes the share as
+// nkey-shamir-v1:<set>:<threshold>:<index>:<base64url data>.
+func (s share) String() string {
+	return strings.Join([]string{
+		shareScheme,
+		s.set,
+		strconv.Itoa(s.threshold),
+		strconv.Itoa(s.index),
+		base64.RawURLEncoding.EncodeToString(s.data),
+	}, ":")
+}
+
+// parseShare decodes a share encoded by share.String. Errors never include the
+// share itself.
+func parseShare(encoded string) (share, error) {
+	parts := strings.Split(strings.TrimSpace(encoded), ":")
+	if len(parts) != 5 || parts[0] != shareScheme {
+		return share{}, fmt.Errorf("the share is not an %s share", shareScheme)
+	}
+
+	threshold, err := strconv.Atoi(parts[2])
+	if err != nil || threshold < 2 || threshold > maxShares {
+		return share{}, errors.New("the share has an invalid threshold")
+	}
+	index, err := strconv.Atoi(parts[3])
+	if err != nil || index < 1 || index > maxShares {
+		return share{}, errors.New("the share has an invalid index")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[4])
+	if err != nil || len(data) == 0 {
+		return share{}, fmt.Errorf("the data of share %d is not valid base64url, check for stray characters or truncation", index)
+	}
+
+	return share{set: parts[1], threshold: threshold, index: index, data: data}, nil
+}
+
+// splitSecret splits secret into n shares of which any threshold recombine to
+// the secret, reading the polynomial coefficients from rr.
+func splitSecret(secret []byte, n, threshold int, set string, rr io.Reader) ([]share, error) {
+	if threshold < 2 || threshold > n || n > maxShares {
+		return nil, fmt.Errorf("the threshold must be between 2 and the number of shares, and there can be at most %d shares", maxShares)
+	}
+
+	shares := make([]share, n)
+	for i := range shares {
+		shares[i] = share{set: set, threshold: threshold, index: i + 1, data: make([]byte, len(secret))}
+	}
+
+	coeffs := make([]byte, threshold)
+	defer wipe(coeffs)
+	for b, s := range secret {
+		coeffs[0] = s
+		if _, err := io.ReadFull(rr, coeffs[1:]); err != nil {
+			return nil, err
+		}
+		for i := range shares {
+			shares[i].data[b] = gfEval(coeffs, byte(shares[i].index))
+		}
+	}
+	return shares, nil
+}
+
+// combineShares recombines the secret from at least threshold shares of the
+// same set.
+func combineShares(shares []share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	first := shares[0]
+	seen := map[int]bool{}
+	for _, s := range shares {
+		switch {
+		case s.set != first.set:
+			return nil, fmt.Errorf("share %d belongs to the set %s but share %d to the set %s, the shares were split from different seeds", s.index, s.set, first.index, first.set)
+		case s.threshold != first.threshold:
+			return nil, fmt.Errorf("share %d has the threshold %d but share %d has %d", s.index, s.threshold, first.index, first.threshold)
+		case len(s.data) != len(first.data):
+			return nil, fmt.Errorf("share %d is truncated", s.index)
+		case seen[s.index]:
+			return nil, fmt.Errorf("share %d is given more than once", s.index)
+		}
+		seen[s.index] = true
+	}
+	if len(shares) < first.threshold {
+		return nil, fmt.Errorf("%d shares given but %d are needed to recombine the seed", len(shares), first.threshold)
+	}
+	shares = shares[:first.threshold]
+
+	secret := make([]byte, len(first.data))
+	for b := range secret {
+		// Lagrange interpolation at x = 0
+		var value byte
+		for i, si := range shares {
+			basis := byte(1)
+			for j, sj := range shares {
+				if i == j {
+					continue
+				}
+				xi, xj := byte(si.index), byte(sj.index)
+				basis = gfMul(basis, gfMul(xj, gfInv(xi^xj)))
+			}
+			value ^= gfMul(si.data[b], basis)
+		}
+		secret[b] = value
+	}
+	return secret, nil
+}
+
+// gfEval evaluates the polynomial with the given coefficients, lowest degree
+// first, at x.
+func gfEval(coeffs []byte, x byte) byte {
+	var y byte
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coeffs[i]
+	}
+	return y
+}
+
+// gfMul multiplies a and b in GF(2^8) modulo x^8 + x^4 + x^3 + x + 1 without
+// data dependent branches.
+func gfMul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= -(b & 1) & a
+		carry := -(a >> 7)
+		a = (a << 1) ^ (carry & 0x1b)
+		b >>= 1
+	}
+	return p
+}
+
+// gfInv returns the multiplicative inverse of a non-zero a in GF(2^8), which
+// is a^254.
+func gfInv(a byte) byte {
+	result := byte(1)
+	for i := 0; i < 254; i++ {
+		result = gfMul(result, a)
+	}
+	return result
+}
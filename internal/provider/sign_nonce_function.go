@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &SignNonceFunction{}
+
+func NewSignNonceFunction() function.Function {
+	return &SignNonceFunction{}
+}
+
+// SignNonceFunction defines the function implementation.
+type SignNonceFunction struct {
+}
+
+func (f *SignNonceFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "sign_nonce"
+}
+
+func (f *SignNonceFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Sign a NATS server nonce with an nkey seed",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Signs the `nonce` of a nats-server `INFO` message with `seed` and returns the signature the way the `sig` field of `CONNECT` expects it: base64 URL encoded without padding. Useful to debug nkey authentication without a client.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "seed",
+				MarkdownDescription: "Seed of the nkey to sign with, usually a user seed. Curve seeds cannot sign",
+			},
+			function.StringParameter{
+				Name:                "nonce",
+				MarkdownDescription: "The nonce as sent by the server, signed as is",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *SignNonceFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var seed, nonce string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &seed, &nonce))
+	if resp.Error != nil {
+		return
+	}
+
+	keys, err := signingKeys(seed)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	defer keys.Wipe()
+
+	signature, err := keys.Sign([]byte(nonce))
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	// The CONNECT protocol uses the URL alphabet without padding
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, base64.RawURLEncoding.EncodeToString(signature)))
+}
@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/nkeys"
+)
+
+func TestSignNonceFunction(t *testing.T) {
+	// The nonce of the INFO of a nats-server 2.10 handshake and the sig of
+	// the CONNECT it accepted from the test user, which has both characters
+	// of the URL alphabet and would be padded in the standard one
+	const (
+		nonce     = "vm8meqei5X_vt_k"
+		signature = "5S1oKZwVyf1WuyFibhBNriZs4N-eD9GmsaV_qOGbXDFXNfhbg1SJjz5T1bqAtD6llmR0Tl0bHH-IAw9TI1ziDw"
+	)
+
+	got, funcErr := runFunction(t, &SignNonceFunction{}, types.StringValue(testUserSeed), types.StringValue(nonce))
+	if funcErr != nil || !got.Equal(types.StringValue(signature)) {
+		t.Fatalf("sign_nonce() = %v, %v, want %s", got, funcErr, signature)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := nkeys.FromPublicKey(testUserPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Verify([]byte(nonce), raw); err != nil {
+		t.Errorf("the signature does not verify: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		seed    string
+		wantErr string
+	}{
+		{name: "public key", seed: testUserPublicKey, wantErr: "seed"},
+		{name: "bad checksum", seed: testUserSeed[:57] + "A", wantErr: "seed"},
+		{name: "empty", seed: "", wantErr: "empty"},
+		{name: "curve seed", seed: testCurveSeed, wantErr: "curve keys are x25519 keys"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, funcErr := runFunction(t, &SignNonceFunction{}, types.StringValue(tt.seed), types.StringValue(nonce))
+			if funcErr == nil || funcErr.FunctionArgument == nil || *funcErr.FunctionArgument != 0 || !strings.Contains(funcErr.Text, tt.wantErr) {
+				t.Fatalf("sign_nonce() = %v, %v, want an error on the seed containing %q", got, funcErr, tt.wantErr)
+			}
+			if strings.Contains(funcErr.Text, tt.seed) && tt.seed != "" {
+				t.Errorf("the error %q contains the seed", funcErr.Text)
+			}
+		})
+	}
+}
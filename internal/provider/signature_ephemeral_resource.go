@@ -0,0 +1,137 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/ephemeralvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ ephemeral.EphemeralResource = &SignatureEphemeral{}
+var _ ephemeral.EphemeralResourceWithConfigValidators = &SignatureEphemeral{}
+
+func NewSignatureEphemeral() ephemeral.EphemeralResource {
+	return &SignatureEphemeral{}
+}
+
+// SignatureEphemeral defines the ephemeral resource implementation.
+type SignatureEphemeral struct {
+}
+
+// SignatureEphemeralModel describes the ephemeral resource data model.
+type SignatureEphemeralModel struct {
+	Seed            types.String `tfsdk:"seed"`
+	Payload         types.String `tfsdk:"payload"`
+	PayloadBase64   types.String `tfsdk:"payload_base64"`
+	SignatureBase64 types.String `tfsdk:"signature_base64"`
+	SignerPublicKey types.String `tfsdk:"signer_public_key"`
+}
+
+func (r *SignatureEphemeral) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_signature"
+}
+
+func (r *SignatureEphemeral) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Makes a detached ed25519 signature of a payload with an nkey seed, e.g. to sign a bootstrap manifest that a device verifies against the account public key. Nothing is persisted to state.",
+
+		Attributes: map[string]schema.Attribute{
+			"seed": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the nkey to sign with. Curve seeds cannot sign",
+				Validators: []validator.String{
+					isSeedFor(keyRoleSigning),
+				},
+			},
+			"payload": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Payload to sign as a string. An empty string signs the empty payload. Exactly one of `payload` and `payload_base64` must be set",
+			},
+			"payload_base64": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Base64 encoded payload to sign, for binary payloads",
+			},
+			"signature_base64": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Base64 encoded 64 byte ed25519 signature of the payload",
+			},
+			"signer_public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key to verify the signature with",
+			},
+		},
+	}
+}
+
+func (r *SignatureEphemeral) ConfigValidators(ctx context.Context) []ephemeral.ConfigValidator {
+	return []ephemeral.ConfigValidator{
+		ephemeralvalidator.ExactlyOneOf(
+			path.MatchRoot("payload"),
+			path.MatchRoot("payload_base64"),
+		),
+	}
+}
+
+func (r *SignatureEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data SignatureEphemeralModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	payload := []byte(data.Payload.ValueString())
+	if !data.PayloadBase64.IsNull() {
+		var err error
+		payload, err = base64.StdEncoding.DecodeString(data.PayloadBase64.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("payload_base64"), "invalid payload", "The payload_base64 is not valid base64: "+err.Error())
+			return
+		}
+	}
+
+	// The seed is only validated here when it was unknown during validation
+	keys, err := signingKeys(data.Seed.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "invalid seed", err.Error())
+		return
+	}
+	defer keys.Wipe()
+	pubKey, err := keys.PublicKey()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "invalid seed", err.Error())
+		return
+	}
+
+	signature, err := keys.Sign(payload)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "signing payload", err.Error())
+		return
+	}
+
+	data.SignatureBase64 = types.StringValue(base64.StdEncoding.EncodeToString(signature))
+	data.SignerPublicKey = types.StringValue(pubKey)
+	tflog.Trace(ctx, "opened ephemeral signature resource", map[string]interface{}{
+		"signer_public_key": pubKey,
+		"payload_length":    len(payload),
+	})
+
+	// Save data into Terraform ephemeral result
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
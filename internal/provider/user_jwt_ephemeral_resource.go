@@ -0,0 +1,218 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ ephemeral.EphemeralResource = &UserJWTEphemeral{}
+var _ ephemeral.EphemeralResourceWithValidateConfig = &UserJWTEphemeral{}
+
+func NewUserJWTEphemeral() ephemeral.EphemeralResource {
+	return &UserJWTEphemeral{}
+}
+
+// defaultEphemeralExpiresIn is the lifetime of ephemeral user JWTs, which are
+// meant for the duration of an apply.
+const defaultEphemeralExpiresIn = "1h"
+
+// UserJWTEphemeral defines the ephemeral resource implementation.
+type UserJWTEphemeral struct {
+}
+
+// UserJWTEphemeralModel describes the ephemeral resource data model.
+type UserJWTEphemeralModel struct {
+	CredsSealModel
+	SigningSeed      types.String `tfsdk:"signing_seed"`
+	AccountPublicKey types.String `tfsdk:"account_public_key"`
+	Name             types.String `tfsdk:"name"`
+	Permissions      types.Object `tfsdk:"permissions"`
+	BearerToken      types.Bool   `tfsdk:"bearer_token"`
+	ExpiresIn        types.String `tfsdk:"expires_in"`
+	PublicKey        types.String `tfsdk:"public_key"`
+	Seed             types.String `tfsdk:"seed"`
+	JWT              types.String `tfsdk:"jwt"`
+	Creds            types.String `tfsdk:"creds"`
+	ExpiresAt        types.String `tfsdk:"expires_at"`
+}
+
+func (r *UserJWTEphemeral) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_jwt"
+}
+
+func (r *UserJWTEphemeral) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "An ephemeral user JWT generates a new user nkey whenever it is opened and issues it a short-lived user JWT signed by the account nkey or one of its signing keys, e.g. for a CI job that connects to NATS during an apply. Nothing is persisted to state.",
+
+		Attributes: credsSealEphemeralAttributes(map[string]schema.Attribute{
+			"signing_seed": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the account nkey or of one of its signing keys, which signs the JWT",
+				Validators: []validator.String{
+					isSeedOfType("account"),
+				},
+			},
+			"account_public_key": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Public key of the account nkey. Required when `signing_seed` is one of the signing keys of the account, so that the JWT names its issuer account",
+				Validators: []validator.String{
+					isPublicKeyOfType("account"),
+				},
+			},
+			"name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the user",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"permissions": permissionsEphemeralAttribute("Publish and subscribe permissions of the user. Without them the user gets the default permissions of the account, which allow every subject unless set"),
+			"bearer_token": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether the user connects with the JWT alone, without proving it holds the user nkey. Defaults to false",
+			},
+			"expires_in": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Positive duration such as `15m` after which the JWT expires, counted from when it is opened. Defaults to `" + defaultEphemeralExpiresIn + "`",
+				Validators: []validator.String{
+					isPositiveDuration(),
+				},
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the generated user nkey, the subject of the JWT",
+			},
+			"seed": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the generated user nkey",
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The encoded user JWT",
+			},
+			"creds": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Creds file with the JWT and the seed, as written by nsc and read by the NATS clients. Null when `seal_only` is set",
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 3339 timestamp at which the JWT expires",
+			},
+		}, "Whether to null `creds`, so that the creds are only output sealed in `creds_sealed`, e.g. for a job that opens them on another host. `seed` is still returned."),
+	}
+}
+
+func (r *UserJWTEphemeral) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data UserJWTEphemeralModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Permissions.IsNull() && !data.Permissions.IsUnknown() {
+		var permissions PermissionsModel
+		resp.Diagnostics.Append(data.Permissions.As(ctx, &permissions, basetypes.ObjectAsOptions{})...)
+		resp.Diagnostics.Append(permissions.checkResponse(ctx, path.Root("permissions"))...)
+	}
+}
+
+func (r *UserJWTEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data UserJWTEphemeralModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Terraform defers opening an ephemeral resource until its configuration
+	// is known, e.g. a signing seed from another ephemeral resource during
+	// plan. Check anyway so that a partial configuration never issues a JWT.
+	// Values that were unknown during validation are validated by issue.
+	if !req.Config.Raw.IsFullyKnown() {
+		resp.Diagnostics.AddError("unknown configuration", "The ephemeral user JWT cannot be issued before its configuration is known.")
+		return
+	}
+
+	// Ephemeral resources cannot declare schema defaults, so fill in the
+	// computed expiry here
+	if data.ExpiresIn.IsNull() {
+		data.ExpiresIn = types.StringValue(defaultEphemeralExpiresIn)
+	}
+
+	seed, pubKey, err := createBootstrapKey(nkeys.PrefixByteUser)
+	if err != nil {
+		resp.Diagnostics.AddError("generating nkey", "The user nkey could not be generated: "+err.Error())
+		return
+	}
+
+	// Issue the JWT the way nkey_user_jwt does, with the claims it shares
+	user := UserClaimsModel{
+		JWTModel:         JWTModel{ExpiresIn: data.ExpiresIn},
+		Name:             data.Name,
+		Permissions:      data.Permissions,
+		BearerToken:      data.BearerToken,
+		AccountPublicKey: data.AccountPublicKey,
+	}
+	resp.Diagnostics.Append(user.issue(ctx, req.Config, pubKey)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	creds, err := jwt.FormatUserConfig(user.JWT.ValueString(), []byte(seed))
+	if err != nil {
+		resp.Diagnostics.AddError("formatting creds", "The creds file could not be formatted: "+err.Error())
+		return
+	}
+	defer wipe(creds)
+
+	resp.Diagnostics.Append(data.seal(creds)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.PublicKey = types.StringValue(pubKey)
+	data.Seed = types.StringValue(seed)
+	data.JWT = user.JWT
+	data.Creds = types.StringValue(string(creds))
+	if data.sealOnly() {
+		data.Creds = types.StringNull()
+	}
+	data.ExpiresAt = types.StringValue(time.Unix(user.ExpiresAtUnix.ValueInt64(), 0).UTC().Format(time.RFC3339))
+	tflog.Trace(ctx, "opened ephemeral user JWT resource", map[string]interface{}{
+		"public_key": pubKey,
+		"issuer":     user.Issuer.ValueString(),
+		"expires_at": data.ExpiresAt.ValueString(),
+		"sealed":     !data.CredsSealed.IsNull(),
+	})
+
+	// Save data into Terraform ephemeral result
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
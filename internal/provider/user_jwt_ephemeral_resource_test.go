@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/nats-io/jwt/v2"
+)
+
+func TestUserJWTEphemeralSeal(t *testing.T) {
+	tests := []struct {
+		name       string
+		sealToXkey string
+		sealOnly   bool
+	}{
+		{name: "plain"},
+		{name: "sealed", sealToXkey: testCurvePublicKey},
+		{name: "seal only", sealToXkey: testCurvePublicKey, sealOnly: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			config := map[string]tftypes.Value{
+				"signing_seed": tftypes.NewValue(tftypes.String, testAccountSeed),
+			}
+			if tt.sealToXkey != "" {
+				config["seal_to_xkey"] = tftypes.NewValue(tftypes.String, tt.sealToXkey)
+			}
+			if tt.sealOnly {
+				config["seal_only"] = tftypes.NewValue(tftypes.Bool, true)
+			}
+			result, diags := openEphemeral(ctx, t, &UserJWTEphemeral{}, config)
+			if diags.HasError() {
+				t.Fatalf("Open() diagnostics = %v", diags)
+			}
+
+			var token, seed, creds, sealed types.String
+			diags.Append(result.GetAttribute(ctx, path.Root("jwt"), &token)...)
+			diags.Append(result.GetAttribute(ctx, path.Root("seed"), &seed)...)
+			diags.Append(result.GetAttribute(ctx, path.Root("creds"), &creds)...)
+			diags.Append(result.GetAttribute(ctx, path.Root("creds_sealed"), &sealed)...)
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+			want, err := jwt.FormatUserConfig(token.ValueString(), []byte(seed.ValueString()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.sealOnly != creds.IsNull() || (!tt.sealOnly && creds.ValueString() != string(want)) {
+				t.Errorf("creds = %s, want the plaintext creds unless seal_only", creds)
+			}
+			if tt.sealToXkey == "" {
+				if !sealed.IsNull() {
+					t.Errorf("creds_sealed without seal_to_xkey = %s, want null", sealed)
+				}
+				return
+			}
+			opened, err := openXkeyEnvelope(sealed.ValueString(), testCurveSeed)
+			if err != nil || string(opened) != string(want) {
+				t.Errorf("creds_sealed opens to %q, %v, want the creds", opened, err)
+			}
+		})
+	}
+}
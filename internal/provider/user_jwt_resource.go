@@ -0,0 +1,490 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &UserJWT{}
+var _ resource.ResourceWithModifyPlan = &UserJWT{}
+var _ resource.ResourceWithValidateConfig = &UserJWT{}
+var _ resource.ResourceWithConfigValidators = &UserJWT{}
+
+func NewUserJWT() resource.Resource {
+	return &UserJWT{}
+}
+
+// UserJWT defines the resource implementation.
+type UserJWT struct {
+}
+
+// UserJWTModel describes the resource data model.
+type UserJWTModel struct {
+	UserClaimsModel
+	Subject types.String `tfsdk:"subject"`
+}
+
+// UserClaimsModel describes the claims of a user JWT and the key it is signed
+// with, shared by nkey_user_jwt and nkey_user.
+type UserClaimsModel struct {
+	JWTModel
+	SigningSeed            types.String `tfsdk:"signing_seed"`
+	Name                   types.String `tfsdk:"name"`
+	Permissions            types.Object `tfsdk:"permissions"`
+	Limits                 types.Object `tfsdk:"limits"`
+	BearerToken            types.Bool   `tfsdk:"bearer_token"`
+	Token                  types.String `tfsdk:"token"`
+	AccountJWT             types.String `tfsdk:"account_jwt"`
+	AccountPublicKey       types.String `tfsdk:"account_public_key"`
+	AllowedConnectionTypes types.Set    `tfsdk:"allowed_connection_types"`
+	SourceNetworks         types.Set    `tfsdk:"source_networks"`
+	UsageTimes             types.Object `tfsdk:"usage_times"`
+	ForceReissue           types.String `tfsdk:"force_reissue"`
+	Tags                   types.Set    `tfsdk:"tags"`
+	AdditionalClaimsJSON   types.String `tfsdk:"additional_claims_json"`
+}
+
+func (r *UserJWT) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_jwt"
+}
+
+func (r *UserJWT) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := userClaimsAttributes()
+	attributes["subject"] = schema.StringAttribute{
+		Required:            true,
+		MarkdownDescription: "Public key of the user nkey. Changing it replaces the resource",
+		Validators: []validator.String{
+			isPublicKeyOfType("user"),
+		},
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A user JWT lets a user nkey connect to the NATS servers as a user of an account, signed by the account nkey or one of its signing keys. The token is kept in state and only issued again when one of its claims or the signing key changes.",
+
+		Attributes: jwtResourceAttributes(attributes),
+	}
+}
+
+// userClaimsAttributes returns the schema attributes of a UserClaimsModel.
+func userClaimsAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"signing_seed": schema.StringAttribute{
+			Required:            true,
+			WriteOnly:           true,
+			Sensitive:           true,
+			MarkdownDescription: "Seed of the account nkey or of one of its signing keys, which signs the JWT. Switching to another key issues the JWT again." + signingSeedDescription,
+			Validators: []validator.String{
+				isSeedOfType("account"),
+			},
+		},
+		"name": schema.StringAttribute{
+			Optional:            true,
+			MarkdownDescription: "Name of the user",
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
+			},
+		},
+		"account_public_key": schema.StringAttribute{
+			Optional:            true,
+			MarkdownDescription: "Public key of the account nkey. When `signing_seed` is one of the signing keys of the account rather than the account nkey, the JWT names the account as its issuer account, without which nats-server rejects the user. Required in that case",
+			Validators: []validator.String{
+				isPublicKeyOfType("account"),
+			},
+		},
+		"account_jwt": schema.StringAttribute{
+			Optional:            true,
+			MarkdownDescription: "JWT of the account, e.g. from `nkey_account_jwt`. When set, `signing_seed` must be the account nkey or one of its signing keys, `account_public_key` must be its subject when set or needed, and `bearer_token` is rejected when the account has `disallow_bearer`. Changing it does not issue the JWT again",
+		},
+		"bearer_token": schema.BoolAttribute{
+			Optional:            true,
+			MarkdownDescription: "Whether the user connects with the JWT alone, without proving it holds the user nkey, e.g. a browser over websockets. Anyone holding a bearer token connects as the user. Defaults to false",
+		},
+		"token": schema.StringAttribute{
+			Computed:            true,
+			Sensitive:           true,
+			MarkdownDescription: "The encoded JWT when `bearer_token` is true, e.g. for the `auth_token` of a websocket client, or null otherwise. It is sensitive since it lets anyone connect as the user",
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+		"force_reissue": schema.StringAttribute{
+			Optional:            true,
+			MarkdownDescription: "Arbitrary value that, when changed, issues the JWT again, e.g. to renew a JWT whose `expires_in` is counted from when it was issued",
+		},
+		"allowed_connection_types": connectionTypesAttribute(),
+		"limits":                   userLimitsAttribute("Limits of the user. Limits that are not set are unlimited, as with nsc"),
+		"permissions":              permissionsAttribute("Publish and subscribe permissions of the user. Without them the user gets the default permissions of the account, which allow every subject unless set"),
+		"source_networks":          sourceNetworksAttribute(),
+		"tags":                     jwtTagsAttribute(),
+		"additional_claims_json":   additionalClaimsAttribute(),
+		"usage_times":              usageTimesAttribute(),
+	}
+}
+
+func (r *UserJWT) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return jwtConfigValidators()
+}
+
+func (r *UserJWT) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data UserJWTModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.validate(ctx)...)
+	if claimsConfigKnown(req.Config) {
+		resp.Diagnostics.Append(data.validateAdditionalClaims(ctx, data.Subject.ValueString())...)
+	}
+}
+
+func (r *UserJWT) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	resp.Diagnostics.Append(planJWTReissue(ctx, req.Config, req.State, &resp.Plan, "account_jwt")...)
+	if resp.Diagnostics.HasError() || resp.Plan.Raw.IsNull() {
+		return
+	}
+	if !req.State.Raw.IsNull() && claimsConfigKnown(req.Config) {
+		var plan, state UserJWTModel
+		resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(plan.planUnchanged(ctx, req.Config, &resp.Plan, &state.UserClaimsModel, plan.Subject.ValueString())...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(planDerivedFromJWT(ctx, &resp.Plan, "token")...)
+}
+
+func (r *UserJWT) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data UserJWTModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.issue(ctx, req.Config, data.Subject.ValueString())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created user JWT resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UserJWT) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data UserJWTModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The token never changes outside of Terraform, so the only thing to
+	// check is that the stored token still decodes to the stored subject.
+	claims, err := jwt.DecodeUserClaims(data.JWT.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted user JWT state", "The stored JWT could not be decoded: "+err.Error())
+		return
+	}
+	if claims.Subject != data.Subject.ValueString() {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted user JWT state", "The stored JWT is issued to "+claims.Subject+" rather than the stored subject.")
+		return
+	}
+
+	data.canonicalize()
+	resp.Diagnostics.Append(data.expiryWarnings(time.Now())...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UserJWT) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	// The token is issued again in place when ModifyPlan planned it as
+	// unknown, i.e. when the claims or the signing key changed.
+	var plan UserJWTModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.JWT.IsUnknown() {
+		resp.Diagnostics.Append(plan.issue(ctx, req.Config, plan.Subject.ValueString())...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *UserJWT) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed
+	tflog.Trace(ctx, "deleted user JWT resource")
+}
+
+// validate checks the configured claims at plan time, as far as they are
+// known.
+func (m *UserClaimsModel) validate(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	diags.Append(m.validateLifetime()...)
+	if !m.Permissions.IsNull() && !m.Permissions.IsUnknown() {
+		var permissions PermissionsModel
+		diags.Append(m.Permissions.As(ctx, &permissions, basetypes.ObjectAsOptions{})...)
+		diags.Append(permissions.checkResponse(ctx, path.Root("permissions"))...)
+	}
+	if !m.UsageTimes.IsNull() && !m.UsageTimes.IsUnknown() {
+		var usageTimes UsageTimesModel
+		diags.Append(m.UsageTimes.As(ctx, &usageTimes, basetypes.ObjectAsOptions{})...)
+		diags.Append(usageTimes.checkWindows(ctx, path.Root("usage_times"))...)
+	}
+	if m.BearerToken.ValueBool() {
+		diags.AddAttributeWarning(path.Root("bearer_token"), "bearer token", "The user JWT is a bearer token, which is not bound to the user nkey: anyone holding the JWT connects as the user. Keep it as secret as a seed.")
+	}
+	if m.AccountJWT.IsUnknown() || m.AccountJWT.IsNull() {
+		return diags
+	}
+	diags.Append(m.checkAccount()...)
+	return diags
+}
+
+// planDerivedFromJWT plans the given attributes derived from the JWT, e.g.
+// the token of a bearer token, as unknown whenever the JWT is issued again.
+func planDerivedFromJWT(ctx context.Context, plan *tfsdk.Plan, derived ...string) diag.Diagnostics {
+	var token types.String
+	diags := plan.GetAttribute(ctx, path.Root("jwt"), &token)
+	if diags.HasError() || !token.IsUnknown() {
+		return diags
+	}
+	for _, name := range derived {
+		diags.Append(plan.SetAttribute(ctx, path.Root(name), types.StringUnknown())...)
+	}
+	return diags
+}
+
+// issue encodes the claims of the model into a JWT issued to subject, signed
+// with the write-only signing seed read from config.
+func (m *UserClaimsModel) issue(ctx context.Context, config tfsdk.Config, subject string) diag.Diagnostics {
+	diags := config.GetAttribute(ctx, path.Root("signing_seed"), &m.SigningSeed)
+	if diags.HasError() {
+		return diags
+	}
+	defer func() { m.SigningSeed = types.StringNull() }()
+
+	// The attribute validator only sees seeds known at plan time
+	if _, keyType, err := publicKeyFromSeed([]byte(m.SigningSeed.ValueString())); err == nil && keyType != "account" {
+		diags.AddAttributeError(path.Root("signing_seed"), "invalid signing seed", "The signing seed is of type "+keyType+", but user JWTs are signed by account nkeys.")
+		return diags
+	}
+
+	if !m.AccountJWT.IsNull() {
+		diags.Append(m.checkAccount()...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	claims, claimsDiags := m.issuedClaims(ctx, subject)
+	diags.Append(claimsDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	diags.Append(m.issueJWT(claims, m.SigningSeed.ValueString())...)
+	m.Token = types.StringNull()
+	if claims.BearerToken {
+		m.Token = m.JWT
+	}
+	return diags
+}
+
+// claims returns the claims of the attributes of the model for the user nkey
+// subject, without the issuer account that depends on the signing seed.
+func (m *UserClaimsModel) claims(ctx context.Context, subject string) (*jwt.UserClaims, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	claims := jwt.NewUserClaims(subject)
+	claims.Name = m.Name.ValueString()
+	addTags(m.Tags, &claims.Tags)
+	claims.BearerToken = m.BearerToken.ValueBool()
+	diags.Append(addConnectionTypes(ctx, m.AllowedConnectionTypes, &claims.AllowedConnectionTypes)...)
+	diags.Append(addNetworks(ctx, m.SourceNetworks, &claims.Src)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+	if !m.Permissions.IsNull() {
+		var permissions PermissionsModel
+		diags.Append(m.Permissions.As(ctx, &permissions, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		diags.Append(permissions.setPermissions(ctx, &claims.Permissions)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+	if !m.Limits.IsNull() {
+		var limits UserLimitsModel
+		diags.Append(m.Limits.As(ctx, &limits, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		limits.setLimits(&claims.NatsLimits)
+	}
+	if !m.UsageTimes.IsNull() {
+		var usageTimes UsageTimesModel
+		diags.Append(m.UsageTimes.As(ctx, &usageTimes, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		diags.Append(usageTimes.setTimes(ctx, &claims.UserLimits)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+	return claims, diags
+}
+
+// issuedClaims returns the claims the JWT is issued with, the claims of the
+// attributes with the issuer account of the signing seed and the additional
+// claims, but for its lifetime.
+func (m *UserClaimsModel) issuedClaims(ctx context.Context, subject string) (*jwt.UserClaims, diag.Diagnostics) {
+	claims, diags := m.claims(ctx, subject)
+	if diags.HasError() {
+		return nil, diags
+	}
+	claims.IssuerAccount = issuerAccount(m.SigningSeed.ValueString(), m.AccountPublicKey.ValueString())
+	diags.Append(addAdditionalClaims(claims, jwt.NewUserClaims(subject), m.AdditionalClaimsJSON)...)
+	return claims, diags
+}
+
+// planUnchanged keeps the token of prior, the model of the state, when the
+// claims of the model, the plan, are those of prior. The write-only signing
+// seed is read from config for the issuer account, and claims that cannot be
+// built are reported when the JWT is issued.
+func (m *UserClaimsModel) planUnchanged(ctx context.Context, config tfsdk.Config, plan *tfsdk.Plan, prior *UserClaimsModel, subject string) diag.Diagnostics {
+	if subject == "" {
+		return nil
+	}
+	diags := config.GetAttribute(ctx, path.Root("signing_seed"), &m.SigningSeed)
+	if diags.HasError() {
+		return diags
+	}
+	defer func() { m.SigningSeed = types.StringNull() }()
+
+	claims, claimsDiags := m.issuedClaims(ctx, subject)
+	if claimsDiags.HasError() {
+		return diags
+	}
+	diags.Append(planJWTUnchanged(ctx, plan, &m.JWTModel, &prior.JWTModel, claims)...)
+	return diags
+}
+
+// validateAdditionalClaims checks additional_claims_json against the claims
+// of the attributes at plan time.
+func (m *UserClaimsModel) validateAdditionalClaims(ctx context.Context, subject string) diag.Diagnostics {
+	// Without a known subject the claims cannot be built yet
+	if m.AdditionalClaimsJSON.IsNull() || subject == "" {
+		return nil
+	}
+	claims, diags := m.claims(ctx, subject)
+	if diags.HasError() {
+		return diags
+	}
+	diags.Append(checkAdditionalClaims(claims, jwt.NewUserClaims(subject), m.AdditionalClaimsJSON)...)
+	return diags
+}
+
+// canonicalize stores the connection types, networks and tags in the form
+// they have in the JWT. They are stored as configured when the JWT is issued,
+// and only differ from it in ways that do not show a diff.
+func (m *UserClaimsModel) canonicalize() {
+	m.AllowedConnectionTypes = canonicalConnectionTypes(m.AllowedConnectionTypes)
+	m.SourceNetworks = canonicalNetworks(m.SourceNetworks)
+	m.Tags = canonicalTags(m.Tags)
+}
+
+// checkAccount checks the user against the account of account_jwt: the
+// signing seed must be allowed to sign its users, account_public_key must be
+// the account when the seed is a signing key, and bearer tokens are rejected
+// when the account disallows them. The signing seed is only checked when it is
+// known.
+func (m *UserClaimsModel) checkAccount() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	account, err := jwt.DecodeAccountClaims(m.AccountJWT.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("account_jwt"), "invalid account JWT", "The account_jwt could not be decoded: "+err.Error())
+		return diags
+	}
+	if m.BearerToken.ValueBool() && account.Limits.DisallowBearer {
+		diags.AddAttributeError(path.Root("bearer_token"), "bearer token disallowed", "The account "+account.Name+" of account_jwt sets disallow_bearer, so nats-server rejects this user JWT, which sets bearer_token. Unset one of them.")
+	}
+	if m.SigningSeed.IsUnknown() || m.SigningSeed.IsNull() {
+		return diags
+	}
+	signer, _, err := publicKeyFromSeed([]byte(m.SigningSeed.ValueString()))
+	if err != nil {
+		// Left for the attribute validator of signing_seed
+		return diags
+	}
+	if err := checkSigner(signer, account.Subject, account.SigningKeys.Keys(), false); err != nil {
+		diags.AddAttributeError(path.Root("signing_seed"), "signing seed not allowed", "The signing seed cannot sign users of account "+account.Name+": "+err.Error()+".")
+		return diags
+	}
+	switch {
+	case m.AccountPublicKey.IsUnknown():
+	case m.AccountPublicKey.IsNull() && signer != account.Subject:
+		diags.AddAttributeError(path.Root("account_public_key"), "missing account public key", "The signing seed is a signing key of account "+account.Name+", so nats-server only accepts the user when the JWT names the account. Set account_public_key to "+account.Subject+".")
+	case !m.AccountPublicKey.IsNull() && m.AccountPublicKey.ValueString() != account.Subject:
+		diags.AddAttributeError(path.Root("account_public_key"), "account mismatch", "The account_public_key is not the subject of account_jwt, "+account.Subject+".")
+	}
+	return diags
+}
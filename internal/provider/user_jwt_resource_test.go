@@ -0,0 +1,339 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// userJWTConfig returns the configuration of nkey_user_jwt.test, the JWT of
+// the test user signed by the test account, with the attributes of body.
+func userJWTConfig(body string) string {
+	return `
+resource "nkey_user_jwt" "test" {
+  subject      = "` + testUserPublicKey + `"
+  signing_seed = "` + testAccountSeed + `"
+` + body + `
+}
+`
+}
+
+// userJWTStep returns a step that applies userJWTConfig of body with action,
+// issuing the JWT again when reissued is set, and checks its claims.
+func userJWTStep(body string, action tfjson.Action, reissued bool, check func(t *testing.T, claims *jwt.UserClaims)) testStep {
+	return jwtStep(userJWTConfig(body), "nkey_user_jwt.test", action, reissued, jwt.DecodeUserClaims, func(t *testing.T, state *testState, claims *jwt.UserClaims) {
+		if claims.Subject != testUserPublicKey {
+			t.Fatalf("the JWT is issued to %s", claims.Subject)
+		}
+		if got := state.stringAttribute(t, "nkey_user_jwt.test", "issuer"); got != claims.Issuer {
+			t.Errorf("issuer = %s, but the JWT is issued by %s", got, claims.Issuer)
+		}
+		check(t, claims)
+	})
+}
+
+func TestUserJWTResource(t *testing.T) {
+	account, err := nkeys.FromSeed([]byte(testAccountSeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var issuedAt int64
+	// expectClaims checks the claims, also once they are encoded again, and
+	// whether they were issued again
+	expectClaims := func(name string, reissued bool) func(t *testing.T, claims *jwt.UserClaims) {
+		return func(t *testing.T, claims *jwt.UserClaims) {
+			var vr jwt.ValidationResults
+			claims.Validate(&vr)
+			if errs := vr.Errors(); len(errs) != 0 {
+				t.Errorf("the decoded claims do not validate: %v", errs)
+			}
+			encoded, err := claims.Encode(account)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := jwt.DecodeUserClaims(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, claims := range []*jwt.UserClaims{claims, again} {
+				if claims.Name != name || claims.Issuer != testAccountPublicKey || claims.IssuerAccount != "" || claims.Expires != 0 {
+					t.Errorf("name = %q, issuer = %s, issuer_account = %q, expires = %d", claims.Name, claims.Issuer, claims.IssuerAccount, claims.Expires)
+				}
+				if claims.ClaimType() != jwt.UserClaim || claims.Version != 2 {
+					t.Errorf("the JWT is a %s JWT of version %d", claims.ClaimType(), claims.Version)
+				}
+			}
+			if (claims.IssuedAt != issuedAt) != reissued {
+				t.Errorf("issued_at changed from %d to %d, expected the JWT to be issued again: %v", issuedAt, claims.IssuedAt, reissued)
+			}
+			issuedAt = claims.IssuedAt
+		}
+	}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			userJWTStep(`name = "alice"`, tfjson.ActionCreate, true, expectClaims("alice", true)),
+			// Time passing alone does not issue the JWT again
+			{
+				PreConfig: func(t *testing.T) {
+					time.Sleep(time.Second)
+				},
+				Config: userJWTConfig(`name = "alice"`),
+				PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+					expectActions(t, plan, "nkey_user_jwt.test", tfjson.ActionNoop)
+				},
+			},
+			// Changing a claim issues the JWT again in place, a second after
+			// the first one
+			userJWTStep(`name = "bob"`, tfjson.ActionUpdate, true, expectClaims("bob", true)),
+			{
+				Config: `
+resource "nkey_user_jwt" "test" {
+  subject      = "` + testUserPublicKey + `"
+  signing_seed = "` + testUserSeed + `"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`signing_seed`),
+			},
+			{
+				Config: `
+resource "nkey_user_jwt" "test" {
+  subject      = "` + testUserPublicKey + `"
+  signing_seed = "` + testOperatorSeed + `"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`signing_seed`),
+			},
+			{
+				Config: `
+resource "nkey_user_jwt" "test" {
+  subject      = "` + testAccountPublicKey + `"
+  signing_seed = "` + testAccountSeed + `"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`subject`),
+			},
+		},
+	})
+}
+
+func TestUserJWTResourcePermissions(t *testing.T) {
+	permissions := func(publish, subscribe string) string {
+		return `
+  permissions = {
+    publish   = ` + publish + `
+    subscribe = ` + subscribe + `
+  }`
+	}
+	// expectPermissions checks the permissions of the claims
+	expectPermissions := func(want jwt.Permissions) func(t *testing.T, claims *jwt.UserClaims) {
+		return func(t *testing.T, claims *jwt.UserClaims) {
+			got := claims.Permissions
+			if !slices.Equal(got.Pub.Allow, want.Pub.Allow) || !slices.Equal(got.Pub.Deny, want.Pub.Deny) ||
+				!slices.Equal(got.Sub.Allow, want.Sub.Allow) || !slices.Equal(got.Sub.Deny, want.Sub.Deny) {
+				t.Errorf("permissions = %+v, want %+v", got, want)
+			}
+		}
+	}
+	sorted := jwt.Permissions{
+		Pub: jwt.Permission{Allow: jwt.StringList{"billing.*.new", "orders.>"}, Deny: jwt.StringList{"orders.secret"}},
+		Sub: jwt.Permission{Allow: jwt.StringList{"_INBOX.>", "orders.> workers"}},
+	}
+	denyAdmin := jwt.Permissions{Pub: jwt.Permission{Deny: jwt.StringList{"admin.>"}}}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			// Subjects are deduplicated and sorted in the JWT
+			userJWTStep(permissions(
+				`{ allow = ["orders.>", "billing.*.new", "orders.>"], deny = ["orders.secret"] }`,
+				`{ allow = ["orders.> workers", "_INBOX.>"] }`,
+			), tfjson.ActionCreate, true, expectPermissions(sorted)),
+			// So reordering them changes neither the plan nor the JWT
+			userJWTStep(permissions(
+				`{ deny = ["orders.secret"], allow = ["billing.*.new", "orders.>"] }`,
+				`{ allow = ["_INBOX.>", "orders.> workers", "_INBOX.>"] }`,
+			), tfjson.ActionNoop, false, expectPermissions(sorted)),
+			// An empty allow list allows every subject like an unset one, as
+			// nats-server only restricts users to a non-empty allow list
+			userJWTStep(permissions(`{ allow = [], deny = ["admin.>"] }`, `{ allow = [] }`), tfjson.ActionUpdate, true, expectPermissions(denyAdmin)),
+			userJWTStep(permissions(`{ deny = ["admin.>"] }`, `{}`), tfjson.ActionUpdate, false, expectPermissions(denyAdmin)),
+			userJWTStep(`permissions = { publish = { deny = ["admin.>"] } }`, tfjson.ActionUpdate, false, expectPermissions(denyAdmin)),
+			{
+				Config:      userJWTConfig(permissions(`{ allow = ["orders.>.new"] }`, `{}`)),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`subject "orders.>.new" can only have ` + "`>`" + ` as its last token`),
+			},
+			{
+				Config:      userJWTConfig(permissions(`{ allow = ["orders..new"] }`, `{}`)),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`permissions.publish.allow`),
+			},
+			{
+				Config:      userJWTConfig(permissions(`{}`, `{ deny = ["orders.new\tx"] }`)),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`cannot contain whitespace`),
+			},
+		},
+	})
+}
+
+func TestUserJWTResourceLimits(t *testing.T) {
+	// expectLimits checks the limits of the claims
+	expectLimits := func(subs, data, payload int64) func(t *testing.T, claims *jwt.UserClaims) {
+		return func(t *testing.T, claims *jwt.UserClaims) {
+			if got := claims.NatsLimits; got.Subs != subs || got.Data != data || got.Payload != payload {
+				t.Errorf("limits = %+v, want subscriptions %d, data %d and payload %d", got, subs, data, payload)
+			}
+		}
+	}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			// Unset limits are unlimited rather than 0, as with nsc
+			userJWTStep("", tfjson.ActionCreate, true, expectLimits(jwt.NoLimit, jwt.NoLimit, jwt.NoLimit)),
+			userJWTStep("limits = {}", tfjson.ActionUpdate, false, expectLimits(jwt.NoLimit, jwt.NoLimit, jwt.NoLimit)),
+			userJWTStep(`
+  limits = {
+    max_subscriptions = 0
+    max_data          = provider::nkey::parse_size("1GiB")
+    max_payload       = provider::nkey::parse_size("1MB")
+  }`, tfjson.ActionUpdate, true, expectLimits(0, 1<<30, 1<<20)),
+			// The same sizes in bytes change nothing
+			userJWTStep(`
+  limits = {
+    max_subscriptions = 0
+    max_data          = 1073741824
+    max_payload       = 1048576
+  }`, tfjson.ActionNoop, false, expectLimits(0, 1<<30, 1<<20)),
+			userJWTStep("limits = { max_data = -1 }", tfjson.ActionUpdate, true, expectLimits(jwt.NoLimit, jwt.NoLimit, jwt.NoLimit)),
+			{
+				Config:      userJWTConfig("limits = { max_payload = -2 }"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Attribute limits.max_payload value must be at least -1, got: -2`),
+			},
+			{
+				Config:      userJWTConfig(`limits = { max_data = provider::nkey::parse_size("-1") }`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`write -1 directly`),
+			},
+		},
+	})
+}
+
+func TestUserJWTResourceSourceNetworks(t *testing.T) {
+	networks := func(values string) string {
+		return "source_networks = " + values
+	}
+	// expectSource checks the networks of the claims
+	expectSource := func(want ...string) func(t *testing.T, claims *jwt.UserClaims) {
+		return func(t *testing.T, claims *jwt.UserClaims) {
+			if !slices.Equal([]string(claims.Src), want) {
+				t.Errorf("src = %q, want %q", claims.Src, want)
+			}
+		}
+	}
+	canonical := []string{"10.0.0.1/32", "192.168.1.0/24", "2001:db8::1/128"}
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			// Addresses and networks with host bits are networks of the JWT
+			userJWTStep(networks(`["10.0.0.1", "2001:DB8::1", "192.168.1.7/24"]`), tfjson.ActionCreate, true, expectSource(canonical...)),
+			// So writing the same networks in CIDR notation changes nothing
+			userJWTStep(networks(`["192.168.1.0/24", "2001:db8::1/128", "10.0.0.1/32"]`), tfjson.ActionNoop, false, expectSource(canonical...)),
+			userJWTStep(networks(`["10.0.0.1/32", "10.0.0.1", "192.168.1.0/24", "2001:db8:0::1"]`), tfjson.ActionNoop, false, expectSource(canonical...)),
+			userJWTStep(networks(`["10.0.0.0/8"]`), tfjson.ActionUpdate, true, expectSource("10.0.0.0/8")),
+			// Users connect from anywhere without networks
+			userJWTStep(networks(`[]`), tfjson.ActionUpdate, true, expectSource()),
+			userJWTStep("", tfjson.ActionUpdate, false, expectSource()),
+			{
+				Config:      userJWTConfig(networks(`["10.0.0.1/33"]`)),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`value must be a network in CIDR notation`),
+			},
+			{
+				Config:      userJWTConfig(networks(`["2001:db8:::1"]`)),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`value must be a network in CIDR notation`),
+			},
+			{
+				Config:      userJWTConfig(networks(`["office"]`)),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`value must be a network in CIDR notation`),
+			},
+		},
+	})
+}
+
+func TestUserJWTResourceIssuerAccount(t *testing.T) {
+	// config returns the configuration of the test user signed by seed, and
+	// of the test account with its signing key to check the user against
+	config := func(seed, body string) string {
+		return accountJWTConfig(`signing_keys = ["`+testAccountSigningPublicKey+`"]`) + `
+resource "nkey_user_jwt" "test" {
+  subject      = "` + testUserPublicKey + `"
+  signing_seed = "` + seed + `"
+  account_jwt  = nkey_account_jwt.test.jwt
+` + body + `
+}
+`
+	}
+	// expectIssuer checks the issuer and issuer account of the claims, and
+	// that the account accepts them as nats-server does
+	expectIssuer := func(issuer, issuerAccount string) func(t *testing.T, claims *jwt.UserClaims) {
+		return func(t *testing.T, claims *jwt.UserClaims) {
+			if claims.Issuer != issuer || claims.IssuerAccount != issuerAccount {
+				t.Errorf("the JWT is issued by %s for account %q, want %s for %q", claims.Issuer, claims.IssuerAccount, issuer, issuerAccount)
+			}
+			account := jwt.NewAccountClaims(testAccountPublicKey)
+			account.SigningKeys.Add(testAccountSigningPublicKey)
+			if !account.DidSign(claims) {
+				t.Errorf("the account does not accept the JWT issued by %s for account %q", claims.Issuer, claims.IssuerAccount)
+			}
+		}
+	}
+	// step applies config of seed and body and checks the claims
+	step := func(seed, body string, action tfjson.Action, reissued bool, check func(t *testing.T, claims *jwt.UserClaims)) testStep {
+		return jwtStep(config(seed, body), "nkey_user_jwt.test", action, reissued, jwt.DecodeUserClaims, func(t *testing.T, _ *testState, claims *jwt.UserClaims) {
+			check(t, claims)
+		})
+	}
+	accountPublicKey := `account_public_key = "` + testAccountPublicKey + `"`
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			// The account nkey names no issuer account, with or without
+			// account_public_key, as nsc rejects a JWT naming its issuer
+			step(testAccountSeed, "", tfjson.ActionCreate, true, expectIssuer(testAccountPublicKey, "")),
+			step(testAccountSeed, accountPublicKey, tfjson.ActionUpdate, false, expectIssuer(testAccountPublicKey, "")),
+			// A signing key names the account
+			step(testAccountSigningSeed, accountPublicKey, tfjson.ActionUpdate, true, expectIssuer(testAccountSigningPublicKey, testAccountPublicKey)),
+			step(testAccountSeed, accountPublicKey, tfjson.ActionUpdate, true, expectIssuer(testAccountPublicKey, "")),
+			{
+				Config:      config(testAccountSigningSeed, ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Set account_public_key to ` + testAccountPublicKey),
+			},
+			{
+				Config:      config(testAccountSigningSeed, `account_public_key = "`+testAccountScopedPublicKey+`"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`The account_public_key is not the subject of account_jwt`),
+			},
+			{
+				Config:      config(testAccountScopedSeed, accountPublicKey),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`The signing seed cannot sign users of account test`),
+			},
+		},
+	})
+}
@@ -0,0 +1,279 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &User{}
+var _ resource.ResourceWithModifyPlan = &User{}
+var _ resource.ResourceWithValidateConfig = &User{}
+var _ resource.ResourceWithConfigValidators = &User{}
+
+func NewUser() resource.Resource {
+	return &User{}
+}
+
+// User defines the resource implementation.
+type User struct {
+}
+
+// UserModel describes the resource data model.
+type UserModel struct {
+	UserClaimsModel
+	GeneratedKeyModel
+	CredsSealModel
+	Creds          types.String `tfsdk:"creds"`
+	LeafnodeRemote types.Object `tfsdk:"leafnode_remote"`
+	LeafnodeConfig types.String `tfsdk:"leafnode_config"`
+}
+
+func (r *User) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user"
+}
+
+func (r *User) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := generatedKeyAttributes(userClaimsAttributes(), "user", "The JWT issued to the previous nkey stays valid until it expires or the account revokes it.")
+	attributes["creds"] = schema.StringAttribute{
+		Computed:            true,
+		Sensitive:           true,
+		MarkdownDescription: "Creds file with the JWT and the seed, as written by nsc and read by the NATS clients. Null when `seal_only` is set",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+	attributes = credsSealResourceAttributes(attributes, "Whether to null `creds`, so that the creds are only output sealed in `creds_sealed`, e.g. so that the deployment pipeline never sees them in plaintext. The seed is still stored in state.")
+	attributes["leafnode_remote"] = leafnodeRemoteAttribute()
+	attributes["leafnode_config"] = leafnodeConfigAttribute()
+
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A user generates a user nkey, or takes an existing seed, and issues its user JWT signed by the account nkey or one of its signing keys, with the same claims as `nkey_user_jwt`, along with its creds file. The token is kept in state and only issued again when one of its claims, the signing key or the user nkey changes.\n\n" +
+			"Deleting the resource only removes it from state. It does not revoke anything: NATS servers keep accepting the user until the JWT expires or the account revokes it.",
+
+		Attributes: jwtResourceAttributes(attributes),
+	}
+}
+
+func (r *User) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return append(jwtConfigValidators(), generatedKeyConfigValidators()...)
+}
+
+func (r *User) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data UserModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.validate(ctx)...)
+	resp.Diagnostics.Append(checkLeafnodeConnectionTypes(data.LeafnodeRemote, data.AllowedConnectionTypes)...)
+	if claimsConfigKnown(req.Config) {
+		claims := leafnodeClaims(data.UserClaimsModel, data.LeafnodeRemote)
+		resp.Diagnostics.Append(claims.validateAdditionalClaims(ctx, data.plannedSubject().ValueString())...)
+	}
+}
+
+func (r *User) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	ctx = redactSecrets(ctx)
+
+	resp.Diagnostics.Append(planGeneratedKey(ctx, req.Config, req.State, &resp.Plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(planJWTReissue(ctx, req.Config, req.State, &resp.Plan, "account_jwt", "leafnode_remote", "leafnode_config", "creds", "seal_to_xkey", "seal_only", "creds_sealed")...)
+	if resp.Diagnostics.HasError() || resp.Plan.Raw.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(planLeafnodeRemote(ctx, req.State, &resp.Plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !req.State.Raw.IsNull() && claimsConfigKnown(req.Config) {
+		var plan, state UserModel
+		resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !plan.PublicKey.IsUnknown() {
+			claims := leafnodeClaims(plan.UserClaimsModel, plan.LeafnodeRemote)
+			resp.Diagnostics.Append(claims.planUnchanged(ctx, req.Config, &resp.Plan, &state.UserClaimsModel, plan.PublicKey.ValueString())...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+	}
+	resp.Diagnostics.Append(planDerivedFromJWT(ctx, &resp.Plan, "token", "creds")...)
+	resp.Diagnostics.Append(planSealedCreds(ctx, req.State, &resp.Plan, path.Root("jwt"), path.Root("creds"))...)
+}
+
+func (r *User) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data UserModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.setKey(nkeys.PrefixByteUser, "user")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(data.issueCreds(ctx, req.Config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(data.setLeafnodeConfig(ctx)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Trace(ctx, "created user resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *User) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data UserModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The key and token never change outside of Terraform, so the only thing
+	// to check is that the stored seed still derives the stored public key
+	// and that the stored token is issued to it.
+	claims, err := jwt.DecodeUserClaims(data.JWT.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("jwt"), "corrupted user state", "The stored JWT could not be decoded: "+err.Error())
+		return
+	}
+	resp.Diagnostics.Append(data.checkKey("user", claims.Subject)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.canonicalize()
+	resp.Diagnostics.Append(data.expiryWarnings(time.Now())...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *User) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx = redactSecrets(ctx)
+
+	// ModifyPlan planned the key as unknown when it is rotated, and the token
+	// as unknown when the claims, the signing key or the user nkey changed.
+	var plan UserModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(plan.setKey(nkeys.PrefixByteUser, "user")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	switch {
+	case plan.JWT.IsUnknown():
+		resp.Diagnostics.Append(plan.issueCreds(ctx, req.Config)...)
+	case plan.Creds.IsUnknown() || plan.CredsSealed.IsUnknown():
+		// Only the creds are output another way
+		resp.Diagnostics.Append(plan.formatCreds()...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(plan.setLeafnodeConfig(ctx)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *User) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx = redactSecrets(ctx)
+
+	// Removing the resource from state is all that is needed. The user is not
+	// revoked, which is up to the account JWT.
+	tflog.Trace(ctx, "deleted user resource")
+}
+
+// issueCreds issues the JWT of the user, restricted to leafnode connections
+// when it has a leafnode remote, and formats its creds file with the seed.
+func (m *UserModel) issueCreds(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	claims := leafnodeClaims(m.UserClaimsModel, m.LeafnodeRemote)
+	diags := claims.issue(ctx, config, m.PublicKey.ValueString())
+	if diags.HasError() {
+		return diags
+	}
+	m.JWTModel, m.Token = claims.JWTModel, claims.Token
+
+	diags.Append(m.formatCreds()...)
+	return diags
+}
+
+// formatCreds formats the creds file of the user from its JWT and seed, and
+// seals it to seal_to_xkey. The plaintext creds are null when they are only
+// output sealed.
+func (m *UserModel) formatCreds() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	creds, err := jwt.FormatUserConfig(m.JWT.ValueString(), []byte(m.Seed.ValueString()))
+	if err != nil {
+		diags.AddAttributeError(path.Root("creds"), "formatting creds", "The creds file could not be formatted: "+err.Error())
+		return diags
+	}
+	defer wipe(creds)
+
+	diags.Append(m.seal(creds)...)
+	m.Creds = types.StringValue(string(creds))
+	if m.sealOnly() {
+		m.Creds = types.StringNull()
+	}
+	return diags
+}
+
+// setLeafnodeConfig renders the leafnode config when it was unknown at plan
+// time.
+func (m *UserModel) setLeafnodeConfig(ctx context.Context) diag.Diagnostics {
+	if !m.LeafnodeConfig.IsUnknown() {
+		return nil
+	}
+	config, diags := leafnodeConfig(ctx, m.LeafnodeRemote)
+	m.LeafnodeConfig = config
+	return diags
+}
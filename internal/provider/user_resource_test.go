@@ -0,0 +1,114 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/nats-io/jwt/v2"
+)
+
+func TestUserResourceSealCreds(t *testing.T) {
+	config := func(seal string) string {
+		return `
+resource "nkey_user" "test" {
+  signing_seed = "` + testAccountSeed + `"
+  name         = "alice"
+` + seal + `
+}
+`
+	}
+	var token, sealed string
+	// step checks the planned action and the creds. The JWT is never issued
+	// again, and the creds are only sealed again when sealedAgain is set.
+	step := func(seal string, action tfjson.Action, sealOnly, sealedAgain bool) testStep {
+		return testStep{
+			Config: config(seal),
+			PlanCheck: func(t *testing.T, plan *tfjson.Plan) {
+				expectActions(t, plan, "nkey_user.test", action)
+			},
+			Check: func(t *testing.T, state *testState) {
+				if got := state.stringAttribute(t, "nkey_user.test", "jwt"); token != "" && got != token {
+					t.Errorf("the JWT was issued again")
+				} else {
+					token = got
+				}
+				seed := state.stringAttribute(t, "nkey_user.test", "seed")
+				creds, err := jwt.FormatUserConfig(token, []byte(seed))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got, _ := state.attribute(t, "nkey_user.test", "creds").(string)
+				if sealOnly != (got == "") || (!sealOnly && got != string(creds)) {
+					t.Errorf("seal_only = %v: creds = %q", sealOnly, got)
+				}
+
+				got, _ = state.attribute(t, "nkey_user.test", "creds_sealed").(string)
+				switch {
+				case seal == "":
+					if got != "" {
+						t.Errorf("creds_sealed = %q without seal_to_xkey", got)
+					}
+					return
+				case (got != sealed) != sealedAgain:
+					t.Errorf("creds_sealed changed from %q to %q, expected it to change: %v", sealed, got, sealedAgain)
+				}
+				sealed = got
+				opened, err := openXkeyEnvelope(sealed, testCurveSeed)
+				if err != nil || string(opened) != string(creds) {
+					t.Errorf("creds_sealed opens to %q, %v, want the creds", opened, err)
+				}
+			},
+		}
+	}
+	const sealTo = `  seal_to_xkey = "` + testCurvePublicKey + `"`
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			step(sealTo+"\n  seal_only    = true", tfjson.ActionCreate, true, true),
+			// Outputting the plaintext creds too neither issues the JWT nor
+			// seals the creds again
+			step(sealTo, tfjson.ActionUpdate, false, false),
+			{
+				Config: `
+resource "nkey_user" "test" {
+  signing_seed = "` + testAccountSeed + `"
+  name         = "alice"
+` + sealTo + `
+}
+
+data "nkey_xkey_open" "test" {
+  ciphertext_base64 = nkey_user.test.creds_sealed
+  recipient_seed    = "` + testCurveSeed + `"
+}
+`,
+				Check: func(t *testing.T, state *testState) {
+					if state.stringAttribute(t, "data.nkey_xkey_open.test", "plaintext") != state.stringAttribute(t, "nkey_user.test", "creds") {
+						t.Error("nkey_xkey_open does not open creds_sealed to the creds")
+					}
+				},
+			},
+			step("", tfjson.ActionUpdate, false, false),
+			// A name change issues the JWT again, so the creds are sealed again
+			{
+				Config: `
+resource "nkey_user" "test" {
+  signing_seed = "` + testAccountSeed + `"
+  name         = "bob"
+` + sealTo + `
+}
+`,
+				Check: func(t *testing.T, state *testState) {
+					got := state.stringAttribute(t, "nkey_user.test", "creds_sealed")
+					opened, err := openXkeyEnvelope(got, testCurveSeed)
+					if err != nil || string(opened) != state.stringAttribute(t, "nkey_user.test", "creds") {
+						t.Errorf("creds_sealed opens to %q, %v, want the creds", opened, err)
+					}
+				},
+			},
+		},
+	})
+}
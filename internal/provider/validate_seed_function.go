@@ -0,0 +1,143 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ValidateSeedFunction{}
+
+func NewValidateSeedFunction() function.Function {
+	return &ValidateSeedFunction{}
+}
+
+// ValidateSeedFunction defines the function implementation.
+type ValidateSeedFunction struct {
+}
+
+// The reasons validate_seed reports. They are part of the function contract,
+// so never change them.
+const (
+	seedReasonBadPrefix   = "bad prefix"
+	seedReasonBadLength   = "bad length"
+	seedReasonCRCMismatch = "crc mismatch"
+	seedReasonNotBase32   = "not base32"
+	seedReasonTrimmed     = "trimmed"
+)
+
+// seedLen is the length of an encoded seed: two prefix bytes, the 32 byte raw
+// seed and a two byte checksum in unpadded base32.
+const seedLen = 58
+
+// validateSeedAttrTypes are the attribute types of the validate_seed result.
+var validateSeedAttrTypes = map[string]attr.Type{
+	"valid":  types.BoolType,
+	"type":   types.StringType,
+	"reason": types.StringType,
+}
+
+func (f *ValidateSeedFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate_seed"
+}
+
+func (f *ValidateSeedFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Check an nkey seed and explain what is wrong with it",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Checks `seed` and returns an object with `valid`, the `type` of a valid seed (null otherwise) and a `reason`. Surrounding whitespace, the usual leftover of copying a seed from a password manager, is trimmed first. The reason is one of:\n\n" +
+			"- `" + seedReasonNotBase32 + "` when the seed has characters outside the base32 alphabet A-Z and 2-7\n" +
+			"- `" + seedReasonBadLength + "` when the seed is not 58 characters long, usually a character went missing\n" +
+			"- `" + seedReasonBadPrefix + "` when the value is not a seed, e.g. a public key, or of an unknown type\n" +
+			"- `" + seedReasonCRCMismatch + "` when the checksum does not match, the seed is mistyped\n\n" +
+			"followed by `; " + seedReasonTrimmed + "` when whitespace was trimmed. A valid seed has the reason `" + seedReasonTrimmed + "` when whitespace was trimmed and an empty reason otherwise. The seed itself is never part of the result, so the reason of a sensitive seed can be wrapped in `nonsensitive()` for error messages.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "seed",
+				MarkdownDescription: "Seed to check",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: validateSeedAttrTypes,
+		},
+	}
+}
+
+func (f *ValidateSeedFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var seed string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &seed))
+	if resp.Error != nil {
+		return
+	}
+
+	trimmed := strings.TrimSpace(seed)
+	keyType, reason := validateSeed(trimmed)
+	if trimmed != seed {
+		if reason == "" {
+			reason = seedReasonTrimmed
+		} else {
+			reason += "; " + seedReasonTrimmed
+		}
+	}
+
+	typeValue := types.StringNull()
+	if keyType != "" {
+		typeValue = types.StringValue(keyType)
+	}
+	result, diags := types.ObjectValue(validateSeedAttrTypes, map[string]attr.Value{
+		"valid":  types.BoolValue(keyType != ""),
+		"type":   typeValue,
+		"reason": types.StringValue(reason),
+	})
+	resp.Error = function.FuncErrorFromDiags(ctx, diags)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, result))
+}
+
+// validateSeed returns the type of seed, or the reason why it is invalid. The
+// checks go from the coarsest to the finest, so that e.g. a public key is
+// reported as a bad prefix and a truncated seed as a bad length rather than by
+// the base32 decoder.
+func validateSeed(seed string) (string, string) {
+	if strings.Trim(seed, "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567") != "" {
+		return "", seedReasonNotBase32
+	}
+	if !strings.HasPrefix(seed, "S") {
+		return "", seedReasonBadPrefix
+	}
+	if len(seed) != seedLen {
+		return "", seedReasonBadLength
+	}
+
+	prefix, raw, err := nkeys.DecodeSeed([]byte(seed))
+	switch {
+	case errors.Is(err, nkeys.ErrInvalidChecksum):
+		return "", seedReasonCRCMismatch
+	case errors.Is(err, nkeys.ErrInvalidSeed):
+		return "", seedReasonBadPrefix
+	case err != nil:
+		return "", seedReasonNotBase32
+	}
+	defer wipe(raw)
+
+	keyType, ok := keyTypeNames[prefix]
+	if !ok {
+		return "", seedReasonBadPrefix
+	}
+	return keyType, ""
+}
@@ -0,0 +1,483 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	// Time zones are validated the same way whether or not the host running
+	// Terraform has a time zone database
+	_ "time/tzdata"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/nats-io/jwt/v2"
+)
+
+// Ensure validators fully satisfy framework interfaces.
+var _ validator.String = durationValidator{}
+var _ validator.String = rfc3339Validator{}
+var _ validator.String = keyValidator{}
+var _ validator.String = urlValidator{}
+var _ validator.String = subjectValidator{}
+var _ validator.String = networkValidator{}
+var _ validator.String = timeOfDayValidator{}
+var _ validator.String = timeZoneValidator{}
+var _ validator.String = jsonObjectValidator{}
+
+// durationValidator validates that a string parses as a Go duration,
+// optionally a positive one.
+type durationValidator struct {
+	positive bool
+}
+
+// isDuration returns a validator which ensures that any configured string
+// value is a Go duration such as "30s" or "1h30m".
+func isDuration() durationValidator {
+	return durationValidator{}
+}
+
+// isPositiveDuration returns a validator which ensures that any configured
+// string value is a Go duration greater than zero, e.g. a time to live.
+func isPositiveDuration() durationValidator {
+	return durationValidator{positive: true}
+}
+
+func (v durationValidator) Description(ctx context.Context) string {
+	if v.positive {
+		return "value must be a positive duration such as \"30s\" or \"1h30m\""
+	}
+	return "value must be a duration such as \"30s\" or \"1h30m\""
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	duration, err := time.ParseDuration(req.ConfigValue.ValueString())
+	if err == nil && v.positive && duration <= 0 {
+		err = fmt.Errorf("the duration is %s", duration)
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid duration", "The "+req.Path.String()+" "+v.Description(ctx)+": "+err.Error())
+	}
+}
+
+// rfc3339Validator validates that a string parses as an RFC 3339 timestamp.
+type rfc3339Validator struct{}
+
+// isRFC3339 returns a validator which ensures that any configured string
+// value is an RFC 3339 timestamp such as "2030-01-02T15:04:05Z".
+func isRFC3339() rfc3339Validator {
+	return rfc3339Validator{}
+}
+
+func (v rfc3339Validator) Description(ctx context.Context) string {
+	return "value must be an RFC 3339 timestamp such as \"2030-01-02T15:04:05Z\""
+}
+
+func (v rfc3339Validator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v rfc3339Validator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := time.Parse(time.RFC3339, req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid timestamp", "The "+req.Path.String()+" "+v.Description(ctx)+": "+err.Error())
+	}
+}
+
+// keyValidator validates that a string is an nkey of a given kind that can be
+// used for a given role, or that is of a given type.
+type keyValidator struct {
+	kind    string
+	role    keyRole
+	keyType string
+}
+
+// isPublicKeyFor returns a validator which ensures that any configured string
+// value is a public key that can be used for role, e.g. a curve key for
+// keyRoleSealing.
+func isPublicKeyFor(role keyRole) keyValidator {
+	return keyValidator{kind: keyKindPublic, role: role}
+}
+
+// isSeedFor returns a validator which ensures that any configured string value
+// is a seed that can be used for role, e.g. any but a curve seed for
+// keyRoleSigning. Errors never include the seed itself.
+func isSeedFor(role keyRole) keyValidator {
+	return keyValidator{kind: keyKindSeed, role: role}
+}
+
+// isPublicKeyOfType returns a validator which ensures that any configured
+// string value is a public key of keyType, e.g. the operator key a JWT is
+// issued for.
+func isPublicKeyOfType(keyType string) keyValidator {
+	return keyValidator{kind: keyKindPublic, keyType: keyType}
+}
+
+// isSeedOfType returns a validator which ensures that any configured string
+// value is a seed of keyType, e.g. the operator seed a JWT is signed with.
+// Errors never include the seed itself.
+func isSeedOfType(keyType string) keyValidator {
+	return keyValidator{kind: keyKindSeed, keyType: keyType}
+}
+
+func (v keyValidator) Description(ctx context.Context) string {
+	if v.keyType != "" {
+		return "value must be an nkey " + v.kindName() + " of type " + v.keyType
+	}
+	if v.role == keyRoleSealing {
+		return "value must be a curve nkey " + v.kindName()
+	}
+	return "value must be an nkey " + v.kindName() + " of a type that can sign, which is any but curve"
+}
+
+// kindName returns the name of the kind of key in messages.
+func (v keyValidator) kindName() string {
+	if v.kind == keyKindPublic {
+		return "public key"
+	}
+	return v.kind
+}
+
+func (v keyValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v keyValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	parsed, err := parseKey(req.ConfigValue.ValueString())
+	if err == nil && parsed.kind != v.kind {
+		err = fmt.Errorf("the value is not a %s", v.kindName())
+	}
+	switch {
+	case err != nil:
+	case v.keyType == "":
+		err = parsed.checkRole(v.role)
+	case parsed.keyType != v.keyType && parsed.keyType == "curve":
+		// Every type but curve signs, so explain why curve keys never fit
+		err = errCurveCannotSign
+	case parsed.keyType != v.keyType:
+		err = fmt.Errorf("the key is of type %s", parsed.keyType)
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid key", "The "+req.Path.String()+" "+v.Description(ctx)+": "+err.Error())
+	}
+}
+
+// urlValidator validates that a string is an absolute URL with one of the
+// given schemes.
+type urlValidator struct {
+	schemes []string
+}
+
+// isURL returns a validator which ensures that any configured string value is
+// an absolute URL with a host and one of schemes, or any scheme when none are
+// given.
+func isURL(schemes ...string) urlValidator {
+	return urlValidator{schemes: schemes}
+}
+
+func (v urlValidator) Description(ctx context.Context) string {
+	if len(v.schemes) == 0 {
+		return "value must be an absolute URL such as \"https://host:port/path\""
+	}
+	return "value must be a URL with scheme " + strings.Join(v.schemes, " or ") + " such as \"" + v.schemes[0] + "://host:port\""
+}
+
+func (v urlValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v urlValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	u, err := url.Parse(req.ConfigValue.ValueString())
+	switch {
+	case err != nil:
+	case u.Scheme == "":
+		err = errors.New("the URL has no scheme")
+	case len(v.schemes) > 0 && !slices.Contains(v.schemes, strings.ToLower(u.Scheme)):
+		err = fmt.Errorf("the scheme is %s", u.Scheme)
+	case u.Host == "":
+		err = errors.New("the URL has no host")
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid URL", "The "+req.Path.String()+" "+v.Description(ctx)+": "+err.Error())
+	}
+}
+
+// subjectValidator validates that a string is a NATS subject, optionally
+// without wildcards or followed by a queue group.
+type subjectValidator struct {
+	literal bool
+	queue   bool
+}
+
+// isSubject returns a validator which ensures that any configured string value
+// is a NATS subject such as "orders.>", wildcards included.
+func isSubject() subjectValidator {
+	return subjectValidator{}
+}
+
+// isLiteralSubject returns a validator which ensures that any configured
+// string value is a NATS subject without wildcards, e.g. a subject to publish
+// to.
+func isLiteralSubject() subjectValidator {
+	return subjectValidator{literal: true}
+}
+
+// isSubscribeSubject returns a validator which ensures that any configured
+// string value is a NATS subject, optionally followed by a queue group as in
+// the subscribe permissions "orders.> workers".
+func isSubscribeSubject() subjectValidator {
+	return subjectValidator{queue: true}
+}
+
+func (v subjectValidator) Description(ctx context.Context) string {
+	if v.queue {
+		return "value must be a NATS subject such as \"orders.>\", optionally followed by a queue group such as \"orders.> workers\""
+	}
+	if v.literal {
+		return "value must be a NATS subject without wildcards such as \"orders.latency\""
+	}
+	return "value must be a NATS subject such as \"orders.*\" or \"orders.>\""
+}
+
+func (v subjectValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v subjectValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	vr := jwt.ValidationResults{}
+	// The jwt library only rejects spaces, while nats-server rejects any
+	// whitespace that separates the arguments of its protocol
+	if strings.ContainsAny(value, "\t\n\r\f") {
+		vr.AddError("subject %q cannot contain whitespace", value)
+	}
+	if queueSubject, queue, ok := strings.Cut(value, " "); ok && v.queue {
+		value = queueSubject
+		jwt.Subject(queue).Validate(&vr)
+	}
+	subject := jwt.Subject(value)
+	subject.Validate(&vr)
+	// The jwt library leaves the position of > to nats-server, which only
+	// matches it as the last token
+	tokens := strings.Split(value, ".")
+	if i := slices.Index(tokens, ">"); i >= 0 && i < len(tokens)-1 {
+		vr.AddError("subject %q can only have `>` as its last token", subject)
+	}
+	if v.literal && subject.HasWildCards() {
+		vr.AddError("subject %q cannot contain wildcards", subject)
+	}
+	for _, issue := range vr.Errors() {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid subject", "The "+req.Path.String()+" "+v.Description(ctx)+": "+issue.Error())
+	}
+}
+
+// networkValidator validates that a string is an IPv4 or IPv6 network in CIDR
+// notation, or a single address.
+type networkValidator struct{}
+
+// isNetwork returns a validator which ensures that any configured string value
+// is a network such as "10.0.0.0/8" or "2001:db8::/32", or an address such as
+// "10.0.0.1" that stands for its own network.
+func isNetwork() networkValidator {
+	return networkValidator{}
+}
+
+func (v networkValidator) Description(ctx context.Context) string {
+	return "value must be a network in CIDR notation such as \"10.0.0.0/8\" or \"2001:db8::/32\", or an IP address"
+}
+
+func (v networkValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v networkValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := parseNetwork(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid network", "The "+req.Path.String()+" "+v.Description(ctx)+": "+err.Error())
+	}
+}
+
+// parseNetwork returns the network of a CIDR or of a single address, which is
+// a /32 for IPv4 and a /128 for IPv6, with the host bits of the address
+// cleared so that equal networks are equal strings.
+func parseNetwork(network string) (netip.Prefix, error) {
+	if !strings.Contains(network, "/") {
+		addr, err := netip.ParseAddr(network)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if addr.Zone() != "" {
+			return netip.Prefix{}, fmt.Errorf("the address %q has a zone", network)
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(network)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return prefix.Masked(), nil
+}
+
+// timeOfDayFormat is the layout of the times of day of usage windows, which
+// nats-server parses with it.
+const timeOfDayFormat = "15:04:05"
+
+// timeOfDayPattern matches the two digits of each field of a time of day,
+// which time.Parse does not require.
+var timeOfDayPattern = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}$`)
+
+// timeOfDayValidator validates that a string is a time of day in the format of
+// usage windows.
+type timeOfDayValidator struct{}
+
+// isTimeOfDay returns a validator which ensures that any configured string
+// value is a time of day such as "09:00:00", in the HH:MM:SS format of
+// nats-server.
+func isTimeOfDay() timeOfDayValidator {
+	return timeOfDayValidator{}
+}
+
+func (v timeOfDayValidator) Description(ctx context.Context) string {
+	return "value must be a time of day in the 24-hour format HH:MM:SS such as \"09:00:00\""
+}
+
+func (v timeOfDayValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v timeOfDayValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	var err error
+	if !timeOfDayPattern.MatchString(value) {
+		err = fmt.Errorf("%q is not two digits each of hours, minutes and seconds", value)
+	} else {
+		_, err = time.Parse(timeOfDayFormat, value)
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid time of day", "The "+req.Path.String()+" "+v.Description(ctx)+": "+err.Error())
+	}
+}
+
+// timeZoneValidator validates that a string is the name of an IANA time zone.
+type timeZoneValidator struct{}
+
+// isTimeZone returns a validator which ensures that any configured string
+// value is the name of an IANA time zone such as "Europe/Madrid", which
+// nats-server loads with time.LoadLocation.
+func isTimeZone() timeZoneValidator {
+	return timeZoneValidator{}
+}
+
+func (v timeZoneValidator) Description(ctx context.Context) string {
+	return "value must be an IANA time zone such as \"Europe/Madrid\" or \"UTC\""
+}
+
+func (v timeZoneValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v timeZoneValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	var err error
+	switch value {
+	case "", "Local":
+		// Both load the local time zone of the host, which differs between
+		// Terraform and nats-server
+		err = fmt.Errorf("%q is the local time zone of the host, leave the time zone unset for the one of nats-server", value)
+	default:
+		_, err = time.LoadLocation(value)
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid time zone", "The "+req.Path.String()+" "+v.Description(ctx)+": "+err.Error())
+	}
+}
+
+// jsonObjectValidator validates that a string is a JSON object.
+type jsonObjectValidator struct{}
+
+// isJSONObject returns a validator which ensures that any configured string
+// value is a JSON object, rather than an array or a scalar.
+func isJSONObject() jsonObjectValidator {
+	return jsonObjectValidator{}
+}
+
+func (v jsonObjectValidator) Description(ctx context.Context) string {
+	return "value must be a JSON object"
+}
+
+func (v jsonObjectValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v jsonObjectValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	var value interface{}
+	err := json.Unmarshal([]byte(req.ConfigValue.ValueString()), &value)
+	if _, ok := value.(map[string]interface{}); err == nil && !ok {
+		err = fmt.Errorf("the value is a JSON %s", jsonKind(value))
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid JSON", "The "+req.Path.String()+" "+v.Description(ctx)+": "+err.Error())
+	}
+}
+
+// jsonKind returns the name of the kind of a decoded JSON value in messages.
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
@@ -0,0 +1,206 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// The test account, curve and user keys.
+const (
+	testAccountSeed      = "SAAG5IEQRGLYWNEYK53KPH4XWI7TCXJDIXP7THHMCUHYSNAXXE6QVLGKXM"
+	testAccountPublicKey = "ADHYBT62NEJ25RXPDYVGAISNJAP3NRRBK5DLHOMQSVCKJ62GYMWBYLXF"
+	testCurveSeed        = "SXAG5IEQRGLYWNEYK53KPH4XWI7TCXJDIXP7THHMCUHYSNAXXE6QVLB22I"
+	testCurvePublicKey   = "XBNHJLFBPML4T6EDIOOZRPNCOZ6ARG5ACGJ2PUFBJHTJPKYURCQUVN2C"
+	testUserSeed         = "SUADZAA7BCUPJYTYHIYS5WCM74ONODEVTMLFVJKXXJDB2ZEHXXBQERYMPQ"
+	testUserPublicKey    = "UBQZX3JGNLK4AFEBVVLUJ4QVERLUES2PKJTHJGSIPVRK3ZXYNVFV7XAC"
+)
+
+// The operator of the JWT tests, which signs the test account, and a plain
+// and a scoped signing key of the test account.
+const (
+	testOperatorSeed            = "SOAK2WDN62OZEIYKL2NWGYJAKB7XXT3BRASXGODDVHT3BAHQMR467DYPDY"
+	testOperatorPublicKey       = "OCSUWUIKFIM6UJU3IXYL6WMG2DUL6EGC5CRVFHU2ICZYAQRK7PV3E5EK"
+	testAccountSigningSeed      = "SAAGCLQG5XKMMDFEP5RDUSDIWDRRKJ63YNNJCOVYM6P4ACEURQ5RASMGIY"
+	testAccountSigningPublicKey = "AB6PX5NJFO3OKCZ6ADYYZC3W4Z6ZUL7OLFINFFJYQCS25K7BTVXHS5LZ"
+	testAccountScopedSeed       = "SAAOU63BD6VGM5KRMGHQC6IBGGNKVS4VSCHHWHGLV5HUNKS7Y3UQYRN53M"
+	testAccountScopedPublicKey  = "AAXLAKK3ZEL72XXQZ4TSIZYWDO7QWN27EXHJU5YKBIL3FHLNS5T467AO"
+)
+
+func TestKeyValidator(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator validator.String
+		value     string
+		wantErr   string
+	}{
+		{name: "signing seed", validator: isSeedFor(keyRoleSigning), value: testAccountSeed},
+		{name: "curve signing seed", validator: isSeedFor(keyRoleSigning), value: testCurveSeed, wantErr: errCurveCannotSign.Error()},
+		{name: "curve account seed", validator: isSeedOfType("account"), value: testCurveSeed, wantErr: errCurveCannotSign.Error()},
+		{name: "curve operator seed", validator: isSeedOfType("operator"), value: testCurveSeed, wantErr: errCurveCannotSign.Error()},
+		{name: "user seed for an account", validator: isSeedOfType("account"), value: testUserSeed, wantErr: "the key is of type user"},
+		{name: "public key for a seed", validator: isSeedFor(keyRoleSigning), value: testAccountPublicKey, wantErr: "the value is not a seed"},
+		{name: "sealing seed", validator: isSeedFor(keyRoleSealing), value: testCurveSeed},
+		{name: "account sealing seed", validator: isSeedFor(keyRoleSealing), value: testAccountSeed, wantErr: "value must be a curve nkey seed"},
+		{name: "sealing public key", validator: isPublicKeyFor(keyRoleSealing), value: testCurvePublicKey},
+		{name: "curve signing public key", validator: isPublicKeyFor(keyRoleSigning), value: testCurvePublicKey, wantErr: errCurveCannotSign.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validator.StringRequest{Path: path.Root("seed"), ConfigValue: types.StringValue(tt.value)}
+			var resp validator.StringResponse
+			tt.validator.ValidateString(context.Background(), req, &resp)
+			if tt.wantErr == "" {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("ValidateString() diagnostics = %v", resp.Diagnostics)
+				}
+				return
+			}
+			if resp.Diagnostics.ErrorsCount() != 1 {
+				t.Fatalf("ValidateString() diagnostics = %v, want an error", resp.Diagnostics)
+			}
+			detail := resp.Diagnostics[0].Detail()
+			if !strings.Contains(detail, tt.wantErr) {
+				t.Errorf("error = %q, want %q", detail, tt.wantErr)
+			}
+			if strings.Contains(detail, tt.value) && strings.HasPrefix(tt.value, "S") {
+				t.Errorf("error %q includes the seed", detail)
+			}
+		})
+	}
+}
+
+func TestSubjectValidator(t *testing.T) {
+	tests := []struct {
+		validator validator.String
+		value     string
+		wantErr   bool
+	}{
+		{validator: isSubject(), value: "orders"},
+		{validator: isSubject(), value: "orders.*.new"},
+		{validator: isSubject(), value: "orders.>"},
+		{validator: isSubject(), value: ">"},
+		{validator: isSubject(), value: "*"},
+		{validator: isSubject(), value: "_INBOX.>"},
+		{validator: isSubject(), value: "", wantErr: true},
+		{validator: isSubject(), value: "orders.>.new", wantErr: true},
+		{validator: isSubject(), value: ">.orders", wantErr: true},
+		{validator: isSubject(), value: "orders new", wantErr: true},
+		{validator: isSubject(), value: "orders.\tnew", wantErr: true},
+		{validator: isSubject(), value: "orders..new", wantErr: true},
+		{validator: isSubject(), value: ".orders", wantErr: true},
+		{validator: isSubject(), value: "orders.", wantErr: true},
+		// Wildcards are only wildcards as whole tokens
+		{validator: isSubject(), value: "orders.n*w"},
+		{validator: isSubject(), value: "orders.>>"},
+		// Queue groups are only part of subscribe permissions
+		{validator: isSubscribeSubject(), value: "orders.> workers"},
+		{validator: isSubscribeSubject(), value: "orders.*"},
+		{validator: isSubscribeSubject(), value: "orders.>.new workers", wantErr: true},
+		{validator: isSubject(), value: "orders.> workers", wantErr: true},
+		{validator: isLiteralSubject(), value: "trace.billing"},
+		{validator: isLiteralSubject(), value: "trace.*", wantErr: true},
+		{validator: isLiteralSubject(), value: "trace.>", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.validator.Description(context.Background())+" "+tt.value, func(t *testing.T) {
+			req := validator.StringRequest{Path: path.Root("allow"), ConfigValue: types.StringValue(tt.value)}
+			var resp validator.StringResponse
+			tt.validator.ValidateString(context.Background(), req, &resp)
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Errorf("ValidateString(%q) diagnostics = %v, want an error: %v", tt.value, resp.Diagnostics, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCurveSeedCannotSign(t *testing.T) {
+	unitTest(t, testCase{
+		SkipBelow: tfVersion1_11,
+		Steps: []testStep{
+			{
+				Config: `
+resource "nkey_user_jwt" "test" {
+  subject      = "` + testUserPublicKey + `"
+  signing_seed = "` + testCurveSeed + `"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`curve keys are x25519 keys for sealing payloads and cannot sign JWTs`),
+			},
+			{
+				Config: `
+ephemeral "nkey_signature" "test" {
+  seed    = "` + testCurveSeed + `"
+  payload = "nonce"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`The seed value must be an nkey seed of a type that can sign, which is any but curve: curve keys are x25519 keys`),
+			},
+		},
+	})
+}
+
+func TestParseNetwork(t *testing.T) {
+	tests := []struct {
+		network string
+		want    string
+		wantErr bool
+	}{
+		{network: "10.0.0.0/8", want: "10.0.0.0/8"},
+		{network: "10.0.0.1", want: "10.0.0.1/32"},
+		{network: "10.0.0.1/32", want: "10.0.0.1/32"},
+		{network: "0.0.0.0/0", want: "0.0.0.0/0"},
+		// Host bits are cleared, as nats-server ignores them
+		{network: "192.168.1.7/24", want: "192.168.1.0/24"},
+		{network: "2001:db8::/32", want: "2001:db8::/32"},
+		{network: "2001:db8::1", want: "2001:db8::1/128"},
+		{network: "2001:DB8:0:0::1/128", want: "2001:db8::1/128"},
+		{network: "2001:db8::1/64", want: "2001:db8::/64"},
+		{network: "::/0", want: "::/0"},
+		{network: "::ffff:10.0.0.1", want: "::ffff:10.0.0.1/128"},
+		{network: "", wantErr: true},
+		{network: "not a network", wantErr: true},
+		{network: "10.0.0", wantErr: true},
+		{network: "10.0.0.256", wantErr: true},
+		{network: "010.0.0.1", wantErr: true},
+		{network: "10.0.0.1/33", wantErr: true},
+		{network: "10.0.0.1/", wantErr: true},
+		{network: "10.0.0.0/-1", wantErr: true},
+		{network: " 10.0.0.1", wantErr: true},
+		{network: "2001:db8::1/129", wantErr: true},
+		{network: "2001:db8:::1", wantErr: true},
+		{network: "fe80::1%eth0", wantErr: true},
+		{network: "fe80::1%eth0/64", wantErr: true},
+		{network: "example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			got, err := parseNetwork(tt.network)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseNetwork(%q) = %s, want an error", tt.network, got)
+				}
+				req := validator.StringRequest{Path: path.Root("source_networks"), ConfigValue: types.StringValue(tt.network)}
+				var resp validator.StringResponse
+				isNetwork().ValidateString(context.Background(), req, &resp)
+				if !resp.Diagnostics.HasError() {
+					t.Errorf("isNetwork() accepts %q", tt.network)
+				}
+				return
+			}
+			if err != nil || got.String() != tt.want {
+				t.Fatalf("parseNetwork(%q) = %s, %v, want %s", tt.network, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	ephemeralschema "github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/nats-io/nkeys"
+)
+
+// defaultVanityTimeout bounds the vanity key search when no timeout is configured.
+const defaultVanityTimeout = time.Minute
+
+// vanityPrefixRegexp matches the vanity prefixes a public key can start with.
+// The character after the type character only carries two bits of the key,
+// so it is always one of A-D.
+var vanityPrefixRegexp = regexp.MustCompile(`^[A-D][A-Z2-7]*$`)
+
+// VanityModel describes the vanity key attributes shared by the nkey resources.
+type VanityModel struct {
+	VanityPrefix  types.String `tfsdk:"vanity_prefix"`
+	VanityWorkers types.Int64  `tfsdk:"vanity_workers"`
+	VanityTimeout types.String `tfsdk:"vanity_timeout"`
+}
+
+// hasVanityPrefix reports whether a vanity prefix is configured.
+func (v VanityModel) hasVanityPrefix() bool {
+	return !v.VanityPrefix.IsNull() && v.VanityPrefix.ValueString() != ""
+}
+
+// generateVanityKeys searches for a key pair of the type configured in m whose
+// public key starts with the vanity prefix right after the type character.
+// The search stops when ctx is cancelled or the vanity timeout expires.
+func (v VanityModel) generateVanityKeys(ctx context.Context, m *KeyModel) error {
+	prefix, err := keyTypePrefix(m.KeyType.ValueString())
+	if err != nil {
+		return err
+	}
+
+	timeout := defaultVanityTimeout
+	if !v.VanityTimeout.IsNull() {
+		if timeout, err = time.ParseDuration(v.VanityTimeout.ValueString()); err != nil {
+			return err
+		}
+	}
+	workers := runtime.GOMAXPROCS(0)
+	if !v.VanityWorkers.IsNull() {
+		workers = int(v.VanityWorkers.ValueInt64())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	keys, err := searchVanityKey(ctx, prefix, v.VanityPrefix.ValueString(), workers)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("no public key with vanity prefix %q found within %s, use a shorter vanity_prefix or a longer vanity_timeout", v.VanityPrefix.ValueString(), timeout)
+	}
+	if err != nil {
+		return err
+	}
+	defer keys.Wipe()
+
+	return m.setKeys(keys)
+}
+
+// searchVanityKey creates key pairs on workers goroutines until one has a
+// public key starting with vanity after the type character. All goroutines
+// have stopped by the time it returns.
+func searchVanityKey(ctx context.Context, prefix nkeys.PrefixByte, vanity string, workers int) (nkeys.KeyPair, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	found := make(chan nkeys.KeyPair, workers)
+	failed := make(chan error, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				keys, err := nkeys.CreatePair(prefix)
+				if err != nil {
+					failed <- err
+					return
+				}
+				pubKey, err := keys.PublicKey()
+				if err != nil {
+					failed <- err
+					return
+				}
+				if strings.HasPrefix(pubKey[1:], vanity) {
+					found <- keys
+					return
+				}
+				keys.Wipe()
+			}
+		}()
+	}
+
+	var keys nkeys.KeyPair
+	var err error
+	select {
+	case keys = <-found:
+	case err = <-failed:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	cancel()
+	wg.Wait()
+
+	// Other workers may have found a key at the same time
+	close(found)
+	for extra := range found {
+		extra.Wipe()
+	}
+	return keys, err
+}
+
+// vanityPrefixValidators validates the vanity_prefix attribute.
+var vanityPrefixValidators = []validator.String{
+	stringvalidator.RegexMatches(vanityPrefixRegexp, "must only contain base32 characters (A-Z, 2-7) and start with one of A-D, since the character after the type character only carries two bits of the key"),
+}
+
+const (
+	vanityPrefixDescription  = "Generate a key whose public key continues with this prefix right after the type character, e.g. `ACME` for an account key `AACME...`. Each extra character makes the search about 32 times longer"
+	vanityWorkersDescription = "Number of goroutines searching for a vanity key. Defaults to the number of CPUs"
+	vanityTimeoutDescription = "Maximum duration of the vanity key search, e.g. `5m`. Defaults to `1m`"
+)
+
+// vanityResourceAttributes adds the VanityModel attributes to the schema
+// attributes of a managed nkey resource.
+func vanityResourceAttributes(attrs map[string]schema.Attribute) map[string]schema.Attribute {
+	attrs["vanity_prefix"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: vanityPrefixDescription,
+		Validators:          vanityPrefixValidators,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
+	attrs["vanity_workers"] = schema.Int64Attribute{
+		Optional:            true,
+		MarkdownDescription: vanityWorkersDescription,
+		Validators: []validator.Int64{
+			int64validator.AtLeast(1),
+		},
+	}
+	attrs["vanity_timeout"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: vanityTimeoutDescription,
+		Validators: []validator.String{
+			isDuration(),
+		},
+	}
+	return attrs
+}
+
+// vanityEphemeralAttributes adds the VanityModel attributes to the schema
+// attributes of an ephemeral nkey resource.
+func vanityEphemeralAttributes(attrs map[string]ephemeralschema.Attribute) map[string]ephemeralschema.Attribute {
+	attrs["vanity_prefix"] = ephemeralschema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: vanityPrefixDescription,
+		Validators:          vanityPrefixValidators,
+	}
+	attrs["vanity_workers"] = ephemeralschema.Int64Attribute{
+		Optional:            true,
+		MarkdownDescription: vanityWorkersDescription,
+		Validators: []validator.Int64{
+			int64validator.AtLeast(1),
+		},
+	}
+	attrs["vanity_timeout"] = ephemeralschema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: vanityTimeoutDescription,
+		Validators: []validator.String{
+			isDuration(),
+		},
+	}
+	return attrs
+}
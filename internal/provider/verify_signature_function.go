@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &VerifySignatureFunction{}
+
+func NewVerifySignatureFunction() function.Function {
+	return &VerifySignatureFunction{}
+}
+
+// VerifySignatureFunction defines the function implementation.
+type VerifySignatureFunction struct {
+}
+
+func (f *VerifySignatureFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "verify_signature"
+}
+
+func (f *VerifySignatureFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Verify an ed25519 signature made with an nkey",
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Returns whether `signature_base64` is a valid signature of the payload by the seed of `public_key`, e.g. one made by the `nkey_signature` ephemeral resource. A wrong signature returns false, while a malformed public key or input that is not base64 is an error, so preconditions can tell a bad signature from bad input.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "public_key",
+				MarkdownDescription: "Public key of the signer. Curve keys cannot sign, so they are an error",
+			},
+			function.StringParameter{
+				Name:                "payload_base64",
+				MarkdownDescription: "Base64 encoded signed payload, e.g. from `base64encode()`",
+			},
+			function.StringParameter{
+				Name:                "signature_base64",
+				MarkdownDescription: "Base64 encoded ed25519 signature",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *VerifySignatureFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var publicKey, payloadBase64, signatureBase64 string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &publicKey, &payloadBase64, &signatureBase64))
+	if resp.Error != nil {
+		return
+	}
+
+	parsed, err := parseKey(publicKey)
+	if err == nil && parsed.kind != keyKindPublic {
+		err = errInvalidPublicKey
+	}
+	if err == nil {
+		err = parsed.checkRole(keyRoleSigning)
+	}
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	payload, err := base64.StdEncoding.DecodeString(payloadBase64)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, "the payload is not valid base64: "+err.Error())
+		return
+	}
+	signature, err := base64.StdEncoding.DecodeString(signatureBase64)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(2, "the signature is not valid base64: "+err.Error())
+		return
+	}
+
+	keys, err := nkeys.FromPublicKey(publicKey)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	err = keys.Verify(payload, signature)
+	if err != nil && !errors.Is(err, nkeys.ErrInvalidSignature) {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, err == nil))
+}
@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestVerifySignatureFunction(t *testing.T) {
+	// Signatures of the test seeds, which `nats` and `nsc` make the same as
+	// the nkeys package they sign with, ed25519 signatures being deterministic
+	const (
+		helloBase64           = "aGVsbG8="
+		userHelloSignature    = "yY7zfRJTaYIrH5i/NngGnnTnIKxPbLRe8SwFE/Wv8QjbfoVGTffoe8IXadPi9ffMv2Lvk4AQCeci123u5T0ADg=="
+		userEmptySignature    = "4dGQRH7kSN6mZFhcB5Wchtq2jYBOiD+y7hVFvIxBgudc+OIGSZaHrMTfKu7adYcMod6OpTqXXJVpmwJDDbw8Dw=="
+		accountHelloSignature = "iUbQ0vY0ciPvC5C1hoA0gWF+XEoZyITL8RYVeu9b2plz8PTIPK2UD2w9ZMULl8CnHLqtIgrA+iiEwE7+siTXAw=="
+	)
+
+	tests := []struct {
+		name      string
+		publicKey string
+		payload   string
+		signature string
+		want      bool
+		wantErr   bool
+		// argument is the argument the error is reported on
+		argument int64
+	}{
+		{name: "user", publicKey: testUserPublicKey, payload: helloBase64, signature: userHelloSignature, want: true},
+		{name: "empty payload", publicKey: testUserPublicKey, payload: "", signature: userEmptySignature, want: true},
+		{name: "account", publicKey: testAccountPublicKey, payload: helloBase64, signature: accountHelloSignature, want: true},
+		{name: "other payload", publicKey: testUserPublicKey, payload: "aGVsbG8h", signature: userHelloSignature},
+		{name: "other signer", publicKey: testUserPublicKey, payload: helloBase64, signature: accountHelloSignature},
+		{name: "other key", publicKey: testAccountPublicKey, payload: helloBase64, signature: userHelloSignature},
+		{name: "short signature", publicKey: testUserPublicKey, payload: helloBase64, signature: "yY7zfRJTaYIr"},
+		{name: "bad checksum", publicKey: testUserPublicKey[:55] + "D", payload: helloBase64, signature: userHelloSignature, wantErr: true},
+		{name: "not a key", publicKey: "not a key", payload: helloBase64, signature: userHelloSignature, wantErr: true},
+		{name: "seed", publicKey: testUserSeed, payload: helloBase64, signature: userHelloSignature, wantErr: true},
+		{name: "curve key", publicKey: testCurvePublicKey, payload: helloBase64, signature: userHelloSignature, wantErr: true},
+		{name: "payload not base64", publicKey: testUserPublicKey, payload: "hello!", signature: userHelloSignature, wantErr: true, argument: 1},
+		{name: "payload URL base64", publicKey: testUserPublicKey, payload: "aGVsbG8", signature: userHelloSignature, wantErr: true, argument: 1},
+		{name: "signature not base64", publicKey: testUserPublicKey, payload: helloBase64, signature: "not base64!", wantErr: true, argument: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, funcErr := runFunction(t, &VerifySignatureFunction{},
+				types.StringValue(tt.publicKey), types.StringValue(tt.payload), types.StringValue(tt.signature))
+			if tt.wantErr {
+				if funcErr == nil || funcErr.FunctionArgument == nil || *funcErr.FunctionArgument != tt.argument {
+					t.Fatalf("verify_signature() = %v, %v, want an error on argument %d", got, funcErr, tt.argument)
+				}
+				return
+			}
+			if funcErr != nil || !got.Equal(types.BoolValue(tt.want)) {
+				t.Fatalf("verify_signature() = %v, %v, want %v", got, funcErr, tt.want)
+			}
+		})
+	}
+}
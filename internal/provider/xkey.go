@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/base64"
+	"errors"
+
+	"github.com/nats-io/nkeys"
+)
+
+// The inputs of openXkey, in the order of the open_xkey function parameters.
+const (
+	xkeyArgCiphertext = iota
+	xkeyArgRecipientSeed
+	xkeyArgSenderPublicKey
+)
+
+// xkeyOpenError is an error of openXkey together with the input it is about.
+type xkeyOpenError struct {
+	arg int
+	err error
+}
+
+func (e *xkeyOpenError) Error() string {
+	return e.err.Error()
+}
+
+func (e *xkeyOpenError) Unwrap() error {
+	return e.err
+}
+
+// openXkey decrypts a base64 encoded payload sealed by the nkeys package to
+// the curve key of recipientSeed by senderPublicKey. Errors are of type
+// *xkeyOpenError and never include the seed or the plaintext.
+func openXkey(ciphertextBase64, recipientSeed, senderPublicKey string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertextBase64)
+	if err != nil {
+		return nil, &xkeyOpenError{xkeyArgCiphertext, errors.New("the ciphertext is not valid base64, check for stray characters or truncation")}
+	}
+	return openXkeyPayload(sealed, recipientSeed, senderPublicKey)
+}
+
+// openXkeyPayload decrypts a payload sealed by the nkeys package, like
+// openXkey.
+func openXkeyPayload(sealed []byte, recipientSeed, senderPublicKey string) ([]byte, error) {
+	recipient, err := parseKey(recipientSeed)
+	if err == nil && recipient.kind != keyKindSeed {
+		err = errNotASeed
+	}
+	if err == nil {
+		err = recipient.checkRole(keyRoleSealing)
+	}
+	if err != nil {
+		return nil, &xkeyOpenError{xkeyArgRecipientSeed, err}
+	}
+
+	sender, err := parseKey(senderPublicKey)
+	if err == nil && sender.kind != keyKindPublic {
+		err = errors.New("the value is not a public key")
+	}
+	if err == nil {
+		err = sender.checkRole(keyRoleSealing)
+	}
+	if err != nil {
+		return nil, &xkeyOpenError{xkeyArgSenderPublicKey, err}
+	}
+
+	keys, err := nkeys.FromCurveSeed([]byte(recipientSeed))
+	if err != nil {
+		return nil, &xkeyOpenError{xkeyArgRecipientSeed, seedError(err)}
+	}
+	defer keys.Wipe()
+
+	plaintext, err := keys.Open(sealed, senderPublicKey)
+	switch {
+	case errors.Is(err, nkeys.ErrInvalidEncrypted), errors.Is(err, nkeys.ErrInvalidEncVersion):
+		return nil, &xkeyOpenError{xkeyArgCiphertext, errors.New("the ciphertext is not a payload sealed by the nkeys package, it is truncated or of another format")}
+	case errors.Is(err, nkeys.ErrCouldNotDecrypt):
+		return nil, &xkeyOpenError{xkeyArgRecipientSeed, errors.New("the payload could not be decrypted: it was sealed to another recipient or by another sender than the sender public key, or it was altered")}
+	case err != nil:
+		return nil, &xkeyOpenError{xkeyArgCiphertext, err}
+	}
+	return plaintext, nil
+}
+
+// xkeyEnvelopeSenderLen is the length of the sender public key that starts a
+// sealed envelope.
+const xkeyEnvelopeSenderLen = 56
+
+// sealXkeyEnvelope seals plaintext to recipientPublicKey with a curve key
+// generated for this payload only. It returns the base64 encoded envelope: the
+// sender public key followed by the payload sealed in the format of the nkeys
+// package, so the recipient opens it with its own seed alone.
+func sealXkeyEnvelope(plaintext []byte, recipientPublicKey string) (string, error) {
+	sender, err := nkeys.CreateCurveKeys()
+	if err != nil {
+		return "", err
+	}
+	defer sender.Wipe()
+
+	senderPub, err := sender.PublicKey()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := sender.Seal(plaintext, recipientPublicKey)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(append([]byte(senderPub), sealed...)), nil
+}
+
+// openXkeyEnvelope decrypts an envelope made by sealXkeyEnvelope with the seed
+// of its recipient. Errors are those of openXkey, with the ones about the
+// sender public key turned into errors about the ciphertext.
+func openXkeyEnvelope(envelopeBase64, recipientSeed string) ([]byte, error) {
+	envelope, err := base64.StdEncoding.DecodeString(envelopeBase64)
+	if err != nil {
+		return nil, &xkeyOpenError{xkeyArgCiphertext, errors.New("the ciphertext is not valid base64, check for stray characters or truncation")}
+	}
+	if len(envelope) < xkeyEnvelopeSenderLen {
+		return nil, &xkeyOpenError{xkeyArgCiphertext, errors.New("the ciphertext is too short to be a sealed envelope, it is truncated or of another format")}
+	}
+
+	plaintext, err := openXkeyPayload(envelope[xkeyEnvelopeSenderLen:], recipientSeed, string(envelope[:xkeyEnvelopeSenderLen]))
+	var openErr *xkeyOpenError
+	if errors.As(err, &openErr) && openErr.arg == xkeyArgSenderPublicKey {
+		return nil, &xkeyOpenError{xkeyArgCiphertext, errors.New("the ciphertext does not start with a sender public key, so it is not a sealed envelope: " + openErr.err.Error())}
+	}
+	return plaintext, err
+}
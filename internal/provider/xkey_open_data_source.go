@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &XkeyOpenDataSource{}
+
+func NewXkeyOpenDataSource() datasource.DataSource {
+	return &XkeyOpenDataSource{}
+}
+
+// XkeyOpenDataSource defines the data source implementation.
+type XkeyOpenDataSource struct {
+}
+
+// XkeyOpenDataSourceModel describes the data source data model.
+type XkeyOpenDataSourceModel struct {
+	CiphertextBase64 types.String `tfsdk:"ciphertext_base64"`
+	RecipientSeed    types.String `tfsdk:"recipient_seed"`
+	SenderPublicKey  types.String `tfsdk:"sender_public_key"`
+	Plaintext        types.String `tfsdk:"plaintext"`
+}
+
+// xkeyOpenDataSourceArgs maps the inputs of openXkey to their attributes.
+var xkeyOpenDataSourceArgs = map[int]path.Path{
+	xkeyArgCiphertext:      path.Root("ciphertext_base64"),
+	xkeyArgRecipientSeed:   path.Root("recipient_seed"),
+	xkeyArgSenderPublicKey: path.Root("sender_public_key"),
+}
+
+func (d *XkeyOpenDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_xkey_open"
+}
+
+func (d *XkeyOpenDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Decrypts a payload sealed to a curve (xkey) key, like the `open_xkey` function but with a sensitive `plaintext`. The plaintext is stored in state, so prefer `nkey_xkey_seal` in the other direction and keep the state protected.",
+
+		Attributes: map[string]schema.Attribute{
+			"ciphertext_base64": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Base64 encoded sealed payload, or a sealed envelope such as `creds_sealed` when `sender_public_key` is omitted",
+			},
+			"recipient_seed": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Curve seed of the recipient, starting with `SX`",
+				Validators: []validator.String{
+					isSeedFor(keyRoleSealing),
+				},
+			},
+			"sender_public_key": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Curve public key of the sender, starting with `X`. When omitted the ciphertext is a sealed envelope, which starts with the sender public key",
+				Validators: []validator.String{
+					isPublicKeyFor(keyRoleSealing),
+				},
+			},
+			"plaintext": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The decrypted payload",
+			},
+		},
+	}
+}
+
+func (d *XkeyOpenDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data XkeyOpenDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plaintext []byte
+	var err error
+	if data.SenderPublicKey.IsNull() {
+		plaintext, err = openXkeyEnvelope(data.CiphertextBase64.ValueString(), data.RecipientSeed.ValueString())
+	} else {
+		plaintext, err = openXkey(data.CiphertextBase64.ValueString(), data.RecipientSeed.ValueString(), data.SenderPublicKey.ValueString())
+	}
+	if err != nil {
+		attr := path.Root("ciphertext_base64")
+		var openErr *xkeyOpenError
+		if errors.As(err, &openErr) {
+			attr = xkeyOpenDataSourceArgs[openErr.arg]
+		}
+		resp.Diagnostics.AddAttributeError(attr, "opening payload", err.Error())
+		return
+	}
+	defer wipe(plaintext)
+
+	data.Plaintext = types.StringValue(string(plaintext))
+	tflog.Trace(ctx, "read xkey open data source", map[string]interface{}{
+		"sender_public_key": data.SenderPublicKey.ValueString(),
+		"envelope":          data.SenderPublicKey.IsNull(),
+	})
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ ephemeral.EphemeralResource = &XkeySealEphemeral{}
+
+func NewXkeySealEphemeral() ephemeral.EphemeralResource {
+	return &XkeySealEphemeral{}
+}
+
+// XkeySealEphemeral defines the ephemeral resource implementation.
+type XkeySealEphemeral struct {
+}
+
+// XkeySealEphemeralModel describes the ephemeral resource data model.
+type XkeySealEphemeralModel struct {
+	RecipientPublicKey types.String `tfsdk:"recipient_public_key"`
+	Plaintext          types.String `tfsdk:"plaintext"`
+	Seed               types.String `tfsdk:"seed"`
+	CiphertextBase64   types.String `tfsdk:"ciphertext_base64"`
+	SenderPublicKey    types.String `tfsdk:"sender_public_key"`
+}
+
+func (r *XkeySealEphemeral) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_xkey_seal"
+}
+
+func (r *XkeySealEphemeral) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Seals a payload to a curve (xkey) public key, e.g. to hand a bootstrap secret to an edge node over NATS. The recipient opens it with its seed and the sender public key. Neither the plaintext nor the ciphertext is persisted to state.",
+
+		Attributes: map[string]schema.Attribute{
+			"recipient_public_key": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Curve public key to seal the payload to, starting with `X`",
+				Validators: []validator.String{
+					isPublicKeyFor(keyRoleSealing),
+				},
+			},
+			"plaintext": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Payload to seal",
+			},
+			"seed": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Curve seed of the sender, starting with `SX`. When omitted a curve key is generated for this payload only, so the recipient merely learns its public key",
+				Validators: []validator.String{
+					isSeedFor(keyRoleSealing),
+				},
+			},
+			"ciphertext_base64": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Base64 encoded sealed payload, in the format of the nkeys package",
+			},
+			"sender_public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Curve public key of the sender, which the recipient needs to open the payload",
+			},
+		},
+	}
+}
+
+func (r *XkeySealEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	ctx = redactSecrets(ctx)
+
+	var data XkeySealEphemeralModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var sender nkeys.KeyPair
+	var err error
+	if data.Seed.IsNull() {
+		sender, err = nkeys.CreateCurveKeys()
+	} else {
+		sender, err = nkeys.FromCurveSeed([]byte(data.Seed.ValueString()))
+		err = seedError(err)
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "sealing payload", err.Error())
+		return
+	}
+	defer sender.Wipe()
+
+	senderPub, err := sender.PublicKey()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "sealing payload", err.Error())
+		return
+	}
+	sealed, err := sender.Seal([]byte(data.Plaintext.ValueString()), data.RecipientPublicKey.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("recipient_public_key"), "sealing payload", err.Error())
+		return
+	}
+
+	data.CiphertextBase64 = types.StringValue(base64.StdEncoding.EncodeToString(sealed))
+	data.SenderPublicKey = types.StringValue(senderPub)
+	tflog.Trace(ctx, "opened ephemeral xkey seal resource", map[string]interface{}{
+		"recipient_public_key": data.RecipientPublicKey.ValueString(),
+		"sender_public_key":    senderPub,
+	})
+
+	// Save data into Terraform ephemeral result
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
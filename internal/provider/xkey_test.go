@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/nkeys"
+)
+
+func TestXkeyEnvelope(t *testing.T) {
+	plaintext := []byte("-----BEGIN NATS USER JWT-----")
+	envelope, err := sealXkeyEnvelope(plaintext, testCurvePublicKey)
+	if err != nil {
+		t.Fatalf("sealXkeyEnvelope() error = %v", err)
+	}
+
+	// The envelope starts with the public key of a curve key generated for
+	// the payload, followed by the payload sealed by the nkeys package
+	decoded, err := base64.StdEncoding.DecodeString(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := string(decoded[:xkeyEnvelopeSenderLen])
+	if !nkeys.IsValidPublicCurveKey(sender) || sender == testCurvePublicKey {
+		t.Fatalf("the envelope starts with %q, want a generated curve public key", sender)
+	}
+	recipient, err := nkeys.FromCurveSeed([]byte(testCurveSeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := recipient.Open(decoded[xkeyEnvelopeSenderLen:], sender)
+	if err != nil || string(opened) != string(plaintext) {
+		t.Fatalf("Open() = %q, %v, want %q", opened, err, plaintext)
+	}
+
+	opened, err = openXkeyEnvelope(envelope, testCurveSeed)
+	if err != nil || string(opened) != string(plaintext) {
+		t.Fatalf("openXkeyEnvelope() = %q, %v, want %q", opened, err, plaintext)
+	}
+	// Sealing again uses another sender key
+	if again, err := sealXkeyEnvelope(plaintext, testCurvePublicKey); err != nil || again == envelope {
+		t.Errorf("sealXkeyEnvelope() sealed the same envelope twice: %v", err)
+	}
+
+	other, err := nkeys.CreateCurveKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSeed, err := other.Seed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	altered := append([]byte(testCurvePublicKey), decoded[xkeyEnvelopeSenderLen:]...)
+	tests := []struct {
+		name     string
+		envelope string
+		seed     string
+		wantArg  int
+		wantErr  string
+	}{
+		{name: "other recipient", envelope: envelope, seed: string(otherSeed), wantArg: xkeyArgRecipientSeed, wantErr: "sealed to another recipient"},
+		{name: "other sender", envelope: base64.StdEncoding.EncodeToString(altered), seed: testCurveSeed, wantArg: xkeyArgRecipientSeed, wantErr: "could not be decrypted"},
+		{name: "no sender", envelope: base64.StdEncoding.EncodeToString(decoded[xkeyEnvelopeSenderLen:]), seed: testCurveSeed, wantArg: xkeyArgCiphertext, wantErr: "does not start with a sender public key"},
+		{name: "truncated", envelope: base64.StdEncoding.EncodeToString(decoded[:xkeyEnvelopeSenderLen-1]), seed: testCurveSeed, wantArg: xkeyArgCiphertext, wantErr: "too short"},
+		{name: "not base64", envelope: envelope[:len(envelope)-1] + "!", seed: testCurveSeed, wantArg: xkeyArgCiphertext, wantErr: "not valid base64"},
+		{name: "account seed", envelope: envelope, seed: testAccountSeed, wantArg: xkeyArgRecipientSeed, wantErr: "curve"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := openXkeyEnvelope(tt.envelope, tt.seed)
+			var openErr *xkeyOpenError
+			if !errors.As(err, &openErr) || openErr.arg != tt.wantArg || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("openXkeyEnvelope() error = %#v, want %q about input %d", err, tt.wantErr, tt.wantArg)
+			}
+			if strings.Contains(err.Error(), tt.seed) {
+				t.Errorf("error %q includes the seed", err)
+			}
+		})
+	}
+
+	if _, err := sealXkeyEnvelope(plaintext, testAccountPublicKey); err == nil {
+		t.Error("sealXkeyEnvelope() to an account public key succeeded")
+	}
+}